@@ -58,6 +58,8 @@ func GetSkeleton() *Config {
 		Eviction: &Eviction{},
 		Logging:  &Logging{},
 		Auth:     &Auth{},
+
+		Notifications: &Notifications{},
 	}
 }
 
@@ -25,6 +25,22 @@ const (
 	CompressionAlgoNone   string = "NONE" // no compression
 	CompressionAlgoLZ4    string = "LZ4"  // LZ4 compression
 
+	WALRecoveryModeStrict   string = "STRICT"   // a corrupt WAL entry before the end of the log fails the startup
+	WALRecoveryModeTolerant string = "TOLERANT" // corrupt WAL entries are skipped, and the bytes dropped reported
+
+	WALDurabilityNone          string = "none"            // writes are acknowledged once buffered, and written out in the background
+	WALDurabilityOSBuffered    string = "os-buffered"     // writes are acknowledged once handed to the OS, which syncs them in its own time
+	WALDurabilityFsyncPerBatch string = "fsync-per-batch" // writes are acknowledged once synced, the concurrent ones with a single fsync
+	WALDurabilityFsyncPerWrite string = "fsync-per-write" // writes are acknowledged once synced, each with an fsync of its own
+
+	ProtocolNative string = "NATIVE" // [value, code, message] triples
+	ProtocolRESP2  string = "RESP2"  // Redis compatible RESP2 replies
+	ProtocolRESP3  string = "RESP3"  // Redis compatible RESP3 replies
+
+	TLSClientAuthNone     string = "NONE"     // client certificates are not asked for
+	TLSClientAuthOptional string = "OPTIONAL" // client certificates are verified when given
+	TLSClientAuthRequired string = "REQUIRED" // client certificates are required and verified
+
 	// Config section names
 	SectionServer        string = "server"
 	SectionLogging       string = "logging"
@@ -32,6 +48,7 @@ const (
 	SectionStorage       string = "storage"
 	SectionAuth          string = "auth"
 	SectionEviction      string = "eviction"
+	SectionNotifications string = "notifications"
 	SectionStorageMemory string = "storage.memory"
 	SectionStorageLSM    string = "storage.lsm"
 
@@ -40,8 +57,17 @@ const (
 	DefultMaxClientConnections    int64  = 10000
 	DefaultConnectionWriteTimeout int64  = 10 // 10 seconds
 	DefaultRequestExecTimeout     int64  = 10 // 10 seconds
+	DefaultIdleConnectionTimeout  int64  = 0  // never
 	DefaultTLSCertFilePath        string = "/etc/universum/cert.pem"
 	DefaultTLSKeyFilePath         string = "/etc/universum/key.pem"
+	DefaultTLSClientAuth          string = TLSClientAuthNone
+	DefaultTLSReloadInterval      int64  = 60 // 60 seconds
+	DefaultUnixSocketPermissions  string = "0700"
+	DefaultPubSubClientBufferSize int64  = 1024
+	DefaultProtocol               string = ProtocolNative
+	DefaultMaxPipelineBatchSize   int64  = 128
+	DefaultHTTPGatewayPort        int64  = 11193
+	DefaultMetricsPort            int64  = 11194
 
 	// Section:Cluster
 	DefaultEnableCluster       bool  = false
@@ -62,18 +88,23 @@ const (
 	DefaultSnapshotCompressionAlgo string = "LZ4"
 
 	// Storage.LSM
-	DefaultMemtableStorageType     string  = MemtableStorageTypeLB
-	DefaultBlockCompressionAlgo    string  = CompressionAlgoLZ4
-	DefaultBloomFilterMaxRecords   int64   = 1000000 // 1 million
-	DefaultBloomFalsePositiveRate  float64 = 0.01    // 1%
-	DefaultDataStorageDirectory    string  = "/opt/universum/data"
-	DefaultWriteBlockSize          int64   = 65536            // 64 KB
-	DefaultWriteBufferSize         int64   = 64 * 1024 * 1024 // 64 MB
-	DefaultWriteAheadLogDirectory  string  = "/opt/universum/wal"
-	DefaultWriteAheadLogAsyncFlush bool    = false
-	DefaultWriteAheadLogBufferSize int64   = 1024 * 1024        // 1 MB
-	DefaultWriteAheadLogFrequency  int64   = 5                  // 5 seconds
-	DefaultBlockCacheMemoryLimit   int64   = 1024 * 1024 * 1024 // 1 GB
+	DefaultMemtableStorageType       string  = MemtableStorageTypeLB
+	DefaultBlockCompressionAlgo      string  = CompressionAlgoLZ4
+	DefaultBloomFilterMaxRecords     int64   = 1000000 // 1 million
+	DefaultBloomFalsePositiveRate    float64 = 0.01    // 1%
+	DefaultDataStorageDirectory      string  = "/opt/universum/data"
+	DefaultWriteBlockSize            int64   = 65536            // 64 KB
+	DefaultWriteBufferSize           int64   = 64 * 1024 * 1024 // 64 MB
+	DefaultWriteAheadLogDirectory    string  = "/opt/universum/wal"
+	DefaultWriteAheadLogAsyncFlush   bool    = false
+	DefaultWriteAheadLogBufferSize   int64   = 1024 * 1024        // 1 MB
+	DefaultWriteAheadLogFrequency    int64   = 5                  // 5 seconds
+	DefaultBlockCacheMemoryLimit     int64   = 1024 * 1024 * 1024 // 1 GB
+	DefaultCheckpointDirectory       string  = "/opt/universum/checkpoint"
+	DefaultAutoCheckpointFrequency   int64   = 3600 // 1 hour
+	DefaultMaxCheckpoints            int64   = 3
+	DefaultWriteAheadLogRecoveryMode string  = WALRecoveryModeStrict
+	DefaultWriteAheadLogDurability   string  = WALDurabilityOSBuffered
 
 	// Section:Logging
 	LogLevelDebug string = "DEBUG"
@@ -86,14 +117,26 @@ const (
 	DefaultMinimumLogLevel  string = LogLevelInfo
 
 	// Section:Eviction
-	EvictionPolicyLRU  = "LRU"
-	EvictionPolicyNone = "NONE"
+	EvictionPolicyLRU          = "LRU"                      // least recently used
+	EvictionPolicyLFU          = "LFU"                      // least frequently used
+	EvictionPolicyRandom       = "RANDOM"                   // random keys
+	EvictionPolicyVolatileLRU  = "VOLATILE_LRU"             // least recently used among keys with expiry
+	EvictionPolicyVolatileTTL  = "VOLATILE_TTL"             // nearest expiry among keys with expiry
+	EvictionPolicyRejectWrites = "NOEVICTION_REJECT_WRITES" // no eviction, writes fail when memory is full
+	EvictionPolicyNone         = "NONE"
 
 	DefaultRecordAutoExpiryFrequency int64  = 5 // 5 seconds
 	DefaultAutoEvictionPolicy        string = EvictionPolicyNone
 
 	// Section:Auth
-	DefaultAuthenticationMode int64 = 1 // TBD
+	DefaultAuthenticationEnabled bool   = false
+	DefaultDbUserName            string = "admin"
+
+	// Section:Notifications
+	KeyspaceEventSet     string = "set"     // a key was written
+	KeyspaceEventDel     string = "del"     // a key was deleted
+	KeyspaceEventExpired string = "expired" // a key was removed on reaching its expiry
+	KeyspaceEventEvicted string = "evicted" // a key was removed by the eviction policy
 )
 
 var AllowedLogLevels []string = []string{
@@ -106,9 +149,45 @@ var AllowedLogLevels []string = []string{
 
 var AllowedAutoEvictionPolicy []string = []string{
 	EvictionPolicyLRU,
+	EvictionPolicyLFU,
+	EvictionPolicyRandom,
+	EvictionPolicyVolatileLRU,
+	EvictionPolicyVolatileTTL,
+	EvictionPolicyRejectWrites,
 	EvictionPolicyNone,
 }
 
+var AllowedProtocols []string = []string{
+	ProtocolNative,
+	ProtocolRESP2,
+	ProtocolRESP3,
+}
+
+var AllowedTLSClientAuths []string = []string{
+	TLSClientAuthNone,
+	TLSClientAuthOptional,
+	TLSClientAuthRequired,
+}
+
+var AllowedKeyspaceEvents []string = []string{
+	KeyspaceEventSet,
+	KeyspaceEventDel,
+	KeyspaceEventExpired,
+	KeyspaceEventEvicted,
+}
+
+var AllowedWALRecoveryModes []string = []string{
+	WALRecoveryModeStrict,
+	WALRecoveryModeTolerant,
+}
+
+var AllowedWALDurabilities []string = []string{
+	WALDurabilityNone,
+	WALDurabilityOSBuffered,
+	WALDurabilityFsyncPerBatch,
+	WALDurabilityFsyncPerWrite,
+}
+
 var AllowedCompressionAlgos []string = []string{
 	CompressionAlgoNone,
 	CompressionAlgoLZ4,
@@ -19,6 +19,8 @@ type Config struct {
 	Logging  *Logging  `toml:"Logging"`  // Log file and log level settings
 	Eviction *Eviction `toml:"Eviction"` // Automatic eviction and expiry policy settings
 	Auth     *Auth     `toml:"Auth"`     // Authentication-related settings
+
+	Notifications *Notifications `toml:"Notifications"` // Keyspace notification settings
 }
 
 type Server struct {
@@ -26,9 +28,22 @@ type Server struct {
 	MaxConnections          int64  `toml:"MaxConnections"`          // Maximum number of concurrent client connections allowed
 	ConnectionWriteTimeout  int64  `toml:"ConnectionWriteTimeout"`  // Maximum duration (seconds) to wait before timing out a write operation
 	RequestExecutionTimeout int64  `toml:"RequestExecutionTimeout"` // Maximum duration (seconds) to wait before timing out a request execution
+	IdleConnectionTimeout   int64  `toml:"IdleConnectionTimeout"`   // Maximum duration (seconds) a client can stay idle before being disconnected, 0 for never
 	EnableTLS               bool   `toml:"EnableTLS"`               // Enable or disable TLS for secure communication
 	TLSCertFilePath         string `toml:"TLSCertFilePath"`         // Path to the TLS certificate file
 	TLSKeyFilePath          string `toml:"TLSKeyFilePath"`          // Path to the TLS key file
+	TLSClientCAFilePath     string `toml:"TLSClientCAFilePath"`     // Path to the CA bundle the client certificates are verified against
+	TLSClientAuth           string `toml:"TLSClientAuth"`           // Whether client certificates are asked for, verified when given, or required
+	TLSReloadInterval       int64  `toml:"TLSReloadInterval"`       // Interval (seconds) at which the TLS files are checked for changes to reload
+	UnixSocketPath          string `toml:"UnixSocketPath"`          // Path of the Unix socket to listen on alongside the TCP port, none if empty
+	UnixSocketPermissions   string `toml:"UnixSocketPermissions"`   // File permissions of the Unix socket, in octal
+	PubSubClientBufferSize  int64  `toml:"PubSubClientBufferSize"`  // Maximum number of pending pub/sub messages per subscriber before it is disconnected
+	DefaultProtocol         string `toml:"DefaultProtocol"`         // Reply protocol of new connections until they negotiate one with HELLO
+	MaxPipelineBatchSize    int64  `toml:"MaxPipelineBatchSize"`    // Maximum number of pipelined commands executed before their responses are flushed
+	EnableHTTPGateway       bool   `toml:"EnableHTTPGateway"`       // Enable or disable the HTTP/JSON gateway to the commands
+	HTTPGatewayPort         int64  `toml:"HTTPGatewayPort"`         // Port where the HTTP/JSON gateway listens for requests
+	EnableMetrics           bool   `toml:"EnableMetrics"`           // Enable or disable the Prometheus metrics endpoint
+	MetricsPort             int64  `toml:"MetricsPort"`             // Port where the metrics endpoint listens for scrapes
 }
 
 type Cluster struct {
@@ -48,18 +63,24 @@ type Memory struct {
 }
 
 type LSM struct {
-	MemtableStorageType     string  `toml:"MemtableStorageType"`     // Type of storage structure used for the MemTable
-	BloomFalsePositiveRate  float64 `toml:"BloomFalsePositiveRate"`  // Probability of false positives for Bloom filters
-	BloomFilterMaxRecords   int64   `toml:"BloomFilterMaxRecords"`   // Maximum number of records tracked by a Bloom filter
-	BlockCompressionAlgo    string  `toml:"BlockCompressionAlgo"`    // Compression algorithm used for SSTable blocks
-	DataStorageDirectory    string  `toml:"DataStorageDirectory"`    // Directory path for storing data files/sstables
-	WriteBlockSize          int64   `toml:"WriteBlockSize"`          // Size of each block written to SSTables
-	WriteBufferSize         int64   `toml:"WriteBufferSize"`         // Size of the buffer for writing data (memtable size)
-	WriteAheadLogDirectory  string  `toml:"WriteAheadLogDirectory"`  // Directory for write-ahead logs (WAL)
-	WriteAheadLogAsyncFlush bool    `toml:"WriteAheadLogAsyncFlush"` // Enable/disable asynchronous flushing of WAL
-	WriteAheadLogFrequency  int64   `toml:"WriteAheadLogFrequency"`  // Frequency of WAL flushes
-	WriteAheadLogBufferSize int64   `toml:"WriteAheadLogBufferSize"` // Buffer size for write-ahead logs
-	BlockCacheMemoryLimit   int64   `toml:"BlockCacheMemoryLimit"`   // Maximum memory allowed for block cache
+	MemtableStorageType           string  `toml:"MemtableStorageType"`           // Type of storage structure used for the MemTable
+	BloomFalsePositiveRate        float64 `toml:"BloomFalsePositiveRate"`        // Probability of false positives for Bloom filters
+	BloomFilterMaxRecords         int64   `toml:"BloomFilterMaxRecords"`         // Maximum number of records tracked by a Bloom filter
+	BlockCompressionAlgo          string  `toml:"BlockCompressionAlgo"`          // Compression algorithm used for SSTable blocks
+	DataStorageDirectory          string  `toml:"DataStorageDirectory"`          // Directory path for storing data files/sstables
+	WriteBlockSize                int64   `toml:"WriteBlockSize"`                // Size of each block written to SSTables
+	WriteBufferSize               int64   `toml:"WriteBufferSize"`               // Size of the buffer for writing data (memtable size)
+	WriteAheadLogDirectory        string  `toml:"WriteAheadLogDirectory"`        // Directory for write-ahead logs (WAL)
+	WriteAheadLogAsyncFlush       bool    `toml:"WriteAheadLogAsyncFlush"`       // Enable/disable asynchronous flushing of WAL
+	WriteAheadLogFrequency        int64   `toml:"WriteAheadLogFrequency"`        // Frequency of WAL flushes
+	WriteAheadLogBufferSize       int64   `toml:"WriteAheadLogBufferSize"`       // Buffer size for write-ahead logs
+	WriteAheadLogArchiveDirectory string  `toml:"WriteAheadLogArchiveDirectory"` // Directory flushed WAL segments are moved to, instead of being deleted
+	WriteAheadLogRecoveryMode     string  `toml:"WriteAheadLogRecoveryMode"`     // Whether corrupt WAL entries fail the startup or are skipped on replay
+	WriteAheadLogDurability       string  `toml:"WriteAheadLogDurability"`       // How durable the WAL entries of a write are once it is acknowledged
+	BlockCacheMemoryLimit         int64   `toml:"BlockCacheMemoryLimit"`         // Maximum memory allowed for block cache
+	CheckpointDirectory           string  `toml:"CheckpointDirectory"`           // Directory where the checkpoints taken by SNAPSHOT are stored
+	AutoCheckpointFrequency       int64   `toml:"AutoCheckpointFrequency"`       // Frequency in seconds to auto-generate checkpoints
+	MaxCheckpoints                int64   `toml:"MaxCheckpoints"`                // Number of most recent checkpoints kept, the older ones being removed
 }
 
 type Storage struct {
@@ -77,11 +98,16 @@ type Logging struct {
 
 type Eviction struct {
 	AutoRecordExpiryFrequency int64  `toml:"AutoRecordExpiryFrequency"` // Frequency of automatic record expiry checks
-	AutoEvictionPolicy        string `toml:"RecordAutoEvictionPolicy"`  // Policy for auto-eviction of records (e.g., LRU, FIFO)
+	AutoEvictionPolicy        string `toml:"RecordAutoEvictionPolicy"`  // Policy for auto-eviction of records (e.g., LRU, LFU, RANDOM)
 }
 
 type Auth struct {
 	AuthenticationEnabled bool   `toml:"AuthenticationEnabled"` // Enable or disable authentication mechanism
 	DbUserName            string `toml:"DbUserName"`            // Username for database access
 	DbUserPassword        string `toml:"DbUserPassword"`        // Password for database access
+	ACLFilePath           string `toml:"ACLFilePath"`           // File where the users managed through the ACL commands are persisted
+}
+
+type Notifications struct {
+	KeyspaceEvents []string `toml:"KeyspaceEvents"` // Classes of keyspace events published to subscribers (e.g., set, del, expired, evicted)
 }
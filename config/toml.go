@@ -116,6 +116,10 @@ func (p *Parser) parseValue(value string) (TOMLValue, error) {
 func (p *Parser) parseArray(value string) ([]TOMLValue, error) {
 	array := []TOMLValue{}
 	arrayContent := strings.Trim(value, "[]")
+	if strings.TrimSpace(arrayContent) == "" {
+		return array, nil
+	}
+
 	elements := strings.Split(arrayContent, ",")
 	for _, element := range elements {
 		trimmedElement := strings.TrimSpace(element)
@@ -59,6 +59,7 @@ func TestParseArray(t *testing.T) {
 	content := `
 numbers = [1, 2, 3, 4, 5]
 fruits = ["apple", "banana", "cherry"]
+empty = []
 `
 	file := createFile(t, content)
 	defer os.Remove(file.Name())
@@ -79,6 +80,10 @@ fruits = ["apple", "banana", "cherry"]
 	if !equalArrays(parser.data["fruits"], expectedFruits) {
 		t.Errorf("Expected fruits to be %v, got %v", expectedFruits, parser.data["fruits"])
 	}
+
+	if !equalArrays(parser.data["empty"], []TOMLValue{}) {
+		t.Errorf("Expected empty to be an empty array, got %v", parser.data["empty"])
+	}
 }
 
 func TestParseSections(t *testing.T) {
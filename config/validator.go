@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"universum/utils"
 	"universum/utils/filesys"
@@ -47,6 +48,11 @@ func (v *ConfigValidator) Validate(config *Config) (*Config, error) {
 		return nil, err
 	}
 
+	err = v.validateNotificationsSection(config)
+	if err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
@@ -71,10 +77,84 @@ func (v *ConfigValidator) validateServerSection(config *Config) error {
 		config.Server.RequestExecutionTimeout = DefaultRequestExecTimeout
 	}
 
+	if config.Server.IdleConnectionTimeout < 0 {
+		return fmt.Errorf("invalid idle connection timeout %d", config.Server.IdleConnectionTimeout)
+	}
+
 	if config.Server.MaxConnections == 0 {
 		config.Server.MaxConnections = DefultMaxClientConnections
 	}
 
+	if config.Server.MaxConnections < 0 {
+		return fmt.Errorf("invalid max connections %d", config.Server.MaxConnections)
+	}
+
+	if config.Server.PubSubClientBufferSize == 0 {
+		config.Server.PubSubClientBufferSize = DefaultPubSubClientBufferSize
+	}
+
+	if config.Server.PubSubClientBufferSize < 0 {
+		return fmt.Errorf("invalid pub/sub client buffer size %d", config.Server.PubSubClientBufferSize)
+	}
+
+	if config.Server.DefaultProtocol == "" {
+		config.Server.DefaultProtocol = DefaultProtocol
+	}
+
+	config.Server.DefaultProtocol = strings.ToUpper(config.Server.DefaultProtocol)
+	if exists, _ := utils.ExistsInList(config.Server.DefaultProtocol, AllowedProtocols); !exists {
+		return fmt.Errorf("invalid default protocol %s set in config", config.Server.DefaultProtocol)
+	}
+
+	if config.Server.MaxPipelineBatchSize == 0 {
+		config.Server.MaxPipelineBatchSize = DefaultMaxPipelineBatchSize
+	}
+
+	if config.Server.MaxPipelineBatchSize < 0 {
+		return fmt.Errorf("invalid max pipeline batch size %d", config.Server.MaxPipelineBatchSize)
+	}
+
+	if config.Server.UnixSocketPermissions == "" {
+		config.Server.UnixSocketPermissions = DefaultUnixSocketPermissions
+	}
+
+	permissions, err := strconv.ParseUint(config.Server.UnixSocketPermissions, 8, 32)
+	if err != nil || permissions > 0777 {
+		return fmt.Errorf("invalid unix socket permissions %s set in config", config.Server.UnixSocketPermissions)
+	}
+
+	if config.Server.HTTPGatewayPort == 0 {
+		config.Server.HTTPGatewayPort = DefaultHTTPGatewayPort
+	}
+
+	if config.Server.HTTPGatewayPort < 1 || config.Server.HTTPGatewayPort > 65535 {
+		return fmt.Errorf("invalid HTTP gateway port number %d", config.Server.HTTPGatewayPort)
+	}
+
+	if config.Server.EnableHTTPGateway && config.Server.HTTPGatewayPort == config.Server.ServerPort {
+		return fmt.Errorf("HTTP gateway port %d cannot be same as server port %d",
+			config.Server.HTTPGatewayPort, config.Server.ServerPort)
+	}
+
+	if config.Server.MetricsPort == 0 {
+		config.Server.MetricsPort = DefaultMetricsPort
+	}
+
+	if config.Server.MetricsPort < 1 || config.Server.MetricsPort > 65535 {
+		return fmt.Errorf("invalid metrics port number %d", config.Server.MetricsPort)
+	}
+
+	if config.Server.EnableMetrics && config.Server.MetricsPort == config.Server.ServerPort {
+		return fmt.Errorf("metrics port %d cannot be same as server port %d",
+			config.Server.MetricsPort, config.Server.ServerPort)
+	}
+
+	if config.Server.EnableMetrics && config.Server.EnableHTTPGateway &&
+		config.Server.MetricsPort == config.Server.HTTPGatewayPort {
+		return fmt.Errorf("metrics port %d cannot be same as HTTP gateway port %d",
+			config.Server.MetricsPort, config.Server.HTTPGatewayPort)
+	}
+
 	if config.Server.EnableTLS && config.Server.TLSCertFilePath == "" {
 		config.Server.TLSCertFilePath = DefaultTLSCertFilePath
 	}
@@ -83,6 +163,27 @@ func (v *ConfigValidator) validateServerSection(config *Config) error {
 		config.Server.TLSKeyFilePath = DefaultTLSKeyFilePath
 	}
 
+	if config.Server.TLSClientAuth == "" {
+		config.Server.TLSClientAuth = DefaultTLSClientAuth
+	}
+
+	config.Server.TLSClientAuth = strings.ToUpper(config.Server.TLSClientAuth)
+	if exists, _ := utils.ExistsInList(config.Server.TLSClientAuth, AllowedTLSClientAuths); !exists {
+		return fmt.Errorf("invalid TLS client auth %s set in config", config.Server.TLSClientAuth)
+	}
+
+	if config.Server.EnableTLS && config.Server.TLSClientAuth != TLSClientAuthNone && config.Server.TLSClientCAFilePath == "" {
+		return fmt.Errorf("TLS client auth %s requires a client CA file", config.Server.TLSClientAuth)
+	}
+
+	if config.Server.TLSReloadInterval == 0 {
+		config.Server.TLSReloadInterval = DefaultTLSReloadInterval
+	}
+
+	if config.Server.TLSReloadInterval < 0 {
+		return fmt.Errorf("invalid TLS reload interval %d", config.Server.TLSReloadInterval)
+	}
+
 	return nil
 }
 
@@ -213,6 +314,29 @@ func (v *ConfigValidator) validateStorageEngineLSM(config *Config) error {
 		return fmt.Errorf("WAL directory %s is not writable", config.Storage.LSM.WriteAheadLogDirectory)
 	}
 
+	if config.Storage.LSM.WriteAheadLogRecoveryMode == "" {
+		config.Storage.LSM.WriteAheadLogRecoveryMode = DefaultWriteAheadLogRecoveryMode
+	}
+
+	config.Storage.LSM.WriteAheadLogRecoveryMode = strings.ToUpper(config.Storage.LSM.WriteAheadLogRecoveryMode)
+	if exists, _ := utils.ExistsInList(config.Storage.LSM.WriteAheadLogRecoveryMode, AllowedWALRecoveryModes); !exists {
+		return fmt.Errorf("invalid WAL recovery mode %s set in config", config.Storage.LSM.WriteAheadLogRecoveryMode)
+	}
+
+	// the asynchronous flush predates the durability setting, and stands for
+	// none when the durability is not set
+	if config.Storage.LSM.WriteAheadLogDurability == "" {
+		config.Storage.LSM.WriteAheadLogDurability = DefaultWriteAheadLogDurability
+		if config.Storage.LSM.WriteAheadLogAsyncFlush {
+			config.Storage.LSM.WriteAheadLogDurability = WALDurabilityNone
+		}
+	}
+
+	config.Storage.LSM.WriteAheadLogDurability = strings.ToLower(config.Storage.LSM.WriteAheadLogDurability)
+	if exists, _ := utils.ExistsInList(config.Storage.LSM.WriteAheadLogDurability, AllowedWALDurabilities); !exists {
+		return fmt.Errorf("invalid WAL durability %s set in config", config.Storage.LSM.WriteAheadLogDurability)
+	}
+
 	if !filesys.IsDirectoryWritable(config.Storage.LSM.DataStorageDirectory) {
 		return fmt.Errorf("data directory %s is not writable", config.Storage.LSM.DataStorageDirectory)
 	}
@@ -229,6 +353,26 @@ func (v *ConfigValidator) validateStorageEngineLSM(config *Config) error {
 		config.Storage.LSM.BlockCacheMemoryLimit = DefaultBlockCacheMemoryLimit
 	}
 
+	if config.Storage.LSM.CheckpointDirectory == "" {
+		config.Storage.LSM.CheckpointDirectory = DefaultCheckpointDirectory
+	}
+
+	if config.Storage.LSM.AutoCheckpointFrequency == 0 {
+		config.Storage.LSM.AutoCheckpointFrequency = DefaultAutoCheckpointFrequency
+	}
+
+	if config.Storage.LSM.AutoCheckpointFrequency < 0 {
+		return fmt.Errorf("invalid auto checkpoint frequency %d", config.Storage.LSM.AutoCheckpointFrequency)
+	}
+
+	if config.Storage.LSM.MaxCheckpoints == 0 {
+		config.Storage.LSM.MaxCheckpoints = DefaultMaxCheckpoints
+	}
+
+	if config.Storage.LSM.MaxCheckpoints < 0 {
+		return fmt.Errorf("invalid max checkpoints %d", config.Storage.LSM.MaxCheckpoints)
+	}
+
 	return nil
 }
 func (v *ConfigValidator) validateStorageEngineMemory(config *Config) error {
@@ -273,7 +417,10 @@ func (v *ConfigValidator) validateEvictionSection(config *Config) error {
 		config.Eviction.AutoEvictionPolicy = DefaultAutoEvictionPolicy
 	}
 
+	// accept both VOLATILE_LRU and VOLATILE-LRU style policy names
 	config.Eviction.AutoEvictionPolicy = strings.ToUpper(config.Eviction.AutoEvictionPolicy)
+	config.Eviction.AutoEvictionPolicy = strings.ReplaceAll(config.Eviction.AutoEvictionPolicy, "-", "_")
+
 	if exists, _ := utils.ExistsInList(config.Eviction.AutoEvictionPolicy, AllowedAutoEvictionPolicy); !exists {
 		return fmt.Errorf("invalid eviction policy %s set in config", config.Eviction.AutoEvictionPolicy)
 	}
@@ -314,5 +461,44 @@ func (v *ConfigValidator) validateAuthSection(config *Config) error {
 	if config.Auth == nil {
 		return errors.New("auth section is missing in config")
 	}
+
+	if !config.Auth.AuthenticationEnabled {
+		return nil
+	}
+
+	if config.Auth.DbUserName == "" {
+		config.Auth.DbUserName = DefaultDbUserName
+	}
+
+	if config.Auth.DbUserPassword == "" {
+		return errors.New("password must be set when authentication is enabled")
+	}
+
+	// keep only the salted hash of a password given in plaintext
+	if !utils.IsHashedPassword(config.Auth.DbUserPassword) {
+		hash, err := utils.HashPassword(config.Auth.DbUserPassword)
+		if err != nil {
+			return fmt.Errorf("unable to hash the password: %v", err)
+		}
+		config.Auth.DbUserPassword = hash
+	}
+
+	return nil
+}
+
+func (v *ConfigValidator) validateNotificationsSection(config *Config) error {
+	// the section is optional, notifications stay disabled without it
+	if config.Notifications == nil {
+		config.Notifications = &Notifications{}
+	}
+
+	for idx, event := range config.Notifications.KeyspaceEvents {
+		event = strings.ToLower(event)
+		if exists, _ := utils.ExistsInList(event, AllowedKeyspaceEvents); !exists {
+			return fmt.Errorf("invalid keyspace event %s set in config", event)
+		}
+		config.Notifications.KeyspaceEvents[idx] = event
+	}
+
 	return nil
 }
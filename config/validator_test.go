@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"universum/utils"
 )
 
 const testingTempDir string = "/tmp"
@@ -31,9 +32,96 @@ func TestConfigValidator_Validate(t *testing.T) {
 			t.Errorf("Expected MaxConnections to be set to %d, got %d", DefultMaxClientConnections, cfg.Server.MaxConnections)
 		}
 
+		if cfg.Server.PubSubClientBufferSize != DefaultPubSubClientBufferSize {
+			t.Errorf("Expected PubSubClientBufferSize to be set to %d, got %d", DefaultPubSubClientBufferSize, cfg.Server.PubSubClientBufferSize)
+		}
+
+		if cfg.Server.DefaultProtocol != DefaultProtocol {
+			t.Errorf("Expected DefaultProtocol to be set to %s, got %s", DefaultProtocol, cfg.Server.DefaultProtocol)
+		}
+
+		if cfg.Server.TLSClientAuth != DefaultTLSClientAuth || cfg.Server.TLSReloadInterval != DefaultTLSReloadInterval {
+			t.Errorf("Expected the TLS client auth and reload interval to be set to their defaults, got %s and %d", cfg.Server.TLSClientAuth, cfg.Server.TLSReloadInterval)
+		}
+
+		if cfg.Server.HTTPGatewayPort != DefaultHTTPGatewayPort {
+			t.Errorf("Expected HTTPGatewayPort to be set to %d, got %d", DefaultHTTPGatewayPort, cfg.Server.HTTPGatewayPort)
+		}
+
+		if cfg.Server.MetricsPort != DefaultMetricsPort {
+			t.Errorf("Expected MetricsPort to be set to %d, got %d", DefaultMetricsPort, cfg.Server.MetricsPort)
+		}
+
+		if cfg.Server.UnixSocketPermissions != DefaultUnixSocketPermissions {
+			t.Errorf("Expected UnixSocketPermissions to be set to %s, got %s", DefaultUnixSocketPermissions, cfg.Server.UnixSocketPermissions)
+		}
+
+		if cfg.Server.MaxPipelineBatchSize != DefaultMaxPipelineBatchSize {
+			t.Errorf("Expected MaxPipelineBatchSize to be set to %d, got %d", DefaultMaxPipelineBatchSize, cfg.Server.MaxPipelineBatchSize)
+		}
+
 		if cfg.Server.ConnectionWriteTimeout != 30 {
 			t.Errorf("Expected ConnectionWriteTimeout to be set to 30, got %d", cfg.Server.ConnectionWriteTimeout)
 		}
+
+		cfg.Server.DefaultProtocol = "resp1"
+		if err := validator.validateServerSection(cfg); err == nil {
+			t.Errorf("Expected an error for invalid default protocol, but got none")
+		}
+
+		cfg.Server.DefaultProtocol = ProtocolNative
+		cfg.Server.MaxPipelineBatchSize = -1
+		if err := validator.validateServerSection(cfg); err == nil {
+			t.Errorf("Expected an error for negative max pipeline batch size, but got none")
+		}
+
+		cfg.Server.MaxPipelineBatchSize = DefaultMaxPipelineBatchSize
+		cfg.Server.IdleConnectionTimeout = -1
+		if err := validator.validateServerSection(cfg); err == nil {
+			t.Errorf("Expected an error for negative idle connection timeout, but got none")
+		}
+
+		cfg.Server.IdleConnectionTimeout = 0
+		cfg.Server.EnableHTTPGateway = true
+		cfg.Server.HTTPGatewayPort = cfg.Server.ServerPort
+		if err := validator.validateServerSection(cfg); err == nil {
+			t.Errorf("Expected an error for the HTTP gateway port being the server port, but got none")
+		}
+
+		cfg.Server.HTTPGatewayPort = DefaultHTTPGatewayPort
+		cfg.Server.EnableMetrics = true
+		cfg.Server.MetricsPort = DefaultHTTPGatewayPort
+		if err := validator.validateServerSection(cfg); err == nil {
+			t.Errorf("Expected an error for the metrics port being the HTTP gateway port, but got none")
+		}
+
+		cfg.Server.EnableHTTPGateway = false
+		cfg.Server.EnableMetrics = false
+		cfg.Server.MetricsPort = DefaultMetricsPort
+		cfg.Server.EnableTLS = true
+		cfg.Server.TLSClientAuth = "required"
+		if err := validator.validateServerSection(cfg); err == nil {
+			t.Errorf("Expected an error for TLS client auth without a client CA file, but got none")
+		}
+
+		cfg.Server.TLSClientCAFilePath = "/etc/universum/ca.pem"
+		if err := validator.validateServerSection(cfg); err != nil || cfg.Server.TLSClientAuth != TLSClientAuthRequired {
+			t.Errorf("Expected TLS client auth to be accepted, got %v", err)
+		}
+
+		cfg.Server.TLSClientAuth = "sometimes"
+		if err := validator.validateServerSection(cfg); err == nil {
+			t.Errorf("Expected an error for invalid TLS client auth, but got none")
+		}
+
+		cfg.Server.EnableTLS = false
+		cfg.Server.TLSClientAuth = TLSClientAuthNone
+		for _, permissions := range []string{"0799", "rw", "1777"} {
+			cfg.Server.UnixSocketPermissions = permissions
+			if err := validator.validateServerSection(cfg); err == nil {
+				t.Errorf("Expected an error for invalid unix socket permissions %s, but got none", permissions)
+			}
+		}
 	})
 
 	t.Run("ValidateLoggingSection", func(t *testing.T) {
@@ -132,6 +220,59 @@ func TestConfigValidator_Validate(t *testing.T) {
 		if cfg.Storage.LSM.WriteAheadLogDirectory != testingTempDir {
 			t.Errorf("Expected WriteAheadLogDirectory to be set to /tmp, got %s", cfg.Storage.LSM.WriteAheadLogDirectory)
 		}
+
+		if cfg.Storage.LSM.WriteAheadLogRecoveryMode != DefaultWriteAheadLogRecoveryMode {
+			t.Errorf("Expected WriteAheadLogRecoveryMode to be set to %s, got %s", DefaultWriteAheadLogRecoveryMode, cfg.Storage.LSM.WriteAheadLogRecoveryMode)
+		}
+
+		if cfg.Storage.LSM.CheckpointDirectory != DefaultCheckpointDirectory {
+			t.Errorf("Expected CheckpointDirectory to be set to %s, got %s", DefaultCheckpointDirectory, cfg.Storage.LSM.CheckpointDirectory)
+		}
+
+		if cfg.Storage.LSM.AutoCheckpointFrequency != DefaultAutoCheckpointFrequency {
+			t.Errorf("Expected AutoCheckpointFrequency to be set to %d, got %d", DefaultAutoCheckpointFrequency, cfg.Storage.LSM.AutoCheckpointFrequency)
+		}
+
+		if cfg.Storage.LSM.MaxCheckpoints != DefaultMaxCheckpoints {
+			t.Errorf("Expected MaxCheckpoints to be set to %d, got %d", DefaultMaxCheckpoints, cfg.Storage.LSM.MaxCheckpoints)
+		}
+
+		if cfg.Storage.LSM.WriteAheadLogDurability != DefaultWriteAheadLogDurability {
+			t.Errorf("Expected WriteAheadLogDurability to be set to %s, got %s", DefaultWriteAheadLogDurability, cfg.Storage.LSM.WriteAheadLogDurability)
+		}
+
+		cfg.Storage.LSM.WriteAheadLogDurability = ""
+		cfg.Storage.LSM.WriteAheadLogAsyncFlush = true
+		if err := validator.validateStorageEngineLSM(cfg); err != nil || cfg.Storage.LSM.WriteAheadLogDurability != WALDurabilityNone {
+			t.Errorf("Expected the asynchronous flush to stand for no durability, got %s (err=%v)", cfg.Storage.LSM.WriteAheadLogDurability, err)
+		}
+
+		cfg.Storage.LSM.WriteAheadLogDurability = "FSYNC-PER-BATCH"
+		if err := validator.validateStorageEngineLSM(cfg); err != nil || cfg.Storage.LSM.WriteAheadLogDurability != WALDurabilityFsyncPerBatch {
+			t.Errorf("Expected the WAL durability to be accepted in any case, got %s (err=%v)", cfg.Storage.LSM.WriteAheadLogDurability, err)
+		}
+
+		cfg.Storage.LSM.WriteAheadLogDurability = "fsync-sometimes"
+		if err := validator.validateStorageEngineLSM(cfg); err == nil {
+			t.Errorf("Expected an error for an invalid WAL durability, but got none")
+		}
+		cfg.Storage.LSM.WriteAheadLogDurability = WALDurabilityOSBuffered
+
+		cfg.Storage.LSM.WriteAheadLogRecoveryMode = "tolerant"
+		if err := validator.validateStorageEngineLSM(cfg); err != nil || cfg.Storage.LSM.WriteAheadLogRecoveryMode != WALRecoveryModeTolerant {
+			t.Errorf("Expected the WAL recovery mode to be accepted in any case, got %s (err=%v)", cfg.Storage.LSM.WriteAheadLogRecoveryMode, err)
+		}
+
+		cfg.Storage.LSM.WriteAheadLogRecoveryMode = "lenient"
+		if err := validator.validateStorageEngineLSM(cfg); err == nil {
+			t.Errorf("Expected an error for an invalid WAL recovery mode, but got none")
+		}
+		cfg.Storage.LSM.WriteAheadLogRecoveryMode = WALRecoveryModeStrict
+
+		cfg.Storage.LSM.MaxCheckpoints = -1
+		if err := validator.validateStorageEngineLSM(cfg); err == nil {
+			t.Errorf("Expected an error for negative max checkpoints, but got none")
+		}
 	})
 
 	t.Run("ValidateStorageSectionWithMemoryEngine", func(t *testing.T) {
@@ -177,4 +318,77 @@ func TestConfigValidator_Validate(t *testing.T) {
 			t.Errorf("Expected AutoRecordExpiryFrequency to be set to %d, got %d", DefaultRecordAutoExpiryFrequency, cfg.Eviction.AutoRecordExpiryFrequency)
 		}
 	})
+
+	t.Run("validateEvictionSectionPolicies", func(t *testing.T) {
+		cfg := GetSkeleton()
+		cfg.Eviction = &Eviction{AutoEvictionPolicy: "volatile-lru"}
+
+		err := validator.validateEvictionSection(cfg)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if cfg.Eviction.AutoEvictionPolicy != EvictionPolicyVolatileLRU {
+			t.Errorf("Expected AutoEvictionPolicy to be normalised to %s, got %s", EvictionPolicyVolatileLRU, cfg.Eviction.AutoEvictionPolicy)
+		}
+
+		cfg.Eviction = &Eviction{AutoEvictionPolicy: "FIFO"}
+		err = validator.validateEvictionSection(cfg)
+		if err == nil {
+			t.Errorf("Expected error for unknown eviction policy, got nil")
+		}
+	})
+
+	t.Run("validateNotificationsSection", func(t *testing.T) {
+		cfg := GetSkeleton()
+		cfg.Notifications = nil
+
+		err := validator.validateNotificationsSection(cfg)
+		if err != nil || cfg.Notifications == nil || len(cfg.Notifications.KeyspaceEvents) != 0 {
+			t.Fatalf("Expected notifications to be disabled without the section, got %v", err)
+		}
+
+		cfg.Notifications = &Notifications{KeyspaceEvents: []string{"EXPIRED", "del"}}
+		err = validator.validateNotificationsSection(cfg)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if cfg.Notifications.KeyspaceEvents[0] != KeyspaceEventExpired {
+			t.Errorf("Expected keyspace event to be normalised to %s, got %s", KeyspaceEventExpired, cfg.Notifications.KeyspaceEvents[0])
+		}
+
+		cfg.Notifications = &Notifications{KeyspaceEvents: []string{"rename"}}
+		err = validator.validateNotificationsSection(cfg)
+		if err == nil {
+			t.Errorf("Expected error for unknown keyspace event, got nil")
+		}
+	})
+
+	t.Run("validateAuthSection", func(t *testing.T) {
+		cfg := GetSkeleton()
+		cfg.Auth = &Auth{AuthenticationEnabled: true}
+
+		if err := validator.validateAuthSection(cfg); err == nil {
+			t.Fatalf("Expected error for missing password, got nil")
+		}
+
+		cfg.Auth = &Auth{AuthenticationEnabled: true, DbUserPassword: "s3cret"}
+		if err := validator.validateAuthSection(cfg); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if cfg.Auth.DbUserName != DefaultDbUserName {
+			t.Errorf("Expected DbUserName to be set to %s, got %s", DefaultDbUserName, cfg.Auth.DbUserName)
+		}
+
+		if !utils.VerifyPassword("s3cret", cfg.Auth.DbUserPassword) {
+			t.Errorf("Expected the password to be replaced by its hash, got %s", cfg.Auth.DbUserPassword)
+		}
+
+		hash := cfg.Auth.DbUserPassword
+		if err := validator.validateAuthSection(cfg); err != nil || cfg.Auth.DbUserPassword != hash {
+			t.Errorf("Expected a hashed password to be kept as it is, got %s", cfg.Auth.DbUserPassword)
+		}
+	})
 }
@@ -376,14 +376,38 @@ func (t *RBTree) inOrderCollect(node *RBTreeNode, result *[]*entity.RecordKV, cu
 
 	if node.expiry > currentTime {
 		*result = append(*result, &entity.RecordKV{
-			Key: node.key,
-			Record: &entity.ScalarRecord{
-				Value:  node.value,
-				Expiry: node.expiry,
-				State:  node.state,
-			},
+			Key:    node.key,
+			Record: entity.NewRecord(node.value, 0, node.expiry, node.state),
 		})
 	}
 
 	t.inOrderCollect(node.right, result, currentTime)
 }
+
+// GetRecordsFrom retrieves the records with keys greater than or equal to the
+// given one in sorted order. Unlike GetAllRecords, expired records are kept so
+// that callers can tell a key expired from a key never written.
+func (t *RBTree) GetRecordsFrom(from string) []*entity.RecordKV {
+	var result []*entity.RecordKV
+	t.inOrderCollectFrom(t.Root, from, &result)
+	return result
+}
+
+// inOrderCollectFrom performs an in-order traversal of the tree, skipping
+// the subtrees holding keys lower than from.
+func (t *RBTree) inOrderCollectFrom(node *RBTreeNode, from string, result *[]*entity.RecordKV) {
+	if node == nil {
+		return
+	}
+
+	if node.key >= from {
+		t.inOrderCollectFrom(node.left, from, result)
+
+		*result = append(*result, &entity.RecordKV{
+			Key:    node.key,
+			Record: entity.NewRecord(node.value, 0, node.expiry, node.state),
+		})
+	}
+
+	t.inOrderCollectFrom(node.right, from, result)
+}
@@ -207,6 +207,30 @@ func TestGetAllRecords(t *testing.T) {
 	}
 }
 
+func TestGetRecordsFrom(t *testing.T) {
+	tree := NewRBTree()
+	now := time.Now().Unix()
+
+	for _, key := range []string{"D", "B", "F", "A", "C", "E"} {
+		tree.Insert(key, key, now+1000, 0)
+	}
+	tree.Insert("CC", "expired", now-10, 0)
+
+	records := tree.GetRecordsFrom("C")
+
+	expected := []string{"C", "CC", "D", "E", "F"}
+	if len(records) != len(expected) {
+		t.Fatalf("GetRecordsFrom returned wrong number of records: got %d, want %d",
+			len(records), len(expected))
+	}
+
+	for i, record := range records {
+		if record.Key != expected[i] {
+			t.Errorf("GetRecordsFrom()[%d] = %s, want %s", i, record.Key, expected[i])
+		}
+	}
+}
+
 func TestRotations(t *testing.T) {
 	tree := NewRBTree()
 
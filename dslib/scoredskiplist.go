@@ -0,0 +1,223 @@
+package dslib
+
+import (
+	"math/rand"
+	"universum/entity"
+)
+
+// ScoredSkipList is a skip list keyed on the (score, member) pair, which keeps
+// the members of a sorted set ordered by score and then lexicographically.
+// Every forward link also records its span, the number of nodes it skips over,
+// so that the rank of a member can be found in logarithmic time.
+type ScoredSkipList struct {
+	head  *ScoredSkipListNode
+	level int
+	size  int
+}
+
+type ScoredSkipListNode struct {
+	member string
+	score  float64
+	next   []*ScoredSkipListNode
+	span   []int
+}
+
+// NewScoredNode creates a new node for the scored skip list
+func NewScoredNode(member string, score float64, level int) *ScoredSkipListNode {
+	return &ScoredSkipListNode{
+		member: member,
+		score:  score,
+		next:   make([]*ScoredSkipListNode, level),
+		span:   make([]int, level),
+	}
+}
+
+// NewScoredSkipList initializes a new empty ScoredSkipList
+func NewScoredSkipList() *ScoredSkipList {
+	return &ScoredSkipList{
+		head:  NewScoredNode(MinString, 0, MaxLevel),
+		level: 1,
+		size:  0,
+	}
+}
+
+// precedes tells if the node is ordered before the given score and member
+func (node *ScoredSkipListNode) precedes(score float64, member string) bool {
+	return node.score < score || (node.score == score && node.member < member)
+}
+
+// Insert adds the member with the given score. The caller must make sure the
+// member is not already present, removing its old entry first if needed.
+func (sl *ScoredSkipList) Insert(member string, score float64) {
+	update := make([]*ScoredSkipListNode, MaxLevel)
+	rank := make([]int, MaxLevel)
+	current := sl.head
+
+	for i := sl.level - 1; i >= 0; i-- {
+		if i < sl.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for current.next[i] != nil && current.next[i].precedes(score, member) {
+			rank[i] += current.span[i]
+			current = current.next[i]
+		}
+		update[i] = current
+	}
+
+	level := sl.randomLevel()
+
+	if level > sl.level {
+		for i := sl.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = sl.head
+			update[i].span[i] = sl.size
+		}
+		sl.level = level
+	}
+
+	newNode := NewScoredNode(member, score, level)
+
+	for i := 0; i < level; i++ {
+		newNode.next[i] = update[i].next[i]
+		update[i].next[i] = newNode
+
+		newNode.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = rank[0] - rank[i] + 1
+	}
+
+	// links above the new node now skip over one more node
+	for i := level; i < sl.level; i++ {
+		update[i].span[i]++
+	}
+
+	sl.size++
+}
+
+// Remove deletes the member stored with the given score, if it exists
+func (sl *ScoredSkipList) Remove(member string, score float64) bool {
+	update := make([]*ScoredSkipListNode, MaxLevel)
+	current := sl.head
+
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.next[i] != nil && current.next[i].precedes(score, member) {
+			current = current.next[i]
+		}
+		update[i] = current
+	}
+
+	current = current.next[0]
+
+	if current == nil || current.score != score || current.member != member {
+		return false
+	}
+
+	for i := 0; i < sl.level; i++ {
+		if update[i].next[i] == current {
+			update[i].span[i] += current.span[i] - 1
+			update[i].next[i] = current.next[i]
+		} else {
+			update[i].span[i]--
+		}
+	}
+
+	for sl.level > 1 && sl.head.next[sl.level-1] == nil {
+		sl.level--
+	}
+
+	sl.size--
+	return true
+}
+
+// Rank returns the zero based position of the member stored with the given
+// score, or -1 if it does not exist
+func (sl *ScoredSkipList) Rank(member string, score float64) int {
+	rank := 0
+	current := sl.head
+
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.next[i] != nil &&
+			(current.next[i].precedes(score, member) ||
+				(current.next[i].score == score && current.next[i].member == member)) {
+			rank += current.span[i]
+			current = current.next[i]
+		}
+
+		if current != sl.head && current.member == member {
+			return rank - 1
+		}
+	}
+
+	return -1
+}
+
+// RangeByRank returns the members between the zero based start and stop
+// positions, both inclusive. Out of range positions are clamped.
+func (sl *ScoredSkipList) RangeByRank(start int, stop int) []entity.ScoredMember {
+	if start < 0 {
+		start = 0
+	}
+	if stop >= sl.size {
+		stop = sl.size - 1
+	}
+	if start > stop {
+		return []entity.ScoredMember{}
+	}
+
+	traversed := 0
+	current := sl.head
+
+	// walk down to the node right before the start position
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.next[i] != nil && traversed+current.span[i] <= start {
+			traversed += current.span[i]
+			current = current.next[i]
+		}
+	}
+
+	members := make([]entity.ScoredMember, 0, stop-start+1)
+	current = current.next[0]
+
+	for rank := start; rank <= stop && current != nil; rank++ {
+		members = append(members, entity.ScoredMember{Member: current.member, Score: current.score})
+		current = current.next[0]
+	}
+
+	return members
+}
+
+// RangeByScore returns the members with scores between min and max, both inclusive
+func (sl *ScoredSkipList) RangeByScore(min float64, max float64) []entity.ScoredMember {
+	current := sl.head
+
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.next[i] != nil && current.next[i].score < min {
+			current = current.next[i]
+		}
+	}
+
+	members := make([]entity.ScoredMember, 0)
+	current = current.next[0]
+
+	for current != nil && current.score <= max {
+		members = append(members, entity.ScoredMember{Member: current.member, Score: current.score})
+		current = current.next[0]
+	}
+
+	return members
+}
+
+// Size returns the number of members in the skip list
+func (sl *ScoredSkipList) Size() int {
+	return sl.size
+}
+
+// randomLevel generates a random level for a new node. Sorted sets are cloned
+// on every write, so the shared source of math/rand is used rather than seeding
+// a dedicated generator for each list.
+func (sl *ScoredSkipList) randomLevel() int {
+	level := 1
+	for level < MaxLevel && rand.Float64() < 0.5 {
+		level++
+	}
+	return level
+}
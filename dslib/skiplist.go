@@ -166,15 +166,34 @@ func (sl *SkipList) GetAllRecords() []*entity.RecordKV {
 		}
 
 		recordList = append(recordList, &entity.RecordKV{
-			Key: current.key,
-			Record: &entity.ScalarRecord{
-				Value:  current.value,
-				Expiry: current.expiry,
-				State:  current.state,
-			},
+			Key:    current.key,
+			Record: entity.NewRecord(current.value, 0, current.expiry, current.state),
 		})
 		current = current.next[0]
 	}
 
 	return recordList
 }
+
+// GetRecordsFrom returns the records with keys greater than or equal to the
+// given one in sorted order. Unlike GetAllRecords, expired records are kept so
+// that callers can tell a key expired from a key never written.
+func (sl *SkipList) GetRecordsFrom(from string) []*entity.RecordKV {
+	recordList := make([]*entity.RecordKV, 0)
+	current := sl.head
+
+	for i := sl.level - 1; i >= 0; i-- {
+		for current.next[i] != nil && current.next[i].key < from {
+			current = current.next[i]
+		}
+	}
+
+	for current = current.next[0]; current != nil; current = current.next[0] {
+		recordList = append(recordList, &entity.RecordKV{
+			Key:    current.key,
+			Record: entity.NewRecord(current.value, 0, current.expiry, current.state),
+		})
+	}
+
+	return recordList
+}
@@ -128,3 +128,30 @@ func TestSkipList(t *testing.T) {
 		}
 	})
 }
+
+func TestSkipListGetRecordsFrom(t *testing.T) {
+	sl := NewSkipList()
+	currTime := time.Now().Unix()
+
+	sl.Insert("a", "Value a", currTime+10, entity.RecordStateActive)
+	sl.Insert("b", "Value b", currTime-10, entity.RecordStateActive)
+	sl.Insert("c", nil, currTime+10, entity.RecordStateTombstoned)
+	sl.Insert("d", "Value d", currTime+10, entity.RecordStateActive)
+
+	records := sl.GetRecordsFrom("b")
+
+	expected := []string{"b", "c", "d"}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(records))
+	}
+
+	for i, record := range records {
+		if record.Key != expected[i] {
+			t.Errorf("GetRecordsFrom()[%d] = %s, want %s", i, record.Key, expected[i])
+		}
+	}
+
+	if !records[1].Record.IsTombstoned() {
+		t.Errorf("Expected the tombstone of c to be kept")
+	}
+}
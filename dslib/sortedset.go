@@ -0,0 +1,105 @@
+package dslib
+
+import (
+	"universum/entity"
+)
+
+func init() {
+	entity.NewSortedSetValue = func() entity.SortedSetValue {
+		return NewSortedSet()
+	}
+}
+
+// SortedSet implements entity.SortedSetValue. Scores are looked up by member
+// in a map, while a ScoredSkipList keeps the members in score order.
+type SortedSet struct {
+	scores map[string]float64
+	index  *ScoredSkipList
+}
+
+// NewSortedSet creates a new empty sorted set
+func NewSortedSet() *SortedSet {
+	return &SortedSet{
+		scores: make(map[string]float64),
+		index:  NewScoredSkipList(),
+	}
+}
+
+// Len returns the number of members in the sorted set
+func (ss *SortedSet) Len() int64 {
+	return int64(len(ss.scores))
+}
+
+// Score returns the score of the member, if it exists
+func (ss *SortedSet) Score(member string) (float64, bool) {
+	score, ok := ss.scores[member]
+	return score, ok
+}
+
+// Add sets the score of the member, and tells if the member was newly added
+func (ss *SortedSet) Add(member string, score float64) bool {
+	oldScore, exists := ss.scores[member]
+	if exists {
+		if oldScore == score {
+			return false
+		}
+		ss.index.Remove(member, oldScore)
+	}
+
+	ss.scores[member] = score
+	ss.index.Insert(member, score)
+	return !exists
+}
+
+// Remove deletes the member, and tells if it existed
+func (ss *SortedSet) Remove(member string) bool {
+	score, exists := ss.scores[member]
+	if !exists {
+		return false
+	}
+
+	delete(ss.scores, member)
+	ss.index.Remove(member, score)
+	return true
+}
+
+// Rank returns the zero based position of the member in score order
+func (ss *SortedSet) Rank(member string) (int64, bool) {
+	score, exists := ss.scores[member]
+	if !exists {
+		return -1, false
+	}
+
+	return int64(ss.index.Rank(member, score)), true
+}
+
+// RangeByRank returns the members between the start and stop positions, both
+// inclusive, where negative positions count backwards from the highest score.
+func (ss *SortedSet) RangeByRank(start int64, stop int64) []entity.ScoredMember {
+	length := ss.Len()
+
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+
+	return ss.index.RangeByRank(int(start), int(stop))
+}
+
+// RangeByScore returns the members with scores between min and max, both inclusive
+func (ss *SortedSet) RangeByScore(min float64, max float64) []entity.ScoredMember {
+	return ss.index.RangeByScore(min, max)
+}
+
+// Clone returns a copy of the sorted set, so that it can be modified
+// without affecting readers of the original one.
+func (ss *SortedSet) Clone() entity.SortedSetValue {
+	clone := NewSortedSet()
+	for _, scored := range ss.index.RangeByRank(0, ss.index.Size()-1) {
+		clone.scores[scored.Member] = scored.Score
+		clone.index.Insert(scored.Member, scored.Score)
+	}
+	return clone
+}
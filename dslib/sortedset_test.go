@@ -0,0 +1,130 @@
+package dslib
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"universum/entity"
+)
+
+func TestSortedSet(t *testing.T) {
+	ss := NewSortedSet()
+
+	ss.Add("c", 3)
+	ss.Add("a", 1)
+	ss.Add("b", 2)
+	ss.Add("bb", 2)
+
+	t.Run("AddExistingMember", func(t *testing.T) {
+		if added := ss.Add("a", 1); added {
+			t.Errorf("Expected Add(a) to report an existing member")
+		}
+		if ss.Len() != 4 {
+			t.Errorf("Expected length 4, got %d", ss.Len())
+		}
+	})
+
+	t.Run("RangeByRank", func(t *testing.T) {
+		expected := []entity.ScoredMember{{Member: "a", Score: 1}, {Member: "b", Score: 2}, {Member: "bb", Score: 2}, {Member: "c", Score: 3}}
+		if members := ss.RangeByRank(0, -1); !reflect.DeepEqual(members, expected) {
+			t.Errorf("RangeByRank(0, -1) = %v, want %v", members, expected)
+		}
+
+		expected = []entity.ScoredMember{{Member: "bb", Score: 2}, {Member: "c", Score: 3}}
+		if members := ss.RangeByRank(-2, 10); !reflect.DeepEqual(members, expected) {
+			t.Errorf("RangeByRank(-2, 10) = %v, want %v", members, expected)
+		}
+
+		if members := ss.RangeByRank(3, 1); len(members) != 0 {
+			t.Errorf("Expected an empty range, got %v", members)
+		}
+	})
+
+	t.Run("RangeByScore", func(t *testing.T) {
+		expected := []entity.ScoredMember{{Member: "b", Score: 2}, {Member: "bb", Score: 2}, {Member: "c", Score: 3}}
+		if members := ss.RangeByScore(1.5, 3); !reflect.DeepEqual(members, expected) {
+			t.Errorf("RangeByScore(1.5, 3) = %v, want %v", members, expected)
+		}
+	})
+
+	t.Run("UpdateScore", func(t *testing.T) {
+		ss.Add("a", 5)
+		if rank, _ := ss.Rank("a"); rank != 3 {
+			t.Errorf("Expected rank 3 after the update, got %d", rank)
+		}
+		if score, _ := ss.Score("a"); score != 5 {
+			t.Errorf("Expected score 5 after the update, got %v", score)
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		if !ss.Remove("b") {
+			t.Errorf("Expected Remove(b) to return true")
+		}
+		if ss.Remove("b") {
+			t.Errorf("Expected second Remove(b) to return false")
+		}
+		if _, ok := ss.Rank("b"); ok {
+			t.Errorf("Expected b to have no rank after removal")
+		}
+		if rank, _ := ss.Rank("bb"); rank != 0 {
+			t.Errorf("Expected rank 0 for bb, got %d", rank)
+		}
+	})
+
+	t.Run("CloneIsIndependent", func(t *testing.T) {
+		clone := ss.Clone()
+		clone.Add("z", 100)
+
+		if ss.Len() != 3 || clone.Len() != 4 {
+			t.Errorf("Expected lengths 3 and 4, got %d and %d", ss.Len(), clone.Len())
+		}
+	})
+}
+
+func TestScoredSkipListRanks(t *testing.T) {
+	ss := NewSortedSet()
+	scores := make(map[string]float64)
+
+	for i := 0; i < 500; i++ {
+		member := fmt.Sprintf("member-%d", rand.Intn(200))
+		score := float64(rand.Intn(50))
+
+		if rand.Intn(4) == 0 {
+			ss.Remove(member)
+			delete(scores, member)
+			continue
+		}
+
+		ss.Add(member, score)
+		scores[member] = score
+	}
+
+	expected := make([]entity.ScoredMember, 0, len(scores))
+	for member, score := range scores {
+		expected = append(expected, entity.ScoredMember{Member: member, Score: score})
+	}
+	sort.Slice(expected, func(i, j int) bool {
+		if expected[i].Score != expected[j].Score {
+			return expected[i].Score < expected[j].Score
+		}
+		return expected[i].Member < expected[j].Member
+	})
+
+	if members := ss.RangeByRank(0, -1); !reflect.DeepEqual(members, expected) {
+		t.Fatalf("RangeByRank(0, -1) does not match the expected order")
+	}
+
+	for idx, scored := range expected {
+		if rank, _ := ss.Rank(scored.Member); rank != int64(idx) {
+			t.Errorf("Rank(%s) = %d, want %d", scored.Member, rank, idx)
+		}
+
+		members := ss.RangeByRank(int64(idx), int64(idx))
+		if len(members) != 1 || members[0] != scored {
+			t.Errorf("RangeByRank(%d, %d) = %v, want %v", idx, idx, members, scored)
+		}
+	}
+}
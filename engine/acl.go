@@ -0,0 +1,766 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"universum/config"
+	"universum/entity"
+	"universum/internal/logger"
+	"universum/resp3"
+	"universum/utils"
+)
+
+const (
+	aclCategoryRead  string = "read"
+	aclCategoryWrite string = "write"
+	aclCategoryAdmin string = "admin"
+
+	// aclDefaultUser is the identity of the connections when authentication
+	// is disabled, which are allowed everything.
+	aclDefaultUser string = "default"
+
+	aclSubcommandSetUser string = "SETUSER"
+	aclSubcommandDelUser string = "DELUSER"
+	aclSubcommandList    string = "LIST"
+	aclSubcommandWhoAmI  string = "WHOAMI"
+)
+
+var aclCategories = []string{aclCategoryRead, aclCategoryWrite, aclCategoryAdmin}
+
+// errACLSaveFailed wraps the failures to persist the ACL file.
+var errACLSaveFailed = errors.New("unable to save the ACL file")
+
+// aclCommandCategories maps the commands run through executeCommand to the
+// category a user must be allowed to run them. Commands missing from it, such
+// as PING and HELP, can be run by any authenticated user.
+var aclCommandCategories = map[string]string{
+	CommandExists:        aclCategoryRead,
+	CommandGet:           aclCategoryRead,
+	CommandMGet:          aclCategoryRead,
+	CommandTTL:           aclCategoryRead,
+	CommandScan:          aclCategoryRead,
+	CommandRange:         aclCategoryRead,
+	CommandPrefix:        aclCategoryRead,
+	CommandHGet:          aclCategoryRead,
+	CommandHMGet:         aclCategoryRead,
+	CommandHExists:       aclCategoryRead,
+	CommandHLen:          aclCategoryRead,
+	CommandHKeys:         aclCategoryRead,
+	CommandHGetAll:       aclCategoryRead,
+	CommandLRange:        aclCategoryRead,
+	CommandLLen:          aclCategoryRead,
+	CommandLIndex:        aclCategoryRead,
+	CommandSIsMember:     aclCategoryRead,
+	CommandSMembers:      aclCategoryRead,
+	CommandSCard:         aclCategoryRead,
+	CommandSInter:        aclCategoryRead,
+	CommandSUnion:        aclCategoryRead,
+	CommandSDiff:         aclCategoryRead,
+	CommandZScore:        aclCategoryRead,
+	CommandZRange:        aclCategoryRead,
+	CommandZRangeByScore: aclCategoryRead,
+	CommandZRank:         aclCategoryRead,
+	CommandZCard:         aclCategoryRead,
+	CommandSubscribe:     aclCategoryRead,
+	CommandPSubscribe:    aclCategoryRead,
+
+	CommandSet:     aclCategoryWrite,
+	CommandDelete:  aclCategoryWrite,
+	CommandIncr:    aclCategoryWrite,
+	CommandDecr:    aclCategoryWrite,
+	CommandAppend:  aclCategoryWrite,
+	CommandMSet:    aclCategoryWrite,
+	CommandMDelete: aclCategoryWrite,
+	CommandExpire:  aclCategoryWrite,
+	CommandSetNX:   aclCategoryWrite,
+	CommandGetSet:  aclCategoryWrite,
+	CommandGetDel:  aclCategoryWrite,
+	CommandCAS:     aclCategoryWrite,
+	CommandPublish: aclCategoryWrite,
+	CommandHSet:    aclCategoryWrite,
+	CommandHDel:    aclCategoryWrite,
+	CommandHIncrBy: aclCategoryWrite,
+	CommandLPush:   aclCategoryWrite,
+	CommandRPush:   aclCategoryWrite,
+	CommandLPop:    aclCategoryWrite,
+	CommandRPop:    aclCategoryWrite,
+	CommandLTrim:   aclCategoryWrite,
+	CommandBLPop:   aclCategoryWrite,
+	CommandBRPop:   aclCategoryWrite,
+	CommandSAdd:    aclCategoryWrite,
+	CommandSRem:    aclCategoryWrite,
+	CommandZAdd:    aclCategoryWrite,
+	CommandZRem:    aclCategoryWrite,
+	CommandZIncrBy: aclCategoryWrite,
+
+	CommandSnapshot: aclCategoryAdmin,
+	CommandInfo:     aclCategoryAdmin,
+	CommandACL:      aclCategoryAdmin,
+}
+
+// aclKeyspaceWideCommands lists the commands which can reach any key, and
+// are thus only allowed to the users allowed all the keys.
+var aclKeyspaceWideCommands = map[string]struct{}{
+	CommandScan:   {},
+	CommandRange:  {},
+	CommandPrefix: {},
+}
+
+// aclUser holds what a user managed through the ACL commands is allowed to do.
+type aclUser struct {
+	name            string
+	enabled         bool
+	passwordHash    string
+	categories      map[string]struct{}
+	keyPatterns     []string
+	channelPatterns []string
+}
+
+func newACLUser(name string) *aclUser {
+	return &aclUser{
+		name:            name,
+		categories:      make(map[string]struct{}),
+		keyPatterns:     make([]string, 0),
+		channelPatterns: make([]string, 0),
+	}
+}
+
+func (u *aclUser) clone() *aclUser {
+	cloned := newACLUser(u.name)
+	cloned.enabled = u.enabled
+	cloned.passwordHash = u.passwordHash
+	cloned.keyPatterns = append(cloned.keyPatterns, u.keyPatterns...)
+	cloned.channelPatterns = append(cloned.channelPatterns, u.channelPatterns...)
+
+	for category := range u.categories {
+		cloned.categories[category] = struct{}{}
+	}
+
+	return cloned
+}
+
+// applyRule updates the user as per a single ACL rule:
+//
+//   - `on`, `off` enable or disable the user
+//   - `>password` sets the password, `#hash` sets an already hashed one
+//   - `+@category`, `-@category` allow or disallow a category, or all of them with `@all`
+//   - `~pattern` allows the keys matching the glob pattern, `allkeys` allows all the keys
+//   - `&pattern` allows the pub/sub channels matching the glob pattern, `allchannels` allows all of them
+//   - `resetkeys` and `resetchannels` disallow all the keys or the channels, `reset` brings
+//     the user back to nothing allowed
+func (u *aclUser) applyRule(rule string) error {
+	switch {
+	case strings.HasPrefix(rule, ">"):
+		hash, err := utils.HashPassword(rule[1:])
+		if err != nil {
+			return err
+		}
+		u.passwordHash = hash
+
+	case strings.HasPrefix(rule, "#"):
+		if !utils.IsHashedPassword(rule[1:]) {
+			return fmt.Errorf("invalid password hash for user `%s`", u.name)
+		}
+		u.passwordHash = rule[1:]
+
+	case strings.HasPrefix(rule, "~"):
+		u.keyPatterns = append(u.keyPatterns, rule[1:])
+
+	case strings.HasPrefix(rule, "&"):
+		u.channelPatterns = append(u.channelPatterns, rule[1:])
+
+	case strings.HasPrefix(rule, "+@"), strings.HasPrefix(rule, "-@"):
+		return u.applyCategoryRule(strings.ToLower(rule[2:]), rule[0] == '+')
+
+	default:
+		switch strings.ToLower(rule) {
+		case "on":
+			u.enabled = true
+		case "off":
+			u.enabled = false
+		case "allkeys":
+			u.keyPatterns = append(u.keyPatterns, "*")
+		case "resetkeys":
+			u.keyPatterns = make([]string, 0)
+		case "allchannels":
+			u.channelPatterns = append(u.channelPatterns, "*")
+		case "resetchannels":
+			u.channelPatterns = make([]string, 0)
+		case "reset":
+			*u = *newACLUser(u.name)
+		default:
+			return fmt.Errorf("unknown ACL rule `%s`", rule)
+		}
+	}
+
+	return nil
+}
+
+func (u *aclUser) applyCategoryRule(category string, allow bool) error {
+	categories := []string{category}
+	if category == "all" {
+		categories = aclCategories
+	} else if exists, _ := utils.ExistsInList(category, aclCategories); !exists {
+		return fmt.Errorf("unknown ACL category `%s`", category)
+	}
+
+	for _, category := range categories {
+		if allow {
+			u.categories[category] = struct{}{}
+		} else {
+			delete(u.categories, category)
+		}
+	}
+
+	return nil
+}
+
+// isPermitted tells whether the user can run the command, and why not.
+func (u *aclUser) isPermitted(command *entity.Command) (bool, string) {
+	if !u.enabled {
+		return false, fmt.Sprintf("user `%s` is disabled", u.name)
+	}
+
+	category, ok := getACLCategory(command)
+	if !ok {
+		return true, ""
+	}
+
+	if _, allowed := u.categories[category]; !allowed {
+		return false, fmt.Sprintf("user `%s` is not allowed to run %s commands", u.name, category)
+	}
+
+	if _, ok := aclKeyspaceWideCommands[command.Name]; ok && !u.canAccessAllKeys() {
+		return false, fmt.Sprintf("user `%s` is not allowed to access all the keys", u.name)
+	}
+
+	for _, key := range getCommandKeys(command) {
+		if !u.canAccessKey(key) {
+			return false, fmt.Sprintf("user `%s` is not allowed to access the key `%s`", u.name, key)
+		}
+	}
+
+	isPattern := command.Name == CommandPSubscribe
+	for _, channel := range getCommandChannels(command) {
+		if !u.canAccessChannel(channel, isPattern) {
+			return false, fmt.Sprintf("user `%s` is not allowed to access the channel `%s`", u.name, channel)
+		}
+	}
+
+	return true, ""
+}
+
+func (u *aclUser) canAccessKey(key string) bool {
+	for _, pattern := range u.keyPatterns {
+		if utils.MatchGlob(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *aclUser) canAccessAllKeys() bool {
+	exists, _ := utils.ExistsInList("*", u.keyPatterns)
+	return exists
+}
+
+// canAccessChannel tells whether the user can publish or subscribe to the channel.
+// A pattern subscribed to could match any channel, so it must be one of the
+// patterns of the user itself, unless the user is allowed all the channels.
+func (u *aclUser) canAccessChannel(channel string, isPattern bool) bool {
+	for _, pattern := range u.channelPatterns {
+		if pattern == "*" || pattern == channel || (!isPattern && utils.MatchGlob(pattern, channel)) {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the user as the rules which recreate it, which is also
+// the way it is persisted to the ACL file.
+func (u *aclUser) String() string {
+	rules := []string{"user", u.name, "off"}
+	if u.enabled {
+		rules[2] = "on"
+	}
+
+	if u.passwordHash != "" {
+		rules = append(rules, "#"+u.passwordHash)
+	}
+
+	for _, pattern := range u.keyPatterns {
+		rules = append(rules, "~"+pattern)
+	}
+
+	for _, pattern := range u.channelPatterns {
+		rules = append(rules, "&"+pattern)
+	}
+
+	for _, category := range aclCategories {
+		if _, ok := u.categories[category]; ok {
+			rules = append(rules, "+@"+category)
+		}
+	}
+
+	return strings.Join(rules, " ")
+}
+
+// aclRegistry holds the users managed through the ACL commands, and persists
+// them to the ACL file after every change, when one is configured.
+type aclRegistry struct {
+	mutex    sync.RWMutex
+	users    map[string]*aclUser
+	filePath string
+
+	// version is bumped on every change of the users, for the credentials
+	// verified before the change to be verified again.
+	version atomic.Uint64
+}
+
+var acl = &aclRegistry{
+	users: make(map[string]*aclUser),
+}
+
+// load replaces the users with the ones stored in the ACL file. A missing
+// file is no error, it is created on the first change.
+func (r *aclRegistry) load(filePath string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.filePath = filePath
+	r.users = make(map[string]*aclUser)
+	r.version.Add(1)
+
+	if filePath == "" {
+		return nil
+	}
+
+	file, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if len(fields) < 2 || fields[0] != "user" {
+			return fmt.Errorf("invalid ACL entry at line %d", lineNumber)
+		}
+
+		user := newACLUser(fields[1])
+		for _, rule := range fields[2:] {
+			if err := user.applyRule(rule); err != nil {
+				return fmt.Errorf("invalid ACL entry at line %d: %v", lineNumber, err)
+			}
+		}
+		r.users[user.name] = user
+	}
+
+	return scanner.Err()
+}
+
+// setUser applies the rules to the user, creating it if it does not exist.
+func (r *aclRegistry) setUser(name string, rules []string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	user := newACLUser(name)
+	if existing, ok := r.users[name]; ok {
+		user = existing.clone()
+	}
+
+	for _, rule := range rules {
+		if err := user.applyRule(rule); err != nil {
+			return err
+		}
+	}
+
+	users := r.copyUsers()
+	users[name] = user
+
+	return r.replaceUsers(users)
+}
+
+// deleteUsers removes the users, and returns how many of them existed.
+func (r *aclRegistry) deleteUsers(names []string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var deleted int64
+	users := r.copyUsers()
+	for _, name := range names {
+		if _, ok := users[name]; ok {
+			delete(users, name)
+			deleted++
+		}
+	}
+
+	if deleted == 0 {
+		return 0, nil
+	}
+
+	return deleted, r.replaceUsers(users)
+}
+
+func (r *aclRegistry) list() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entries := make([]string, 0, len(r.users))
+	for _, user := range r.users {
+		entries = append(entries, user.String())
+	}
+
+	sort.Strings(entries)
+	return entries
+}
+
+func (r *aclRegistry) getUser(name string) (*aclUser, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	user, ok := r.users[name]
+	return user, ok
+}
+
+// authenticate tells whether the enabled user exists with the given password.
+func (r *aclRegistry) authenticate(name string, password string) bool {
+	user, ok := r.getUser(name)
+	if !ok || !user.enabled || user.passwordHash == "" {
+		return false
+	}
+
+	return utils.VerifyPassword(password, user.passwordHash)
+}
+
+// copyUsers returns a shallow copy of the users, for a change to be made to
+// it and persisted before it replaces them. The caller must hold the mutex.
+func (r *aclRegistry) copyUsers() map[string]*aclUser {
+	users := make(map[string]*aclUser, len(r.users))
+	for name, user := range r.users {
+		users[name] = user
+	}
+	return users
+}
+
+// replaceUsers persists the users to the ACL file, and only then replaces the
+// current users with them, so that a failed write changes nothing. The users
+// themselves are never modified in place, which lets getUser hand them out.
+// The caller must hold the mutex.
+func (r *aclRegistry) replaceUsers(users map[string]*aclUser) error {
+	if r.filePath != "" {
+		entries := make([]string, 0, len(users))
+		for _, user := range users {
+			entries = append(entries, user.String()+"\n")
+		}
+		sort.Strings(entries)
+
+		if err := writeFileAtomically(r.filePath, []byte(strings.Join(entries, ""))); err != nil {
+			return fmt.Errorf("%w: %v", errACLSaveFailed, err)
+		}
+	}
+
+	r.users = users
+	r.version.Add(1)
+	return nil
+}
+
+// writeFileAtomically replaces the file with the content, through a temporary
+// file renamed over it, so that a crash never leaves it half written.
+func writeFileAtomically(filePath string, content []byte) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(content); err != nil {
+		tempFile.Close()
+		return err
+	}
+
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		return err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), filePath)
+}
+
+// setupACL loads the users from the ACL file set in the config.
+func setupACL() error {
+	if err := acl.load(config.Store.Auth.ACLFilePath); err != nil {
+		return fmt.Errorf("unable to load the ACL file: %v", err)
+	}
+
+	return nil
+}
+
+type sessionUserKey struct{}
+
+// withSessionUser attaches the user the session authenticated as to the context
+// of its commands, for the ACL to be checked against. Unauthenticated sessions
+// carry no user, as they only get to run commands when authentication is off.
+func withSessionUser(ctx context.Context, session *Session) context.Context {
+	if !session.isAuthenticated() {
+		return ctx
+	}
+	return context.WithValue(ctx, sessionUserKey{}, session.user)
+}
+
+func getSessionUser(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(sessionUserKey{}).(string)
+	return user, ok
+}
+
+// checkPermissions tells whether the user the command runs as is allowed to
+// run it as per the ACL, and why not. The user from the config is allowed
+// everything, as are all the connections when authentication is disabled.
+func checkPermissions(ctx context.Context, command *entity.Command) (bool, string) {
+	name, ok := getSessionUser(ctx)
+	if !ok || name == config.Store.Auth.DbUserName {
+		return true, ""
+	}
+
+	user, ok := acl.getUser(name)
+	if !ok {
+		return false, fmt.Sprintf("user `%s` does not exist anymore", name)
+	}
+
+	return user.isPermitted(command)
+}
+
+// getACLCategory returns the category of the command, and false if it has
+// none. ACL WHOAMI has none, unlike the other ACL subcommands.
+func getACLCategory(command *entity.Command) (string, bool) {
+	if command.Name == CommandACL && len(command.Args) > 0 {
+		if subcommand, ok := command.Args[0].(string); ok && strings.ToUpper(subcommand) == aclSubcommandWhoAmI {
+			return "", false
+		}
+	}
+
+	category, ok := aclCommandCategories[command.Name]
+	return category, ok
+}
+
+// getCommandKeys returns the keys the command operates on. Commands whose
+// arguments are not what they expect are left for their validation to refuse.
+func getCommandKeys(command *entity.Command) []string {
+	if len(command.Args) == 0 {
+		return nil
+	}
+
+	switch command.Name {
+	case CommandMGet, CommandMDelete, CommandBLPop, CommandBRPop,
+		CommandSInter, CommandSUnion, CommandSDiff:
+		keys, _ := getStringSlice(command.Args[0])
+		return keys
+
+	case CommandMSet:
+		kvMap, _ := command.Args[0].(map[string]interface{})
+		keys := make([]string, 0, len(kvMap))
+		for key := range kvMap {
+			keys = append(keys, key)
+		}
+		return keys
+
+	case CommandPublish, CommandSubscribe, CommandPSubscribe,
+		CommandACL, CommandScan, CommandRange, CommandPrefix:
+		return nil
+	}
+
+	if key, ok := command.Args[0].(string); ok {
+		return []string{key}
+	}
+	return nil
+}
+
+// getCommandChannels returns the pub/sub channels the command publishes or
+// subscribes to, or the patterns for PSUBSCRIBE.
+func getCommandChannels(command *entity.Command) []string {
+	if len(command.Args) == 0 {
+		return nil
+	}
+
+	switch command.Name {
+	case CommandPublish:
+		if channel, ok := command.Args[0].(string); ok {
+			return []string{channel}
+		}
+
+	case CommandSubscribe, CommandPSubscribe:
+		channels, _ := getStringSlice(command.Args[0])
+		return channels
+	}
+
+	return nil
+}
+
+// authenticate tells whether the user exists with the given password, which
+// is either the user from the config or one of the ACL users.
+func authenticate(user string, password string) bool {
+	if user == config.Store.Auth.DbUserName {
+		return utils.VerifyPassword(password, config.Store.Auth.DbUserPassword)
+	}
+
+	return acl.authenticate(user, password)
+}
+
+// isKnownUser tells whether the user is the one from the config or an enabled
+// ACL user.
+func isKnownUser(name string) bool {
+	if name == config.Store.Auth.DbUserName {
+		return true
+	}
+
+	user, ok := acl.getUser(name)
+	return ok && user.enabled
+}
+
+func executeACL(ctx context.Context, command *entity.Command) string {
+	if len(command.Args) == 0 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, "ACL subcommand expected"})
+	}
+
+	subcommand, ok := command.Args[0].(string)
+	if !ok {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, "ACL subcommand should be a string"})
+	}
+
+	subcommand = strings.ToUpper(subcommand)
+	aclCommand := &entity.Command{Name: CommandACL + " " + subcommand, Args: command.Args[1:]}
+
+	switch subcommand {
+	case aclSubcommandSetUser:
+		return executeACLSETUSER(aclCommand)
+
+	case aclSubcommandDelUser:
+		return executeACLDELUSER(aclCommand)
+
+	case aclSubcommandList:
+		return executeACLLIST(aclCommand)
+
+	case aclSubcommandWhoAmI:
+		return executeACLWHOAMI(ctx, aclCommand)
+
+	default:
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, fmt.Sprintf("unknown ACL subcommand `%s`", subcommand)})
+	}
+}
+
+func executeACLSETUSER(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "user", Datatype: reflect.String},
+		{Name: "rules", Datatype: reflect.Slice},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	name := command.Args[0].(string)
+	aclRules, ok := getStringSlice(command.Args[1])
+	if !ok {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, "second argument should be a list of string"})
+	}
+
+	if name == "" || strings.ContainsAny(name, " \t\r\n") {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, "user name should be a non-empty string without spaces"})
+	}
+
+	if name == config.Store.Auth.DbUserName || name == aclDefaultUser {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, fmt.Sprintf("user `%s` can not be managed through ACL", name)})
+	}
+
+	if err := acl.setUser(name, aclRules); err != nil {
+		return getACLErrorResponse(err)
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{"OK", entity.CRC_ACL_UPDATED, ""})
+}
+
+func executeACLDELUSER(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "users", Datatype: reflect.Slice},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	names, ok := getStringSlice(command.Args[0])
+	if !ok || len(names) == 0 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, "first argument should be a non-empty list of string"})
+	}
+
+	deleted, err := acl.deleteUsers(names)
+	if err != nil {
+		return getACLErrorResponse(err)
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{deleted, entity.CRC_ACL_UPDATED, ""})
+}
+
+func executeACLLIST(command *entity.Command) string {
+	rules := []utils.ValidationRule{}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{acl.list(), entity.CRC_ACL_CONTENT_OK, ""})
+}
+
+func executeACLWHOAMI(ctx context.Context, command *entity.Command) string {
+	rules := []utils.ValidationRule{}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	user, ok := getSessionUser(ctx)
+	if !ok {
+		user = aclDefaultUser
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{user, entity.CRC_ACL_CONTENT_OK, ""})
+}
+
+// getACLErrorResponse tells the invalid rules apart from the failures to
+// persist the change, which are logged as well.
+func getACLErrorResponse(err error) string {
+	if errors.Is(err, errACLSaveFailed) {
+		logger.Get().Error("%v", err)
+		return resp3.EncodedRESP3Response([]interface{}{nil, entity.CRC_ACL_SAVE_FAILED, err.Error()})
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{nil, entity.CRC_INVALID_CMD_INPUT, err.Error()})
+}
@@ -0,0 +1,207 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"universum/config"
+	"universum/entity"
+)
+
+func setupACLTests(t *testing.T) *Session {
+	setupAuthTests(t)
+
+	config.Store.Auth.ACLFilePath = filepath.Join(t.TempDir(), "users.acl")
+	if err := setupACL(); err != nil {
+		t.Fatalf("failed to set up the ACL: %v", err)
+	}
+	t.Cleanup(func() { acl.load("") })
+
+	admin := NewSession()
+	runInSession(t, admin, CommandAuth, "admin", "s3cret")
+	return admin
+}
+
+func TestACLSetUserPermissions(t *testing.T) {
+	admin := setupACLTests(t)
+
+	response := runInSession(t, admin, CommandACL, "SETUSER", "reader", []interface{}{"on", ">pass", "+@read", "~app:*"})
+	if responseCode(response) != entity.CRC_ACL_UPDATED {
+		t.Fatalf("expected the user to be set, got %v", response)
+	}
+
+	runInSession(t, admin, CommandSet, "app:name", "universum", int64(0))
+
+	reader := NewSession()
+	if response := runInSession(t, reader, CommandAuth, "reader", "pass"); responseCode(response) != entity.CRC_AUTHENTICATED {
+		t.Fatalf("expected the ACL user to authenticate, got %v", response)
+	}
+
+	if response := runInSession(t, reader, CommandGet, "app:name"); responseCode(response) != entity.CRC_RECORD_FOUND {
+		t.Errorf("expected reads of matching keys to be allowed, got %v", response)
+	}
+
+	testCases := []struct {
+		name string
+		args []interface{}
+	}{
+		{CommandSet, []interface{}{"app:name", "other", int64(0)}},
+		{CommandGet, []interface{}{"secret"}},
+		{CommandMGet, []interface{}{[]interface{}{"app:name", "secret"}}},
+		{CommandScan, []interface{}{"0"}},
+		{CommandInfo, []interface{}{}},
+		{CommandACL, []interface{}{"LIST"}},
+	}
+
+	for _, tc := range testCases {
+		if response := runInSession(t, reader, tc.name, tc.args...); responseCode(response) != entity.CRC_PERMISSION_DENIED {
+			t.Errorf("expected %s %v to be denied, got %v", tc.name, tc.args, response)
+		}
+	}
+
+	if response := runInSession(t, reader, CommandACL, "WHOAMI"); response[0] != "reader" {
+		t.Errorf("expected WHOAMI to be allowed to everyone, got %v", response)
+	}
+}
+
+func TestACLQueuedCommandsAreChecked(t *testing.T) {
+	admin := setupACLTests(t)
+	runInSession(t, admin, CommandACL, "SETUSER", "reader", []interface{}{"on", ">pass", "+@read", "allkeys"})
+
+	reader := NewSession()
+	runInSession(t, reader, CommandAuth, "reader", "pass")
+	runInSession(t, reader, CommandMulti)
+	runInSession(t, reader, CommandSet, "key", "value", int64(0))
+
+	results := runInSession(t, reader, CommandExec)[0].([]interface{})
+	if responseCode(results[0].([]interface{})) != entity.CRC_PERMISSION_DENIED {
+		t.Errorf("expected the queued write to be denied, got %v", results)
+	}
+}
+
+func TestACLChannelPermissions(t *testing.T) {
+	admin := setupACLTests(t)
+	runInSession(t, admin, CommandACL, "SETUSER", "reader", []interface{}{"on", ">pass", "+@read", "+@write", "~app:*", "&news:*"})
+
+	reader := NewSession()
+	defer reader.Close()
+	runInSession(t, reader, CommandAuth, "reader", "pass")
+
+	testCases := []struct {
+		name    string
+		args    []interface{}
+		allowed bool
+	}{
+		{CommandSubscribe, []interface{}{[]interface{}{"news:sports"}}, true},
+		{CommandPSubscribe, []interface{}{[]interface{}{"news:*"}}, true},
+		{CommandPublish, []interface{}{"news:sports", "goal"}, true},
+		{CommandSubscribe, []interface{}{[]interface{}{"news:sports", "__keyevent__:set"}}, false},
+		{CommandPSubscribe, []interface{}{[]interface{}{"__keyspace__:*"}}, false},
+		{CommandPSubscribe, []interface{}{[]interface{}{"*"}}, false},
+		{CommandPublish, []interface{}{"__keyspace__:secret", "set"}, false},
+	}
+
+	for _, tc := range testCases {
+		response := runInSession(t, reader, tc.name, tc.args...)
+		if denied := responseCode(response) == entity.CRC_PERMISSION_DENIED; denied == tc.allowed {
+			t.Errorf("expected %s %v to be allowed: %v, got %v", tc.name, tc.args, tc.allowed, response)
+		}
+	}
+
+	// the channels are kept along with the rest of the user
+	listed := runInSession(t, admin, CommandACL, "LIST")[0].([]interface{})
+	if len(listed) != 1 || !strings.Contains(listed[0].(string), "&news:*") {
+		t.Errorf("expected the channel patterns to be listed, got %v", listed)
+	}
+
+	runInSession(t, admin, CommandACL, "SETUSER", "reader", []interface{}{"resetchannels"})
+	if response := runInSession(t, reader, CommandSubscribe, []interface{}{"news:sports"}); responseCode(response) != entity.CRC_PERMISSION_DENIED {
+		t.Errorf("expected the channels to be reset, got %v", response)
+	}
+}
+
+func TestACLDeleteUser(t *testing.T) {
+	admin := setupACLTests(t)
+	runInSession(t, admin, CommandACL, "SETUSER", "temp", []interface{}{"on", ">pass", "+@all", "allkeys"})
+
+	temp := NewSession()
+	runInSession(t, temp, CommandAuth, "temp", "pass")
+
+	if response := runInSession(t, admin, CommandACL, "DELUSER", []interface{}{"temp", "missing"}); response[0] != int64(1) {
+		t.Fatalf("expected one user to be deleted, got %v", response)
+	}
+
+	if response := runInSession(t, temp, CommandGet, "key"); responseCode(response) != entity.CRC_PERMISSION_DENIED {
+		t.Errorf("expected the deleted user to be denied, got %v", response)
+	}
+
+	if response := runInSession(t, NewSession(), CommandAuth, "temp", "pass"); responseCode(response) != entity.CRC_INVALID_CREDENTIALS {
+		t.Errorf("expected the deleted user not to authenticate, got %v", response)
+	}
+}
+
+func TestACLPersistence(t *testing.T) {
+	admin := setupACLTests(t)
+
+	runInSession(t, admin, CommandACL, "SETUSER", "writer", []interface{}{"on", ">pass", "+@write", "~jobs:*"})
+	runInSession(t, admin, CommandACL, "SETUSER", "writer", []interface{}{"+@read"})
+
+	if response := runInSession(t, admin, CommandACL, "SETUSER", "writer", []interface{}{"+@nothing"}); responseCode(response) != entity.CRC_INVALID_CMD_INPUT {
+		t.Fatalf("expected an unknown category to be refused, got %v", response)
+	}
+
+	if response := runInSession(t, admin, CommandACL, "SETUSER", "admin", []interface{}{"off"}); responseCode(response) != entity.CRC_INVALID_CMD_INPUT {
+		t.Fatalf("expected the config user not to be managed through ACL, got %v", response)
+	}
+
+	content, err := os.ReadFile(config.Store.Auth.ACLFilePath)
+	if err != nil {
+		t.Fatalf("expected the ACL file to be written: %v", err)
+	}
+
+	if strings.Contains(string(content), "pass ") || !strings.Contains(string(content), "+@read +@write") {
+		t.Errorf("unexpected ACL file content %q", content)
+	}
+
+	listed := runInSession(t, admin, CommandACL, "LIST")[0].([]interface{})
+
+	// reloading the file brings the users back as they were
+	if err := setupACL(); err != nil {
+		t.Fatalf("failed to reload the ACL: %v", err)
+	}
+
+	reloaded := runInSession(t, admin, CommandACL, "LIST")[0].([]interface{})
+	if len(listed) != 1 || len(reloaded) != 1 || listed[0] != reloaded[0] {
+		t.Errorf("expected %v to be reloaded, got %v", listed, reloaded)
+	}
+
+	if response := runInSession(t, NewSession(), CommandAuth, "writer", "pass"); responseCode(response) != entity.CRC_AUTHENTICATED {
+		t.Errorf("expected the reloaded user to authenticate, got %v", response)
+	}
+}
+
+func TestAuthenticateSessionAsCertificateUser(t *testing.T) {
+	admin := setupACLTests(t)
+	runInSession(t, admin, CommandACL, "SETUSER", "sidecar", []interface{}{"on", "+@read", "allkeys"})
+	runInSession(t, admin, CommandACL, "SETUSER", "retired", []interface{}{"off", "+@all", "allkeys"})
+
+	session := NewSession()
+	if !AuthenticateSession(session, "sidecar") {
+		t.Fatal("expected the session to authenticate as the user without a password")
+	}
+
+	if response := runInSession(t, session, CommandGet, "key"); responseCode(response) == entity.CRC_AUTHENTICATION_REQUIRED {
+		t.Errorf("expected the session to be authenticated, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandSet, "key", "value", int64(0)); responseCode(response) != entity.CRC_PERMISSION_DENIED {
+		t.Errorf("expected the permissions of the user to apply, got %v", response)
+	}
+
+	for _, user := range []string{"retired", "missing"} {
+		if AuthenticateSession(NewSession(), user) {
+			t.Errorf("expected the session not to authenticate as %s", user)
+		}
+	}
+}
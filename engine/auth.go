@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"reflect"
+	"universum/config"
+	"universum/entity"
+	"universum/resp3"
+	"universum/utils"
+)
+
+// commandsAllowedBeforeAuthentication lists the commands a connection can run
+// before it authenticates, when authentication is enabled.
+var commandsAllowedBeforeAuthentication = map[string]struct{}{
+	CommandPing:  {},
+	CommandHelp:  {},
+	CommandAuth:  {},
+	CommandHello: {},
+}
+
+// isAuthenticationRequired tells whether the command has to be refused on
+// the session, for the connection not having authenticated yet.
+func isAuthenticationRequired(session *Session, command *entity.Command) bool {
+	if !config.Store.Auth.AuthenticationEnabled || session.isAuthenticated() {
+		return false
+	}
+
+	_, allowed := commandsAllowedBeforeAuthentication[command.Name]
+	return !allowed
+}
+
+// AuthenticateSession authenticates the session as the user, without asking for
+// a password, for the connections which proved who they are otherwise, such as
+// with a client certificate verified against the CA bundle. It tells whether the
+// user exists and is enabled, authentication being enabled.
+func AuthenticateSession(session *Session, user string) bool {
+	if !config.Store.Auth.AuthenticationEnabled || !isKnownUser(user) {
+		return false
+	}
+
+	session.user = user
+	return true
+}
+
+// GetCredentialsVersion returns the version of the users and their passwords,
+// which changes with every change made to the ACL users, for the clients which
+// remember the credentials they verified to forget them.
+func GetCredentialsVersion() uint64 {
+	return acl.version.Load()
+}
+
+// AuthenticateSessionWithPassword authenticates the session as the user with the
+// password, just like AUTH, for the clients which do not send AUTH as a command,
+// such as the HTTP gateway. It tells whether the credentials are valid,
+// authentication being enabled.
+func AuthenticateSessionWithPassword(session *Session, user string, password string) bool {
+	if !config.Store.Auth.AuthenticationEnabled || !authenticate(user, password) {
+		return false
+	}
+
+	session.user = user
+	return true
+}
+
+func executeAUTH(session *Session, command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "user", Datatype: reflect.String},
+		{Name: "password", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	if !config.Store.Auth.AuthenticationEnabled {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CREDENTIALS, "authentication is not enabled"})
+	}
+
+	user := command.Args[0].(string)
+	password := command.Args[1].(string)
+
+	if !authenticate(user, password) {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CREDENTIALS, "invalid username or password"})
+	}
+
+	session.user = user
+	return resp3.EncodedRESP3Response([]interface{}{"OK", entity.CRC_AUTHENTICATED, ""})
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"testing"
+	"universum/config"
+	"universum/entity"
+	"universum/utils"
+)
+
+func setupAuthTests(t *testing.T) {
+	setupBlockingTests()
+
+	hash, err := utils.HashPassword("s3cret")
+	if err != nil {
+		t.Fatalf("failed to hash the password: %v", err)
+	}
+
+	config.Store.Auth.AuthenticationEnabled = true
+	config.Store.Auth.DbUserName = "admin"
+	config.Store.Auth.DbUserPassword = hash
+
+	t.Cleanup(func() { config.Store.Auth = &config.Auth{} })
+}
+
+func TestCommandsRefusedUntilAuthenticated(t *testing.T) {
+	setupAuthTests(t)
+	session := NewSession()
+
+	if response := runInSession(t, session, CommandSet, "key", "value", int64(0)); responseCode(response) != entity.CRC_AUTHENTICATION_REQUIRED {
+		t.Fatalf("expected SET to require authentication, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandMulti); responseCode(response) != entity.CRC_AUTHENTICATION_REQUIRED {
+		t.Fatalf("expected MULTI to require authentication, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandPing); responseCode(response) != entity.CRC_PING_SUCCESS {
+		t.Errorf("expected PING to be allowed, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandHelp); responseCode(response) != entity.CRC_HELP_CONTENT_OK {
+		t.Errorf("expected HELP to be allowed, got %v", response)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	setupAuthTests(t)
+	session := NewSession()
+
+	if response := runInSession(t, session, CommandAuth, "admin", "wrong"); responseCode(response) != entity.CRC_INVALID_CREDENTIALS {
+		t.Fatalf("expected a wrong password to be refused, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandAuth, "root", "s3cret"); responseCode(response) != entity.CRC_INVALID_CREDENTIALS {
+		t.Fatalf("expected an unknown user to be refused, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandAuth, "admin", "s3cret"); responseCode(response) != entity.CRC_AUTHENTICATED {
+		t.Fatalf("expected valid credentials to authenticate, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandSet, "key", "value", int64(0)); responseCode(response) != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected SET to be allowed once authenticated, got %v", response)
+	}
+
+	// the authentication belongs to the connection only
+	if response := runInSession(t, NewSession(), CommandGet, "key"); responseCode(response) != entity.CRC_AUTHENTICATION_REQUIRED {
+		t.Errorf("expected other sessions to still require authentication, got %v", response)
+	}
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"sync"
+)
+
+// listWaiterRegistry keeps track of the clients blocked on list keys by BLPOP
+// and BRPOP, so that a push on any of those keys can wake them up.
+type listWaiterRegistry struct {
+	mutex   sync.Mutex
+	waiters map[string]map[chan struct{}]struct{}
+}
+
+var listWaiters = &listWaiterRegistry{
+	waiters: make(map[string]map[chan struct{}]struct{}),
+}
+
+// register subscribes a new waiter to pushes on all the given keys. The returned
+// channel receives a signal after a push on any of them, and the returned function
+// must be called to unsubscribe the waiter once it is done waiting.
+func (r *listWaiterRegistry) register(keys []string) (<-chan struct{}, func()) {
+	notifier := make(chan struct{}, 1)
+
+	r.mutex.Lock()
+	for _, key := range keys {
+		if _, ok := r.waiters[key]; !ok {
+			r.waiters[key] = make(map[chan struct{}]struct{})
+		}
+		r.waiters[key][notifier] = struct{}{}
+	}
+	r.mutex.Unlock()
+
+	release := func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		for _, key := range keys {
+			delete(r.waiters[key], notifier)
+			if len(r.waiters[key]) == 0 {
+				delete(r.waiters, key)
+			}
+		}
+	}
+
+	return notifier, release
+}
+
+// signal wakes up all the waiters blocked on the given key. Waiters compete
+// for the pushed elements, and the ones left empty handed go back to waiting.
+func (r *listWaiterRegistry) signal(key string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for notifier := range r.waiters[key] {
+		select {
+		case notifier <- struct{}{}:
+		default: // already signalled
+		}
+	}
+}
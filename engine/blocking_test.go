@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"universum/config"
+	"universum/entity"
+	"universum/resp3"
+)
+
+func setupBlockingTests() {
+	setupEngineTests()
+	config.Store.Storage.MaxRecordSizeInBytes = 1024
+	datastore = getDataStore(config.StorageEngineMemory)
+	setupKeyspaceNotifications()
+}
+
+func decodeResponse(t *testing.T, output string) []interface{} {
+	decoded, err := resp3.Decode(bufio.NewReader(strings.NewReader(output)))
+	if err != nil {
+		t.Fatalf("failed to decode response %q: %v", output, err)
+	}
+	return decoded.([]interface{})
+}
+
+func TestBlockingPopWakesUpOnPush(t *testing.T) {
+	setupBlockingTests()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := make(chan string, 1)
+	go func() {
+		result <- executeBLOCKINGPOP(ctx, &entity.Command{
+			Name: CommandBLPop,
+			Args: []interface{}{[]interface{}{"queue"}, int64(0)},
+		}, true)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	executePUSH(&entity.Command{
+		Name: CommandRPush,
+		Args: []interface{}{"queue", []interface{}{"job-1"}},
+	}, false)
+
+	select {
+	case output := <-result:
+		response := decodeResponse(t, output)
+		if response[1].(int64) != int64(entity.CRC_RECORD_FOUND) {
+			t.Fatalf("expected popped element, got %v", response)
+		}
+
+		popped := response[0].([]interface{})
+		if popped[0] != "queue" || popped[1] != "job-1" {
+			t.Errorf("unexpected popped element %v", popped)
+		}
+
+	case <-time.After(2 * time.Second):
+		t.Fatal("blocked pop was not woken up by the push")
+	}
+
+	if length, _ := datastore.LLen("queue"); length != 0 {
+		t.Errorf("expected the pushed element to be consumed, %d left", length)
+	}
+}
+
+func TestBlockingPopTimesOut(t *testing.T) {
+	setupBlockingTests()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	output := executeBLOCKINGPOP(ctx, &entity.Command{
+		Name: CommandBRPop,
+		Args: []interface{}{[]interface{}{"empty"}, int64(0)},
+	}, false)
+
+	response := decodeResponse(t, output)
+	if response[0] != nil || response[1].(int64) != int64(entity.CRC_WAIT_TIMED_OUT) {
+		t.Errorf("expected a timed out response, got %v", response)
+	}
+
+	listWaiters.mutex.Lock()
+	defer listWaiters.mutex.Unlock()
+	if len(listWaiters.waiters) != 0 {
+		t.Errorf("expected waiters to be released after timeout, got %v", listWaiters.waiters)
+	}
+}
@@ -19,10 +19,19 @@ func Startup() {
 		Shutdown(entity.ExitCodeStartupFailure)
 	}
 
+	if err := setupACL(); err != nil {
+		logger.Get().Fatal("Application startup failed: %v", err)
+		Shutdown(entity.ExitCodeStartupFailure)
+	}
+
 	AutoRestoreDatabaseSnapshot(datastore)
 
+	// Publish the keyspace events, after the restore so it does not flood the subscribers
+	setupKeyspaceNotifications()
+
 	expiryJobExecutionFrequency = time.Duration(config.Store.Eviction.AutoRecordExpiryFrequency) * time.Second
-	snapshotJobExecutionFrequency = time.Duration(config.Store.Storage.Memory.AutoSnapshotFrequency) * time.Second
+	snapshotJobExecutionFrequency = getSnapshotFrequency(config.Store.Storage.StorageEngine)
+	evictionJobExecutionFrequency = DefaultEvictionJobFrequency
 
 	// Trigger periodic jobs
 	triggerPeriodicExpiryJob()
@@ -2,10 +2,16 @@
 package engine
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"reflect"
+	"strings"
+	"time"
 	"universum/config"
 	"universum/entity"
 	"universum/resp3"
+	"universum/storage"
 	"universum/utils"
 )
 
@@ -65,6 +71,14 @@ func executeSET(command *entity.Command) string {
 		{Name: "ttl", Datatype: reflect.Int64},
 	}
 
+	if len(command.Args) > len(rules) {
+		required := &entity.Command{Name: command.Name, Args: command.Args[:len(rules)]}
+		if isValid, validityRes := utils.ValidateArguments(required, rules); !isValid {
+			return resp3.EncodedRESP3Response(validityRes)
+		}
+		return executeSETWITHOPTIONS(command)
+	}
+
 	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
 		return resp3.EncodedRESP3Response(validityRes)
 	}
@@ -77,6 +91,94 @@ func executeSET(command *entity.Command) string {
 	return resp3.EncodedRESP3Response([]interface{}{success, code, ""})
 }
 
+// executeSETWITHOPTIONS handles SET called with the NX, XX or GET options after
+// the key, value and ttl. The options are checked and applied atomically.
+func executeSETWITHOPTIONS(command *entity.Command) string {
+	condition, returnPrevious, errMsg := getSetOptions(command.Args[3:])
+	if errMsg != "" {
+		return resp3.EncodedRESP3Response([]interface{}{nil, entity.CRC_INVALID_CMD_INPUT, errMsg})
+	}
+
+	key, _ := command.Args[0].(string)
+	value := command.Args[1]
+	ttl, _ := command.Args[2].(int64)
+
+	previous, code := datastore.SetWithCondition(key, value, ttl, condition)
+	if returnPrevious {
+		return resp3.EncodedRESP3Response([]interface{}{getRecordValue(previous), code, ""})
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{code == entity.CRC_RECORD_UPDATED, code, ""})
+}
+
+func executeSETNX(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "value", Datatype: reflect.Interface},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	value := command.Args[1]
+
+	_, code := datastore.SetWithCondition(key, value, 0, storage.SetIfAbsent)
+	return resp3.EncodedRESP3Response([]interface{}{code == entity.CRC_RECORD_UPDATED, code, ""})
+}
+
+func executeGETSET(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "value", Datatype: reflect.Interface},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	value := command.Args[1]
+
+	previous, code := datastore.SetWithCondition(key, value, 0, storage.SetAlways)
+	return resp3.EncodedRESP3Response([]interface{}{getRecordValue(previous), code, ""})
+}
+
+func executeGETDEL(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+
+	previous, code := datastore.GetDelete(key)
+	return resp3.EncodedRESP3Response([]interface{}{getRecordValue(previous), code, ""})
+}
+
+func executeCAS(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "expected", Datatype: reflect.Interface},
+		{Name: "value", Datatype: reflect.Interface},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	expected := command.Args[1]
+	value := command.Args[2]
+
+	swapped, code := datastore.CompareAndSwap(key, expected, value)
+	return resp3.EncodedRESP3Response([]interface{}{swapped, code, ""})
+}
+
 func executeDELETE(command *entity.Command) string {
 	rules := []utils.ValidationRule{
 		{Name: "key", Datatype: reflect.String},
@@ -235,6 +337,82 @@ func executeEXPIRE(command *entity.Command) string {
 	return resp3.EncodedRESP3Response([]interface{}{success, code, ""})
 
 }
+func executeSCAN(command *entity.Command) string {
+	if len(command.Args) == 0 || len(command.Args)%2 == 0 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"ERR: SCAN takes a cursor followed by optional MATCH, COUNT and TYPE pairs"})
+	}
+
+	cursor, ok := command.Args[0].(string)
+	if zero, isInt := command.Args[0].(int64); isInt && zero == 0 {
+		cursor, ok = storage.ScanCursorStart, true
+	}
+
+	if !ok {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, "ERR: cursor has invalid type. string expected"})
+	}
+
+	options, errMessage := getScanOptions(command.Args[1:])
+	if errMessage != "" {
+		return resp3.EncodedRESP3Response([]interface{}{nil, entity.CRC_INVALID_CMD_INPUT, errMessage})
+	}
+
+	nextCursor, keys, code := datastore.Scan(cursor, options)
+	if code != entity.CRC_SCAN_COMPLETED {
+		return resp3.EncodedRESP3Response([]interface{}{nil, code, ""})
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{[]interface{}{nextCursor, keys}, code, ""})
+}
+
+func executeRANGE(command *entity.Command) string {
+	if len(command.Args) < 2 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"ERR: RANGE takes a start and an end key followed by optional LIMIT and REVERSE"})
+	}
+
+	start, isStartValid := command.Args[0].(string)
+	end, isEndValid := command.Args[1].(string)
+
+	if !isStartValid || !isEndValid {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, "ERR: start and end keys should be strings"})
+	}
+
+	limit, reverse, errMessage := getRangeOptions(command.Args[2:], true)
+	if errMessage != "" {
+		return resp3.EncodedRESP3Response([]interface{}{nil, entity.CRC_INVALID_CMD_INPUT, errMessage})
+	}
+
+	records, code := datastore.Range(start, end, limit, reverse)
+	return resp3.EncodedRESP3Response([]interface{}{recordsToPairs(records), code, ""})
+}
+
+func executePREFIX(command *entity.Command) string {
+	if len(command.Args) < 1 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"ERR: PREFIX takes a key prefix followed by optional LIMIT"})
+	}
+
+	prefix, ok := command.Args[0].(string)
+	if !ok {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, "ERR: prefix has invalid type. string expected"})
+	}
+
+	limit, _, errMessage := getRangeOptions(command.Args[1:], false)
+	if errMessage != "" {
+		return resp3.EncodedRESP3Response([]interface{}{nil, entity.CRC_INVALID_CMD_INPUT, errMessage})
+	}
+
+	records, code := datastore.Prefix(prefix, limit)
+	return resp3.EncodedRESP3Response([]interface{}{recordsToPairs(records), code, ""})
+}
+
 func executeSNAPSHOT(command *entity.Command) string {
 	rules := []utils.ValidationRule{}
 
@@ -282,3 +460,821 @@ func executeHELP(command *entity.Command) string {
 
 	return resp3.EncodedRESP3Response([]interface{}{helpcontent, entity.CRC_HELP_CONTENT_OK, ""})
 }
+
+func executeHSET(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "fields", Datatype: reflect.Map},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	fields, ok := command.Args[1].(map[string]interface{})
+
+	if !ok || len(fields) == 0 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"second argument should be a non-empty dict of string to scalar values"})
+	}
+
+	added, code := datastore.HSet(key, fields)
+	return resp3.EncodedRESP3Response([]interface{}{added, code, ""})
+}
+
+func executeHGET(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "field", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	field, _ := command.Args[1].(string)
+
+	value, code := datastore.HGet(key, field)
+	return resp3.EncodedRESP3Response([]interface{}{value, code, ""})
+}
+
+func executeHMGET(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "fields", Datatype: reflect.Slice},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	fields, ok := getStringSlice(command.Args[1])
+
+	if !ok {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"second argument should be a list of string, one or more invalid values provided"})
+	}
+
+	values, code := datastore.HMGet(key, fields)
+	return resp3.EncodedRESP3Response([]interface{}{values, code, ""})
+}
+
+func executeHDEL(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "fields", Datatype: reflect.Slice},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	fields, ok := getStringSlice(command.Args[1])
+
+	if !ok {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"second argument should be a list of string, one or more invalid values provided"})
+	}
+
+	removed, code := datastore.HDel(key, fields)
+	return resp3.EncodedRESP3Response([]interface{}{removed, code, ""})
+}
+
+func executeHEXISTS(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "field", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	field, _ := command.Args[1].(string)
+
+	exists, code := datastore.HExists(key, field)
+	return resp3.EncodedRESP3Response([]interface{}{exists, code, ""})
+}
+
+func executeHLEN(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+
+	length, code := datastore.HLen(key)
+	return resp3.EncodedRESP3Response([]interface{}{length, code, ""})
+}
+
+func executeHKEYS(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+
+	fields, code := datastore.HKeys(key)
+	return resp3.EncodedRESP3Response([]interface{}{fields, code, ""})
+}
+
+func executeHGETALL(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+
+	fields, code := datastore.HGetAll(key)
+	return resp3.EncodedRESP3Response([]interface{}{fields, code, ""})
+}
+
+func executeHINCRBY(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "field", Datatype: reflect.String},
+		{Name: "offset", Datatype: reflect.Int64},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	field, _ := command.Args[1].(string)
+	offset, _ := command.Args[2].(int64)
+
+	updatedValue, code := datastore.HIncrBy(key, field, offset)
+	return resp3.EncodedRESP3Response([]interface{}{updatedValue, code, ""})
+}
+
+func executePUSH(command *entity.Command, atHead bool) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "values", Datatype: reflect.Slice},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	values, ok := command.Args[1].([]interface{})
+
+	if !ok || len(values) == 0 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"second argument should be a non-empty list of scalar values"})
+	}
+
+	var length int64
+	var code uint32
+
+	if atHead {
+		length, code = datastore.LPush(key, values)
+	} else {
+		length, code = datastore.RPush(key, values)
+	}
+
+	if code == entity.CRC_RECORD_UPDATED {
+		listWaiters.signal(key)
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{length, code, ""})
+}
+
+func executePOP(command *entity.Command, atHead bool) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+
+	value, code := popFromList(key, atHead)
+	return resp3.EncodedRESP3Response([]interface{}{value, code, ""})
+}
+
+func executeLRANGE(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "start", Datatype: reflect.Int64},
+		{Name: "stop", Datatype: reflect.Int64},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	start, _ := command.Args[1].(int64)
+	stop, _ := command.Args[2].(int64)
+
+	values, code := datastore.LRange(key, start, stop)
+	return resp3.EncodedRESP3Response([]interface{}{values, code, ""})
+}
+
+func executeLLEN(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+
+	length, code := datastore.LLen(key)
+	return resp3.EncodedRESP3Response([]interface{}{length, code, ""})
+}
+
+func executeLINDEX(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "index", Datatype: reflect.Int64},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	index, _ := command.Args[1].(int64)
+
+	value, code := datastore.LIndex(key, index)
+	return resp3.EncodedRESP3Response([]interface{}{value, code, ""})
+}
+
+func executeLTRIM(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "start", Datatype: reflect.Int64},
+		{Name: "stop", Datatype: reflect.Int64},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	start, _ := command.Args[1].(int64)
+	stop, _ := command.Args[2].(int64)
+
+	success, code := datastore.LTrim(key, start, stop)
+	return resp3.EncodedRESP3Response([]interface{}{success, code, ""})
+}
+
+// executeBLOCKINGPOP pops from the first non-empty list among the given keys. When all
+// of them are empty, the calling connection is parked until another client pushes into
+// one of the lists, or until the timeout expires. The wait is always bounded by the
+// request execution timeout carried by the context, and a zero timeout waits for that.
+func executeBLOCKINGPOP(ctx context.Context, command *entity.Command, atHead bool) string {
+	rules := []utils.ValidationRule{
+		{Name: "keys", Datatype: reflect.Slice},
+		{Name: "timeout", Datatype: reflect.Int64},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	keys, ok := getStringSlice(command.Args[0])
+	timeout, _ := command.Args[1].(int64)
+
+	if !ok || len(keys) == 0 || timeout < 0 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"first argument should be a non-empty list of string, and timeout should not be negative"})
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	// registering before the first attempt, so that a push landing between
+	// an unsuccessful attempt and the wait below is not missed.
+	notifier, release := listWaiters.register(keys)
+	defer release()
+
+	for {
+		for _, key := range keys {
+			value, code := popFromList(key, atHead)
+
+			if code == entity.CRC_RECORD_FOUND {
+				return resp3.EncodedRESP3Response([]interface{}{[]interface{}{key, value}, code, ""})
+			}
+
+			if code != entity.CRC_RECORD_NOT_FOUND {
+				return resp3.EncodedRESP3Response([]interface{}{nil, code, ""})
+			}
+		}
+
+		select {
+		case <-notifier:
+			// retry the pop, some other waiter may have already taken the element
+		case <-ctx.Done():
+			return resp3.EncodedRESP3Response([]interface{}{nil, entity.CRC_WAIT_TIMED_OUT, ""})
+		}
+	}
+}
+
+func executeSADD(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "members", Datatype: reflect.Slice},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	members, ok := getStringSlice(command.Args[1])
+
+	if !ok || len(members) == 0 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"second argument should be a non-empty list of string, one or more invalid values provided"})
+	}
+
+	added, code := datastore.SAdd(key, members)
+	return resp3.EncodedRESP3Response([]interface{}{added, code, ""})
+}
+
+func executeSREM(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "members", Datatype: reflect.Slice},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	members, ok := getStringSlice(command.Args[1])
+
+	if !ok {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"second argument should be a list of string, one or more invalid values provided"})
+	}
+
+	removed, code := datastore.SRem(key, members)
+	return resp3.EncodedRESP3Response([]interface{}{removed, code, ""})
+}
+
+func executeSISMEMBER(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "member", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	member, _ := command.Args[1].(string)
+
+	isMember, code := datastore.SIsMember(key, member)
+	return resp3.EncodedRESP3Response([]interface{}{isMember, code, ""})
+}
+
+func executeSMEMBERS(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+
+	members, code := datastore.SMembers(key)
+	return resp3.EncodedRESP3Response([]interface{}{members, code, ""})
+}
+
+func executeSCARD(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+
+	cardinality, code := datastore.SCard(key)
+	return resp3.EncodedRESP3Response([]interface{}{cardinality, code, ""})
+}
+
+// executeSETALGEBRA runs one of SINTER, SUNION or SDIFF, which all take a
+// list of keys and return the members of the combined set.
+func executeSETALGEBRA(command *entity.Command, combine func(keys []string) ([]string, uint32)) string {
+	rules := []utils.ValidationRule{
+		{Name: "keys", Datatype: reflect.Slice},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	keys, ok := getStringSlice(command.Args[0])
+
+	if !ok || len(keys) == 0 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"first argument should be a non-empty list of string keys"})
+	}
+
+	members, code := combine(keys)
+	return resp3.EncodedRESP3Response([]interface{}{members, code, ""})
+}
+
+func executeZADD(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "members", Datatype: reflect.Map},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	rawMembers, ok := command.Args[1].(map[string]interface{})
+	members := make(map[string]float64, len(rawMembers))
+
+	for member, rawScore := range rawMembers {
+		score, isNumeric := getScore(rawScore)
+		if !isNumeric {
+			ok = false
+			break
+		}
+		members[member] = score
+	}
+
+	if !ok || len(members) == 0 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"second argument should be a non-empty dict of string members to numeric scores"})
+	}
+
+	added, code := datastore.ZAdd(key, members)
+	return resp3.EncodedRESP3Response([]interface{}{added, code, ""})
+}
+
+func executeZREM(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "members", Datatype: reflect.Slice},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	members, ok := getStringSlice(command.Args[1])
+
+	if !ok {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"second argument should be a list of string, one or more invalid values provided"})
+	}
+
+	removed, code := datastore.ZRem(key, members)
+	return resp3.EncodedRESP3Response([]interface{}{removed, code, ""})
+}
+
+func executeZSCORE(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "member", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	member, _ := command.Args[1].(string)
+
+	score, code := datastore.ZScore(key, member)
+	return resp3.EncodedRESP3Response([]interface{}{score, code, ""})
+}
+
+func executeZINCRBY(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "member", Datatype: reflect.String},
+		{Name: "increment", Datatype: reflect.Interface},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	member, _ := command.Args[1].(string)
+	increment, ok := getScore(command.Args[2])
+
+	if !ok {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, "third argument should be a number"})
+	}
+
+	score, code := datastore.ZIncrBy(key, member, increment)
+	return resp3.EncodedRESP3Response([]interface{}{score, code, ""})
+}
+
+func executeZRANGE(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "start", Datatype: reflect.Int64},
+		{Name: "stop", Datatype: reflect.Int64},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	start, _ := command.Args[1].(int64)
+	stop, _ := command.Args[2].(int64)
+
+	members, code := datastore.ZRange(key, start, stop)
+	return resp3.EncodedRESP3Response([]interface{}{scoredMembersToSlice(members), code, ""})
+}
+
+func executeZRANGEBYSCORE(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "min", Datatype: reflect.Interface},
+		{Name: "max", Datatype: reflect.Interface},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	min, isMinValid := getScore(command.Args[1])
+	max, isMaxValid := getScore(command.Args[2])
+
+	if !isMinValid || !isMaxValid {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT, "min and max scores should be numbers"})
+	}
+
+	members, code := datastore.ZRangeByScore(key, min, max)
+	return resp3.EncodedRESP3Response([]interface{}{scoredMembersToSlice(members), code, ""})
+}
+
+func executeZRANK(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+		{Name: "member", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+	member, _ := command.Args[1].(string)
+
+	rank, code := datastore.ZRank(key, member)
+	return resp3.EncodedRESP3Response([]interface{}{rank, code, ""})
+}
+
+func executeZCARD(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "key", Datatype: reflect.String},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	key, _ := command.Args[0].(string)
+
+	cardinality, code := datastore.ZCard(key)
+	return resp3.EncodedRESP3Response([]interface{}{cardinality, code, ""})
+}
+
+// popFromList makes a single pop attempt for the blocking pops, which run outside
+// of the keyspace lock and take it around every attempt instead.
+func popFromList(key string, atHead bool) (interface{}, uint32) {
+	keyspaceLock.RLock()
+	defer keyspaceLock.RUnlock()
+
+	if atHead {
+		return datastore.LPop(key)
+	}
+	return datastore.RPop(key)
+}
+
+// getStringSlice converts a decoded RESP list argument into a list of strings,
+// failing if any of the elements is not a string.
+func getStringSlice(argument interface{}) ([]string, bool) {
+	intrSlice, ok := argument.([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	stringSlice := make([]string, 0, len(intrSlice))
+	for idx := range intrSlice {
+		val, isOk := intrSlice[idx].(string)
+		if !isOk {
+			return nil, false
+		}
+		stringSlice = append(stringSlice, val)
+	}
+
+	return stringSlice, true
+}
+
+// getScore converts a decoded RESP number into a sorted set score. Integers are
+// accepted as well as doubles, but NaN is not as it cannot be ordered.
+func getScore(argument interface{}) (float64, bool) {
+	switch score := argument.(type) {
+	case int64:
+		return float64(score), true
+	case float64:
+		return score, !math.IsNaN(score)
+	default:
+		return 0, false
+	}
+}
+
+// scoredMembersToSlice converts sorted set members into a list of
+// [member, score] pairs, in the form they are sent to the clients.
+func scoredMembersToSlice(members []entity.ScoredMember) []interface{} {
+	pairs := make([]interface{}, len(members))
+	for idx, scored := range members {
+		pairs[idx] = []interface{}{scored.Member, scored.Score}
+	}
+	return pairs
+}
+
+// scannableFamilies are the record families accepted by the TYPE option of SCAN.
+var scannableFamilies = []string{
+	entity.RecordTypeScalar,
+	entity.RecordTypeHash,
+	entity.RecordTypeList,
+	entity.RecordTypeSet,
+	entity.RecordTypeSortedSet,
+}
+
+// getScanOptions parses the optional `MATCH pattern`, `COUNT n` and `TYPE family`
+// pairs of the SCAN command. It returns an error message if any of them is invalid.
+func getScanOptions(args []interface{}) (storage.ScanOptions, string) {
+	options := storage.ScanOptions{Count: storage.DefaultScanCount}
+
+	for i := 0; i+1 < len(args); i += 2 {
+		name, _ := args[i].(string)
+
+		switch strings.ToUpper(name) {
+		case "MATCH":
+			pattern, ok := args[i+1].(string)
+			if !ok {
+				return options, "ERR: MATCH pattern should be a string"
+			}
+			options.Match = pattern
+
+		case "COUNT":
+			count, ok := args[i+1].(int64)
+			if !ok || count <= 0 {
+				return options, "ERR: COUNT should be a positive integer"
+			}
+			options.Count = count
+
+		case "TYPE":
+			family, _ := args[i+1].(string)
+			family = strings.ToLower(family)
+
+			if yes, _ := utils.ExistsInList(family, scannableFamilies); !yes {
+				return options, fmt.Sprintf("ERR: TYPE should be one of %v", scannableFamilies)
+			}
+			options.Family = family
+
+		default:
+			return options, fmt.Sprintf("ERR: unknown SCAN option `%v`", args[i])
+		}
+	}
+
+	return options, ""
+}
+
+// getSetOptions parses the options following the key, value and ttl of SET, the
+// NX or XX write condition, and GET to respond with the value being replaced.
+func getSetOptions(args []interface{}) (storage.SetCondition, bool, string) {
+	condition, returnPrevious := storage.SetAlways, false
+
+	for i := 0; i < len(args); i++ {
+		name, _ := args[i].(string)
+
+		switch strings.ToUpper(name) {
+		case "NX", "XX":
+			if condition != storage.SetAlways {
+				return condition, returnPrevious, "ERR: NX and XX can not be used together"
+			}
+
+			condition = storage.SetIfAbsent
+			if strings.ToUpper(name) == "XX" {
+				condition = storage.SetIfPresent
+			}
+
+		case "GET":
+			returnPrevious = true
+
+		default:
+			return condition, returnPrevious, fmt.Sprintf("ERR: unknown option `%v`", args[i])
+		}
+	}
+
+	return condition, returnPrevious, ""
+}
+
+// getRecordValue returns the value held by the record, or nil if there is no record.
+func getRecordValue(record entity.Record) interface{} {
+	if record == nil {
+		return nil
+	}
+	return record.GetValue()
+}
+
+// getRangeOptions parses the optional `LIMIT n` and `REVERSE` arguments of the
+// RANGE and PREFIX commands. It returns an error message if any is invalid.
+func getRangeOptions(args []interface{}, allowReverse bool) (int64, bool, string) {
+	limit, reverse := storage.DefaultRangeLimit, false
+
+	for i := 0; i < len(args); i++ {
+		name, _ := args[i].(string)
+
+		switch strings.ToUpper(name) {
+		case "LIMIT":
+			if i+1 >= len(args) {
+				return limit, reverse, "ERR: LIMIT should be followed by a positive integer"
+			}
+
+			count, ok := args[i+1].(int64)
+			if !ok || count <= 0 {
+				return limit, reverse, "ERR: LIMIT should be a positive integer"
+			}
+			limit = count
+			i++
+
+		case "REVERSE":
+			if !allowReverse {
+				return limit, reverse, "ERR: REVERSE is not supported by this command"
+			}
+			reverse = true
+
+		default:
+			return limit, reverse, fmt.Sprintf("ERR: unknown option `%v`", args[i])
+		}
+	}
+
+	return limit, reverse, ""
+}
+
+// recordsToPairs converts the records of a range scan into a list of
+// [key, value] pairs, in the form they are sent to the clients.
+func recordsToPairs(records []*entity.RecordKV) []interface{} {
+	pairs := make([]interface{}, len(records))
+	for idx, recordKV := range records {
+		pairs[idx] = []interface{}{recordKV.Key, recordKV.Record.GetValue()}
+	}
+	return pairs
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"testing"
+	"universum/entity"
+)
+
+func TestSetWithOptions(t *testing.T) {
+	setupBlockingTests()
+
+	response := decodeResponse(t, executeSET(&entity.Command{
+		Name: CommandSet, Args: []interface{}{"lock", "owner-1", int64(0), "NX"}}))
+	if response[0] != true || responseCode(response) != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected NX write on missing key, got %v", response)
+	}
+
+	response = decodeResponse(t, executeSET(&entity.Command{
+		Name: CommandSet, Args: []interface{}{"lock", "owner-2", int64(0), "nx", "GET"}}))
+	if response[0] != "owner-1" || responseCode(response) != entity.CRC_CONDITION_NOT_MET {
+		t.Errorf("expected NX on existing key to respond with the current value, got %v", response)
+	}
+
+	response = decodeResponse(t, executeSET(&entity.Command{
+		Name: CommandSet, Args: []interface{}{"lock", "owner-2", int64(0), "NX", "XX"}}))
+	if responseCode(response) != entity.CRC_INVALID_CMD_INPUT {
+		t.Errorf("expected NX and XX together to be refused, got %v", response)
+	}
+
+	response = decodeResponse(t, executeSETNX(&entity.Command{
+		Name: CommandSetNX, Args: []interface{}{"lock", "owner-3"}}))
+	if response[0] != false || responseCode(response) != entity.CRC_CONDITION_NOT_MET {
+		t.Errorf("expected SETNX on existing key to not be met, got %v", response)
+	}
+
+	response = decodeResponse(t, executeGETSET(&entity.Command{
+		Name: CommandGetSet, Args: []interface{}{"lock", "owner-4"}}))
+	if response[0] != "owner-1" || responseCode(response) != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected GETSET to respond with the replaced value, got %v", response)
+	}
+
+	response = decodeResponse(t, executeGETDEL(&entity.Command{
+		Name: CommandGetDel, Args: []interface{}{"lock"}}))
+	if response[0] != "owner-4" || responseCode(response) != entity.CRC_RECORD_DELETED {
+		t.Errorf("expected GETDEL to respond with the deleted value, got %v", response)
+	}
+}
@@ -0,0 +1,470 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"universum/config"
+	"universum/entity"
+	"universum/resp3"
+	"universum/utils"
+)
+
+var (
+	errRedisArity        = errors.New("wrong number of arguments")
+	errRedisSyntax       = errors.New("syntax error")
+	errRedisNotAnInteger = errors.New("value is not an integer or out of range")
+	errRedisNotAFloat    = errors.New("value is not a valid float")
+)
+
+// redisCommand describes a command whose Redis form differs from the native one,
+// either by its name or by the shape of its arguments. Redis clients send all the
+// arguments as strings, and variadic ones in place of the native lists and maps.
+type redisCommand struct {
+	// name is the native command to run, when it is not the same as Redis'.
+	name string
+
+	// args turns the arguments of the Redis form into the native arguments.
+	args func(args []interface{}) ([]interface{}, error)
+}
+
+// redisCommands lists the commands which need translating. The others are run
+// as they come, which works for all the commands taking plain string arguments.
+var redisCommands = map[string]redisCommand{
+	"DEL":    {name: CommandMDelete, args: redisListArgs(0, 1)},
+	"INCRBY": {name: CommandIncr, args: redisIntegerArgs(2, 1)},
+	"DECRBY": {name: CommandDecr, args: redisIntegerArgs(2, 1)},
+
+	CommandAuth:    {args: redisAuthArgs},
+	CommandACL:     {args: redisACLArgs},
+	CommandSet:     {args: redisSetArgs},
+	CommandIncr:    {args: redisOffsetArgs},
+	CommandDecr:    {args: redisOffsetArgs},
+	CommandMGet:    {args: redisListArgs(0, 1)},
+	CommandMSet:    {args: redisMapArgs(0)},
+	CommandExpire:  {args: redisIntegerArgs(2, 1)},
+	CommandScan:    {args: redisScanArgs},
+	CommandWatch:   {args: redisListArgs(0, 1)},
+	CommandHSet:    {args: redisMapArgs(1)},
+	CommandHMGet:   {args: redisListArgs(1, 1)},
+	CommandHDel:    {args: redisListArgs(1, 1)},
+	CommandHIncrBy: {args: redisIntegerArgs(3, 2)},
+	CommandLPush:   {args: redisListArgs(1, 1)},
+	CommandRPush:   {args: redisListArgs(1, 1)},
+	CommandLRange:  {args: redisIntegerArgs(3, 1, 2)},
+	CommandLIndex:  {args: redisIntegerArgs(2, 1)},
+	CommandLTrim:   {args: redisIntegerArgs(3, 1, 2)},
+	CommandBLPop:   {args: redisBlockingPopArgs},
+	CommandBRPop:   {args: redisBlockingPopArgs},
+	CommandSAdd:    {args: redisListArgs(1, 1)},
+	CommandSRem:    {args: redisListArgs(1, 1)},
+	CommandSInter:  {args: redisListArgs(0, 1)},
+	CommandSUnion:  {args: redisListArgs(0, 1)},
+	CommandSDiff:   {args: redisListArgs(0, 1)},
+	CommandZAdd:    {args: redisZAddArgs},
+	CommandZRem:    {args: redisListArgs(1, 1)},
+	CommandZIncrBy: {args: redisZIncrByArgs},
+	CommandZRange:  {args: redisIntegerArgs(3, 1, 2)},
+
+	CommandZRangeByScore: {args: redisZRangeByScoreArgs},
+}
+
+// redisReplies shape the data of the native responses which Redis replies with
+// differently. They get the native command, and the code of a successful response.
+var redisReplies = map[string]func(command *entity.Command, data interface{}, code uint32) interface{}{
+	CommandGet:           redisGetReply,
+	CommandSet:           redisSetReply,
+	CommandIncr:          redisIncrDecrReply,
+	CommandDecr:          redisIncrDecrReply,
+	CommandMGet:          redisMGetReply,
+	CommandMDelete:       redisMDeleteReply,
+	CommandTTL:           redisTTLReply,
+	CommandHMGet:         redisHMGetReply,
+	CommandLTrim:         redisOKReply,
+	CommandACL:           redisACLReply,
+	CommandZRange:        redisZRangeReply,
+	CommandZRangeByScore: redisZRangeReply,
+}
+
+// translateRedisCommand turns the command, as sent by a Redis client, into the
+// native command to run.
+func translateRedisCommand(command *entity.Command) (*entity.Command, error) {
+	translation, ok := redisCommands[command.Name]
+	if !ok {
+		return command, nil
+	}
+
+	args, err := translation.args(command.Args)
+	if err == errRedisArity {
+		return nil, fmt.Errorf("wrong number of arguments for '%s' command", strings.ToLower(command.Name))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	name := translation.name
+	if name == "" {
+		name = command.Name
+	}
+
+	return &entity.Command{Name: name, Args: args}, nil
+}
+
+// redisListArgs collects the trailing arguments, after the fixed ones, into a list
+// of at least the minimum length.
+func redisListArgs(fixed int, minimum int) func(args []interface{}) ([]interface{}, error) {
+	return func(args []interface{}) ([]interface{}, error) {
+		if len(args) < fixed+minimum {
+			return nil, errRedisArity
+		}
+
+		list := make([]interface{}, len(args)-fixed)
+		copy(list, args[fixed:])
+
+		return append(append([]interface{}{}, args[:fixed]...), list), nil
+	}
+}
+
+// redisMapArgs collects the trailing arguments, after the fixed ones, into a map
+// of the key and value pairs they alternate.
+func redisMapArgs(fixed int) func(args []interface{}) ([]interface{}, error) {
+	return func(args []interface{}) ([]interface{}, error) {
+		if len(args) < fixed+2 || (len(args)-fixed)%2 != 0 {
+			return nil, errRedisArity
+		}
+
+		pairs := make(map[string]interface{})
+		for i := fixed; i < len(args); i += 2 {
+			pairs[fmt.Sprintf("%v", args[i])] = args[i+1]
+		}
+
+		return append(append([]interface{}{}, args[:fixed]...), pairs), nil
+	}
+}
+
+// redisIntegerArgs checks the number of arguments, and reads the ones at the
+// given positions as integers.
+func redisIntegerArgs(count int, positions ...int) func(args []interface{}) ([]interface{}, error) {
+	return func(args []interface{}) ([]interface{}, error) {
+		if len(args) != count {
+			return nil, errRedisArity
+		}
+
+		translated := append([]interface{}{}, args...)
+		for _, position := range positions {
+			integer, ok := getInteger(args[position])
+			if !ok {
+				return nil, errRedisNotAnInteger
+			}
+			translated[position] = integer
+		}
+
+		return translated, nil
+	}
+}
+
+// redisOffsetArgs adds the offset of one INCR and DECR take in Redis.
+func redisOffsetArgs(args []interface{}) ([]interface{}, error) {
+	if len(args) != 1 {
+		return nil, errRedisArity
+	}
+
+	return []interface{}{args[0], int64(1)}, nil
+}
+
+// redisAuthArgs authenticates AUTH with a password alone as the configured user,
+// which stands for the default user of Redis.
+func redisAuthArgs(args []interface{}) ([]interface{}, error) {
+	if len(args) == 1 {
+		return []interface{}{config.Store.Auth.DbUserName, args[0]}, nil
+	}
+
+	return args, nil
+}
+
+// redisACLArgs collects the rules of ACL SETUSER and the users of ACL DELUSER
+// into lists.
+func redisACLArgs(args []interface{}) ([]interface{}, error) {
+	subcommand := ""
+	if len(args) > 0 {
+		subcommand, _ = args[0].(string)
+	}
+
+	switch strings.ToUpper(subcommand) {
+	case aclSubcommandSetUser:
+		return redisListArgs(2, 0)(args)
+	case aclSubcommandDelUser:
+		return redisListArgs(1, 1)(args)
+	default:
+		return args, nil
+	}
+}
+
+// redisSetArgs reads the expiry of SET from its EX or PX option, into the ttl
+// argument the native SET takes after the value. The other options are the same.
+func redisSetArgs(args []interface{}) ([]interface{}, error) {
+	if len(args) < 2 {
+		return nil, errRedisArity
+	}
+
+	var ttl int64
+	options := make([]interface{}, 0)
+
+	for i := 2; i < len(args); i++ {
+		option, _ := args[i].(string)
+
+		switch strings.ToUpper(option) {
+		case "EX", "PX":
+			if i+1 >= len(args) || ttl != 0 {
+				return nil, errRedisSyntax
+			}
+
+			expiry, ok := getInteger(args[i+1])
+			if !ok || expiry <= 0 {
+				return nil, fmt.Errorf("invalid expire time in 'set' command")
+			}
+
+			// the native expiries are in seconds, so milliseconds are rounded up
+			ttl = expiry
+			if strings.ToUpper(option) == "PX" {
+				ttl = (expiry + 999) / 1000
+			}
+			i++
+
+		case "NX", "XX", "GET":
+			options = append(options, strings.ToUpper(option))
+
+		default:
+			return nil, errRedisSyntax
+		}
+	}
+
+	return append([]interface{}{args[0], args[1], ttl}, options...), nil
+}
+
+// redisScanArgs reads the COUNT option of SCAN as an integer.
+func redisScanArgs(args []interface{}) ([]interface{}, error) {
+	if len(args) == 0 || len(args)%2 == 0 {
+		return nil, errRedisSyntax
+	}
+
+	translated := append([]interface{}{}, args...)
+	for i := 1; i+1 < len(args); i += 2 {
+		if option, _ := args[i].(string); strings.ToUpper(option) == "COUNT" {
+			count, ok := getInteger(args[i+1])
+			if !ok {
+				return nil, errRedisNotAnInteger
+			}
+			translated[i+1] = count
+		}
+	}
+
+	return translated, nil
+}
+
+// redisBlockingPopArgs collects the keys of BLPOP and BRPOP into a list. The
+// timeout is in seconds, and fractions of a second are rounded up.
+func redisBlockingPopArgs(args []interface{}) ([]interface{}, error) {
+	if len(args) < 2 {
+		return nil, errRedisArity
+	}
+
+	timeout, ok := getFloat(args[len(args)-1])
+	if !ok || timeout < 0 {
+		return nil, errors.New("timeout is not a float or out of range")
+	}
+
+	keys := append([]interface{}{}, args[:len(args)-1]...)
+	return []interface{}{keys, int64(math.Ceil(timeout))}, nil
+}
+
+// redisZAddArgs collects the score and member pairs of ZADD into a map.
+func redisZAddArgs(args []interface{}) ([]interface{}, error) {
+	if len(args) < 3 || (len(args)-1)%2 != 0 {
+		return nil, errRedisArity
+	}
+
+	members := make(map[string]interface{})
+	for i := 1; i < len(args); i += 2 {
+		score, ok := getFloat(args[i])
+		if !ok {
+			return nil, errRedisNotAFloat
+		}
+		members[fmt.Sprintf("%v", args[i+1])] = score
+	}
+
+	return []interface{}{args[0], members}, nil
+}
+
+// redisZIncrByArgs swaps the increment and the member, which Redis takes the
+// other way round.
+func redisZIncrByArgs(args []interface{}) ([]interface{}, error) {
+	if len(args) != 3 {
+		return nil, errRedisArity
+	}
+
+	increment, ok := getFloat(args[1])
+	if !ok {
+		return nil, errRedisNotAFloat
+	}
+
+	return []interface{}{args[0], args[2], increment}, nil
+}
+
+func redisZRangeByScoreArgs(args []interface{}) ([]interface{}, error) {
+	if len(args) != 3 {
+		return nil, errRedisArity
+	}
+
+	min, isMinValid := getFloat(args[1])
+	max, isMaxValid := getFloat(args[2])
+
+	if !isMinValid || !isMaxValid {
+		return nil, errors.New("min or max is not a float")
+	}
+
+	return []interface{}{args[0], min, max}, nil
+}
+
+// getFloat reads a float argument, given either as a number or as the string
+// of one, the infinities included.
+func getFloat(argument interface{}) (float64, bool) {
+	switch value := argument.(type) {
+	case int64:
+		return float64(value), true
+	case float64:
+		return value, !math.IsNaN(value)
+	case string:
+		float, err := strconv.ParseFloat(value, 64)
+		return float, err == nil && !math.IsNaN(float)
+	default:
+		return 0, false
+	}
+}
+
+func redisOKReply(command *entity.Command, data interface{}, code uint32) interface{} {
+	return resp3.SimpleString("OK")
+}
+
+// redisGetReply returns the value of the record GET returns.
+func redisGetReply(command *entity.Command, data interface{}, code uint32) interface{} {
+	if record, ok := data.(map[string]interface{}); ok {
+		return record["Value"]
+	}
+	return nil
+}
+
+// redisSetReply returns OK once the value is set, nil when the NX or XX
+// condition is not met, and the previous value when asked to with GET.
+func redisSetReply(command *entity.Command, data interface{}, code uint32) interface{} {
+	if len(command.Args) > 3 {
+		for _, option := range command.Args[3:] {
+			if option == "GET" {
+				return data
+			}
+		}
+	}
+
+	if code == entity.CRC_CONDITION_NOT_MET {
+		return nil
+	}
+
+	return resp3.SimpleString("OK")
+}
+
+// redisIncrDecrReply reports the keys which do not exist as errors, as INCR and
+// DECR only work on existing integers, unlike in Redis where they start at 0.
+func redisIncrDecrReply(command *entity.Command, data interface{}, code uint32) interface{} {
+	if code == entity.CRC_RECORD_NOT_FOUND {
+		return errors.New("ERR no such key")
+	}
+	return data
+}
+
+// redisMGetReply lists the values of the keys in the order they were asked for.
+func redisMGetReply(command *entity.Command, data interface{}, code uint32) interface{} {
+	keys, _ := getStringSlice(command.Args[0])
+	records, _ := data.(map[string]interface{})
+
+	values := make([]interface{}, len(keys))
+	for idx, key := range keys {
+		if record, ok := records[key].(map[string]interface{}); ok {
+			values[idx] = record["Value"]
+		}
+	}
+
+	return values
+}
+
+// redisMDeleteReply counts the keys deleted, which is what DEL returns.
+func redisMDeleteReply(command *entity.Command, data interface{}, code uint32) interface{} {
+	statuses, _ := data.(map[string]interface{})
+
+	var deleted int64
+	for _, status := range statuses {
+		if status == true {
+			deleted++
+		}
+	}
+
+	return deleted
+}
+
+// redisTTLReply returns -2 for keys which do not exist, and -1 for the keys
+// which never expire.
+func redisTTLReply(command *entity.Command, data interface{}, code uint32) interface{} {
+	if code != entity.CRC_RECORD_FOUND {
+		return int64(-2)
+	}
+
+	ttl, _ := data.(int64)
+	if ttl+utils.GetCurrentEPochTime() >= config.InfiniteExpiryTime {
+		return int64(-1)
+	}
+
+	return ttl
+}
+
+// redisHMGetReply lists the values of the fields in the order they were asked for.
+func redisHMGetReply(command *entity.Command, data interface{}, code uint32) interface{} {
+	fields, _ := getStringSlice(command.Args[1])
+	values, _ := data.(map[string]interface{})
+
+	ordered := make([]interface{}, len(fields))
+	for idx, field := range fields {
+		ordered[idx] = values[field]
+	}
+
+	return ordered
+}
+
+// redisACLReply acknowledges ACL SETUSER with OK, the other subcommands
+// returning their data.
+func redisACLReply(command *entity.Command, data interface{}, code uint32) interface{} {
+	if len(command.Args) == 0 {
+		return data
+	}
+
+	if subcommand, _ := command.Args[0].(string); strings.ToUpper(subcommand) == aclSubcommandSetUser {
+		return resp3.SimpleString("OK")
+	}
+	return data
+}
+
+// redisZRangeReply lists the members alone, without their scores, as Redis does
+// when WITHSCORES is not given.
+func redisZRangeReply(command *entity.Command, data interface{}, code uint32) interface{} {
+	pairs, _ := data.([]interface{})
+
+	members := make([]interface{}, len(pairs))
+	for idx, pair := range pairs {
+		if pair, ok := pair.([]interface{}); ok && len(pair) == 2 {
+			members[idx] = pair[0]
+		}
+	}
+
+	return members
+}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"strings"
+	"time"
 	"universum/config"
 	"universum/entity"
 	"universum/internal/logger"
@@ -27,9 +28,9 @@ func getDataStore(id string) storage.DataStore {
 	case config.StorageEngineLSM:
 		if _, ok := _allStores[id]; !ok {
 			memtableType := config.Store.Storage.LSM.MemtableStorageType
-			_allStores[config.StorageEngineMemory] = lsm.CreateNewLSMStore(memtableType)
+			_allStores[config.StorageEngineLSM] = lsm.CreateNewLSMStore(memtableType)
 		}
-		return _allStores[config.StorageEngineMemory]
+		return _allStores[config.StorageEngineLSM]
 
 	default:
 		logger.Get().Error("GetDataStore: unknown storage engine `%s` requested, shutting down.", id)
@@ -54,3 +55,12 @@ func getSnapshotService(id string) storage.SnapshotService {
 		return nil
 	}
 }
+
+// getSnapshotFrequency returns how often the periodic snapshot job snapshots the
+// storage engine, that is the checkpoints of the LSM engine.
+func getSnapshotFrequency(id string) time.Duration {
+	if strings.ToUpper(id) == config.StorageEngineLSM {
+		return time.Duration(config.Store.Storage.LSM.AutoCheckpointFrequency) * time.Second
+	}
+	return time.Duration(config.Store.Storage.Memory.AutoSnapshotFrequency) * time.Second
+}
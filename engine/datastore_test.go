@@ -1,14 +1,33 @@
 package engine
 
 import (
+	"os"
 	"reflect"
 	"testing"
 	"universum/config"
+	"universum/internal/logger"
 	"universum/storage"
 	"universum/storage/lsm"
 	"universum/storage/memory"
 )
 
+// TestMain points the logger at a temporary directory, for the tests not to leave
+// a log file behind in the package directory.
+func TestMain(m *testing.M) {
+	logdir, err := os.MkdirTemp("", "universum-test-logs")
+	if err != nil {
+		panic(err)
+	}
+
+	config.Store = config.GetSkeleton()
+	config.Store.Logging.LogFileDirectory = logdir
+	logger.Get()
+
+	code := m.Run()
+	os.RemoveAll(logdir)
+	os.Exit(code)
+}
+
 func setupEngineTests() {
 	_allStores = make(map[string]storage.DataStore)
 	config.Store = config.GetSkeleton()
@@ -2,14 +2,27 @@ package engine
 
 import (
 	"fmt"
+	"runtime/debug"
 	"sync"
 	"time"
 	"universum/config"
+	"universum/internal/logger"
+	"universum/storage/memory"
 	"universum/utils"
 )
 
 const (
 	HealthyMemotyConsumptionRatio float64 = 1.01
+
+	DefaultEvictionJobFrequency time.Duration = 1 * time.Second
+
+	// evictionBatchSize is the number of keys evicted before the process
+	// memory usage is measured again.
+	evictionBatchSize int64 = 1000
+
+	// maxEvictionRounds bounds a single eviction run, so that the worker
+	// cannot spin forever if memory is held by something other than records.
+	maxEvictionRounds int = 1000
 )
 
 var evictionMutex sync.Mutex
@@ -55,16 +68,26 @@ func EvictRecords() {
 	evictionMutex.Lock()
 	defer evictionMutex.Unlock()
 
+	store := getDataStore(config.Store.Storage.StorageEngine)
+	if store.GetStoreType() != config.StorageEngineMemory {
+		return
+	}
+
 	currMemUsage := utils.GetMemoryUsedByCurrentPID()
 	allowedUsage := config.Store.Storage.Memory.AllowedMemoryStorageLimit
+	memstore := store.(*memory.MemoryStore)
 
-	policy := config.Store.Eviction.AutoEvictionPolicy
-	if policy == config.EvictionPolicyNone || !isDbOverflown(currMemUsage, allowedUsage) {
+	switch policy := config.Store.Eviction.AutoEvictionPolicy; policy {
+	case config.EvictionPolicyNone:
 		return
-	}
 
-	if policy == config.EvictionPolicyLRU {
-		evictLRU(int64(currMemUsage), allowedUsage)
+	case config.EvictionPolicyRejectWrites:
+		rejectWrites(memstore, currMemUsage, allowedUsage)
+
+	default:
+		if isDbOverflown(currMemUsage, allowedUsage) {
+			evictByPolicy(memstore, policy, int64(currMemUsage), allowedUsage)
+		}
 	}
 }
 
@@ -72,6 +95,56 @@ func isDbOverflown(currUsage uint64, allowedUsage int64) bool {
 	return float64(allowedUsage)*HealthyMemotyConsumptionRatio < float64(currUsage)
 }
 
-func evictLRU(currUsage int64, allowedUsage int64) {
-	// @TODO: to be implemented
+// rejectWrites toggles the write rejection of the memory store, depending on
+// whether the memory usage is above or below the allowed limit.
+func rejectWrites(memstore *memory.MemoryStore, currUsage uint64, allowedUsage int64) {
+	overflown := isDbOverflown(currUsage, allowedUsage)
+
+	if overflown != memstore.IsWritesRejected() {
+		logger.Get().Warn("Memory limit check: Overflown=%t, MemoryUsage=%d, AllowedUsage=%d; writes rejected=%t",
+			overflown, currUsage, allowedUsage, overflown)
+	}
+
+	memstore.SetWritesRejected(overflown)
+}
+
+// evictByPolicy keeps evicting records as per the given policy in batches, until
+// the process memory usage is back under the allowed limit.
+func evictByPolicy(memstore *memory.MemoryStore, policy string, currUsage int64, allowedUsage int64) {
+	var totalEvicted int64 = 0
+
+	for round := 0; round < maxEvictionRounds; round++ {
+		if !isDbOverflown(uint64(currUsage), allowedUsage) {
+			break
+		}
+
+		evicted := evictSample(memstore, policy, evictionBatchSize)
+		if evicted == 0 {
+			break // nothing eligible left to evict
+		}
+
+		totalEvicted += evicted
+		AddEvictedKeys(evicted)
+
+		// return the freed heap to the OS, otherwise the RSS would never
+		// reflect the evicted records and the loop would not terminate.
+		debug.FreeOSMemory()
+		currUsage = int64(utils.GetMemoryUsedByCurrentPID())
+	}
+
+	if totalEvicted > 0 {
+		logger.Get().Info("Auto eviction completed. Policy=%s, EvictedKeys=%d, MemoryUsage=%d, AllowedUsage=%d",
+			policy, totalEvicted, currUsage, allowedUsage)
+	}
+}
+
+// evictSample evicts a batch of records under the read side of the keyspace lock,
+// like the commands, for a transaction not to see its keys evicted half way
+// through. The watchers of the keys are told through the keyspace listener, which
+// the store notifies of the eviction.
+func evictSample(memstore *memory.MemoryStore, policy string, maxKeys int64) int64 {
+	keyspaceLock.RLock()
+	defer keyspaceLock.RUnlock()
+
+	return memory.EvictSample(memstore, policy, maxKeys)
 }
@@ -54,7 +54,14 @@ func (w *recordExpiryWorker) expireDeletedRecords(expiryChan chan<- recordExpiry
 	}
 }
 
+// expireRandomSample removes the expired records of a random shard, under the
+// read side of the keyspace lock like the commands, for a transaction not to see
+// its keys removed half way through. The watchers of the keys are told through
+// the keyspace listener, which the store notifies of the expiry.
 func (w *recordExpiryWorker) expireRandomSample(store storage.DataStore) int64 {
+	keyspaceLock.RLock()
+	defer keyspaceLock.RUnlock()
+
 	switch store.GetStoreType() {
 	case config.StorageEngineMemory:
 		shards := store.(*memory.MemoryStore).GetAllShards()
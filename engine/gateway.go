@@ -26,22 +26,102 @@ const (
 	CommandMDelete  string = "MDELETE"
 	CommandTTL      string = "TTL"
 	CommandExpire   string = "EXPIRE"
+	CommandSetNX    string = "SETNX"
+	CommandGetSet   string = "GETSET"
+	CommandGetDel   string = "GETDEL"
+	CommandCAS      string = "CAS"
+	CommandScan     string = "SCAN"
+	CommandRange    string = "RANGE"
+	CommandPrefix   string = "PREFIX"
 	CommandSnapshot string = "SNAPSHOT"
 	CommandInfo     string = "INFO"
 	CommandHelp     string = "HELP"
+	CommandAuth     string = "AUTH"
+	CommandACL      string = "ACL"
+	CommandHello    string = "HELLO"
+
+	CommandMulti   string = "MULTI"
+	CommandExec    string = "EXEC"
+	CommandDiscard string = "DISCARD"
+	CommandWatch   string = "WATCH"
+	CommandUnwatch string = "UNWATCH"
+
+	CommandSubscribe    string = "SUBSCRIBE"
+	CommandUnsubscribe  string = "UNSUBSCRIBE"
+	CommandPSubscribe   string = "PSUBSCRIBE"
+	CommandPUnsubscribe string = "PUNSUBSCRIBE"
+	CommandPublish      string = "PUBLISH"
+
+	CommandHSet    string = "HSET"
+	CommandHGet    string = "HGET"
+	CommandHMGet   string = "HMGET"
+	CommandHDel    string = "HDEL"
+	CommandHExists string = "HEXISTS"
+	CommandHLen    string = "HLEN"
+	CommandHKeys   string = "HKEYS"
+	CommandHGetAll string = "HGETALL"
+	CommandHIncrBy string = "HINCRBY"
+
+	CommandLPush  string = "LPUSH"
+	CommandRPush  string = "RPUSH"
+	CommandLPop   string = "LPOP"
+	CommandRPop   string = "RPOP"
+	CommandLRange string = "LRANGE"
+	CommandLLen   string = "LLEN"
+	CommandLIndex string = "LINDEX"
+	CommandLTrim  string = "LTRIM"
+	CommandBLPop  string = "BLPOP"
+	CommandBRPop  string = "BRPOP"
+
+	CommandSAdd      string = "SADD"
+	CommandSRem      string = "SREM"
+	CommandSIsMember string = "SISMEMBER"
+	CommandSMembers  string = "SMEMBERS"
+	CommandSCard     string = "SCARD"
+	CommandSInter    string = "SINTER"
+	CommandSUnion    string = "SUNION"
+	CommandSDiff     string = "SDIFF"
+
+	CommandZAdd          string = "ZADD"
+	CommandZRem          string = "ZREM"
+	CommandZScore        string = "ZSCORE"
+	CommandZIncrBy       string = "ZINCRBY"
+	CommandZRange        string = "ZRANGE"
+	CommandZRangeByScore string = "ZRANGEBYSCORE"
+	CommandZRank         string = "ZRANK"
+	CommandZCard         string = "ZCARD"
 )
 
-func ExecuteCommand(buffer *bufio.Reader, timeout time.Duration) (string, error) {
+// errInvalidCommand is returned for the commands which do not exist.
+var errInvalidCommand = errors.New("invalid command")
+
+// ExecuteCommand reads the next command off the buffer and executes it in the
+// given client session, returning the response encoded in the session protocol.
+func ExecuteCommand(buffer *bufio.Reader, timeout time.Duration, session *Session) (string, error) {
 	command, err := parseCommand(buffer)
 	if err != nil {
 		return "", err
 	}
 
+	return ExecuteParsedCommand(command, timeout, session)
+}
+
+// ExecuteParsedCommand executes the command in the given client session, just
+// like ExecuteCommand, for the commands which do not come as RESP, such as the
+// ones of the HTTP gateway.
+func ExecuteParsedCommand(command *entity.Command, timeout time.Duration, session *Session) (string, error) {
+	var err error
 	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(timeout))
 	defer cancel()
 
 	logger.Get().Debug("REQUEST: %#v", command)
-	output, err := executeCommand(ctx, command)
+
+	var output string
+	if session.getProtocol() != protocolNative || command.Name == CommandHello {
+		output, err = executeRedisCommand(ctx, session, command)
+	} else {
+		output, err = executeInSession(ctx, session, command)
+	}
 	logger.Get().Debug("RESPONSE: %#v", output)
 
 	if err != nil {
@@ -52,9 +132,27 @@ func ExecuteCommand(buffer *bufio.Reader, timeout time.Duration) (string, error)
 	return output, nil
 }
 
+// HasBufferedCommand tells whether a whole command has already been received
+// in the buffer, in which case it can be executed without waiting on the client.
+func HasBufferedCommand(buffer *bufio.Reader) bool {
+	if buffer.Buffered() == 0 {
+		return false
+	}
+
+	buffered, _ := buffer.Peek(buffer.Buffered())
+	_, err := resp3.ScanMessage(buffered)
+
+	// a malformed command counts as whole, for the decoder to report it
+	return err != resp3.ErrIncompleteMessage
+}
+
 func parseCommand(buffer *bufio.Reader) (*entity.Command, error) {
-	buffer.Peek(1)
-	AddNetworkBytesReceived(int64(buffer.Buffered()))
+	length, err := awaitCommand(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	AddNetworkBytesReceived(int64(length))
 	decodedResp, err := resp3.Decode(buffer)
 
 	if err != nil {
@@ -64,6 +162,34 @@ func parseCommand(buffer *bufio.Reader) (*entity.Command, error) {
 	return getCommandFromRESP(decodedResp)
 }
 
+// awaitCommand waits for the next command to be received whole, so that the
+// decoder does not give up on a command which arrives over several reads, and
+// returns its length. A command which is malformed, or larger than the buffer,
+// is left to the decoder as it is.
+func awaitCommand(buffer *bufio.Reader) (int, error) {
+	if _, err := buffer.Peek(1); err != nil {
+		return 0, err
+	}
+
+	for {
+		buffered, _ := buffer.Peek(buffer.Buffered())
+
+		length, err := resp3.ScanMessage(buffered)
+		if err == nil {
+			return length, nil
+		}
+
+		if err != resp3.ErrIncompleteMessage || len(buffered) == buffer.Size() {
+			return len(buffered), nil
+		}
+
+		// blocks until more of the command is received
+		if _, err := buffer.Peek(len(buffered) + 1); err != nil {
+			return 0, err
+		}
+	}
+}
+
 func getCommandFromRESP(decodedResp interface{}) (*entity.Command, error) {
 	decodedList, ok := decodedResp.([]interface{})
 
@@ -87,6 +213,10 @@ func executeCommand(ctx context.Context, command *entity.Command) (string, error
 		// Continue processing the command
 	}
 
+	if isPermitted, reason := checkPermissions(ctx, command); !isPermitted {
+		return resp3.EncodedRESP3Response([]interface{}{nil, entity.CRC_PERMISSION_DENIED, reason}), nil
+	}
+
 	switch command.Name {
 	case CommandPing:
 		return executePING(command), nil
@@ -127,6 +257,27 @@ func executeCommand(ctx context.Context, command *entity.Command) (string, error
 	case CommandExpire:
 		return executeEXPIRE(command), nil
 
+	case CommandSetNX:
+		return executeSETNX(command), nil
+
+	case CommandGetSet:
+		return executeGETSET(command), nil
+
+	case CommandGetDel:
+		return executeGETDEL(command), nil
+
+	case CommandCAS:
+		return executeCAS(command), nil
+
+	case CommandScan:
+		return executeSCAN(command), nil
+
+	case CommandRange:
+		return executeRANGE(command), nil
+
+	case CommandPrefix:
+		return executePREFIX(command), nil
+
 	case CommandSnapshot:
 		return executeSNAPSHOT(command), nil
 
@@ -136,8 +287,119 @@ func executeCommand(ctx context.Context, command *entity.Command) (string, error
 	case CommandHelp:
 		return executeHELP(command), nil
 
+	case CommandPublish:
+		return executePUBLISH(command), nil
+
+	case CommandACL:
+		return executeACL(ctx, command), nil
+
+	case CommandHSet:
+		return executeHSET(command), nil
+
+	case CommandHGet:
+		return executeHGET(command), nil
+
+	case CommandHMGet:
+		return executeHMGET(command), nil
+
+	case CommandHDel:
+		return executeHDEL(command), nil
+
+	case CommandHExists:
+		return executeHEXISTS(command), nil
+
+	case CommandHLen:
+		return executeHLEN(command), nil
+
+	case CommandHKeys:
+		return executeHKEYS(command), nil
+
+	case CommandHGetAll:
+		return executeHGETALL(command), nil
+
+	case CommandHIncrBy:
+		return executeHINCRBY(command), nil
+
+	case CommandLPush:
+		return executePUSH(command, true), nil
+
+	case CommandRPush:
+		return executePUSH(command, false), nil
+
+	case CommandLPop:
+		return executePOP(command, true), nil
+
+	case CommandRPop:
+		return executePOP(command, false), nil
+
+	case CommandLRange:
+		return executeLRANGE(command), nil
+
+	case CommandLLen:
+		return executeLLEN(command), nil
+
+	case CommandLIndex:
+		return executeLINDEX(command), nil
+
+	case CommandLTrim:
+		return executeLTRIM(command), nil
+
+	case CommandBLPop:
+		return executeBLOCKINGPOP(ctx, command, true), nil
+
+	case CommandBRPop:
+		return executeBLOCKINGPOP(ctx, command, false), nil
+
+	case CommandSAdd:
+		return executeSADD(command), nil
+
+	case CommandSRem:
+		return executeSREM(command), nil
+
+	case CommandSIsMember:
+		return executeSISMEMBER(command), nil
+
+	case CommandSMembers:
+		return executeSMEMBERS(command), nil
+
+	case CommandSCard:
+		return executeSCARD(command), nil
+
+	case CommandSInter:
+		return executeSETALGEBRA(command, datastore.SInter), nil
+
+	case CommandSUnion:
+		return executeSETALGEBRA(command, datastore.SUnion), nil
+
+	case CommandSDiff:
+		return executeSETALGEBRA(command, datastore.SDiff), nil
+
+	case CommandZAdd:
+		return executeZADD(command), nil
+
+	case CommandZRem:
+		return executeZREM(command), nil
+
+	case CommandZScore:
+		return executeZSCORE(command), nil
+
+	case CommandZIncrBy:
+		return executeZINCRBY(command), nil
+
+	case CommandZRange:
+		return executeZRANGE(command), nil
+
+	case CommandZRangeByScore:
+		return executeZRANGEBYSCORE(command), nil
+
+	case CommandZRank:
+		return executeZRANK(command), nil
+
+	case CommandZCard:
+		return executeZCARD(command), nil
+
 	default:
-		return "", fmt.Errorf("invalid command `%s` provided", command.Name)
+		return "", fmt.Errorf("%w `%s` provided", errInvalidCommand, command.Name)
 	}
 
 }
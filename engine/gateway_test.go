@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"universum/config"
+	"universum/entity"
+	"universum/resp3"
+)
+
+func encodeRequest(t *testing.T, args ...interface{}) string {
+	request, err := resp3.Encode(args)
+	if err != nil {
+		t.Fatalf("failed to encode %v: %v", args, err)
+	}
+	return request
+}
+
+func TestPipelinedCommands(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+
+	value := strings.Repeat("v", 64)
+	request := encodeRequest(t, CommandSet, "key", value, int64(0)) +
+		encodeRequest(t, CommandGet, "key") +
+		encodeRequest(t, CommandDelete, "key")
+
+	buffer := bufio.NewReader(strings.NewReader(request))
+	received := GetNetworkBytesReceived()
+
+	var responses [][]interface{}
+	for count := 0; count == 0 || HasBufferedCommand(buffer); count++ {
+		output, err := ExecuteCommand(buffer, time.Second, session)
+		if err != nil {
+			t.Fatalf("failed to execute the pipelined command: %v", err)
+		}
+		responses = append(responses, decodeResponse(t, output))
+	}
+
+	if len(responses) != 3 {
+		t.Fatalf("expected the three pipelined commands to be executed, got %v", responses)
+	}
+
+	record, _ := responses[1][0].(map[string]interface{})
+	if responseCode(responses[0]) != entity.CRC_RECORD_UPDATED || record["Value"] != value ||
+		responseCode(responses[2]) != entity.CRC_RECORD_DELETED {
+		t.Errorf("expected the commands to be executed in order, got %v", responses)
+	}
+
+	if delta := GetNetworkBytesReceived() - received; delta != int64(len(request)) {
+		t.Errorf("expected %d bytes to be received, got %d", len(request), delta)
+	}
+}
+
+func TestCommandReceivedOverSeveralReads(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+
+	value := strings.Repeat("v", 64)
+	request := encodeRequest(t, CommandSet, "key", value, int64(0))
+
+	reader, writer := io.Pipe()
+	buffer := bufio.NewReader(reader)
+
+	// the command is cut in the middle of the value
+	go writer.Write([]byte(request[:len(request)/2]))
+	buffer.Peek(1)
+
+	if HasBufferedCommand(buffer) {
+		t.Fatal("expected the partly received command not to be executable")
+	}
+
+	go writer.Write([]byte(request[len(request)/2:]))
+
+	output, err := ExecuteCommand(buffer, time.Second, session)
+	if err != nil {
+		t.Fatalf("expected the command to be awaited whole, got %v", err)
+	}
+
+	if response := decodeResponse(t, output); responseCode(response) != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected the record to be set, got %v", response)
+	}
+
+	writer.Close()
+	if _, err := ExecuteCommand(buffer, time.Second, session); err != io.EOF {
+		t.Errorf("expected the closed connection to end with EOF, got %v", err)
+	}
+}
+
+func TestMalformedCommandIsReported(t *testing.T) {
+	setupBlockingTests()
+	buffer := bufio.NewReader(strings.NewReader("?nonsense\r\n"))
+	buffer.Peek(1)
+
+	if !HasBufferedCommand(buffer) {
+		t.Fatal("expected the malformed command to be handed to the decoder")
+	}
+
+	if _, err := ExecuteCommand(buffer, time.Second, NewSession()); err == nil {
+		t.Error("expected the malformed command to fail")
+	}
+}
+
+func TestExecuteParsedCommandInNativeSession(t *testing.T) {
+	setupBlockingTests()
+	config.Store.Server.DefaultProtocol = config.ProtocolRESP3
+	t.Cleanup(func() { config.Store.Server.DefaultProtocol = config.ProtocolNative })
+
+	session := NewNativeSession()
+	output, err := ExecuteParsedCommand(&entity.Command{
+		Name: CommandSet, Args: []interface{}{"key", "value", int64(0)}}, time.Second, session)
+	if err != nil {
+		t.Fatalf("failed to execute the command: %v", err)
+	}
+
+	if response := decodeResponse(t, output); responseCode(response) != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected the native response whatever the default protocol, got %v", response)
+	}
+}
@@ -25,7 +25,7 @@ func getCommandHelpContent(command string) string {
 		return "USAGE:\n\n\tGET <key:string>\n"
 
 	case CommandSet:
-		return "USAGE:\n\n\tEXISTS <key:string> <value:any> <ttl:int>\n"
+		return "USAGE:\n\n\tSET <key:string> <value:any> <ttl:int> [NX|XX] [GET]\n"
 
 	case CommandDelete:
 		return "USAGE:\n\n\tDELETE <key:string>\n"
@@ -54,12 +54,178 @@ func getCommandHelpContent(command string) string {
 	case CommandExpire:
 		return "USAGE:\n\n\tEXPIRE <key:string> <ttl:int>\n"
 
+	case CommandSetNX:
+		return "USAGE:\n\n\tSETNX <key:string> <value:any>\n"
+
+	case CommandGetSet:
+		return "USAGE:\n\n\tGETSET <key:string> <value:any>\n"
+
+	case CommandGetDel:
+		return "USAGE:\n\n\tGETDEL <key:string>\n"
+
+	case CommandCAS:
+		return "USAGE:\n\n\tCAS <key:string> <expected:any> <value:any>\n"
+
+	case CommandScan:
+		return "USAGE:\n\n\tSCAN <cursor:string> [MATCH <pattern:string>] [COUNT <count:int>] [TYPE <family:string>]\n"
+
+	case CommandRange:
+		return "USAGE:\n\n\tRANGE <start:string> <end:string> [LIMIT <limit:int>] [REVERSE]\n"
+
+	case CommandPrefix:
+		return "USAGE:\n\n\tPREFIX <prefix:string> [LIMIT <limit:int>]\n"
+
 	case CommandSnapshot:
 		return "USAGE:\n\n\tSNAPSHOT\n"
 
 	case CommandInfo:
 		return "USAGE:\n\n\tINFO\n"
 
+	case CommandMulti:
+		return "USAGE:\n\n\tMULTI\n"
+
+	case CommandExec:
+		return "USAGE:\n\n\tEXEC\n"
+
+	case CommandDiscard:
+		return "USAGE:\n\n\tDISCARD\n"
+
+	case CommandWatch:
+		return "USAGE:\n\n\tWATCH <keys:[]string>\n"
+
+	case CommandUnwatch:
+		return "USAGE:\n\n\tUNWATCH\n"
+
+	case CommandSubscribe:
+		return "USAGE:\n\n\tSUBSCRIBE <channels:[]string>\n"
+
+	case CommandUnsubscribe:
+		return "USAGE:\n\n\tUNSUBSCRIBE [channels:[]string]\n"
+
+	case CommandPSubscribe:
+		return "USAGE:\n\n\tPSUBSCRIBE <patterns:[]string>\n"
+
+	case CommandPUnsubscribe:
+		return "USAGE:\n\n\tPUNSUBSCRIBE [patterns:[]string]\n"
+
+	case CommandPublish:
+		return "USAGE:\n\n\tPUBLISH <channel:string> <message:any>\n"
+
+	case CommandAuth:
+		return "USAGE:\n\n\tAUTH <user:string> <password:string>\n"
+
+	case CommandACL:
+		return "USAGE:\n\n\tACL SETUSER <user:string> <rules:[]string>\n" +
+			"\tACL DELUSER <users:[]string>\n\tACL LIST\n\tACL WHOAMI\n"
+
+	case CommandHello:
+		return "USAGE:\n\n\tHELLO [protover:int] [AUTH <user:string> <password:string>] [SETNAME <name:string>]\n"
+
+	case CommandHSet:
+		return "USAGE:\n\n\tHSET <key:string> <fields:map[string][any]>\n"
+
+	case CommandHGet:
+		return "USAGE:\n\n\tHGET <key:string> <field:string>\n"
+
+	case CommandHMGet:
+		return "USAGE:\n\n\tHMGET <key:string> <fields:[]string>\n"
+
+	case CommandHDel:
+		return "USAGE:\n\n\tHDEL <key:string> <fields:[]string>\n"
+
+	case CommandHExists:
+		return "USAGE:\n\n\tHEXISTS <key:string> <field:string>\n"
+
+	case CommandHLen:
+		return "USAGE:\n\n\tHLEN <key:string>\n"
+
+	case CommandHKeys:
+		return "USAGE:\n\n\tHKEYS <key:string>\n"
+
+	case CommandHGetAll:
+		return "USAGE:\n\n\tHGETALL <key:string>\n"
+
+	case CommandHIncrBy:
+		return "USAGE:\n\n\tHINCRBY <key:string> <field:string> <offset:int>\n"
+
+	case CommandLPush:
+		return "USAGE:\n\n\tLPUSH <key:string> <values:[]any>\n"
+
+	case CommandRPush:
+		return "USAGE:\n\n\tRPUSH <key:string> <values:[]any>\n"
+
+	case CommandLPop:
+		return "USAGE:\n\n\tLPOP <key:string>\n"
+
+	case CommandRPop:
+		return "USAGE:\n\n\tRPOP <key:string>\n"
+
+	case CommandLRange:
+		return "USAGE:\n\n\tLRANGE <key:string> <start:int> <stop:int>\n"
+
+	case CommandLLen:
+		return "USAGE:\n\n\tLLEN <key:string>\n"
+
+	case CommandLIndex:
+		return "USAGE:\n\n\tLINDEX <key:string> <index:int>\n"
+
+	case CommandLTrim:
+		return "USAGE:\n\n\tLTRIM <key:string> <start:int> <stop:int>\n"
+
+	case CommandBLPop:
+		return "USAGE:\n\n\tBLPOP <keys:[]string> <timeout:int>\n"
+
+	case CommandBRPop:
+		return "USAGE:\n\n\tBRPOP <keys:[]string> <timeout:int>\n"
+
+	case CommandSAdd:
+		return "USAGE:\n\n\tSADD <key:string> <members:[]string>\n"
+
+	case CommandSRem:
+		return "USAGE:\n\n\tSREM <key:string> <members:[]string>\n"
+
+	case CommandSIsMember:
+		return "USAGE:\n\n\tSISMEMBER <key:string> <member:string>\n"
+
+	case CommandSMembers:
+		return "USAGE:\n\n\tSMEMBERS <key:string>\n"
+
+	case CommandSCard:
+		return "USAGE:\n\n\tSCARD <key:string>\n"
+
+	case CommandSInter:
+		return "USAGE:\n\n\tSINTER <keys:[]string>\n"
+
+	case CommandSUnion:
+		return "USAGE:\n\n\tSUNION <keys:[]string>\n"
+
+	case CommandSDiff:
+		return "USAGE:\n\n\tSDIFF <keys:[]string>\n"
+
+	case CommandZAdd:
+		return "USAGE:\n\n\tZADD <key:string> <members:map[string][float]>\n"
+
+	case CommandZRem:
+		return "USAGE:\n\n\tZREM <key:string> <members:[]string>\n"
+
+	case CommandZScore:
+		return "USAGE:\n\n\tZSCORE <key:string> <member:string>\n"
+
+	case CommandZIncrBy:
+		return "USAGE:\n\n\tZINCRBY <key:string> <member:string> <increment:float>\n"
+
+	case CommandZRange:
+		return "USAGE:\n\n\tZRANGE <key:string> <start:int> <stop:int>\n"
+
+	case CommandZRangeByScore:
+		return "USAGE:\n\n\tZRANGEBYSCORE <key:string> <min:float> <max:float>\n"
+
+	case CommandZRank:
+		return "USAGE:\n\n\tZRANK <key:string> <member:string>\n"
+
+	case CommandZCard:
+		return "USAGE:\n\n\tZCARD <key:string>\n"
+
 	default:
 		return fmt.Sprintf("\nInvalid subcommand `%s`. Retry with correct subcommand\n", command)
 	}
@@ -21,7 +21,7 @@ func TestGetCommandHelpContent(t *testing.T) {
 		{CommandPing, "USAGE:\n\n\tPING\n"},
 		{CommandExists, "USAGE:\n\n\tEXISTS <key:string>\n"},
 		{CommandGet, "USAGE:\n\n\tGET <key:string>\n"},
-		{CommandSet, "USAGE:\n\n\tEXISTS <key:string> <value:any> <ttl:int>\n"},
+		{CommandSet, "USAGE:\n\n\tSET <key:string> <value:any> <ttl:int> [NX|XX] [GET]\n"},
 		{CommandDelete, "USAGE:\n\n\tDELETE <key:string>\n"},
 		{CommandIncr, "USAGE:\n\n\tINCR <key:string> <value:int>\n"},
 		{CommandDecr, "USAGE:\n\n\tDECR <key:string> <value:int>\n"},
@@ -31,8 +31,63 @@ func TestGetCommandHelpContent(t *testing.T) {
 		{CommandMDelete, "USAGE:\n\n\tMDELETE <keys:[]string>\n"},
 		{CommandTTL, "USAGE:\n\n\tTTL <key:string>\n"},
 		{CommandExpire, "USAGE:\n\n\tEXPIRE <key:string> <ttl:int>\n"},
+		{CommandSetNX, "USAGE:\n\n\tSETNX <key:string> <value:any>\n"},
+		{CommandGetSet, "USAGE:\n\n\tGETSET <key:string> <value:any>\n"},
+		{CommandGetDel, "USAGE:\n\n\tGETDEL <key:string>\n"},
+		{CommandCAS, "USAGE:\n\n\tCAS <key:string> <expected:any> <value:any>\n"},
+		{CommandScan, "USAGE:\n\n\tSCAN <cursor:string> [MATCH <pattern:string>] [COUNT <count:int>] [TYPE <family:string>]\n"},
+		{CommandRange, "USAGE:\n\n\tRANGE <start:string> <end:string> [LIMIT <limit:int>] [REVERSE]\n"},
+		{CommandPrefix, "USAGE:\n\n\tPREFIX <prefix:string> [LIMIT <limit:int>]\n"},
 		{CommandSnapshot, "USAGE:\n\n\tSNAPSHOT\n"},
 		{CommandInfo, "USAGE:\n\n\tINFO\n"},
+		{CommandMulti, "USAGE:\n\n\tMULTI\n"},
+		{CommandExec, "USAGE:\n\n\tEXEC\n"},
+		{CommandDiscard, "USAGE:\n\n\tDISCARD\n"},
+		{CommandWatch, "USAGE:\n\n\tWATCH <keys:[]string>\n"},
+		{CommandUnwatch, "USAGE:\n\n\tUNWATCH\n"},
+		{CommandSubscribe, "USAGE:\n\n\tSUBSCRIBE <channels:[]string>\n"},
+		{CommandUnsubscribe, "USAGE:\n\n\tUNSUBSCRIBE [channels:[]string]\n"},
+		{CommandPSubscribe, "USAGE:\n\n\tPSUBSCRIBE <patterns:[]string>\n"},
+		{CommandPUnsubscribe, "USAGE:\n\n\tPUNSUBSCRIBE [patterns:[]string]\n"},
+		{CommandPublish, "USAGE:\n\n\tPUBLISH <channel:string> <message:any>\n"},
+		{CommandAuth, "USAGE:\n\n\tAUTH <user:string> <password:string>\n"},
+		{CommandACL, "USAGE:\n\n\tACL SETUSER <user:string> <rules:[]string>\n\tACL DELUSER <users:[]string>\n\tACL LIST\n\tACL WHOAMI\n"},
+		{CommandHello, "USAGE:\n\n\tHELLO [protover:int] [AUTH <user:string> <password:string>] [SETNAME <name:string>]\n"},
+		{CommandHSet, "USAGE:\n\n\tHSET <key:string> <fields:map[string][any]>\n"},
+		{CommandHGet, "USAGE:\n\n\tHGET <key:string> <field:string>\n"},
+		{CommandHMGet, "USAGE:\n\n\tHMGET <key:string> <fields:[]string>\n"},
+		{CommandHDel, "USAGE:\n\n\tHDEL <key:string> <fields:[]string>\n"},
+		{CommandHExists, "USAGE:\n\n\tHEXISTS <key:string> <field:string>\n"},
+		{CommandHLen, "USAGE:\n\n\tHLEN <key:string>\n"},
+		{CommandHKeys, "USAGE:\n\n\tHKEYS <key:string>\n"},
+		{CommandHGetAll, "USAGE:\n\n\tHGETALL <key:string>\n"},
+		{CommandHIncrBy, "USAGE:\n\n\tHINCRBY <key:string> <field:string> <offset:int>\n"},
+		{CommandLPush, "USAGE:\n\n\tLPUSH <key:string> <values:[]any>\n"},
+		{CommandRPush, "USAGE:\n\n\tRPUSH <key:string> <values:[]any>\n"},
+		{CommandLPop, "USAGE:\n\n\tLPOP <key:string>\n"},
+		{CommandRPop, "USAGE:\n\n\tRPOP <key:string>\n"},
+		{CommandLRange, "USAGE:\n\n\tLRANGE <key:string> <start:int> <stop:int>\n"},
+		{CommandLLen, "USAGE:\n\n\tLLEN <key:string>\n"},
+		{CommandLIndex, "USAGE:\n\n\tLINDEX <key:string> <index:int>\n"},
+		{CommandLTrim, "USAGE:\n\n\tLTRIM <key:string> <start:int> <stop:int>\n"},
+		{CommandBLPop, "USAGE:\n\n\tBLPOP <keys:[]string> <timeout:int>\n"},
+		{CommandBRPop, "USAGE:\n\n\tBRPOP <keys:[]string> <timeout:int>\n"},
+		{CommandSAdd, "USAGE:\n\n\tSADD <key:string> <members:[]string>\n"},
+		{CommandSRem, "USAGE:\n\n\tSREM <key:string> <members:[]string>\n"},
+		{CommandSIsMember, "USAGE:\n\n\tSISMEMBER <key:string> <member:string>\n"},
+		{CommandSMembers, "USAGE:\n\n\tSMEMBERS <key:string>\n"},
+		{CommandSCard, "USAGE:\n\n\tSCARD <key:string>\n"},
+		{CommandSInter, "USAGE:\n\n\tSINTER <keys:[]string>\n"},
+		{CommandSUnion, "USAGE:\n\n\tSUNION <keys:[]string>\n"},
+		{CommandSDiff, "USAGE:\n\n\tSDIFF <keys:[]string>\n"},
+		{CommandZAdd, "USAGE:\n\n\tZADD <key:string> <members:map[string][float]>\n"},
+		{CommandZRem, "USAGE:\n\n\tZREM <key:string> <members:[]string>\n"},
+		{CommandZScore, "USAGE:\n\n\tZSCORE <key:string> <member:string>\n"},
+		{CommandZIncrBy, "USAGE:\n\n\tZINCRBY <key:string> <member:string> <increment:float>\n"},
+		{CommandZRange, "USAGE:\n\n\tZRANGE <key:string> <start:int> <stop:int>\n"},
+		{CommandZRangeByScore, "USAGE:\n\n\tZRANGEBYSCORE <key:string> <min:float> <max:float>\n"},
+		{CommandZRank, "USAGE:\n\n\tZRANK <key:string> <member:string>\n"},
+		{CommandZCard, "USAGE:\n\n\tZCARD <key:string>\n"},
 		{"InvalidCommand", "\nInvalid subcommand `InvalidCommand`. Retry with correct subcommand\n"},
 	}
 
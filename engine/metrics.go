@@ -0,0 +1,286 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"universum/config"
+	"universum/entity"
+	"universum/storage/lsm"
+	"universum/utils"
+)
+
+// commandLatencyBuckets are the upper bounds of the buckets of the command
+// latency histograms, in seconds.
+var commandLatencyBuckets = []float64{
+	0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+// snapshotDurationBuckets are the upper bounds of the buckets of the snapshot
+// duration histogram, in seconds.
+var snapshotDurationBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300}
+
+// histogram counts the observations falling in each of its buckets, along with
+// their sum, the way the Prometheus histograms do.
+type histogram struct {
+	bounds []float64
+	counts []atomic.Int64 // one for each bound, and the last one for +Inf
+	sum    atomic.Int64   // in nanoseconds
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds: bounds,
+		counts: make([]atomic.Int64, len(bounds)+1),
+	}
+}
+
+func (h *histogram) observe(elapsed time.Duration) {
+	// the bounds are inclusive, an observation equal to one falls in its bucket
+	h.counts[sort.SearchFloat64s(h.bounds, elapsed.Seconds())].Add(1)
+	h.sum.Add(int64(elapsed))
+}
+
+// commandStats holds the metrics of one command.
+type commandStats struct {
+	calls    atomic.Int64
+	failures atomic.Int64
+	latency  *histogram
+}
+
+var ks_commandStats sync.Map // map[string]*commandStats
+
+var ks_snapshotDurations = newHistogram(snapshotDurationBuckets)
+var ks_snapshotFailures int64 = 0
+var ks_snapshotReplayDuration int64 = 0 // in nanoseconds
+
+// recordCommandMetrics records a call of the command, which started at the given
+// time and ended with the given error. The commands which do not exist are not
+// recorded, for the clients not to make up as many metrics as they like.
+func recordCommandMetrics(name string, startTime time.Time, err *error) {
+	if errors.Is(*err, errInvalidCommand) {
+		return
+	}
+
+	value, ok := ks_commandStats.Load(name)
+	if !ok {
+		value, _ = ks_commandStats.LoadOrStore(name, &commandStats{latency: newHistogram(commandLatencyBuckets)})
+	}
+
+	stats := value.(*commandStats)
+	stats.calls.Add(1)
+	if *err != nil {
+		stats.failures.Add(1)
+	}
+	stats.latency.observe(time.Since(startTime))
+}
+
+// WriteMetrics writes out the metrics of the server in the Prometheus text
+// exposition format: the commands, the connections, the memory, the keyspace
+// and the snapshots, along with the LSM store internals when it is in use.
+func WriteMetrics(w io.Writer) error {
+	m := &metricsWriter{buffer: bufio.NewWriter(w)}
+
+	m.describe("build_info", "gauge", "Version of the server, as a label.")
+	m.sample("build_info", fmt.Sprintf(`version="%s"`, escapeLabelValue(entity.SERVER_VERSION)), 1)
+
+	writeCommandMetrics(m)
+	writeConnectionMetrics(m)
+	writeMemoryMetrics(m)
+	writeKeyspaceMetrics(m)
+	writeSnapshotMetrics(m)
+
+	if store, ok := datastore.(*lsm.LSMStore); ok {
+		writeLSMMetrics(m, store.GetStatistics())
+	}
+
+	return m.buffer.Flush()
+}
+
+func writeCommandMetrics(m *metricsWriter) {
+	m.metric("commands_processed_total", "counter", "Commands processed.", float64(GetCommandsProcessed()))
+
+	names := make([]string, 0)
+	ks_commandStats.Range(func(key, _ interface{}) bool {
+		names = append(names, key.(string))
+		return true
+	})
+	sort.Strings(names)
+
+	m.describe("command_calls_total", "counter", "Calls of each command.")
+	for _, name := range names {
+		stats, _ := ks_commandStats.Load(name)
+		m.sample("command_calls_total", commandLabel(name), float64(stats.(*commandStats).calls.Load()))
+	}
+
+	m.describe("command_failures_total", "counter", "Calls of each command which failed to execute, such as on timeout.")
+	for _, name := range names {
+		stats, _ := ks_commandStats.Load(name)
+		m.sample("command_failures_total", commandLabel(name), float64(stats.(*commandStats).failures.Load()))
+	}
+
+	m.describe("command_duration_seconds", "histogram", "Time taken to execute each command.")
+	for _, name := range names {
+		stats, _ := ks_commandStats.Load(name)
+		m.histogram("command_duration_seconds", commandLabel(name), stats.(*commandStats).latency)
+	}
+
+	m.metric("network_received_bytes_total", "counter", "Bytes of the commands received.", float64(GetNetworkBytesReceived()))
+	m.metric("network_sent_bytes_total", "counter", "Bytes of the responses sent.", float64(GetNetworkBytesSent()))
+}
+
+func writeConnectionMetrics(m *metricsWriter) {
+	activeConnections := entity.GetActiveTCPConnectionCount()
+	if activeConnections < 0 {
+		activeConnections = 0
+	}
+
+	m.metric("connected_clients", "gauge", "Client connections open.", float64(activeConnections))
+	m.metric("connected_clients_peak", "gauge", "Most client connections open at once.", float64(entity.GetPeakTCPConnectionCount()))
+	m.metric("connections_max", "gauge", "Client connections allowed at once.", float64(config.Store.Server.MaxConnections))
+	m.metric("connections_rejected_total", "counter", "Client connections turned away, the limit being reached.",
+		float64(entity.GetRejectedTCPConnectionCount()))
+}
+
+func writeMemoryMetrics(m *metricsWriter) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	m.metric("memory_used_bytes", "gauge", "Resident memory of the server process.", float64(utils.GetMemoryUsedByCurrentPID()))
+	m.metric("memory_limit_bytes", "gauge", "Memory allowed for the in-memory storage.",
+		float64(config.Store.Storage.Memory.AllowedMemoryStorageLimit))
+	m.metric("memory_heap_bytes", "gauge", "Heap memory allocated and in use.", float64(memStats.HeapAlloc))
+	m.metric("goroutines", "gauge", "Goroutines running.", float64(runtime.NumGoroutine()))
+}
+
+func writeKeyspaceMetrics(m *metricsWriter) {
+	keyCount := int64(0)
+	if DatabaseInfoStats != nil {
+		keyCount = DatabaseInfoStats.Keyspace.TotalKeyCount
+	}
+
+	m.metric("keyspace_keys", "gauge", "Keys in the keyspace, as of the last snapshot.", float64(keyCount))
+	m.metric("keyspace_evicted_keys_total", "counter", "Keys evicted to stay under the memory limit.", float64(GetEvictedKeys()))
+}
+
+func writeSnapshotMetrics(m *metricsWriter) {
+	sizeInBytes, keysReplayed := int64(0), int64(0)
+	if DatabaseInfoStats != nil {
+		sizeInBytes = DatabaseInfoStats.Persistence.SnapshotSizeInBytes
+		keysReplayed = DatabaseInfoStats.Persistence.TotalKeysReplayed
+	}
+
+	m.describe("snapshot_duration_seconds", "histogram", "Time taken to take each snapshot.")
+	m.histogram("snapshot_duration_seconds", "", ks_snapshotDurations)
+
+	m.metric("snapshot_failures_total", "counter", "Snapshots which failed.", float64(atomic.LoadInt64(&ks_snapshotFailures)))
+	m.metric("snapshot_size_bytes", "gauge", "Size of the last snapshot.", float64(sizeInBytes))
+	m.metric("snapshot_replay_duration_seconds", "gauge", "Time taken to replay the snapshot on startup.",
+		time.Duration(atomic.LoadInt64(&ks_snapshotReplayDuration)).Seconds())
+	m.metric("snapshot_replayed_keys", "gauge", "Keys replayed from the snapshot on startup.", float64(keysReplayed))
+}
+
+func writeLSMMetrics(m *metricsWriter, stats lsm.Statistics) {
+	m.metric("lsm_memtable_size_bytes", "gauge", "Size of the records in the memtable.", float64(stats.MemtableSizeInBytes))
+	m.metric("lsm_memtable_records", "gauge", "Records in the memtable.", float64(stats.MemtableRecordCount))
+	m.metric("lsm_sstables", "gauge", "SSTables on disk.", float64(stats.SSTableCount))
+
+	m.metric("lsm_memtable_flushes_total", "counter", "Memtables flushed to SSTables.", float64(stats.Flushes))
+	m.metric("lsm_memtable_flush_failures_total", "counter", "Memtable flushes which failed.", float64(stats.FlushFailures))
+	m.metric("lsm_memtable_flush_duration_seconds_total", "counter", "Time spent flushing memtables.",
+		stats.FlushDuration.Seconds())
+	m.metric("lsm_wal_replay_dropped_bytes", "gauge", "Bytes of torn or corrupt WAL entries dropped by the replay on startup.",
+		float64(stats.WALDroppedBytes))
+	m.metric("lsm_wal_group_commit_failures_total", "counter", "WAL group commits which failed to write or sync, and were retried in a new segment.",
+		float64(stats.WALGroupCommitFailures))
+
+	m.metric("lsm_compactions_total", "counter", "SSTable levels compacted.", float64(stats.Compaction.Compactions))
+	m.metric("lsm_compaction_failures_total", "counter", "SSTable compactions which failed.", float64(stats.Compaction.Failures))
+	m.metric("lsm_compaction_duration_seconds_total", "counter", "Time spent compacting SSTables.",
+		stats.Compaction.Duration.Seconds())
+
+	m.metric("lsm_block_cache_hits_total", "counter", "Block lookups served by the block cache.", float64(stats.BlockCache.Hits))
+	m.metric("lsm_block_cache_misses_total", "counter", "Block lookups missing the block cache.", float64(stats.BlockCache.Misses))
+	m.metric("lsm_block_cache_evictions_total", "counter", "Blocks evicted from the block cache.", float64(stats.BlockCache.Evictions))
+	m.metric("lsm_block_cache_size_bytes", "gauge", "Size of the blocks in the block cache.", float64(stats.BlockCache.SizeInBytes))
+	m.metric("lsm_block_cache_limit_bytes", "gauge", "Size allowed for the block cache.", float64(stats.BlockCache.LimitInBytes))
+}
+
+// metricsWriter lays out the metrics in the Prometheus text exposition format,
+// prefixing their names with the name of the application.
+type metricsWriter struct {
+	buffer *bufio.Writer
+}
+
+// describe writes out the help and the type of a metric, which go before its
+// samples.
+func (m *metricsWriter) describe(name string, kind string, help string) {
+	name = config.AppCodeName + "_" + name
+	fmt.Fprintf(m.buffer, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes out a sample of a metric, with its labels, if any.
+func (m *metricsWriter) sample(name string, labels string, value float64) {
+	name = config.AppCodeName + "_" + name
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	fmt.Fprintf(m.buffer, "%s %s\n", name, formatMetricValue(value))
+}
+
+// metric writes out a metric of a single sample, along with its description.
+func (m *metricsWriter) metric(name string, kind string, help string, value float64) {
+	m.describe(name, kind, help)
+	m.sample(name, "", value)
+}
+
+// histogram writes out the cumulative buckets of the histogram, its sum and its
+// count.
+func (m *metricsWriter) histogram(name string, labels string, h *histogram) {
+	separator := ""
+	if labels != "" {
+		separator = ","
+	}
+
+	cumulative := int64(0)
+	for idx := range h.counts {
+		cumulative += h.counts[idx].Load()
+
+		bound := "+Inf"
+		if idx < len(h.bounds) {
+			bound = formatMetricValue(h.bounds[idx])
+		}
+		m.sample(name+"_bucket", fmt.Sprintf(`%s%sle="%s"`, labels, separator, bound), float64(cumulative))
+	}
+
+	m.sample(name+"_sum", labels, time.Duration(h.sum.Load()).Seconds())
+	m.sample(name+"_count", labels, float64(cumulative))
+}
+
+func commandLabel(name string) string {
+	return fmt.Sprintf(`command="%s"`, escapeLabelValue(name))
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+// formatMetricValue formats the whole numbers without an exponent, which the
+// counters of bytes would otherwise get.
+func formatMetricValue(value float64) string {
+	if value == math.Trunc(value) && math.Abs(value) < 1<<53 {
+		return strconv.FormatInt(int64(value), 10)
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
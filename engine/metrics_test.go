@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"universum/entity"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	h := newHistogram([]float64{0.001, 0.01})
+
+	h.observe(500 * time.Microsecond)
+	h.observe(time.Millisecond)
+	h.observe(5 * time.Millisecond)
+	h.observe(time.Second)
+
+	for idx, expected := range []int64{2, 1, 1} {
+		if count := h.counts[idx].Load(); count != expected {
+			t.Errorf("expected %d observations in bucket %d, got %d", expected, idx, count)
+		}
+	}
+}
+
+func TestWriteMetricsAfterCommands(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+
+	runInSession(t, session, CommandSet, "key", "value", int64(0))
+	runInSession(t, session, CommandGet, "key")
+	if _, err := executeInSession(context.Background(), session, &entity.Command{Name: "NOSUCHCOMMAND"}); err == nil {
+		t.Fatal("expected the unknown command to fail")
+	}
+
+	var output strings.Builder
+	if err := WriteMetrics(&output); err != nil {
+		t.Fatalf("failed to write the metrics: %v", err)
+	}
+	metrics := output.String()
+
+	for _, expected := range []string{
+		"# TYPE universum_command_duration_seconds histogram\n",
+		`universum_command_calls_total{command="GET"} `,
+		`universum_command_duration_seconds_bucket{command="SET",le="+Inf"} `,
+		`universum_command_duration_seconds_count{command="SET"} `,
+		"# TYPE universum_connected_clients gauge\n",
+		"# TYPE universum_snapshot_duration_seconds histogram\n",
+		"universum_snapshot_duration_seconds_bucket{le=\"0.01\"} ",
+	} {
+		if !strings.Contains(metrics, expected) {
+			t.Errorf("expected the metrics to contain %q, got\n%s", expected, metrics)
+		}
+	}
+
+	if strings.Contains(metrics, "NOSUCHCOMMAND") {
+		t.Errorf("expected the unknown command not to be recorded, got\n%s", metrics)
+	}
+
+	if strings.Contains(metrics, "universum_lsm_") {
+		t.Errorf("expected no LSM metrics for the memory store, got\n%s", metrics)
+	}
+}
+
+func TestFormatMetricValue(t *testing.T) {
+	for value, expected := range map[float64]string{
+		0:           "0",
+		1073741824:  "1073741824",
+		0.00025:     "0.00025",
+		1.5:         "1.5",
+		-3:          "-3",
+		12345678901: "12345678901",
+	} {
+		if formatted := formatMetricValue(value); formatted != expected {
+			t.Errorf("expected %v to be formatted as %s, got %s", value, expected, formatted)
+		}
+	}
+}
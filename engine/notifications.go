@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"universum/config"
+	"universum/storage"
+)
+
+const (
+	// keyeventChannelPrefix prefixes the channels named after the events,
+	// which receive the keys the events happened on.
+	keyeventChannelPrefix string = "__keyevent__:"
+
+	// keyspaceChannelPrefix prefixes the channels named after the keys,
+	// which receive the events happening on the keys.
+	keyspaceChannelPrefix string = "__keyspace__:"
+)
+
+// setupKeyspaceNotifications has the stores report their writes to the watched
+// keys, and publish the classes of keyspace events enabled in the config.
+func setupKeyspaceNotifications() {
+	var events []string
+	if config.Store.Notifications != nil {
+		events = config.Store.Notifications.KeyspaceEvents
+	}
+
+	enabled := make(map[storage.KeyspaceEvent]struct{}, len(events))
+	for _, event := range events {
+		enabled[storage.KeyspaceEvent(event)] = struct{}{}
+	}
+
+	storage.SetKeyspaceListener(func(event storage.KeyspaceEvent, key string) {
+		watchedKeys.modified(key)
+
+		if _, ok := enabled[event]; ok {
+			publishKeyspaceEvent(event, key)
+		}
+	})
+}
+
+// publishKeyspaceEvent publishes the event both to the channel of the event and
+// to the channel of the key, for the clients to subscribe to either of them.
+func publishKeyspaceEvent(event storage.KeyspaceEvent, key string) {
+	pubsub.publish(keyeventChannelPrefix+string(event), key)
+	pubsub.publish(keyspaceChannelPrefix+key, string(event))
+}
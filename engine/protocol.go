@@ -0,0 +1,327 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"universum/config"
+	"universum/entity"
+	"universum/resp3"
+)
+
+// The reply protocols a session can speak. The native one replies with the
+// [value, code, message] triples, and the others reply the way Redis does,
+// over RESP2 or RESP3, for the standard Redis clients and tools to work.
+const (
+	protocolNative int32 = 0
+	protocolRESP2  int32 = 2
+	protocolRESP3  int32 = 3
+)
+
+// redisNilCodes are the response codes of a command which did not find what it
+// was after, or did not get to do what it was asked to. Redis does not consider
+// those errors, and replies with nil instead, or with what the command returned.
+var redisNilCodes = map[uint32]struct{}{
+	entity.CRC_RECORD_NOT_FOUND:    {},
+	entity.CRC_RECORD_EXPIRED:      {},
+	entity.CRC_RECORD_NOT_DELETED:  {},
+	entity.CRC_RECORD_TOMBSTONED:   {},
+	entity.CRC_FIELD_NOT_FOUND:     {},
+	entity.CRC_MEMBER_NOT_FOUND:    {},
+	entity.CRC_INDEX_OUT_OF_RANGE:  {},
+	entity.CRC_WAIT_TIMED_OUT:      {},
+	entity.CRC_TRANSACTION_ABORTED: {},
+	entity.CRC_CONDITION_NOT_MET:   {},
+	entity.CRC_VALUE_MISMATCH:      {},
+}
+
+// redisStatusReplies are the status replies Redis sends in place of the data
+// of the responses with these codes.
+var redisStatusReplies = map[uint32]resp3.SimpleString{
+	entity.CRC_PING_SUCCESS:          "PONG",
+	entity.CRC_SNAPSHOT_STARTED:      "Background saving started",
+	entity.CRC_COMMAND_QUEUED:        "QUEUED",
+	entity.CRC_TRANSACTION_STARTED:   "OK",
+	entity.CRC_TRANSACTION_DISCARDED: "OK",
+	entity.CRC_KEYS_WATCHED:          "OK",
+	entity.CRC_AUTHENTICATED:         "OK",
+	entity.CRC_MSET_COMPLETED:        "OK",
+}
+
+// redisErrorPrefixes are the error codes Redis gives to these failures, the
+// others being reported as generic `ERR` errors.
+var redisErrorPrefixes = map[uint32]string{
+	entity.CRC_WRONG_TYPE:              "WRONGTYPE",
+	entity.CRC_MEMORY_LIMIT_EXCEEDED:   "OOM",
+	entity.CRC_AUTHENTICATION_REQUIRED: "NOAUTH",
+	entity.CRC_INVALID_CREDENTIALS:     "WRONGPASS",
+	entity.CRC_PERMISSION_DENIED:       "NOPERM",
+	entity.CRC_PROTOCOL_NOT_SUPPORTED:  "NOPROTO",
+	entity.CRC_SERVER_BUSY:             "BUSY",
+}
+
+// redisErrorMessages stand in for the messages the native errors leave out.
+var redisErrorMessages = map[uint32]string{
+	entity.CRC_WRONG_TYPE:        "Operation against a key holding the wrong kind of value",
+	entity.CRC_INCR_INVALID_TYPE: "value is not an integer or out of range",
+	entity.CRC_RECORD_TOO_BIG:    "value is too big",
+}
+
+// getProtocolVersion maps the protocol names of the configuration to the
+// protocol versions, the native protocol being the fallback.
+func getProtocolVersion(name string) int32 {
+	switch strings.ToUpper(name) {
+	case config.ProtocolRESP2:
+		return protocolRESP2
+	case config.ProtocolRESP3:
+		return protocolRESP3
+	default:
+		return protocolNative
+	}
+}
+
+// executeHELLO switches the session to the given protocol version, 2 or 3, after
+// authenticating it if the AUTH option is given. Without a version, the protocol
+// is left as it is. Either way, the reply describes the server and the protocol.
+func executeHELLO(session *Session, command *entity.Command) string {
+	protocol := session.getProtocol()
+
+	if len(command.Args) > 0 {
+		version, ok := getInteger(command.Args[0])
+		if !ok || (version != int64(protocolRESP2) && version != int64(protocolRESP3)) {
+			return resp3.EncodedRESP3Response([]interface{}{
+				nil, entity.CRC_PROTOCOL_NOT_SUPPORTED, fmt.Sprintf("unsupported protocol version `%v`", command.Args[0])})
+		}
+		protocol = int32(version)
+	}
+
+	for i := 1; i < len(command.Args); i++ {
+		option, _ := command.Args[i].(string)
+
+		switch strings.ToUpper(option) {
+		case "AUTH":
+			if i+2 >= len(command.Args) {
+				return resp3.EncodedRESP3Response([]interface{}{
+					nil, entity.CRC_INVALID_CMD_INPUT, "AUTH should be followed by a user and a password"})
+			}
+
+			authResponse := executeAUTH(session, &entity.Command{
+				Name: CommandAuth, Args: command.Args[i+1 : i+3]})
+
+			if response, _ := decodeQueuedOutput(authResponse).([]interface{}); len(response) != 3 ||
+				response[1] != int64(entity.CRC_AUTHENTICATED) {
+				return authResponse
+			}
+			i += 2
+
+		case "SETNAME":
+			// connection names are not kept, but clients are free to set one
+			if i+1 >= len(command.Args) {
+				return resp3.EncodedRESP3Response([]interface{}{
+					nil, entity.CRC_INVALID_CMD_INPUT, "SETNAME should be followed by a name"})
+			}
+			i++
+
+		default:
+			return resp3.EncodedRESP3Response([]interface{}{
+				nil, entity.CRC_INVALID_CMD_INPUT, fmt.Sprintf("unknown HELLO option `%v`", command.Args[i])})
+		}
+	}
+
+	session.setProtocol(protocol)
+
+	return resp3.EncodedRESP3Response([]interface{}{map[string]interface{}{
+		"server":  config.AppCodeName,
+		"version": config.AppVersion,
+		"proto":   int64(protocol),
+		"mode":    "standalone",
+		"role":    "master",
+		"modules": []interface{}{},
+	}, entity.CRC_HELLO_CONTENT_OK, ""})
+}
+
+// executeRedisCommand runs the command sent by a client speaking the Redis
+// protocol. The arguments are taken the way Redis takes them, translated to
+// the ones of the native command, and the native response is turned into the
+// reply Redis would send, in the protocol version the session negotiated.
+func executeRedisCommand(ctx context.Context, session *Session, command *entity.Command) (string, error) {
+	if isRedisSubscriptionCommand(command.Name) {
+		return executeRedisSubscription(ctx, session, command)
+	}
+
+	native, err := translateRedisCommand(command)
+	if err != nil {
+		return encodeRedisReply(session, fmt.Errorf("ERR %v", err)), nil
+	}
+
+	// the queued commands are needed to make out the replies in the result of EXEC
+	var queued []*entity.Command
+	if native.Name == CommandExec {
+		queued = session.transaction
+	}
+
+	output, err := executeInSession(ctx, session, native)
+	if err != nil {
+		return encodeRedisReply(session, fmt.Errorf("ERR %v", err)), nil
+	}
+
+	// HELLO called without a version leaves a native session native
+	if session.getProtocol() == protocolNative {
+		return output, nil
+	}
+
+	response, _ := decodeQueuedOutput(output).([]interface{})
+	if native.Name == CommandExec && len(response) == 3 && response[1] == int64(entity.CRC_TRANSACTION_COMPLETED) {
+		results, _ := response[0].([]interface{})
+		replies := make([]interface{}, len(results))
+
+		for idx, result := range results {
+			result, _ := result.([]interface{})
+			replies[idx] = getRedisReply(queued[idx], result)
+		}
+
+		return encodeRedisReply(session, replies), nil
+	}
+
+	return encodeRedisReply(session, getRedisReply(native, response)), nil
+}
+
+// getRedisReply turns the [value, code, message] response of the native command
+// into the reply Redis sends for it. Errors come out as error values.
+func getRedisReply(command *entity.Command, response []interface{}) interface{} {
+	if len(response) != 3 {
+		return fmt.Errorf("ERR unexpected response %v", response)
+	}
+
+	data, message := response[0], fmt.Sprintf("%v", response[2])
+	code, _ := response[1].(int64)
+
+	if isRedisErrorCode(uint32(code)) {
+		return getRedisError(uint32(code), message)
+	}
+
+	if status, ok := redisStatusReplies[uint32(code)]; ok {
+		return status
+	}
+
+	if reply, ok := redisReplies[command.Name]; ok {
+		return reply(command, data, uint32(code))
+	}
+
+	// Redis replies with integers to the yes or no questions, whatever the protocol
+	if answer, ok := data.(bool); ok {
+		if answer {
+			return int64(1)
+		}
+		return int64(0)
+	}
+
+	return data
+}
+
+// isRedisErrorCode tells whether the response code stands for a failure, as
+// opposed to a command which was fine but had nothing to return.
+func isRedisErrorCode(code uint32) bool {
+	if _, ok := redisNilCodes[code]; ok {
+		return false
+	}
+
+	return code >= entity.CRC_INVALID_CMD_INPUT || (code >= entity.CRC_SERVER_SHUTTING_DOWN && code < entity.CRC_RECORD_FOUND)
+}
+
+func getRedisError(code uint32, message string) error {
+	prefix, ok := redisErrorPrefixes[code]
+	if !ok {
+		prefix = "ERR"
+	}
+
+	// some of the native messages carry an `ERR:` prefix of their own
+	message = strings.TrimSpace(strings.TrimPrefix(message, "ERR:"))
+	if message == "" {
+		message = redisErrorMessages[code]
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("command failed with code %d", code)
+	}
+
+	return fmt.Errorf("%s %s", prefix, message)
+}
+
+func encodeRedisReply(session *Session, reply interface{}) string {
+	return resp3.EncodeRedisReply(reply, int(session.getProtocol()))
+}
+
+func isRedisSubscriptionCommand(name string) bool {
+	switch name {
+	case CommandSubscribe, CommandUnsubscribe, CommandPSubscribe, CommandPUnsubscribe:
+		return true
+	default:
+		return false
+	}
+}
+
+// executeRedisSubscription runs the subscription commands one channel or pattern
+// at a time, as Redis confirms each of them separately, with a reply such as
+// ["subscribe", channel, count]. With RESP3, the confirmations are push frames.
+func executeRedisSubscription(ctx context.Context, session *Session, command *entity.Command) (string, error) {
+	kind := strings.ToLower(command.Name)
+	protocol := int(session.getProtocol())
+
+	names := make([]interface{}, len(command.Args))
+	copy(names, command.Args)
+
+	// unsubscribing from nothing in particular confirms each of the subscriptions
+	if len(names) == 0 && session.subscriber != nil {
+		isPattern := command.Name == CommandPUnsubscribe
+		for _, name := range pubsub.subscriptions(session.subscriber, isPattern) {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		switch command.Name {
+		case CommandSubscribe, CommandPSubscribe:
+			return encodeRedisReply(session, fmt.Errorf("ERR wrong number of arguments for '%s' command", kind)), nil
+		default:
+			return resp3.EncodeRedisPush([]interface{}{kind, nil, int64(0)}, protocol), nil
+		}
+	}
+
+	var replies strings.Builder
+	for _, name := range names {
+		output, err := executeInSession(ctx, session, &entity.Command{
+			Name: command.Name, Args: []interface{}{[]interface{}{name}}})
+		if err != nil {
+			return "", err
+		}
+
+		response, _ := decodeQueuedOutput(output).([]interface{})
+		if len(response) != 3 {
+			return encodeRedisReply(session, fmt.Errorf("ERR unexpected response %v", response)), nil
+		}
+
+		if code, _ := response[1].(int64); isRedisErrorCode(uint32(code)) {
+			return encodeRedisReply(session, getRedisError(uint32(code), fmt.Sprintf("%v", response[2]))), nil
+		}
+
+		replies.WriteString(resp3.EncodeRedisPush([]interface{}{kind, name, response[0]}, protocol))
+	}
+
+	return replies.String(), nil
+}
+
+// getInteger reads an integer argument, given either as an integer or as
+// the string of one, which is how all the arguments of Redis clients come.
+func getInteger(argument interface{}) (int64, bool) {
+	switch value := argument.(type) {
+	case int64:
+		return value, true
+	case string:
+		integer, err := strconv.ParseInt(value, 10, 64)
+		return integer, err == nil
+	default:
+		return 0, false
+	}
+}
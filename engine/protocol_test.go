@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"universum/config"
+	"universum/entity"
+)
+
+// runRedisCommand sends the command the way Redis clients do, as an array of
+// bulk strings, and returns the raw reply.
+func runRedisCommand(t *testing.T, session *Session, args ...string) string {
+	request := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		request += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	output, err := ExecuteCommand(bufio.NewReader(strings.NewReader(request)), time.Second, session)
+	if err != nil {
+		t.Fatalf("failed to execute %v: %v", args, err)
+	}
+	return output
+}
+
+func newRedisSession(t *testing.T, protover string) *Session {
+	session := NewSession()
+	if reply := runRedisCommand(t, session, CommandHello, protover); strings.HasPrefix(reply, "-") {
+		t.Fatalf("failed to switch to protocol %s: %q", protover, reply)
+	}
+	return session
+}
+
+func TestHelloSwitchesProtocol(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+
+	if response := runInSession(t, session, CommandHello); responseCode(response) != entity.CRC_HELLO_CONTENT_OK {
+		t.Fatalf("expected HELLO without a version to describe the server, got %v", response)
+	}
+
+	if session.ResponseDelimiter() != entity.ResponseDelimiter {
+		t.Fatal("expected HELLO without a version to keep the native protocol")
+	}
+
+	reply := runRedisCommand(t, session, CommandHello, "2")
+	if !strings.HasPrefix(reply, "*12\r\n") || !strings.Contains(reply, "$5\r\nproto\r\n:2\r\n") {
+		t.Errorf("unexpected HELLO reply %q", reply)
+	}
+
+	if session.ResponseDelimiter() != "" {
+		t.Error("expected the Redis replies not to be delimited")
+	}
+
+	if reply := runRedisCommand(t, session, CommandHello, "4"); !strings.HasPrefix(reply, "-NOPROTO ") {
+		t.Errorf("expected an unsupported version to be refused, got %q", reply)
+	}
+
+	if reply := runRedisCommand(t, session, CommandHello, "3"); !strings.HasPrefix(reply, "%6\r\n") {
+		t.Errorf("expected the RESP3 reply to be a map, got %q", reply)
+	}
+}
+
+func TestDefaultProtocol(t *testing.T) {
+	setupBlockingTests()
+	config.Store.Server.DefaultProtocol = config.ProtocolRESP2
+
+	if reply := runRedisCommand(t, NewSession(), CommandPing); reply != "+PONG\r\n" {
+		t.Errorf("expected new sessions to speak RESP2, got %q", reply)
+	}
+}
+
+func TestRedisCommandsOverRESP2(t *testing.T) {
+	setupBlockingTests()
+	session := newRedisSession(t, "2")
+
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"SET", "name", "universum"}, "+OK\r\n"},
+		{[]string{"GET", "name"}, "$9\r\nuniversum\r\n"},
+		{[]string{"GET", "missing"}, "$-1\r\n"},
+		{[]string{"SET", "name", "other", "NX"}, "$-1\r\n"},
+		{[]string{"SET", "name", "other", "XX", "GET"}, "$9\r\nuniversum\r\n"},
+		{[]string{"SET", "session", "token", "EX", "60"}, "+OK\r\n"},
+		{[]string{"SET", "session", "token", "EX"}, "-ERR syntax error\r\n"},
+		{[]string{"EXISTS", "name"}, ":1\r\n"},
+		{[]string{"TTL", "name"}, ":-1\r\n"},
+		{[]string{"TTL", "session"}, ":60\r\n"},
+		{[]string{"TTL", "missing"}, ":-2\r\n"},
+		{[]string{"INCR", "counter"}, "-ERR no such key\r\n"},
+		{[]string{"INCR", "name"}, "-ERR value is not an integer or out of range\r\n"},
+		{[]string{"INCRBY", "name", "five"}, "-ERR value is not an integer or out of range\r\n"},
+		{[]string{"MSET", "a", "1", "b", "2"}, "+OK\r\n"},
+		{[]string{"MGET", "a", "missing", "b"}, "*3\r\n$1\r\n1\r\n$-1\r\n$1\r\n2\r\n"},
+		{[]string{"DEL", "a", "b"}, ":2\r\n"},
+		{[]string{"RPUSH", "queue", "x", "y", "z"}, ":3\r\n"},
+		{[]string{"LRANGE", "queue", "0", "-1"}, "*3\r\n$1\r\nx\r\n$1\r\ny\r\n$1\r\nz\r\n"},
+		{[]string{"RPUSH", "name", "x"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{[]string{"HSET", "user", "name", "ann", "role", "admin"}, ":2\r\n"},
+		{[]string{"HMGET", "user", "role", "missing", "name"}, "*3\r\n$5\r\nadmin\r\n$-1\r\n$3\r\nann\r\n"},
+		{[]string{"HGETALL", "user"}, "*4\r\n$4\r\nname\r\n$3\r\nann\r\n$4\r\nrole\r\n$5\r\nadmin\r\n"},
+		{[]string{"SADD", "tags", "go", "db"}, ":2\r\n"},
+		{[]string{"SISMEMBER", "tags", "go"}, ":1\r\n"},
+		{[]string{"ZADD", "board", "1", "ann", "2.5", "bob"}, ":2\r\n"},
+		{[]string{"ZRANGE", "board", "0", "-1"}, "*2\r\n$3\r\nann\r\n$3\r\nbob\r\n"},
+		{[]string{"ZSCORE", "board", "bob"}, "$3\r\n2.5\r\n"},
+		{[]string{"ZINCRBY", "board", "1", "ann"}, "$1\r\n2\r\n"},
+		{[]string{"NOSUCHCOMMAND"}, "-ERR invalid command `NOSUCHCOMMAND` provided\r\n"},
+	}
+
+	for _, tc := range testCases {
+		if reply := runRedisCommand(t, session, tc.args...); reply != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, reply)
+		}
+	}
+}
+
+func TestRedisCommandsOverRESP3(t *testing.T) {
+	setupBlockingTests()
+	session := newRedisSession(t, "3")
+
+	runRedisCommand(t, session, "HSET", "user", "name", "ann")
+	runRedisCommand(t, session, "ZADD", "board", "2.5", "bob")
+
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"GET", "missing"}, "_\r\n"},
+		{[]string{"HGETALL", "user"}, "%1\r\n$4\r\nname\r\n$3\r\nann\r\n"},
+		{[]string{"HEXISTS", "user", "name"}, ":1\r\n"},
+		{[]string{"ZSCORE", "board", "bob"}, ",2.5\r\n"},
+	}
+
+	for _, tc := range testCases {
+		if reply := runRedisCommand(t, session, tc.args...); reply != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, reply)
+		}
+	}
+}
+
+func TestRedisTransaction(t *testing.T) {
+	setupBlockingTests()
+	session := newRedisSession(t, "2")
+
+	if reply := runRedisCommand(t, session, "MULTI"); reply != "+OK\r\n" {
+		t.Fatalf("expected MULTI to be acknowledged, got %q", reply)
+	}
+
+	for _, args := range [][]string{{"SET", "key", "value"}, {"GET", "key"}, {"DEL", "key"}} {
+		if reply := runRedisCommand(t, session, args...); reply != "+QUEUED\r\n" {
+			t.Fatalf("expected %v to be queued, got %q", args, reply)
+		}
+	}
+
+	if reply := runRedisCommand(t, session, "EXEC"); reply != "*3\r\n+OK\r\n$5\r\nvalue\r\n:1\r\n" {
+		t.Errorf("unexpected EXEC reply %q", reply)
+	}
+}
+
+func TestRedisSubscriptions(t *testing.T) {
+	setupBlockingTests()
+	session := newRedisSession(t, "2")
+	defer session.Close()
+
+	reply := runRedisCommand(t, session, "SUBSCRIBE", "news", "sports")
+	expected := "*3\r\n$9\r\nsubscribe\r\n$4\r\nnews\r\n:1\r\n" +
+		"*3\r\n$9\r\nsubscribe\r\n$6\r\nsports\r\n:2\r\n"
+	if reply != expected {
+		t.Fatalf("expected each subscription to be confirmed, got %q", reply)
+	}
+
+	runRedisCommand(t, NewSession(), "PUBLISH", "news", "hello")
+	if frame := nextPush(t, session.Subscriber()); frame != "*3\r\n$7\r\nmessage\r\n$4\r\nnews\r\n$5\r\nhello\r\n" {
+		t.Errorf("unexpected RESP2 message %q", frame)
+	}
+
+	reply = runRedisCommand(t, session, "UNSUBSCRIBE")
+	expected = "*3\r\n$11\r\nunsubscribe\r\n$4\r\nnews\r\n:1\r\n" +
+		"*3\r\n$11\r\nunsubscribe\r\n$6\r\nsports\r\n:0\r\n"
+	if reply != expected {
+		t.Errorf("expected each unsubscription to be confirmed, got %q", reply)
+	}
+}
+
+func TestRedisAuthentication(t *testing.T) {
+	setupAuthTests(t)
+	session := newRedisSession(t, "2")
+
+	if reply := runRedisCommand(t, session, "GET", "key"); !strings.HasPrefix(reply, "-NOAUTH ") {
+		t.Fatalf("expected the command to require authentication, got %q", reply)
+	}
+
+	if reply := runRedisCommand(t, session, "AUTH", "wrong"); !strings.HasPrefix(reply, "-WRONGPASS ") {
+		t.Errorf("expected a wrong password to be refused, got %q", reply)
+	}
+
+	if reply := runRedisCommand(t, session, "AUTH", "s3cret"); reply != "+OK\r\n" {
+		t.Errorf("expected AUTH with a password alone to authenticate the configured user, got %q", reply)
+	}
+
+	other := NewSession()
+	if reply := runRedisCommand(t, other, "HELLO", "3", "AUTH", "admin", "s3cret"); !strings.HasPrefix(reply, "%6\r\n") {
+		t.Errorf("expected HELLO to authenticate, got %q", reply)
+	}
+
+	if reply := runRedisCommand(t, other, "GET", "key"); reply != "_\r\n" {
+		t.Errorf("expected the session to be authenticated, got %q", reply)
+	}
+}
@@ -0,0 +1,379 @@
+package engine
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"universum/config"
+	"universum/entity"
+	"universum/internal/logger"
+	"universum/resp3"
+	"universum/utils"
+)
+
+const (
+	pushKindMessage  string = "message"
+	pushKindPMessage string = "pmessage"
+)
+
+// Subscriber is the receiving end of a client subscribed to channels or patterns.
+// The published messages are queued up as push frames, encoded in the protocol of
+// the client, for the connection to write out at its own pace. The queue is bounded, and a subscriber which lets
+// it fill up is dropped, so that a slow client never holds the publishers back.
+type Subscriber struct {
+	outbox   chan string
+	dropped  chan struct{}
+	dropOnce sync.Once
+
+	// protocol is the reply protocol of the session the subscriber belongs to.
+	protocol *atomic.Int32
+
+	// channels and patterns are guarded by the mutex of the hub.
+	channels map[string]struct{}
+	patterns map[string]struct{}
+}
+
+func newSubscriber(protocol *atomic.Int32) *Subscriber {
+	bufferSize := config.Store.Server.PubSubClientBufferSize
+	if bufferSize <= 0 {
+		bufferSize = config.DefaultPubSubClientBufferSize
+	}
+
+	return &Subscriber{
+		outbox:   make(chan string, bufferSize),
+		dropped:  make(chan struct{}),
+		protocol: protocol,
+		channels: make(map[string]struct{}),
+		patterns: make(map[string]struct{}),
+	}
+}
+
+// Pushes returns the encoded push frames waiting to be written to the client.
+func (s *Subscriber) Pushes() <-chan string {
+	return s.outbox
+}
+
+// Dropped returns a channel which is closed once the subscriber has fallen
+// too far behind, after which the connection is expected to be closed.
+func (s *Subscriber) Dropped() <-chan struct{} {
+	return s.dropped
+}
+
+func (s *Subscriber) subscriptionCount() int64 {
+	return int64(len(s.channels) + len(s.patterns))
+}
+
+// pubsubHub routes the published messages to the subscribers of the channel,
+// and to the subscribers of all the patterns which match the channel.
+type pubsubHub struct {
+	mutex    sync.RWMutex
+	channels map[string]map[*Subscriber]struct{}
+	patterns map[string]map[*Subscriber]struct{}
+}
+
+var pubsub = &pubsubHub{
+	channels: make(map[string]map[*Subscriber]struct{}),
+	patterns: make(map[string]map[*Subscriber]struct{}),
+}
+
+func (h *pubsubHub) subscribe(subscriber *Subscriber, channels []string) int64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, channel := range channels {
+		addSubscription(h.channels, subscriber.channels, channel, subscriber)
+	}
+
+	return subscriber.subscriptionCount()
+}
+
+func (h *pubsubHub) psubscribe(subscriber *Subscriber, patterns []string) int64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, pattern := range patterns {
+		addSubscription(h.patterns, subscriber.patterns, pattern, subscriber)
+	}
+
+	return subscriber.subscriptionCount()
+}
+
+// unsubscribe removes the subscriber from the given channels, or from all
+// of its channels when none are given.
+func (h *pubsubHub) unsubscribe(subscriber *Subscriber, channels []string) int64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(channels) == 0 {
+		channels = getSortedKeys(subscriber.channels)
+	}
+
+	for _, channel := range channels {
+		removeSubscription(h.channels, subscriber.channels, channel, subscriber)
+	}
+
+	return subscriber.subscriptionCount()
+}
+
+// punsubscribe removes the subscriber from the given patterns, or from all
+// of its patterns when none are given.
+func (h *pubsubHub) punsubscribe(subscriber *Subscriber, patterns []string) int64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(patterns) == 0 {
+		patterns = getSortedKeys(subscriber.patterns)
+	}
+
+	for _, pattern := range patterns {
+		removeSubscription(h.patterns, subscriber.patterns, pattern, subscriber)
+	}
+
+	return subscriber.subscriptionCount()
+}
+
+// subscriptions returns the channels, or the patterns, the subscriber is
+// subscribed to, in order.
+func (h *pubsubHub) subscriptions(subscriber *Subscriber, patterns bool) []string {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	if patterns {
+		return getSortedKeys(subscriber.patterns)
+	}
+	return getSortedKeys(subscriber.channels)
+}
+
+// unsubscribeAll removes the subscriber from all its channels and patterns.
+func (h *pubsubHub) unsubscribeAll(subscriber *Subscriber) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for channel := range subscriber.channels {
+		removeSubscription(h.channels, subscriber.channels, channel, subscriber)
+	}
+
+	for pattern := range subscriber.patterns {
+		removeSubscription(h.patterns, subscriber.patterns, pattern, subscriber)
+	}
+}
+
+// publish queues the message up for all the subscribers of the channel, and
+// returns the number of subscribers it was queued up for. Subscribers whose
+// queue is full are dropped rather than waited on.
+func (h *pubsubHub) publish(channel string, message interface{}) int64 {
+	var receivers int64
+	laggards := make([]*Subscriber, 0)
+
+	h.mutex.RLock()
+
+	if subscribers, ok := h.channels[channel]; ok {
+		frame := newPushFrame(pushKindMessage, channel, message)
+		for subscriber := range subscribers {
+			if deliver(subscriber, frame.encode(subscriber.protocol.Load())) {
+				receivers++
+			} else {
+				laggards = append(laggards, subscriber)
+			}
+		}
+	}
+
+	for pattern, subscribers := range h.patterns {
+		if !utils.MatchGlob(pattern, channel) {
+			continue
+		}
+
+		frame := newPushFrame(pushKindPMessage, pattern, channel, message)
+		for subscriber := range subscribers {
+			if deliver(subscriber, frame.encode(subscriber.protocol.Load())) {
+				receivers++
+			} else {
+				laggards = append(laggards, subscriber)
+			}
+		}
+	}
+
+	h.mutex.RUnlock()
+
+	for _, subscriber := range laggards {
+		h.drop(subscriber)
+	}
+
+	return receivers
+}
+
+// drop unsubscribes the subscriber from everything, and signals its connection
+// that it has fallen behind.
+func (h *pubsubHub) drop(subscriber *Subscriber) {
+	subscriber.dropOnce.Do(func() {
+		logger.Get().Warn("Dropping a pub/sub subscriber lagging behind by %d messages", cap(subscriber.outbox))
+		h.unsubscribeAll(subscriber)
+		close(subscriber.dropped)
+	})
+}
+
+// deliver queues the frame up for the subscriber without blocking, and tells
+// whether there was room left for it.
+func deliver(subscriber *Subscriber, frame string) bool {
+	select {
+	case subscriber.outbox <- frame:
+		return true
+	default:
+		return false
+	}
+}
+
+// pushFrame encodes the items of a push once for each of the protocols spoken
+// by the subscribers it is delivered to, and only for those.
+type pushFrame struct {
+	items   []interface{}
+	encoded map[int32]string
+}
+
+func newPushFrame(items ...interface{}) *pushFrame {
+	return &pushFrame{items: items, encoded: make(map[int32]string)}
+}
+
+func (f *pushFrame) encode(protocol int32) string {
+	if frame, ok := f.encoded[protocol]; ok {
+		return frame
+	}
+
+	var frame string
+	if protocol == protocolNative {
+		var err error
+		if frame, err = resp3.EncodePush(f.items); err != nil {
+			logger.Get().Error("Failed to encode the push frame: %v", err)
+		}
+	} else {
+		frame = resp3.EncodeRedisPush(f.items, int(protocol))
+	}
+
+	f.encoded[protocol] = frame
+	return frame
+}
+
+func addSubscription(index map[string]map[*Subscriber]struct{}, own map[string]struct{}, name string, subscriber *Subscriber) {
+	if _, ok := index[name]; !ok {
+		index[name] = make(map[*Subscriber]struct{})
+	}
+
+	index[name][subscriber] = struct{}{}
+	own[name] = struct{}{}
+}
+
+func removeSubscription(index map[string]map[*Subscriber]struct{}, own map[string]struct{}, name string, subscriber *Subscriber) {
+	delete(own, name)
+	delete(index[name], subscriber)
+
+	if len(index[name]) == 0 {
+		delete(index, name)
+	}
+}
+
+func getSortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func executeSUBSCRIBE(session *Session, command *entity.Command) string {
+	channels, errorResponse := getSubscriptionArgs(session, command, true)
+	if errorResponse != "" {
+		return errorResponse
+	}
+
+	count := pubsub.subscribe(session.getSubscriber(), channels)
+	return resp3.EncodedRESP3Response([]interface{}{count, entity.CRC_SUBSCRIBED, ""})
+}
+
+func executeUNSUBSCRIBE(session *Session, command *entity.Command) string {
+	channels, errorResponse := getSubscriptionArgs(session, command, false)
+	if errorResponse != "" {
+		return errorResponse
+	}
+
+	var count int64
+	if session.subscriber != nil {
+		count = pubsub.unsubscribe(session.subscriber, channels)
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{count, entity.CRC_UNSUBSCRIBED, ""})
+}
+
+func executePSUBSCRIBE(session *Session, command *entity.Command) string {
+	patterns, errorResponse := getSubscriptionArgs(session, command, true)
+	if errorResponse != "" {
+		return errorResponse
+	}
+
+	count := pubsub.psubscribe(session.getSubscriber(), patterns)
+	return resp3.EncodedRESP3Response([]interface{}{count, entity.CRC_SUBSCRIBED, ""})
+}
+
+func executePUNSUBSCRIBE(session *Session, command *entity.Command) string {
+	patterns, errorResponse := getSubscriptionArgs(session, command, false)
+	if errorResponse != "" {
+		return errorResponse
+	}
+
+	var count int64
+	if session.subscriber != nil {
+		count = pubsub.punsubscribe(session.subscriber, patterns)
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{count, entity.CRC_UNSUBSCRIBED, ""})
+}
+
+func executePUBLISH(command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "channel", Datatype: reflect.String},
+		{Name: "message", Datatype: reflect.Interface},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	channel := command.Args[0].(string)
+	receivers := pubsub.publish(channel, command.Args[1])
+
+	return resp3.EncodedRESP3Response([]interface{}{receivers, entity.CRC_MESSAGE_PUBLISHED, ""})
+}
+
+// getSubscriptionArgs validates the list of channels or patterns given to the
+// subscription commands, which is optional for the unsubscribing ones. A non
+// empty error response is returned if the arguments are not valid.
+func getSubscriptionArgs(session *Session, command *entity.Command, required bool) ([]string, string) {
+	if session.inTransaction() {
+		return nil, resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_NOT_ALLOWED_IN_TRANSACTION,
+			"subscriptions can not be changed inside MULTI"})
+	}
+
+	if !required && len(command.Args) == 0 {
+		return []string{}, ""
+	}
+
+	rules := []utils.ValidationRule{
+		{Name: "names", Datatype: reflect.Slice},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return nil, resp3.EncodedRESP3Response(validityRes)
+	}
+
+	names, ok := getStringSlice(command.Args[0])
+	if !ok || (required && len(names) == 0) {
+		return nil, resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"first argument should be a non-empty list of string"})
+	}
+
+	return names, ""
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+	"universum/config"
+	"universum/entity"
+)
+
+func nextPush(t *testing.T, subscriber *Subscriber) string {
+	select {
+	case frame := <-subscriber.Pushes():
+		return frame
+	default:
+		t.Fatal("expected a push frame to be queued")
+		return ""
+	}
+}
+
+func TestPublishToChannelSubscribers(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+	defer session.Close()
+	publisher := NewSession()
+
+	response := runInSession(t, session, CommandSubscribe, []interface{}{"news", "sports"})
+	if responseCode(response) != entity.CRC_SUBSCRIBED || response[0] != int64(2) {
+		t.Fatalf("expected two subscriptions, got %v", response)
+	}
+
+	response = runInSession(t, publisher, CommandPublish, "news", "hello")
+	if responseCode(response) != entity.CRC_MESSAGE_PUBLISHED || response[0] != int64(1) {
+		t.Fatalf("expected the message to reach one subscriber, got %v", response)
+	}
+
+	if frame := nextPush(t, session.Subscriber()); frame != ">3\r\n+message\r\n+news\r\n+hello\r\n" {
+		t.Errorf("unexpected push frame %q", frame)
+	}
+
+	if response := runInSession(t, publisher, CommandPublish, "weather", "rain"); response[0] != int64(0) {
+		t.Errorf("expected nobody to receive messages on other channels, got %v", response)
+	}
+}
+
+func TestPublishToPatternSubscribers(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+	defer session.Close()
+
+	runInSession(t, session, CommandPSubscribe, []interface{}{"user:*"})
+
+	if response := runInSession(t, session, CommandPublish, "user:42", int64(7)); response[0] != int64(1) {
+		t.Fatalf("expected the pattern subscriber to receive the message, got %v", response)
+	}
+
+	if frame := nextPush(t, session.Subscriber()); frame != ">4\r\n+pmessage\r\n+user:*\r\n+user:42\r\n:7\r\n" {
+		t.Errorf("unexpected push frame %q", frame)
+	}
+
+	if response := runInSession(t, session, CommandPublish, "order:42", int64(7)); response[0] != int64(0) {
+		t.Errorf("expected non matching channels not to be delivered, got %v", response)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+	defer session.Close()
+
+	runInSession(t, session, CommandSubscribe, []interface{}{"a", "b", "c"})
+	runInSession(t, session, CommandPSubscribe, []interface{}{"d*"})
+
+	response := runInSession(t, session, CommandUnsubscribe, []interface{}{"a"})
+	if responseCode(response) != entity.CRC_UNSUBSCRIBED || response[0] != int64(3) {
+		t.Fatalf("expected three subscriptions to be left, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandUnsubscribe); response[0] != int64(1) {
+		t.Fatalf("expected only the pattern to be left, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandPUnsubscribe); response[0] != int64(0) {
+		t.Fatalf("expected no subscriptions to be left, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandPublish, "b", "gone"); response[0] != int64(0) {
+		t.Errorf("expected unsubscribed channels not to be delivered, got %v", response)
+	}
+}
+
+func TestLaggingSubscriberIsDropped(t *testing.T) {
+	setupBlockingTests()
+	config.Store.Server.PubSubClientBufferSize = 2
+	defer func() { config.Store.Server.PubSubClientBufferSize = config.DefaultPubSubClientBufferSize }()
+
+	session := NewSession()
+	defer session.Close()
+
+	runInSession(t, session, CommandSubscribe, []interface{}{"firehose"})
+	for i := 0; i < 3; i++ {
+		runInSession(t, session, CommandPublish, "firehose", int64(i))
+	}
+
+	select {
+	case <-session.Subscriber().Dropped():
+	default:
+		t.Fatal("expected the lagging subscriber to be dropped")
+	}
+
+	if response := runInSession(t, session, CommandPublish, "firehose", "more"); response[0] != int64(0) {
+		t.Errorf("expected the dropped subscriber to be unsubscribed, got %v", response)
+	}
+}
+
+func TestSubscribeNotAllowedInTransaction(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+
+	runInSession(t, session, CommandMulti)
+	if response := runInSession(t, session, CommandSubscribe, []interface{}{"news"}); responseCode(response) != entity.CRC_NOT_ALLOWED_IN_TRANSACTION {
+		t.Errorf("expected SUBSCRIBE inside MULTI to be refused, got %v", response)
+	}
+}
+
+func TestKeyspaceNotifications(t *testing.T) {
+	setupBlockingTests()
+	config.Store.Notifications.KeyspaceEvents = []string{config.KeyspaceEventDel}
+	setupKeyspaceNotifications()
+	defer func() {
+		config.Store.Notifications.KeyspaceEvents = nil
+		setupKeyspaceNotifications()
+	}()
+
+	session := NewSession()
+	defer session.Close()
+
+	runInSession(t, session, CommandSubscribe, []interface{}{"__keyevent__:del", "__keyevent__:set"})
+	runInSession(t, session, CommandPSubscribe, []interface{}{"__keyspace__:*"})
+
+	runInSession(t, session, CommandSet, "session", "value", int64(0))
+	if pending := len(session.Subscriber().Pushes()); pending != 0 {
+		t.Fatalf("expected the disabled set events not to be published, got %d", pending)
+	}
+
+	runInSession(t, session, CommandDelete, "session")
+
+	// push frames are laid out like arrays, which the decoder understands
+	keyevent := decodeResponse(t, "*"+nextPush(t, session.Subscriber())[1:])
+	if fmt.Sprint(keyevent) != "[message __keyevent__:del session]" {
+		t.Errorf("unexpected keyevent push %v", keyevent)
+	}
+
+	keyspace := decodeResponse(t, "*"+nextPush(t, session.Subscriber())[1:])
+	if fmt.Sprint(keyspace) != "[pmessage __keyspace__:* __keyspace__:session del]" {
+		t.Errorf("unexpected keyspace push %v", keyspace)
+	}
+}
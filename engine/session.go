@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"sync/atomic"
+	"universum/config"
+	"universum/entity"
+)
+
+// Session holds the state of a single client connection which outlives the
+// individual commands, such as an open transaction and the keys it watches.
+// A session belongs to its connection and is not safe for concurrent use.
+type Session struct {
+	// transaction holds the commands queued since MULTI, and is nil
+	// when no transaction is open.
+	transaction []*entity.Command
+
+	// watched maps the keys watched by WATCH to the state they were in at
+	// the time, which EXEC compares against before applying.
+	watched map[string]watchedState
+
+	// subscriber receives the messages published to the channels and the
+	// patterns subscribed to, and is nil until the first subscription.
+	subscriber *Subscriber
+
+	// user is the name the connection authenticated as, with AUTH or with its
+	// client certificate, and is empty until it does.
+	user string
+
+	// protocol is the reply protocol of the connection, negotiated with HELLO.
+	// The pushes are written out by a goroutine of their own, which reads it
+	// as well, hence it being atomic.
+	protocol atomic.Int32
+}
+
+// NewSession creates the state for a newly accepted client connection, which
+// speaks the configured default protocol until it negotiates another.
+func NewSession() *Session {
+	session := &Session{}
+	session.protocol.Store(getProtocolVersion(config.Store.Server.DefaultProtocol))
+	return session
+}
+
+// NewNativeSession creates the state for a client which does not speak RESP,
+// such as the HTTP gateway, and converts the native responses itself whatever
+// the configured default protocol.
+func NewNativeSession() *Session {
+	session := &Session{}
+	session.protocol.Store(protocolNative)
+	return session
+}
+
+// inTransaction tells whether the commands are being queued for an EXEC.
+func (s *Session) inTransaction() bool {
+	return s.transaction != nil
+}
+
+// isAuthenticated tells whether the connection has authenticated with AUTH.
+func (s *Session) isAuthenticated() bool {
+	return s.user != ""
+}
+
+func (s *Session) getProtocol() int32 {
+	return s.protocol.Load()
+}
+
+func (s *Session) setProtocol(protocol int32) {
+	s.protocol.Store(protocol)
+}
+
+// ResponseDelimiter returns what is to be written after each response on the
+// connection. The native protocol ends its responses with a delimiter, whereas
+// the Redis clients expect nothing but the replies.
+func (s *Session) ResponseDelimiter() string {
+	if s.getProtocol() == protocolNative {
+		return entity.ResponseDelimiter
+	}
+	return ""
+}
+
+// resetTransaction closes the open transaction, if any, and forgets the
+// watched keys, which is what both EXEC and DISCARD end up with.
+func (s *Session) resetTransaction() {
+	s.transaction = nil
+	s.unwatchAll()
+}
+
+// unwatchAll forgets the watched keys, which stop being tracked for the session.
+func (s *Session) unwatchAll() {
+	for key := range s.watched {
+		watchedKeys.unwatch(key)
+	}
+	s.watched = nil
+}
+
+// Subscriber returns the receiving end of the pub/sub subscriptions made on
+// the session, or nil if nothing was ever subscribed to.
+func (s *Session) Subscriber() *Subscriber {
+	return s.subscriber
+}
+
+// Close releases what the session holds beyond the connection, which is to be
+// called once the connection is done with.
+func (s *Session) Close() {
+	s.unwatchAll()
+
+	if s.subscriber != nil {
+		pubsub.unsubscribeAll(s.subscriber)
+	}
+}
+
+func (s *Session) getSubscriber() *Subscriber {
+	if s.subscriber == nil {
+		s.subscriber = newSubscriber(&s.protocol)
+	}
+	return s.subscriber
+}
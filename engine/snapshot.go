@@ -2,6 +2,7 @@ package engine
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 	"universum/config"
 	"universum/entity"
@@ -36,7 +37,7 @@ func (w *databaseSnapshotWorker) startDatabaseSnapshot(snapshotChan chan<- datab
 	snapshotJobLastExecutedAt = time.Now()
 
 	for {
-		nextScheduledTime := snapshotJobLastExecutedAt.Add(expiryJobExecutionFrequency)
+		nextScheduledTime := snapshotJobLastExecutedAt.Add(snapshotJobExecutionFrequency)
 
 		if nextScheduledTime.Compare(time.Now()) < 1 {
 			StartDatabaseSnapshot(getDataStore(config.Store.Storage.StorageEngine))
@@ -56,6 +57,11 @@ func StartDatabaseSnapshot(store storage.DataStore) error {
 	snapshotservice := getSnapshotService(config.Store.Storage.StorageEngine)
 	recordCount, ssSizeInBytes, err = snapshotservice.Snapshot(store)
 
+	ks_snapshotDurations.observe(time.Since(time.UnixMilli(snapshotStartTime)))
+	if err != nil {
+		atomic.AddInt64(&ks_snapshotFailures, 1)
+	}
+
 	DatabaseInfoStats.Persistence.LastSnapshotTakenAt = utils.GetCurrentReadableTime()
 	DatabaseInfoStats.Persistence.LastSnapshotLatency = fmt.Sprintf("%dms", time.Now().UnixMilli()-snapshotStartTime)
 	DatabaseInfoStats.Persistence.SnapshotSizeInBytes = ssSizeInBytes
@@ -78,6 +84,7 @@ func AutoRestoreDatabaseSnapshot(datastore storage.DataStore) {
 	}
 
 	keyCount, err := snapshotservice.Restore(datastore)
+	atomic.StoreInt64(&ks_snapshotReplayDuration, int64(time.Since(time.UnixMilli(replayStartTime))))
 
 	replayLatency := fmt.Sprintf("%d ms", time.Now().UnixMilli()-replayStartTime)
 
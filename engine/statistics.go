@@ -18,6 +18,7 @@ var DatabaseInfoStats *entity.InfoStats
 var ks_networkBytesSent int64 = 0
 var ks_networkBytesReceived int64 = 0
 var ks_commandsProcessed int64 = 0
+var ks_evictedKeys int64 = 0
 
 func GetNetworkBytesSent() int64 {
 	return atomic.LoadInt64(&ks_networkBytesSent)
@@ -43,6 +44,14 @@ func AddCommandsProcessed(delta int64) {
 	atomic.AddInt64(&ks_commandsProcessed, delta)
 }
 
+func GetEvictedKeys() int64 {
+	return atomic.LoadInt64(&ks_evictedKeys)
+}
+
+func AddEvictedKeys(delta int64) {
+	atomic.AddInt64(&ks_evictedKeys, delta)
+}
+
 func InitInfoStatistics() {
 	timezone, _ := time.Now().Zone()
 
@@ -51,6 +60,7 @@ func InitInfoStatistics() {
 		Server: &entity.ServerStats{
 			BuildVersion: entity.SERVER_VERSION,
 			TCPPort:      config.Store.Server.ServerPort,
+			UnixSocket:   config.Store.Server.UnixSocketPath,
 			ClockTime:    utils.GetCurrentReadableTime(),
 			ConfigFile:   config.DefaultConfigFilePath,
 			OSName:       build.Default.GOOS,
@@ -82,6 +92,8 @@ func InitInfoStatistics() {
 		Keyspace: &entity.KeyspaceStats{
 			TotalKeyCount:   0,
 			KeyCountWithTTL: 0,
+			EvictedKeyCount: 0,
+			EvictionPolicy:  config.Store.Eviction.AutoEvictionPolicy,
 		},
 	}
 }
@@ -110,6 +122,11 @@ func GetDatabaseInfoStatistics() *entity.InfoStats {
 	DatabaseInfoStats.Network.NetworkBytesSent = GetNetworkBytesSent()
 	DatabaseInfoStats.Network.NetworkBytesReceived = GetNetworkBytesReceived()
 
+	DatabaseInfoStats.Keyspace.EvictedKeyCount = GetEvictedKeys()
+
+	DatabaseInfoStats.Clients.MaxConnectionConcurrency = entity.GetPeakTCPConnectionCount()
+	DatabaseInfoStats.Clients.RejectedConnections = entity.GetRejectedTCPConnectionCount()
+
 	activeConnections := entity.GetActiveTCPConnectionCount()
 	DatabaseInfoStats.Clients.ConnectedClients = activeConnections
 
@@ -0,0 +1,366 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"universum/entity"
+	"universum/resp3"
+	"universum/storage"
+	"universum/utils"
+)
+
+// keyspaceLock isolates the transactions from all the other commands. Commands
+// hold its read side while they run, and EXEC holds the write side throughout
+// the queued commands, so that nothing else gets to run in between them.
+var keyspaceLock sync.RWMutex
+
+// watchedKeys counts the writes to the keys watched by the sessions, which the
+// stores report through the keyspace listener, for EXEC to tell whether the keys
+// were modified since WATCH. Only the keys being watched are tracked.
+var watchedKeys = &watchRegistry{keys: make(map[string]*watchedKey)}
+
+type watchRegistry struct {
+	mutex sync.Mutex
+	keys  map[string]*watchedKey
+	count atomic.Int64 // keys watched, for the writes to skip the lookup when none is
+}
+
+type watchedKey struct {
+	version  uint64 // writes to the key since it started being watched
+	watchers int    // sessions watching the key
+}
+
+// watchedState is what WATCH captured of a key, for EXEC to compare against.
+type watchedState struct {
+	version uint64
+
+	// exists tells whether the key held a live record, as the records expiring
+	// are not reported as writes by all the stores.
+	exists bool
+}
+
+// watch starts tracking the writes to the key for one more session, and returns
+// the version it is at.
+func (wr *watchRegistry) watch(key string) uint64 {
+	wr.mutex.Lock()
+	defer wr.mutex.Unlock()
+
+	watched, ok := wr.keys[key]
+	if !ok {
+		watched = &watchedKey{}
+		wr.keys[key] = watched
+		wr.count.Add(1)
+	}
+
+	watched.watchers++
+	return watched.version
+}
+
+// unwatch stops tracking the writes to the key for one session, and forgets the
+// key once no session watches it anymore.
+func (wr *watchRegistry) unwatch(key string) {
+	wr.mutex.Lock()
+	defer wr.mutex.Unlock()
+
+	watched, ok := wr.keys[key]
+	if !ok {
+		return
+	}
+
+	if watched.watchers--; watched.watchers == 0 {
+		delete(wr.keys, key)
+		wr.count.Add(-1)
+	}
+}
+
+// version returns the version the watched key is at.
+func (wr *watchRegistry) version(key string) uint64 {
+	wr.mutex.Lock()
+	defer wr.mutex.Unlock()
+
+	if watched, ok := wr.keys[key]; ok {
+		return watched.version
+	}
+	return 0
+}
+
+// modified bumps the version of the key if it is watched. It is called from the
+// write paths of the stores, and costs a single atomic load when nothing is watched.
+func (wr *watchRegistry) modified(key string) {
+	if wr.count.Load() == 0 {
+		return
+	}
+
+	wr.mutex.Lock()
+	defer wr.mutex.Unlock()
+
+	if watched, ok := wr.keys[key]; ok {
+		watched.version++
+	}
+}
+
+// commandsNotAllowedInTransaction lists the commands which cannot be queued, the
+// blocking ones, as nothing could push to their lists while EXEC holds the keyspace.
+var commandsNotAllowedInTransaction = map[string]struct{}{
+	CommandBLPop: {},
+	CommandBRPop: {},
+}
+
+// executeInSession runs the command in the context of the client session, once
+// the session is allowed to. The session commands, such as the transaction and
+// the subscription ones, are run right away, whereas the others get queued while
+// a transaction is open, or executed isolated otherwise.
+func executeInSession(ctx context.Context, session *Session, command *entity.Command) (output string, err error) {
+	defer recordCommandMetrics(command.Name, time.Now(), &err)
+
+	if isAuthenticationRequired(session, command) {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_AUTHENTICATION_REQUIRED, "authentication required, use AUTH"}), nil
+	}
+
+	ctx = withSessionUser(ctx, session)
+
+	// the subscriptions are not run through executeCommand, which checks the
+	// permissions of the other commands
+	if command.Name == CommandSubscribe || command.Name == CommandPSubscribe {
+		if isPermitted, reason := checkPermissions(ctx, command); !isPermitted {
+			return resp3.EncodedRESP3Response([]interface{}{nil, entity.CRC_PERMISSION_DENIED, reason}), nil
+		}
+	}
+
+	switch command.Name {
+	case CommandAuth:
+		return executeAUTH(session, command), nil
+
+	case CommandHello:
+		return executeHELLO(session, command), nil
+
+	case CommandMulti:
+		return executeMULTI(session, command), nil
+
+	case CommandExec:
+		return executeEXEC(session, command), nil
+
+	case CommandDiscard:
+		return executeDISCARD(session, command), nil
+
+	case CommandWatch:
+		return executeWATCH(session, command), nil
+
+	case CommandUnwatch:
+		return executeUNWATCH(session, command), nil
+
+	case CommandSubscribe:
+		return executeSUBSCRIBE(session, command), nil
+
+	case CommandUnsubscribe:
+		return executeUNSUBSCRIBE(session, command), nil
+
+	case CommandPSubscribe:
+		return executePSUBSCRIBE(session, command), nil
+
+	case CommandPUnsubscribe:
+		return executePUNSUBSCRIBE(session, command), nil
+	}
+
+	if session.inTransaction() {
+		return queueCommand(session, command), nil
+	}
+
+	return executeIsolated(ctx, command)
+}
+
+// executeIsolated runs the command under the read side of the keyspace lock,
+// so that it never observes a transaction half way through. The blocking pops
+// are the exception, they take the lock around each of their pop attempts
+// instead, so that waiting on an empty list does not hold transactions off.
+func executeIsolated(ctx context.Context, command *entity.Command) (string, error) {
+	if command.Name != CommandBLPop && command.Name != CommandBRPop {
+		keyspaceLock.RLock()
+		defer keyspaceLock.RUnlock()
+	}
+
+	return executeCommand(ctx, command)
+}
+
+func executeMULTI(session *Session, command *entity.Command) string {
+	rules := []utils.ValidationRule{}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	if session.inTransaction() {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_NOT_ALLOWED_IN_TRANSACTION, "MULTI calls can not be nested"})
+	}
+
+	session.transaction = make([]*entity.Command, 0)
+	return resp3.EncodedRESP3Response([]interface{}{"OK", entity.CRC_TRANSACTION_STARTED, ""})
+}
+
+func executeEXEC(session *Session, command *entity.Command) string {
+	rules := []utils.ValidationRule{}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	if !session.inTransaction() {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_NOT_IN_TRANSACTION, "EXEC without MULTI"})
+	}
+
+	queued, watched := session.transaction, session.watched
+
+	keyspaceLock.Lock()
+	defer keyspaceLock.Unlock()
+
+	// the watched keys are compared before the session stops watching them,
+	// as their writes are only counted for as long as they are watched
+	modified := ""
+	for key, state := range watched {
+		if state != getWatchedState(key) {
+			modified = key
+			break
+		}
+	}
+	session.resetTransaction()
+
+	if modified != "" {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_TRANSACTION_ABORTED, fmt.Sprintf("watched key `%s` was modified", modified)})
+	}
+
+	batchWriter, isBatchWriter := datastore.(storage.BatchWriter)
+	if isBatchWriter {
+		batchWriter.BeginBatch()
+	}
+
+	results := make([]interface{}, 0, len(queued))
+	for _, queuedCommand := range queued {
+		// the queued commands are all run regardless of the request deadline,
+		// as stopping half way through would break the atomicity of EXEC.
+		output, err := executeCommand(withSessionUser(context.Background(), session), queuedCommand)
+		if err != nil {
+			results = append(results, []interface{}{nil, entity.CRC_INVALID_CMD_INPUT, err.Error()})
+			continue
+		}
+
+		results = append(results, decodeQueuedOutput(output))
+	}
+
+	if isBatchWriter {
+		// the writes failing to be logged are rolled back, and none is reported
+		if err := batchWriter.CommitBatch(); err != nil {
+			return resp3.EncodedRESP3Response([]interface{}{
+				nil, entity.CRC_WAL_WRITE_FAILED, fmt.Sprintf("transaction rolled back: %v", err)})
+		}
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{results, entity.CRC_TRANSACTION_COMPLETED, ""})
+}
+
+func executeDISCARD(session *Session, command *entity.Command) string {
+	rules := []utils.ValidationRule{}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	if !session.inTransaction() {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_NOT_IN_TRANSACTION, "DISCARD without MULTI"})
+	}
+
+	session.resetTransaction()
+	return resp3.EncodedRESP3Response([]interface{}{"OK", entity.CRC_TRANSACTION_DISCARDED, ""})
+}
+
+func executeWATCH(session *Session, command *entity.Command) string {
+	rules := []utils.ValidationRule{
+		{Name: "keys", Datatype: reflect.Slice},
+	}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	if session.inTransaction() {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_NOT_ALLOWED_IN_TRANSACTION, "WATCH inside MULTI is not allowed"})
+	}
+
+	keys, ok := getStringSlice(command.Args[0])
+	if !ok || len(keys) == 0 {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_INVALID_CMD_INPUT,
+			"first argument should be a non-empty list of string"})
+	}
+
+	if session.watched == nil {
+		session.watched = make(map[string]watchedState)
+	}
+
+	keyspaceLock.RLock()
+	defer keyspaceLock.RUnlock()
+
+	for _, key := range keys {
+		// a key watched again keeps the state from the first WATCH, so that
+		// changes made in between are not forgiven.
+		if _, ok := session.watched[key]; !ok {
+			watchedKeys.watch(key)
+			session.watched[key] = getWatchedState(key)
+		}
+	}
+
+	return resp3.EncodedRESP3Response([]interface{}{"OK", entity.CRC_KEYS_WATCHED, ""})
+}
+
+func executeUNWATCH(session *Session, command *entity.Command) string {
+	rules := []utils.ValidationRule{}
+
+	if isValid, validityRes := utils.ValidateArguments(command, rules); !isValid {
+		return resp3.EncodedRESP3Response(validityRes)
+	}
+
+	session.unwatchAll()
+	return resp3.EncodedRESP3Response([]interface{}{"OK", entity.CRC_KEYS_WATCHED, ""})
+}
+
+// queueCommand adds the command to the open transaction, to be run on EXEC.
+func queueCommand(session *Session, command *entity.Command) string {
+	if _, ok := commandsNotAllowedInTransaction[command.Name]; ok {
+		return resp3.EncodedRESP3Response([]interface{}{
+			nil, entity.CRC_NOT_ALLOWED_IN_TRANSACTION,
+			fmt.Sprintf("command `%s` is not allowed inside MULTI", command.Name)})
+	}
+
+	session.transaction = append(session.transaction, command)
+	return resp3.EncodedRESP3Response([]interface{}{"QUEUED", entity.CRC_COMMAND_QUEUED, ""})
+}
+
+// getWatchedState captures the state of the watched key, to detect whether it
+// was modified later on. The key is checked for with Exists rather than read,
+// for watching it not to count as an access to the record.
+func getWatchedState(key string) watchedState {
+	exists, _ := datastore.Exists(key)
+	return watchedState{version: watchedKeys.version(key), exists: exists}
+}
+
+// decodeQueuedOutput decodes the encoded response of a queued command, so that
+// the responses of all the queued commands can be sent back together by EXEC.
+func decodeQueuedOutput(output string) interface{} {
+	decoded, err := resp3.Decode(bufio.NewReader(strings.NewReader(output)))
+	if err != nil {
+		return []interface{}{nil, entity.CRC_DATA_READ_ERROR, err.Error()}
+	}
+
+	return decoded
+}
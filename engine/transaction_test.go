@@ -0,0 +1,261 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+	"universum/config"
+	"universum/entity"
+	"universum/storage/memory"
+	"universum/utils"
+)
+
+func runInSession(t *testing.T, session *Session, name string, args ...interface{}) []interface{} {
+	output, err := executeInSession(context.Background(), session, &entity.Command{Name: name, Args: args})
+	if err != nil {
+		t.Fatalf("failed to execute %s: %v", name, err)
+	}
+	return decodeResponse(t, output)
+}
+
+func responseCode(response []interface{}) uint32 {
+	return uint32(response[1].(int64))
+}
+
+func TestTransactionQueuesUntilExec(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+
+	if response := runInSession(t, session, CommandMulti); responseCode(response) != entity.CRC_TRANSACTION_STARTED {
+		t.Fatalf("expected transaction to start, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandSet, "counter", int64(1), int64(0)); responseCode(response) != entity.CRC_COMMAND_QUEUED {
+		t.Fatalf("expected SET to be queued, got %v", response)
+	}
+	runInSession(t, session, CommandIncr, "counter", int64(5))
+	runInSession(t, session, CommandGet, "counter")
+
+	if exists, _ := datastore.Exists("counter"); exists {
+		t.Fatal("expected queued commands not to be applied before EXEC")
+	}
+
+	response := runInSession(t, session, CommandExec)
+	if responseCode(response) != entity.CRC_TRANSACTION_COMPLETED {
+		t.Fatalf("expected transaction to complete, got %v", response)
+	}
+
+	results := response[0].([]interface{})
+	if len(results) != 3 {
+		t.Fatalf("expected a result per queued command, got %v", results)
+	}
+
+	last := results[2].([]interface{})
+	if record, ok := last[0].(map[string]interface{}); !ok || record["Value"] != int64(6) {
+		t.Errorf("expected GET to observe the earlier queued writes, got %v", last)
+	}
+
+	if session.inTransaction() {
+		t.Error("expected EXEC to close the transaction")
+	}
+}
+
+func TestTransactionDiscard(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+
+	runInSession(t, session, CommandMulti)
+	runInSession(t, session, CommandSet, "discarded", "value", int64(0))
+
+	if response := runInSession(t, session, CommandDiscard); responseCode(response) != entity.CRC_TRANSACTION_DISCARDED {
+		t.Fatalf("expected transaction to be discarded, got %v", response)
+	}
+
+	if exists, _ := datastore.Exists("discarded"); exists {
+		t.Error("expected discarded commands not to be applied")
+	}
+
+	if response := runInSession(t, session, CommandExec); responseCode(response) != entity.CRC_NOT_IN_TRANSACTION {
+		t.Errorf("expected EXEC without MULTI to fail, got %v", response)
+	}
+}
+
+func TestWatchAbortsExecOnModifiedKey(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+	other := NewSession()
+
+	runInSession(t, other, CommandSet, "balance", int64(100), int64(0))
+	runInSession(t, session, CommandWatch, []interface{}{"balance", "missing"})
+
+	runInSession(t, other, CommandSet, "balance", int64(50), int64(0))
+
+	runInSession(t, session, CommandMulti)
+	runInSession(t, session, CommandSet, "balance", int64(200), int64(0))
+
+	if response := runInSession(t, session, CommandExec); responseCode(response) != entity.CRC_TRANSACTION_ABORTED {
+		t.Fatalf("expected EXEC to abort on the modified key, got %v", response)
+	}
+
+	record, _ := datastore.Get("balance")
+	if record.GetValue() != int64(50) {
+		t.Errorf("expected aborted transaction not to be applied, got %v", record.GetValue())
+	}
+
+	// the watched keys are forgotten along with the aborted transaction
+	runInSession(t, session, CommandMulti)
+	runInSession(t, session, CommandSet, "balance", int64(200), int64(0))
+
+	if response := runInSession(t, session, CommandExec); responseCode(response) != entity.CRC_TRANSACTION_COMPLETED {
+		t.Errorf("expected EXEC to complete once the keys are no longer watched, got %v", response)
+	}
+}
+
+func TestWatchAllowsExecOnUnmodifiedKey(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+	other := NewSession()
+
+	runInSession(t, other, CommandHSet, "profile", map[string]interface{}{"name": "alice"})
+	runInSession(t, session, CommandWatch, []interface{}{"profile", "missing"})
+
+	// reads do not count as modifications
+	runInSession(t, other, CommandHGetAll, "profile")
+
+	runInSession(t, session, CommandMulti)
+	runInSession(t, session, CommandHSet, "profile", map[string]interface{}{"name": "bob"})
+
+	if response := runInSession(t, session, CommandExec); responseCode(response) != entity.CRC_TRANSACTION_COMPLETED {
+		t.Errorf("expected EXEC to complete, got %v", response)
+	}
+}
+
+func TestWatchAbortsExecOnKeyRewrittenWithSameValue(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+	other := NewSession()
+
+	runInSession(t, other, CommandSet, "balance", int64(100), int64(0))
+	record, _ := datastore.Get("balance")
+	frequency := record.GetFrequency()
+
+	runInSession(t, session, CommandWatch, []interface{}{"balance"})
+
+	// watching a key does not count as an access to its record
+	if record.GetFrequency() != frequency {
+		t.Errorf("expected WATCH not to touch the record, frequency went from %d to %d", frequency, record.GetFrequency())
+	}
+
+	runInSession(t, other, CommandSet, "balance", int64(100), int64(0))
+
+	runInSession(t, session, CommandMulti)
+	runInSession(t, session, CommandSet, "balance", int64(200), int64(0))
+
+	if response := runInSession(t, session, CommandExec); responseCode(response) != entity.CRC_TRANSACTION_ABORTED {
+		t.Errorf("expected EXEC to abort on the key rewritten with the same value, got %v", response)
+	}
+
+	if len(watchedKeys.keys) != 0 {
+		t.Errorf("expected the keys to stop being tracked once no longer watched, got %v", watchedKeys.keys)
+	}
+}
+
+func TestWatchAbortsExecOnKeyExpiredOrEvicted(t *testing.T) {
+	setupBlockingTests()
+	memstore := datastore.(*memory.MemoryStore)
+
+	// looked up in the shards, for the read not to expire the key itself
+	isStored := func() bool {
+		for _, shard := range memstore.GetAllShards() {
+			if _, ok := shard.GetData().Load("balance"); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	removals := map[string]func(){
+		"expired": func() {
+			record, _ := datastore.Get("balance")
+			record.(*entity.ScalarRecord).Expiry = utils.GetCurrentEPochTime() - 1
+
+			worker := &recordExpiryWorker{}
+			for i := 0; i < 10000 && isStored(); i++ {
+				worker.expireRandomSample(datastore)
+			}
+		},
+		"evicted": func() {
+			evictSample(memstore, config.EvictionPolicyRandom, 1<<20)
+		},
+	}
+
+	for name, remove := range removals {
+		session := NewSession()
+		runInSession(t, session, CommandSet, "balance", int64(100), int64(0))
+		runInSession(t, session, CommandWatch, []interface{}{"balance"})
+
+		remove()
+		if isStored() {
+			t.Fatalf("[%s] expected the key to be removed", name)
+		}
+
+		runInSession(t, session, CommandMulti)
+		runInSession(t, session, CommandSet, "balance", int64(200), int64(0))
+
+		if response := runInSession(t, session, CommandExec); responseCode(response) != entity.CRC_TRANSACTION_ABORTED {
+			t.Errorf("[%s] expected EXEC to abort on the removed key, got %v", name, response)
+		}
+	}
+}
+
+func TestEvictionWaitsForTransaction(t *testing.T) {
+	setupBlockingTests()
+	runInSession(t, NewSession(), CommandSet, "balance", int64(100), int64(0))
+
+	// EXEC holds the write side of the lock throughout the queued commands
+	keyspaceLock.Lock()
+
+	done := make(chan int64, 1)
+	go func() { done <- evictSample(datastore.(*memory.MemoryStore), config.EvictionPolicyRandom, 1<<20) }()
+
+	select {
+	case <-done:
+		t.Error("expected the eviction to wait for the transaction")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	keyspaceLock.Unlock()
+
+	select {
+	case evicted := <-done:
+		if evicted == 0 {
+			t.Error("expected the keys to be evicted once the transaction is done")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the eviction to go ahead once the transaction is done")
+	}
+}
+
+func TestCommandsNotAllowedInTransaction(t *testing.T) {
+	setupBlockingTests()
+	session := NewSession()
+
+	runInSession(t, session, CommandMulti)
+
+	if response := runInSession(t, session, CommandMulti); responseCode(response) != entity.CRC_NOT_ALLOWED_IN_TRANSACTION {
+		t.Errorf("expected nested MULTI to be refused, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandWatch, []interface{}{"key"}); responseCode(response) != entity.CRC_NOT_ALLOWED_IN_TRANSACTION {
+		t.Errorf("expected WATCH inside MULTI to be refused, got %v", response)
+	}
+
+	if response := runInSession(t, session, CommandBLPop, []interface{}{"queue"}, int64(1)); responseCode(response) != entity.CRC_NOT_ALLOWED_IN_TRANSACTION {
+		t.Errorf("expected BLPOP inside MULTI to be refused, got %v", response)
+	}
+
+	response := runInSession(t, session, CommandExec)
+	if results := response[0].([]interface{}); len(results) != 0 {
+		t.Errorf("expected refused commands not to be queued, got %v", results)
+	}
+}
@@ -8,15 +8,32 @@ const (
 	CRC_SERVER_BUSY          uint32 = 502
 	CRC_SNAPSHOT_FAILED      uint32 = 503
 
-	CRC_RECORD_FOUND    uint32 = 1000
-	CRC_RECORD_UPDATED  uint32 = 1001
-	CRC_RECORD_DELETED  uint32 = 1002
-	CRC_HELP_CONTENT_OK uint32 = 1010
-	CRC_INFO_CONTENT_OK uint32 = 1011
+	CRC_RECORD_FOUND     uint32 = 1000
+	CRC_RECORD_UPDATED   uint32 = 1001
+	CRC_RECORD_DELETED   uint32 = 1002
+	CRC_HELP_CONTENT_OK  uint32 = 1010
+	CRC_INFO_CONTENT_OK  uint32 = 1011
+	CRC_HELLO_CONTENT_OK uint32 = 1012
 
-	CRC_MGET_COMPLETED uint32 = 1100
-	CRC_MSET_COMPLETED uint32 = 1101
-	CRC_MDEL_COMPLETED uint32 = 1102
+	CRC_COMMAND_QUEUED        uint32 = 1020
+	CRC_TRANSACTION_STARTED   uint32 = 1021
+	CRC_TRANSACTION_DISCARDED uint32 = 1022
+	CRC_KEYS_WATCHED          uint32 = 1023
+
+	CRC_SUBSCRIBED        uint32 = 1030
+	CRC_UNSUBSCRIBED      uint32 = 1031
+	CRC_MESSAGE_PUBLISHED uint32 = 1032
+
+	CRC_AUTHENTICATED  uint32 = 1040
+	CRC_ACL_UPDATED    uint32 = 1041
+	CRC_ACL_CONTENT_OK uint32 = 1042
+
+	CRC_MGET_COMPLETED        uint32 = 1100
+	CRC_MSET_COMPLETED        uint32 = 1101
+	CRC_MDEL_COMPLETED        uint32 = 1102
+	CRC_SCAN_COMPLETED        uint32 = 1103
+	CRC_RANGE_COMPLETED       uint32 = 1104
+	CRC_TRANSACTION_COMPLETED uint32 = 1105
 
 	CRC_INVALID_CMD_INPUT  uint32 = 5000
 	CRC_RECORD_NOT_FOUND   uint32 = 5001
@@ -27,6 +44,22 @@ const (
 	CRC_INVALID_DATATYPE   uint32 = 5006
 	CRC_RECORD_TOMBSTONED  uint32 = 5007
 
-	CRC_DATA_READ_ERROR  uint32 = 5010
-	CRC_WAL_WRITE_FAILED uint32 = 5011
+	CRC_DATA_READ_ERROR            uint32 = 5010
+	CRC_WAL_WRITE_FAILED           uint32 = 5011
+	CRC_MEMORY_LIMIT_EXCEEDED      uint32 = 5012
+	CRC_WRONG_TYPE                 uint32 = 5013
+	CRC_FIELD_NOT_FOUND            uint32 = 5014
+	CRC_INDEX_OUT_OF_RANGE         uint32 = 5015
+	CRC_WAIT_TIMED_OUT             uint32 = 5016
+	CRC_MEMBER_NOT_FOUND           uint32 = 5017
+	CRC_TRANSACTION_ABORTED        uint32 = 5018
+	CRC_NOT_IN_TRANSACTION         uint32 = 5019
+	CRC_NOT_ALLOWED_IN_TRANSACTION uint32 = 5020
+	CRC_CONDITION_NOT_MET          uint32 = 5021
+	CRC_VALUE_MISMATCH             uint32 = 5022
+	CRC_AUTHENTICATION_REQUIRED    uint32 = 5023
+	CRC_INVALID_CREDENTIALS        uint32 = 5024
+	CRC_PERMISSION_DENIED          uint32 = 5025
+	CRC_ACL_SAVE_FAILED            uint32 = 5026
+	CRC_PROTOCOL_NOT_SUPPORTED     uint32 = 5027
 )
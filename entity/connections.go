@@ -14,14 +14,39 @@ type Connection struct {
 }
 
 var activeTCPConnections int64 = 0
+var peakTCPConnections int64 = 0
+var rejectedTCPConnections int64 = 0
 var activeConnections = sync.Map{}
 
 func GetActiveTCPConnectionCount() int64 {
 	return atomic.LoadInt64(&activeTCPConnections)
 }
 
+// GetPeakTCPConnectionCount returns the highest number of connections which
+// were served at the same time since the server started.
+func GetPeakTCPConnectionCount() int64 {
+	return atomic.LoadInt64(&peakTCPConnections)
+}
+
+// GetRejectedTCPConnectionCount returns the number of connections which were
+// turned away, the server being busy or shutting down.
+func GetRejectedTCPConnectionCount() int64 {
+	return atomic.LoadInt64(&rejectedTCPConnections)
+}
+
 func IncrementActiveTCPConnection() {
-	atomic.AddInt64(&activeTCPConnections, 1)
+	active := atomic.AddInt64(&activeTCPConnections, 1)
+
+	for {
+		peak := atomic.LoadInt64(&peakTCPConnections)
+		if active <= peak || atomic.CompareAndSwapInt64(&peakTCPConnections, peak, active) {
+			return
+		}
+	}
+}
+
+func IncrementRejectedTCPConnection() {
+	atomic.AddInt64(&rejectedTCPConnections, 1)
 }
 
 func DecrementActiveTCPConnection() {
@@ -29,7 +54,12 @@ func DecrementActiveTCPConnection() {
 }
 
 func AddActiveConnection(key net.Conn) {
-	remoteAddr := key.RemoteAddr().String()
+	// the clients of the Unix socket may have no address of their own
+	remoteAddr := ""
+	if addr := key.RemoteAddr(); addr != nil {
+		remoteAddr = addr.String()
+	}
+
 	connection := &Connection{
 		Conn:       key,
 		RemoteAddr: remoteAddr,
@@ -38,7 +68,7 @@ func AddActiveConnection(key net.Conn) {
 	activeConnections.Store(key, connection)
 }
 
-func RemoveActiveConnection(key *net.TCPConn) {
+func RemoveActiveConnection(key net.Conn) {
 	if conn, ok := activeConnections.Load(key); ok {
 		conn.(*Connection).Conn.Close()
 		activeConnections.Delete(key)
@@ -47,7 +77,7 @@ func RemoveActiveConnection(key *net.TCPConn) {
 
 func CloseAllConnections() {
 	activeConnections.Range(func(key, value interface{}) bool {
-		conn := key.(*Connection)
+		conn := value.(*Connection)
 		conn.Conn.Close()
 		activeConnections.Delete(key)
 		return true
@@ -0,0 +1,52 @@
+package entity
+
+// HashValue is the value held by a hash record, mapping field names to
+// their scalar values.
+type HashValue map[string]interface{}
+
+// Clone returns a shallow copy of the hash, so that it can be modified
+// without affecting readers of the original one.
+func (hv HashValue) Clone() HashValue {
+	clone := make(HashValue, len(hv))
+	for field, value := range hv {
+		clone[field] = value
+	}
+	return clone
+}
+
+type HashRecord struct {
+	RecordHeader
+	Value HashValue
+}
+
+func (hr *HashRecord) GetFamily() string {
+	return RecordTypeHash
+}
+
+func (hr *HashRecord) GetValue() interface{} {
+	return hr.Value
+}
+
+func (hr *HashRecord) ToMap() map[string]interface{} {
+	recordMap := hr.toMap(map[string]interface{}(hr.Value))
+	recordMap["Family"] = RecordTypeHash
+	return recordMap
+}
+
+func (hr *HashRecord) FromMap(recordMap map[string]interface{}) (string, Record) {
+	hr.Value = toHashValue(recordMap["Value"])
+	return hr.fromMap(recordMap), hr
+}
+
+// toHashValue converts a decoded map into a HashValue. Anything other
+// than a map results into an empty hash.
+func toHashValue(value interface{}) HashValue {
+	switch v := value.(type) {
+	case HashValue:
+		return v
+	case map[string]interface{}:
+		return HashValue(v)
+	default:
+		return HashValue{}
+	}
+}
@@ -22,6 +22,7 @@ func (is *InfoStats) ToString() string {
 type ServerStats struct {
 	BuildVersion string
 	TCPPort      int64
+	UnixSocket   string
 	ConfigFile   string
 	OSName       string
 	ArchBits     string
@@ -35,6 +36,7 @@ type ClientStats struct {
 	MaxAllowedConnections    int64
 	MaxConnectionConcurrency int64
 	ConnectedClients         int64
+	RejectedConnections      int64
 }
 
 type PersistenceStats struct {
@@ -66,4 +68,6 @@ type NetworkStats struct {
 type KeyspaceStats struct {
 	TotalKeyCount   int64
 	KeyCountWithTTL int64
+	EvictedKeyCount int64
+	EvictionPolicy  string
 }
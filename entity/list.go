@@ -0,0 +1,102 @@
+package entity
+
+// ListValue is the value held by a list record, an ordered sequence of
+// scalar values with the head of the list at index 0.
+type ListValue []interface{}
+
+// Clone returns a shallow copy of the list, so that it can be modified
+// without affecting readers of the original one.
+func (lv ListValue) Clone() ListValue {
+	clone := make(ListValue, len(lv))
+	copy(clone, lv)
+	return clone
+}
+
+// PushHead pushes the values one after another to the head of the list, so
+// the last of the values ends up as the first element. The receiver's backing
+// array is not modified.
+func (lv ListValue) PushHead(values ...interface{}) ListValue {
+	list := make(ListValue, 0, len(values)+len(lv))
+	for i := len(values) - 1; i >= 0; i-- {
+		list = append(list, values[i])
+	}
+	return append(list, lv...)
+}
+
+// Index returns the element at the given index, where negative indices
+// count backwards from the tail of the list (-1 being the last element).
+func (lv ListValue) Index(index int64) (interface{}, bool) {
+	length := int64(len(lv))
+	if index < 0 {
+		index += length
+	}
+
+	if index < 0 || index >= length {
+		return nil, false
+	}
+
+	return lv[index], true
+}
+
+// Bounds converts an inclusive start-stop range, which may use negative
+// indices, into slice bounds of the list. An empty range yields equal bounds.
+func (lv ListValue) Bounds(start int64, stop int64) (int64, int64) {
+	length := int64(len(lv))
+
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+
+	if start > stop || start >= length {
+		return 0, 0
+	}
+
+	return start, stop + 1
+}
+
+type ListRecord struct {
+	RecordHeader
+	Value ListValue
+}
+
+func (lr *ListRecord) GetFamily() string {
+	return RecordTypeList
+}
+
+func (lr *ListRecord) GetValue() interface{} {
+	return lr.Value
+}
+
+func (lr *ListRecord) ToMap() map[string]interface{} {
+	recordMap := lr.toMap([]interface{}(lr.Value))
+	recordMap["Family"] = RecordTypeList
+	return recordMap
+}
+
+func (lr *ListRecord) FromMap(recordMap map[string]interface{}) (string, Record) {
+	lr.Value = toListValue(recordMap["Value"])
+	return lr.fromMap(recordMap), lr
+}
+
+// toListValue converts a decoded slice into a ListValue. Anything other
+// than a slice results into an empty list.
+func toListValue(value interface{}) ListValue {
+	switch v := value.(type) {
+	case ListValue:
+		return v
+	case []interface{}:
+		return ListValue(v)
+	default:
+		return ListValue{}
+	}
+}
@@ -1,91 +1,274 @@
 package entity
 
 import (
+	"math/rand"
+	"sync/atomic"
 	"time"
 )
 
 const (
 	RecordTypeScalar = "scalar"
+	RecordTypeHash   = "hash"
+	RecordTypeList   = "list"
+	RecordTypeSet    = "set"
+
+	RecordTypeSortedSet = "zset"
 
 	RecordStateActive     = 0
 	RecordStateTombstoned = 1
 	RecordStateObsolete   = 2
 )
 
+// The access frequency of the records is a logarithmic counter, as in Redis. An
+// access bumps it with a probability which falls as it grows, so that it takes
+// about a million accesses to saturate it, and it loses a point for every period
+// the record is left idle, so that the keys which were hot once do not stay
+// ahead of the ones in use now.
+const (
+	LFUInitialFrequency uint32 = 5   // the counter of new records, for them not to be evicted right away
+	LFUMaxFrequency     uint32 = 255 // the counter saturates there
+	LFULogFactor        uint32 = 10  // the higher, the more accesses it takes to bump the counter
+	LFUDecayPeriod      int64  = 60  // seconds of idleness for the counter to lose a point
+)
+
 type Record interface {
 	GetFamily() string
 	GetValue() interface{}
 	GetExpiry() int64
+	GetLAT() int64
+	GetFrequency() uint32
+	SetFrequency(frequency uint32)
+	Touch(accessTime int64)
 	IsExpired() bool
 	IsTombstoned() bool
 	ToMap() map[string]interface{}
 	FromMap(m map[string]interface{}) (string, Record)
 }
 
-type ScalarRecord struct {
-	Value  interface{}
-	LAT    int64
-	Expiry int64
-	State  uint8
+// RecordHeader is what the records of all the families keep besides their value,
+// and implements the part of Record which does not depend on the value.
+type RecordHeader struct {
+	LAT       int64  // last access time
+	Expiry    int64  // epoch seconds when the record expires
+	State     uint8  // one of RecordState*
+	Frequency uint32 // logarithmic access frequency counter, used by the LFU eviction
 }
 
-func (sr *ScalarRecord) GetFamily() string {
-	return RecordTypeScalar
+func (rh *RecordHeader) GetExpiry() int64 {
+	return rh.Expiry
 }
 
-func (sr *ScalarRecord) GetValue() interface{} {
-	return sr.Value
+func (rh *RecordHeader) GetLAT() int64 {
+	return atomic.LoadInt64(&rh.LAT)
+}
+
+// GetFrequency returns the access frequency counter, decayed by the time the
+// record was left idle since its last access.
+func (rh *RecordHeader) GetFrequency() uint32 {
+	return rh.decayedFrequency(time.Now().Unix())
+}
+
+func (rh *RecordHeader) decayedFrequency(now int64) uint32 {
+	frequency := atomic.LoadUint32(&rh.Frequency)
+
+	periods := (now - atomic.LoadInt64(&rh.LAT)) / LFUDecayPeriod
+	if periods <= 0 {
+		return frequency
+	}
+
+	if periods >= int64(frequency) {
+		return 0
+	}
+	return frequency - uint32(periods)
 }
 
-func (sr *ScalarRecord) GetExpiry() int64 {
-	return sr.Expiry
+func (rh *RecordHeader) SetFrequency(frequency uint32) {
+	atomic.StoreUint32(&rh.Frequency, frequency)
 }
 
-func (sr *ScalarRecord) IsExpired() bool {
-	if sr.Expiry == 0 {
+func (rh *RecordHeader) IsExpired() bool {
+	if rh.Expiry == 0 {
 		return false
 	}
 
-	return time.Now().Unix() > sr.Expiry
+	return time.Now().Unix() > rh.Expiry
 }
 
-func (sr *ScalarRecord) IsTombstoned() bool {
-	return sr.State == RecordStateTombstoned
+func (rh *RecordHeader) IsTombstoned() bool {
+	return rh.State == RecordStateTombstoned
 }
 
-func (sr *ScalarRecord) ToMap() map[string]interface{} {
+// Touch marks the record as accessed, updating its last access time, and
+// bumping its access frequency counter once decayed, with a probability which
+// falls as the counter grows.
+func (rh *RecordHeader) Touch(accessTime int64) {
+	frequency := rh.decayedFrequency(accessTime)
+
+	if frequency < LFUMaxFrequency {
+		base := float64(0)
+		if frequency > LFUInitialFrequency {
+			base = float64(frequency - LFUInitialFrequency)
+		}
+
+		if rand.Float64() < 1.0/(base*float64(LFULogFactor)+1) {
+			frequency++
+		}
+	}
+
+	atomic.StoreUint32(&rh.Frequency, frequency)
+	atomic.StoreInt64(&rh.LAT, accessTime)
+}
+
+// toMap returns the map representation of the record holding the value, which
+// the records of the collection families add their family to.
+func (rh *RecordHeader) toMap(value interface{}) map[string]interface{} {
 	return map[string]interface{}{
-		"Value":  sr.Value,
-		"LAT":    sr.LAT,
-		"Expiry": sr.Expiry,
-		"State":  sr.State,
+		"Value":  value,
+		"LAT":    rh.LAT,
+		"Expiry": rh.Expiry,
+		"State":  rh.State,
 	}
 }
 
-func (sr *ScalarRecord) FromMap(recordMap map[string]interface{}) (string, Record) {
+// fromMap restores the header from the map representation of the record, and
+// returns the key the record is stored against, empty if the map has none.
+func (rh *RecordHeader) fromMap(recordMap map[string]interface{}) string {
 	var key string
 
 	if k, ok := recordMap["Key"]; ok {
 		key, _ = k.(string)
 	}
 
+	if lat, ok := recordMap["LAT"]; ok {
+		rh.LAT, _ = lat.(int64)
+	}
+
+	if expiry, ok := recordMap["Expiry"]; ok {
+		rh.Expiry, _ = expiry.(int64)
+	}
+
+	if state, ok := recordMap["State"]; ok {
+		rh.State = uint8(state.(int64))
+	}
+
+	rh.Frequency = LFUInitialFrequency
+	return key
+}
+
+type ScalarRecord struct {
+	RecordHeader
+	Value interface{}
+}
+
+func (sr *ScalarRecord) GetFamily() string {
+	return RecordTypeScalar
+}
+
+func (sr *ScalarRecord) GetValue() interface{} {
+	return sr.Value
+}
+
+func (sr *ScalarRecord) ToMap() map[string]interface{} {
+	return sr.toMap(sr.Value)
+}
+
+func (sr *ScalarRecord) FromMap(recordMap map[string]interface{}) (string, Record) {
 	if val, ok := recordMap["Value"]; ok {
 		sr.Value = val
 	}
 
-	if lat, ok := recordMap["LAT"]; ok {
-		sr.LAT, _ = lat.(int64)
+	return sr.fromMap(recordMap), sr
+}
+
+// NewRecord builds a record of the family matching the type of the given value.
+// Values of the collection types (eg. HashValue) produce their own record family,
+// everything else is stored as a scalar.
+func NewRecord(value interface{}, lat int64, expiry int64, state uint8) Record {
+	header := RecordHeader{LAT: lat, Expiry: expiry, State: state, Frequency: LFUInitialFrequency}
+
+	switch v := value.(type) {
+	case HashValue:
+		return &HashRecord{RecordHeader: header, Value: v}
+	case ListValue:
+		return &ListRecord{RecordHeader: header, Value: v}
+	case SetValue:
+		return &SetRecord{RecordHeader: header, Value: v}
+	case SortedSetValue:
+		return &SortedSetRecord{RecordHeader: header, Value: v}
+	default:
+		return &ScalarRecord{RecordHeader: header, Value: value}
 	}
+}
 
-	if expiry, ok := recordMap["Expiry"]; ok {
-		sr.Expiry, _ = expiry.(int64)
+// RecordFromMap rebuilds a record from its map representation, as produced by
+// Record.ToMap(). The record family is picked from the "Family" entry, and maps
+// without one are treated as scalar records.
+func RecordFromMap(recordMap map[string]interface{}) (string, Record) {
+	family, _ := recordMap["Family"].(string)
+
+	switch family {
+	case RecordTypeHash:
+		return (&HashRecord{}).FromMap(recordMap)
+	case RecordTypeList:
+		return (&ListRecord{}).FromMap(recordMap)
+	case RecordTypeSet:
+		return (&SetRecord{}).FromMap(recordMap)
+	case RecordTypeSortedSet:
+		return (&SortedSetRecord{}).FromMap(recordMap)
+	default:
+		return (&ScalarRecord{}).FromMap(recordMap)
 	}
+}
 
-	if state, ok := recordMap["State"]; ok {
-		sr.State = uint8(state.(int64))
+// GetValueFamily returns the record family a value would be stored as.
+func GetValueFamily(value interface{}) string {
+	switch value.(type) {
+	case HashValue:
+		return RecordTypeHash
+	case ListValue:
+		return RecordTypeList
+	case SetValue:
+		return RecordTypeSet
+	case SortedSetValue:
+		return RecordTypeSortedSet
+	default:
+		return RecordTypeScalar
+	}
+}
+
+// ValueOfFamily converts a decoded wire value back into the value type of
+// the given record family. Scalar values are returned as is.
+func ValueOfFamily(family string, value interface{}) interface{} {
+	switch family {
+	case RecordTypeHash:
+		return toHashValue(value)
+	case RecordTypeList:
+		return toListValue(value)
+	case RecordTypeSet:
+		return toSetValue(value)
+	case RecordTypeSortedSet:
+		return toSortedSetValue(value)
+	default:
+		return value
 	}
+}
 
-	return key, sr
+// ToNativeValue converts the collection value types into plain maps and slices,
+// which can be handed over to the RESP encoder. The second return value tells
+// if any conversion happened.
+func ToNativeValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case HashValue:
+		return map[string]interface{}(v), true
+	case ListValue:
+		return []interface{}(v), true
+	case SetValue:
+		return v.toSlice(), true
+	case SortedSetValue:
+		return sortedSetToMap(v), true
+	default:
+		return value, false
+	}
 }
 
 type RecordKV struct {
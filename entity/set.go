@@ -0,0 +1,88 @@
+package entity
+
+import "sort"
+
+// SetValue is the value held by a set record, an unordered collection of
+// unique string members.
+type SetValue map[string]struct{}
+
+// Clone returns a copy of the set, so that it can be modified without
+// affecting readers of the original one.
+func (sv SetValue) Clone() SetValue {
+	clone := make(SetValue, len(sv))
+	for member := range sv {
+		clone[member] = struct{}{}
+	}
+	return clone
+}
+
+// Members returns the members of the set in lexicographical order.
+func (sv SetValue) Members() []string {
+	members := make([]string, 0, len(sv))
+	for member := range sv {
+		members = append(members, member)
+	}
+
+	sort.Strings(members)
+	return members
+}
+
+// toSlice returns the sorted members as a generic slice, which is the form
+// the set is serialised in.
+func (sv SetValue) toSlice() []interface{} {
+	members := sv.Members()
+
+	slice := make([]interface{}, len(members))
+	for idx, member := range members {
+		slice[idx] = member
+	}
+	return slice
+}
+
+type SetRecord struct {
+	RecordHeader
+	Value SetValue
+}
+
+func (sr *SetRecord) GetFamily() string {
+	return RecordTypeSet
+}
+
+func (sr *SetRecord) GetValue() interface{} {
+	return sr.Value
+}
+
+func (sr *SetRecord) ToMap() map[string]interface{} {
+	recordMap := sr.toMap(sr.Value.toSlice())
+	recordMap["Family"] = RecordTypeSet
+	return recordMap
+}
+
+func (sr *SetRecord) FromMap(recordMap map[string]interface{}) (string, Record) {
+	sr.Value = toSetValue(recordMap["Value"])
+	return sr.fromMap(recordMap), sr
+}
+
+// toSetValue converts a decoded slice of members into a SetValue. Members
+// which are not strings are dropped, and anything other than a slice results
+// into an empty set.
+func toSetValue(value interface{}) SetValue {
+	set := SetValue{}
+
+	switch v := value.(type) {
+	case SetValue:
+		return v
+	case []interface{}:
+		for _, item := range v {
+			if member, ok := item.(string); ok {
+				set[member] = struct{}{}
+			}
+		}
+	case []string:
+		for _, member := range v {
+			set[member] = struct{}{}
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,98 @@
+package entity
+
+import "strconv"
+
+// ScoredMember is a member of a sorted set along with its score.
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+// SortedSetValue is the value held by a sorted set record, a collection of
+// unique string members kept ordered by their scores. Members with equal
+// scores are ordered lexicographically.
+//
+// The ordered structures live in dslib, which depends on this package, so the
+// implementation is plugged in through NewSortedSetValue.
+type SortedSetValue interface {
+	Len() int64
+	Score(member string) (float64, bool)
+	Add(member string, score float64) bool
+	Remove(member string) bool
+	Rank(member string) (int64, bool)
+	RangeByRank(start int64, stop int64) []ScoredMember
+	RangeByScore(min float64, max float64) []ScoredMember
+	Clone() SortedSetValue
+}
+
+// NewSortedSetValue returns an empty sorted set value. It is registered by
+// dslib when that package is initialised.
+var NewSortedSetValue func() SortedSetValue
+
+// sortedSetToMap returns the members of the sorted set mapped to their scores.
+// Scores are formatted as strings, since the RESP encoder only keeps six
+// decimal places of floating point numbers.
+func sortedSetToMap(zset SortedSetValue) map[string]interface{} {
+	members := zset.RangeByRank(0, -1)
+
+	zmap := make(map[string]interface{}, len(members))
+	for _, scored := range members {
+		zmap[scored.Member] = strconv.FormatFloat(scored.Score, 'g', -1, 64)
+	}
+	return zmap
+}
+
+type SortedSetRecord struct {
+	RecordHeader
+	Value SortedSetValue
+}
+
+func (zr *SortedSetRecord) GetFamily() string {
+	return RecordTypeSortedSet
+}
+
+func (zr *SortedSetRecord) GetValue() interface{} {
+	return zr.Value
+}
+
+func (zr *SortedSetRecord) ToMap() map[string]interface{} {
+	recordMap := zr.toMap(sortedSetToMap(zr.Value))
+	recordMap["Family"] = RecordTypeSortedSet
+	return recordMap
+}
+
+func (zr *SortedSetRecord) FromMap(recordMap map[string]interface{}) (string, Record) {
+	zr.Value = toSortedSetValue(recordMap["Value"])
+	return zr.fromMap(recordMap), zr
+}
+
+// toSortedSetValue converts a decoded map of members to scores into a
+// SortedSetValue. Members whose score cannot be parsed are dropped, and
+// anything other than a map results into an empty sorted set.
+func toSortedSetValue(value interface{}) SortedSetValue {
+	if zset, ok := value.(SortedSetValue); ok {
+		return zset
+	}
+
+	zset := NewSortedSetValue()
+
+	zmap, ok := value.(map[string]interface{})
+	if !ok {
+		return zset
+	}
+
+	for member, rawScore := range zmap {
+		switch score := rawScore.(type) {
+		case string:
+			if parsed, err := strconv.ParseFloat(score, 64); err == nil {
+				zset.Add(member, parsed)
+			}
+		case float64:
+			zset.Add(member, score)
+		case int64:
+			zset.Add(member, float64(score))
+		}
+	}
+
+	return zset
+}
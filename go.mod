@@ -6,6 +6,7 @@ require (
 	github.com/cshekharsharma/resp-go v1.6.0
 	github.com/pierrec/lz4 v2.6.1+incompatible
 	github.com/shirou/gopsutil/v3 v3.24.3
+	golang.org/x/crypto v0.31.0
 	golang.org/x/exp v0.0.0-20240909161429-701f63a606c0
 )
 
@@ -18,5 +19,5 @@ require (
 	github.com/tklauser/go-sysconf v0.3.13 // indirect
 	github.com/tklauser/numcpus v0.7.0 // indirect
 	github.com/yusufpapurcu/wmi v1.2.4 // indirect
-	golang.org/x/sys v0.19.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 )
@@ -0,0 +1,152 @@
+package resp3
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SimpleString is a string sent as a RESP simple string (`+`), which is how the
+// Redis replies carry statuses such as OK or QUEUED. Every other string is sent
+// as a bulk string, so that it arrives byte for byte whatever it holds.
+type SimpleString string
+
+// EncodeRedisReply encodes the value the way Redis lays out its replies for the
+// given protocol version, 2 or 3. Unlike Encode, strings are always bulk strings,
+// and with version 2 the RESP3 only types fall back to their RESP2 counterparts:
+// nil to the nil bulk string, maps to flat arrays, booleans to integers and floats
+// to bulk strings.
+func EncodeRedisReply(value interface{}, protover int) string {
+	var builder strings.Builder
+	writeRedisValue(&builder, value, protover)
+	return builder.String()
+}
+
+// EncodeRedisPush encodes the items as the out of band data Redis sends, such as
+// the published messages. That is a push frame with version 3, and a plain array
+// with version 2, which does not know about pushes.
+func EncodeRedisPush(items []interface{}, protover int) string {
+	encoded := EncodeRedisReply(items, protover)
+	if protover < 3 {
+		return encoded
+	}
+
+	// a push frame is laid out just like an array, only with its own type marker
+	return ">" + encoded[1:]
+}
+
+func writeRedisValue(builder *strings.Builder, value interface{}, protover int) {
+	switch v := value.(type) {
+	case nil:
+		if protover < 3 {
+			builder.WriteString("$-1\r\n")
+		} else {
+			builder.WriteString("_\r\n")
+		}
+
+	case SimpleString:
+		builder.WriteString("+" + stripLineBreaks(string(v)) + "\r\n")
+
+	case string:
+		builder.WriteString("$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n")
+
+	case error:
+		builder.WriteString("-" + stripLineBreaks(v.Error()) + "\r\n")
+
+	case bool:
+		switch {
+		case protover >= 3 && v:
+			builder.WriteString("#t\r\n")
+		case protover >= 3:
+			builder.WriteString("#f\r\n")
+		case v:
+			builder.WriteString(":1\r\n")
+		default:
+			builder.WriteString(":0\r\n")
+		}
+
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		builder.WriteString(fmt.Sprintf(":%d\r\n", v))
+
+	case float32:
+		writeRedisFloat(builder, float64(v), protover)
+
+	case float64:
+		writeRedisFloat(builder, v, protover)
+
+	case []interface{}:
+		builder.WriteString("*" + strconv.Itoa(len(v)) + "\r\n")
+		for _, item := range v {
+			writeRedisValue(builder, item, protover)
+		}
+
+	case []string:
+		builder.WriteString("*" + strconv.Itoa(len(v)) + "\r\n")
+		for _, item := range v {
+			writeRedisValue(builder, item, protover)
+		}
+
+	case map[string]interface{}:
+		// the keys are sorted so that the same map is always laid out the same way
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		if protover < 3 {
+			builder.WriteString("*" + strconv.Itoa(len(v)*2) + "\r\n")
+		} else {
+			builder.WriteString("%" + strconv.Itoa(len(v)) + "\r\n")
+		}
+
+		for _, key := range keys {
+			writeRedisValue(builder, key, protover)
+			writeRedisValue(builder, v[key], protover)
+		}
+
+	default:
+		// slices of other element types are sent as arrays of their elements
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Slice {
+			items := make([]interface{}, rv.Len())
+			for i := range items {
+				items[i] = rv.Index(i).Interface()
+			}
+			writeRedisValue(builder, items, protover)
+			return
+		}
+
+		writeRedisValue(builder, fmt.Sprintf("%v", value), protover)
+	}
+}
+
+// writeRedisFloat writes the float as a RESP3 double, or as a bulk string with
+// version 2, using the shortest representation which reads back the same value.
+func writeRedisFloat(builder *strings.Builder, value float64, protover int) {
+	var formatted string
+	switch {
+	case math.IsInf(value, 1):
+		formatted = "inf"
+	case math.IsInf(value, -1):
+		formatted = "-inf"
+	default:
+		formatted = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	if protover < 3 {
+		writeRedisValue(builder, formatted, protover)
+		return
+	}
+
+	builder.WriteString("," + formatted + "\r\n")
+}
+
+// stripLineBreaks keeps the simple strings and the errors on a single line, which
+// is all the room the protocol gives them.
+func stripLineBreaks(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
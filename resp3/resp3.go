@@ -23,7 +23,7 @@ import (
 //   - string: The RESP3 encoded string representation of the input `response`. If the
 //     input is an error or encoding fails, the returned string will be an encoded error message.
 func EncodedRESP3Response(response interface{}) string {
-	encoded, err := resp3.Encode(response)
+	encoded, err := Encode(response)
 
 	if err != nil {
 		newErr := fmt.Errorf("unexpected error occured while processing output: %v", err)
@@ -35,11 +35,54 @@ func EncodedRESP3Response(response interface{}) string {
 
 // Wrapper function for resp3.Encode
 func Encode(value interface{}) (string, error) {
-	if _, ok := value.(*entity.ScalarRecord); ok {
-		return resp3.Encode(value.(*entity.ScalarRecord).ToMap())
+	native, _ := toNative(value)
+	return resp3.Encode(native)
+}
+
+// toNative walks through the value and replaces records and collection values,
+// which the RESP encoder does not know about, with their plain map or slice forms.
+// Maps and slices are only modified in place when a replacement is needed, so the
+// values shared with the datastore are never written to.
+func toNative(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case entity.Record:
+		return v.ToMap(), true
+
+	case map[string]interface{}:
+		changed := false
+		for key, item := range v {
+			if native, ok := toNative(item); ok {
+				v[key] = native
+				changed = true
+			}
+		}
+		return v, changed
+
+	case []interface{}:
+		changed := false
+		for idx, item := range v {
+			if native, ok := toNative(item); ok {
+				v[idx] = native
+				changed = true
+			}
+		}
+		return v, changed
+
+	default:
+		return entity.ToNativeValue(value)
+	}
+}
+
+// EncodePush encodes the items as a RESP3 push frame (`>`), which carries the data
+// the server sends out of band from the responses, such as the published messages.
+func EncodePush(items []interface{}) (string, error) {
+	encoded, err := Encode(items)
+	if err != nil {
+		return "", err
 	}
 
-	return resp3.Encode(value)
+	// a push frame is laid out just like an array, only with its own type marker
+	return ">" + encoded[1:], nil
 }
 
 // Wrapper function for resp3.Decode
@@ -47,19 +90,17 @@ func Decode(reader *bufio.Reader) (interface{}, error) {
 	return resp3.Decode(reader)
 }
 
-func GetScalarRecordFromResp(raw string) (entity.Record, error) {
+// GetRecordFromResp decodes a RESP encoded record map into a record
+// of the family it was serialised from.
+func GetRecordFromResp(raw string) (entity.Record, error) {
 	decodedRecord, err := resp3.Decode(bufio.NewReader(bytes.NewReader([]byte(raw))))
 	if err != nil {
 		return nil, fmt.Errorf("record could not be decoded: %v", err)
 	}
 
-	if record, ok := decodedRecord.(map[string]interface{}); ok {
-		return &entity.ScalarRecord{
-			Value:  record["Value"],
-			LAT:    int64(record["LAT"].(int64)),
-			Expiry: int64(record["Expiry"].(int64)),
-			State:  uint8(record["State"].(int64)),
-		}, nil
+	if recordMap, ok := decodedRecord.(map[string]interface{}); ok {
+		_, record := entity.RecordFromMap(recordMap)
+		return record, nil
 	}
 
 	return nil, fmt.Errorf("record is not in the correct format: %v", decodedRecord)
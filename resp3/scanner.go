@@ -0,0 +1,82 @@
+package resp3
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrIncompleteMessage is returned by ScanMessage when the data holds only
+// the beginning of a message, the rest of which is yet to be received.
+var ErrIncompleteMessage = errors.New("incomplete RESP message")
+
+var crlf = []byte("\r\n")
+
+// ScanMessage walks through the framing of the RESP message at the start of the
+// data, without decoding it, and returns the number of bytes the message takes.
+// It tells whether a whole message has been received, which the decoder can not
+// be asked without consuming what it reads.
+func ScanMessage(data []byte) (int, error) {
+	return scanValue(data, 0)
+}
+
+// scanValue returns the offset at which the value starting at the given offset ends.
+func scanValue(data []byte, offset int) (int, error) {
+	if offset >= len(data) {
+		return 0, ErrIncompleteMessage
+	}
+
+	end := bytes.Index(data[offset:], crlf)
+	if end < 0 {
+		return 0, ErrIncompleteMessage
+	}
+
+	header := data[offset+1 : offset+end]
+	next := offset + end + 2
+
+	switch data[offset] {
+	case '+', '-', ':', ',', '#', '_', '(':
+		return next, nil
+
+	case '$', '!', '=':
+		length, err := scanLength(header)
+		if err != nil || length < 0 {
+			return next, err
+		}
+
+		if next+length+2 > len(data) {
+			return 0, ErrIncompleteMessage
+		}
+		return next + length + 2, nil
+
+	case '*', '>', '~', '%', '|':
+		count, err := scanLength(header)
+		if err != nil || count < 0 {
+			return next, err
+		}
+
+		// the maps and the attributes hold a key and a value per entry
+		if data[offset] == '%' || data[offset] == '|' {
+			count *= 2
+		}
+
+		for i := 0; i < count; i++ {
+			if next, err = scanValue(data, next); err != nil {
+				return 0, err
+			}
+		}
+		return next, nil
+
+	default:
+		return 0, fmt.Errorf("unknown RESP type %q", data[offset])
+	}
+}
+
+func scanLength(header []byte) (int, error) {
+	length, err := strconv.Atoi(string(header))
+	if err != nil {
+		return 0, fmt.Errorf("invalid RESP length %q", header)
+	}
+	return length, nil
+}
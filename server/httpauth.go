@@ -0,0 +1,110 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+const (
+	// httpCredentialsTTL is how long the basic credentials of the HTTP requests
+	// are trusted once verified, for the requests not to hash the password every
+	// time. A change made to the users since forgets them all the same.
+	httpCredentialsTTL time.Duration = 60 * time.Second
+
+	// httpCredentialsCacheSize bounds the number of credentials remembered.
+	httpCredentialsCacheSize int = 1024
+)
+
+// credentialsCache remembers the credentials verified lately, until they expire
+// or the users change. They are keyed by their HMAC under a key of the process,
+// so that the passwords themselves are never kept in memory.
+type credentialsCache struct {
+	mutex   sync.Mutex
+	key     []byte
+	expiry  map[string]time.Time
+	version uint64 // the version of the users the credentials were verified against
+	ttl     time.Duration
+	maxSize int
+}
+
+var httpCredentials = newCredentialsCache(httpCredentialsTTL, httpCredentialsCacheSize)
+
+func newCredentialsCache(ttl time.Duration, maxSize int) *credentialsCache {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+
+	return &credentialsCache{
+		key:     key,
+		expiry:  make(map[string]time.Time),
+		ttl:     ttl,
+		maxSize: maxSize,
+	}
+}
+
+func (c *credentialsCache) digest(user string, password string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(user))
+	mac.Write([]byte{0})
+	mac.Write([]byte(password))
+	return string(mac.Sum(nil))
+}
+
+// forgetChangedUsers forgets all the credentials once the users have changed
+// since they were verified. The caller must hold the mutex.
+func (c *credentialsCache) forgetChangedUsers(version uint64) {
+	if version != c.version {
+		c.expiry = make(map[string]time.Time)
+		c.version = version
+	}
+}
+
+// isVerified tells whether the credentials were verified against the given
+// version of the users, and have not expired.
+func (c *credentialsCache) isVerified(user string, password string, version uint64) bool {
+	digest := c.digest(user, password)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.forgetChangedUsers(version)
+
+	expiresAt, ok := c.expiry[digest]
+	if ok && time.Now().After(expiresAt) {
+		delete(c.expiry, digest)
+		return false
+	}
+	return ok
+}
+
+// remember trusts the credentials, which were just verified against the given
+// version of the users, until they expire or the users change. The expired
+// credentials are dropped once the cache is full, and all of them if none has
+// expired.
+func (c *credentialsCache) remember(user string, password string, version uint64) {
+	digest := c.digest(user, password)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.forgetChangedUsers(version)
+
+	if len(c.expiry) >= c.maxSize {
+		now := time.Now()
+		for key, expiresAt := range c.expiry {
+			if now.After(expiresAt) {
+				delete(c.expiry, key)
+			}
+		}
+
+		if len(c.expiry) >= c.maxSize {
+			c.expiry = make(map[string]time.Time)
+		}
+	}
+
+	c.expiry[digest] = time.Now().Add(c.ttl)
+}
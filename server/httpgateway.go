@@ -0,0 +1,350 @@
+package server
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"universum/config"
+	"universum/engine"
+	"universum/entity"
+	"universum/internal/logger"
+	"universum/resp3"
+)
+
+// httpRequestOverhead is the room left in the request bodies for what comes
+// along with the values, such as the command name and the other arguments.
+const httpRequestOverhead int64 = 64 * 1024
+
+// httpUnsupportedCommands cannot run over HTTP, which has no connection for
+// them to keep state on, each request being a session of its own.
+var httpUnsupportedCommands = map[string]struct{}{
+	engine.CommandMulti:        {},
+	engine.CommandExec:         {},
+	engine.CommandDiscard:      {},
+	engine.CommandWatch:        {},
+	engine.CommandUnwatch:      {},
+	engine.CommandSubscribe:    {},
+	engine.CommandUnsubscribe:  {},
+	engine.CommandPSubscribe:   {},
+	engine.CommandPUnsubscribe: {},
+	engine.CommandHello:        {},
+	engine.CommandAuth:         {},
+}
+
+// httpStatusCodes maps the response codes to the HTTP statuses they stand for,
+// the other responses coming with 200 OK, and their own code in the body.
+var httpStatusCodes = map[uint32]int{
+	entity.CRC_INVALID_CMD_INPUT:       http.StatusBadRequest,
+	entity.CRC_INVALID_DATATYPE:        http.StatusBadRequest,
+	entity.CRC_AUTHENTICATION_REQUIRED: http.StatusUnauthorized,
+	entity.CRC_INVALID_CREDENTIALS:     http.StatusUnauthorized,
+	entity.CRC_PERMISSION_DENIED:       http.StatusForbidden,
+	entity.CRC_RECORD_NOT_FOUND:        http.StatusNotFound,
+	entity.CRC_RECORD_EXPIRED:          http.StatusNotFound,
+	entity.CRC_RECORD_TOO_BIG:          http.StatusRequestEntityTooLarge,
+	entity.CRC_MEMORY_LIMIT_EXCEEDED:   http.StatusInsufficientStorage,
+	entity.CRC_SERVER_BUSY:             http.StatusServiceUnavailable,
+	entity.CRC_SERVER_SHUTTING_DOWN:    http.StatusServiceUnavailable,
+}
+
+// httpResponse is the JSON body of the gateway responses, which carries the
+// native [value, code, message] response of the command.
+type httpResponse struct {
+	Data    interface{} `json:"data"`
+	Code    uint32      `json:"code"`
+	Message string      `json:"message"`
+}
+
+// httpCommand is the JSON body of the requests to POST /cmd.
+type httpCommand struct {
+	Command string        `json:"command"`
+	Args    []interface{} `json:"args"`
+}
+
+// listenHTTPGateway starts the listener of the HTTP/JSON gateway, over TLS when
+// the server uses it, with the same certificates.
+//
+// Returns:
+// - net.Listener: The listener accepting the HTTP connections.
+// - error: The error which prevented listening on the port.
+func listenHTTPGateway() (net.Listener, error) {
+	port := fmt.Sprintf(":%d", config.Store.Server.HTTPGatewayPort)
+
+	if config.Store.Server.EnableTLS {
+		return tls.Listen(NetworkTCP, port, certificates.serverConfig())
+	}
+	return net.Listen(NetworkTCP, port)
+}
+
+// serveHTTPGateway serves the HTTP/JSON gateway on the listener, which maps the
+// requests onto the commands, executed just like the ones sent over RESP.
+//
+// Parameters:
+// - listener net.Listener: The listener accepting the HTTP connections.
+func serveHTTPGateway(listener net.Listener) {
+	reqTimeout := time.Duration(config.Store.Server.RequestExecutionTimeout) * time.Second
+	writeTimeout := time.Duration(config.Store.Server.ConnectionWriteTimeout) * time.Second
+
+	httpServer := &http.Server{
+		Handler:      newHTTPGatewayHandler(),
+		ReadTimeout:  writeTimeout,
+		WriteTimeout: reqTimeout + writeTimeout,
+		IdleTimeout:  time.Duration(config.Store.Server.IdleConnectionTimeout) * time.Second,
+	}
+
+	if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+		logger.Get().Error("HTTP gateway stopped serving: %v", err.Error())
+	}
+}
+
+// newHTTPGatewayHandler routes the requests of the gateway to their handlers.
+func newHTTPGatewayHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /keys/{key}", handleGetKey)
+	mux.HandleFunc("PUT /keys/{key}", handlePutKey)
+	mux.HandleFunc("DELETE /keys/{key}", handleDeleteKey)
+	mux.HandleFunc("POST /cmd", handleCommand)
+	return mux
+}
+
+func handleGetKey(w http.ResponseWriter, r *http.Request) {
+	executeHTTPCommand(w, r, &entity.Command{
+		Name: engine.CommandGet,
+		Args: []interface{}{r.PathValue("key")},
+	})
+}
+
+func handlePutKey(w http.ResponseWriter, r *http.Request) {
+	ttl := int64(0)
+	if param := r.URL.Query().Get("ttl"); param != "" {
+		var err error
+		if ttl, err = strconv.ParseInt(param, 10, 64); err != nil || ttl < 0 {
+			writeHTTPResponse(w, nil, entity.CRC_INVALID_CMD_INPUT, "ttl should be a non-negative integer")
+			return
+		}
+	}
+
+	value, err := io.ReadAll(http.MaxBytesReader(w, r.Body, config.Store.Storage.MaxRecordSizeInBytes+httpRequestOverhead))
+	if err != nil {
+		writeHTTPResponse(w, nil, entity.CRC_RECORD_TOO_BIG, err.Error())
+		return
+	}
+
+	executeHTTPCommand(w, r, &entity.Command{
+		Name: engine.CommandSet,
+		Args: []interface{}{r.PathValue("key"), string(value), ttl},
+	})
+}
+
+func handleDeleteKey(w http.ResponseWriter, r *http.Request) {
+	executeHTTPCommand(w, r, &entity.Command{
+		Name: engine.CommandDelete,
+		Args: []interface{}{r.PathValue("key")},
+	})
+}
+
+func handleCommand(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, config.Store.Storage.MaxRecordSizeInBytes+httpRequestOverhead)
+
+	// the numbers are decoded as they are written, for the integers to stay integers
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+
+	var request httpCommand
+	if err := decoder.Decode(&request); err != nil || request.Command == "" {
+		writeHTTPResponse(w, nil, entity.CRC_INVALID_CMD_INPUT,
+			"body should be a JSON object with a command name and its args")
+		return
+	}
+
+	args := make([]interface{}, len(request.Args))
+	for idx, arg := range request.Args {
+		args[idx] = fromJSONValue(arg)
+	}
+
+	executeHTTPCommand(w, r, &entity.Command{
+		Name: strings.ToUpper(request.Command),
+		Args: args,
+	})
+}
+
+// executeHTTPCommand executes the command of the request in a session of its
+// own, authenticated with the credentials of the request, and writes out the
+// native response of the command as JSON.
+func executeHTTPCommand(w http.ResponseWriter, r *http.Request, command *entity.Command) {
+	if _, unsupported := httpUnsupportedCommands[command.Name]; unsupported {
+		writeHTTPResponse(w, nil, entity.CRC_INVALID_CMD_INPUT,
+			fmt.Sprintf("command `%s` is not supported over HTTP", command.Name))
+		return
+	}
+
+	session := engine.NewNativeSession()
+	defer session.Close()
+
+	reqTimeout := time.Duration(config.Store.Server.RequestExecutionTimeout) * time.Second
+
+	if !authenticateHTTPRequest(r, session) {
+		writeHTTPResponse(w, nil, entity.CRC_INVALID_CREDENTIALS, "invalid username or password")
+		return
+	}
+
+	output, err := engine.ExecuteParsedCommand(command, reqTimeout, session)
+	if err != nil {
+		writeHTTPResponse(w, nil, entity.CRC_INVALID_CMD_INPUT, err.Error())
+		return
+	}
+
+	writeHTTPOutput(w, output)
+}
+
+// authenticateHTTPRequest authenticates the session with the basic credentials
+// of the request, or else with the client certificate, just like AUTH and the
+// TLS connections do. Without either, the commands are refused the way they are
+// on an unauthenticated connection. The credentials verified lately are trusted
+// without hashing the password again, until the users change, and none of it
+// counts as a command. It tells whether the credentials, if any, are valid.
+func authenticateHTTPRequest(r *http.Request, session *engine.Session) bool {
+	if !config.Store.Auth.AuthenticationEnabled {
+		return true
+	}
+
+	if user, password, ok := r.BasicAuth(); ok {
+		// the version is read first, for a change made during the check to
+		// have the credentials verified again
+		version := engine.GetCredentialsVersion()
+		if httpCredentials.isVerified(user, password, version) && engine.AuthenticateSession(session, user) {
+			return true
+		}
+
+		if !engine.AuthenticateSessionWithPassword(session, user, password) {
+			return false
+		}
+
+		httpCredentials.remember(user, password, version)
+		return true
+	}
+
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		engine.AuthenticateSession(session, r.TLS.VerifiedChains[0][0].Subject.CommonName)
+	}
+	return true
+}
+
+// writeHTTPOutput writes out the native RESP output of a command as JSON.
+func writeHTTPOutput(w http.ResponseWriter, output string) {
+	data, code, message := decodeNativeOutput(output)
+	writeHTTPResponse(w, data, code, message)
+}
+
+// writeHTTPResponse writes out the response as JSON, with the HTTP status its
+// code stands for.
+func writeHTTPResponse(w http.ResponseWriter, data interface{}, code uint32, message string) {
+	status, ok := httpStatusCodes[code]
+	if !ok {
+		status = http.StatusOK
+	}
+
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", config.AppCodeName))
+	}
+
+	body, err := json.Marshal(httpResponse{Data: toJSONValue(data), Code: code, Message: message})
+	if err != nil {
+		status = http.StatusInternalServerError
+		body, _ = json.Marshal(httpResponse{Code: entity.CRC_INVALID_DATATYPE, Message: err.Error()})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+	w.Write([]byte("\n"))
+}
+
+// decodeNativeOutput decodes the native [value, code, message] response of a
+// command. An output which is not such a response is reported as invalid input,
+// which is what the engine errors are about.
+func decodeNativeOutput(output string) (interface{}, uint32, string) {
+	decoded, err := resp3.Decode(bufio.NewReader(strings.NewReader(output)))
+	if err != nil {
+		return nil, entity.CRC_INVALID_CMD_INPUT, err.Error()
+	}
+
+	response, ok := decoded.([]interface{})
+	if !ok || len(response) != 3 {
+		return nil, entity.CRC_INVALID_CMD_INPUT, fmt.Sprintf("%v", decoded)
+	}
+
+	code, _ := response[1].(int64)
+	message, _ := response[2].(string)
+	return response[0], uint32(code), message
+}
+
+// fromJSONValue converts the JSON values to the ones the commands take, the
+// integers to int64 and the other numbers to float64.
+func fromJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if integer, err := v.Int64(); err == nil {
+			return integer
+		}
+		float, _ := v.Float64()
+		return float
+
+	case []interface{}:
+		for idx, item := range v {
+			v[idx] = fromJSONValue(item)
+		}
+		return v
+
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = fromJSONValue(item)
+		}
+		return v
+
+	default:
+		return value
+	}
+}
+
+// toJSONValue converts the decoded responses to values JSON can carry, such as
+// the maps keyed by other than strings, and the infinite scores.
+func toJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprintf("%v", key)] = toJSONValue(item)
+		}
+		return converted
+
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = toJSONValue(item)
+		}
+		return v
+
+	case []interface{}:
+		for idx, item := range v {
+			v[idx] = toJSONValue(item)
+		}
+		return v
+
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return v
+
+	default:
+		return value
+	}
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"universum/config"
+	"universum/engine"
+	"universum/entity"
+)
+
+// sendHTTPRequest sends the request to the gateway, with the basic credentials
+// if a user is given, and returns the HTTP status and the decoded body.
+func sendHTTPRequest(t *testing.T, gateway *httptest.Server, method string, path string, body string, user string, password string) (*http.Response, httpResponse) {
+	t.Helper()
+
+	request, err := http.NewRequest(method, gateway.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to build the request: %v", err)
+	}
+
+	if user != "" {
+		request.SetBasicAuth(user, password)
+	}
+
+	response, err := gateway.Client().Do(request)
+	if err != nil {
+		t.Fatalf("Failed to send the request: %v", err)
+	}
+	defer response.Body.Close()
+
+	var decoded httpResponse
+	if err := json.NewDecoder(response.Body).Decode(&decoded); err != nil {
+		t.Fatalf("Failed to decode the response: %v", err)
+	}
+	return response, decoded
+}
+
+func TestHTTPGatewayRoundTrip(t *testing.T) {
+	gateway := httptest.NewServer(newHTTPGatewayHandler())
+	defer gateway.Close()
+
+	if response, body := sendHTTPRequest(t, gateway, http.MethodPut, "/keys/http:key", "value", "", ""); response.StatusCode != http.StatusOK || body.Code != entity.CRC_RECORD_UPDATED {
+		t.Fatalf("Expected the key to be set, got %d %+v", response.StatusCode, body)
+	}
+
+	response, body := sendHTTPRequest(t, gateway, http.MethodGet, "/keys/http:key", "", "", "")
+	record, _ := body.Data.(map[string]interface{})
+	if response.StatusCode != http.StatusOK || body.Code != entity.CRC_RECORD_FOUND || record["Value"] != "value" {
+		t.Errorf("Expected the key to be read back, got %d %+v", response.StatusCode, body)
+	}
+
+	sendHTTPRequest(t, gateway, http.MethodPost, "/cmd", `{"command": "set", "args": ["http:counter", 10, 0]}`, "", "")
+	response, body = sendHTTPRequest(t, gateway, http.MethodPost, "/cmd", `{"command": "incr", "args": ["http:counter", 5]}`, "", "")
+	if response.StatusCode != http.StatusOK || body.Data != float64(15) {
+		t.Errorf("Expected the command to be executed with its integer args, got %d %+v", response.StatusCode, body)
+	}
+
+	if response, body := sendHTTPRequest(t, gateway, http.MethodDelete, "/keys/http:key", "", "", ""); response.StatusCode != http.StatusOK || body.Code != entity.CRC_RECORD_DELETED {
+		t.Errorf("Expected the key to be deleted, got %d %+v", response.StatusCode, body)
+	}
+}
+
+func TestHTTPGatewayStatusCodes(t *testing.T) {
+	gateway := httptest.NewServer(newHTTPGatewayHandler())
+	defer gateway.Close()
+
+	maxRecordSize := config.Store.Storage.MaxRecordSizeInBytes
+	config.Store.Storage.MaxRecordSizeInBytes = 1024
+	t.Cleanup(func() { config.Store.Storage.MaxRecordSizeInBytes = maxRecordSize })
+
+	testCases := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		code   uint32
+	}{
+		{http.MethodGet, "/keys/http:missing", "", http.StatusNotFound, entity.CRC_RECORD_NOT_FOUND},
+		{http.MethodPut, "/keys/http:key?ttl=-1", "value", http.StatusBadRequest, entity.CRC_INVALID_CMD_INPUT},
+		{http.MethodPut, "/keys/http:key", strings.Repeat("v", 1024+int(httpRequestOverhead)+1), http.StatusRequestEntityTooLarge, entity.CRC_RECORD_TOO_BIG},
+		{http.MethodPost, "/cmd", "not json", http.StatusBadRequest, entity.CRC_INVALID_CMD_INPUT},
+		{http.MethodPost, "/cmd", `{"command": "MULTI"}`, http.StatusBadRequest, entity.CRC_INVALID_CMD_INPUT},
+		{http.MethodPost, "/cmd", `{"command": "UNKNOWN"}`, http.StatusBadRequest, entity.CRC_INVALID_CMD_INPUT},
+	}
+
+	for _, tc := range testCases {
+		response, body := sendHTTPRequest(t, gateway, tc.method, tc.path, tc.body, "", "")
+		if response.StatusCode != tc.status || body.Code != tc.code {
+			t.Errorf("Expected %s %s to be answered with %d and code %d, got %d %+v", tc.method, tc.path, tc.status, tc.code, response.StatusCode, body)
+		}
+	}
+}
+
+func TestHTTPGatewayRequiresCredentials(t *testing.T) {
+	setupAuthentication(t)
+
+	gateway := httptest.NewServer(newHTTPGatewayHandler())
+	defer gateway.Close()
+
+	response, body := sendHTTPRequest(t, gateway, http.MethodGet, "/keys/app:key", "", "", "")
+	if response.StatusCode != http.StatusUnauthorized || body.Code != entity.CRC_AUTHENTICATION_REQUIRED {
+		t.Errorf("Expected the request without credentials to be refused, got %d %+v", response.StatusCode, body)
+	}
+
+	if !strings.HasPrefix(response.Header.Get("WWW-Authenticate"), "Basic") {
+		t.Errorf("Expected the client to be asked for basic credentials, got %q", response.Header.Get("WWW-Authenticate"))
+	}
+
+	response, body = sendHTTPRequest(t, gateway, http.MethodGet, "/keys/app:key", "", "reader", "wrong")
+	if response.StatusCode != http.StatusUnauthorized || body.Code != entity.CRC_INVALID_CREDENTIALS {
+		t.Errorf("Expected the request with a wrong password to be refused, got %d %+v", response.StatusCode, body)
+	}
+
+	if response, body := sendHTTPRequest(t, gateway, http.MethodGet, "/keys/app:key", "", "reader", "pass"); response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the request with valid credentials to be served, got %d %+v", response.StatusCode, body)
+	}
+}
+
+func TestHTTPGatewayAppliesACL(t *testing.T) {
+	setupAuthentication(t)
+
+	gateway := httptest.NewServer(newHTTPGatewayHandler())
+	defer gateway.Close()
+
+	response, body := sendHTTPRequest(t, gateway, http.MethodGet, "/keys/secret", "", "reader", "pass")
+	if response.StatusCode != http.StatusForbidden || body.Code != entity.CRC_PERMISSION_DENIED {
+		t.Errorf("Expected the read of a key outside the user patterns to be denied, got %d %+v", response.StatusCode, body)
+	}
+
+	response, body = sendHTTPRequest(t, gateway, http.MethodPut, "/keys/app:key", "value", "reader", "pass")
+	if response.StatusCode != http.StatusForbidden || body.Code != entity.CRC_PERMISSION_DENIED {
+		t.Errorf("Expected the write of a read-only user to be denied, got %d %+v", response.StatusCode, body)
+	}
+}
+
+func TestHTTPGatewayTrustsVerifiedCredentials(t *testing.T) {
+	setupAuthentication(t)
+
+	gateway := httptest.NewServer(newHTTPGatewayHandler())
+	defer gateway.Close()
+
+	sendHTTPRequest(t, gateway, http.MethodGet, "/keys/app:key", "", "reader", "pass")
+	version := engine.GetCredentialsVersion()
+	if !httpCredentials.isVerified("reader", "pass", version) || httpCredentials.isVerified("reader", "wrong", version) {
+		t.Fatal("Expected only the valid credentials to be remembered")
+	}
+
+	// only the command counts, and not the authentication
+	processed := engine.GetCommandsProcessed()
+	sendHTTPRequest(t, gateway, http.MethodGet, "/keys/app:key", "", "reader", "pass")
+
+	if delta := engine.GetCommandsProcessed() - processed; delta != 1 {
+		t.Errorf("Expected a single command to be counted, got %d", delta)
+	}
+
+	// the password changed since is no longer trusted
+	admin := engine.NewSession()
+	executeInTestSession(t, admin, engine.CommandAuth, "admin", "s3cret")
+	executeInTestSession(t, admin, engine.CommandACL, "SETUSER", "reader", []interface{}{">changed"})
+
+	if response, body := sendHTTPRequest(t, gateway, http.MethodGet, "/keys/app:key", "", "reader", "pass"); response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected the previous password to be refused, got %d %+v", response.StatusCode, body)
+	}
+
+	if response, body := sendHTTPRequest(t, gateway, http.MethodGet, "/keys/app:key", "", "reader", "changed"); response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the new password to be accepted, got %d %+v", response.StatusCode, body)
+	}
+
+	// the user deleted since is no longer trusted
+	executeInTestSession(t, admin, engine.CommandACL, "DELUSER", []interface{}{"reader"})
+
+	if response, body := sendHTTPRequest(t, gateway, http.MethodGet, "/keys/app:key", "", "reader", "changed"); response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected the deleted user to be refused, got %d %+v", response.StatusCode, body)
+	}
+}
+
+func TestCredentialsCacheExpiresEntries(t *testing.T) {
+	cache := newCredentialsCache(50*time.Millisecond, 2)
+
+	cache.remember("user", "pass", 1)
+	if !cache.isVerified("user", "pass", 1) {
+		t.Fatal("Expected the credentials to be remembered")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if cache.isVerified("user", "pass", 1) {
+		t.Error("Expected the credentials to expire")
+	}
+
+	cache.remember("a", "pass", 1)
+	cache.remember("b", "pass", 1)
+	cache.remember("c", "pass", 1)
+
+	if len(cache.expiry) > 2 || !cache.isVerified("c", "pass", 1) {
+		t.Errorf("Expected the cache to stay bounded with the latest credentials, got %d entries", len(cache.expiry))
+	}
+
+	if cache.isVerified("c", "pass", 2) {
+		t.Error("Expected the credentials to be forgotten once the users change")
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+	"universum/config"
+	"universum/engine"
+	"universum/internal/logger"
+)
+
+// metricsContentType is the content type of the Prometheus text exposition format.
+const metricsContentType string = "text/plain; version=0.0.4; charset=utf-8"
+
+// listenMetrics starts the listener of the metrics endpoint, over TLS when the
+// server uses it, with the same certificates.
+//
+// Returns:
+// - net.Listener: The listener accepting the scrapes.
+// - error: The error which prevented listening on the port.
+func listenMetrics() (net.Listener, error) {
+	port := fmt.Sprintf(":%d", config.Store.Server.MetricsPort)
+
+	if config.Store.Server.EnableTLS {
+		return tls.Listen(NetworkTCP, port, certificates.serverConfig())
+	}
+	return net.Listen(NetworkTCP, port)
+}
+
+// serveMetrics serves the metrics of the server at /metrics on the listener, in
+// the Prometheus text exposition format.
+//
+// Parameters:
+// - listener net.Listener: The listener accepting the scrapes.
+func serveMetrics(listener net.Listener) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", handleMetrics)
+
+	writeTimeout := time.Duration(config.Store.Server.ConnectionWriteTimeout) * time.Second
+
+	httpServer := &http.Server{
+		Handler:      mux,
+		ReadTimeout:  writeTimeout,
+		WriteTimeout: writeTimeout,
+	}
+
+	if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+		logger.Get().Error("Metrics endpoint stopped serving: %v", err.Error())
+	}
+}
+
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metricsContentType)
+
+	if err := engine.WriteMetrics(w); err != nil {
+		logger.Get().Debug("Failed to write the metrics to %s: %v", r.RemoteAddr, err.Error())
+	}
+}
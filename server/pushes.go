@@ -0,0 +1,64 @@
+package server
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"time"
+	"universum/engine"
+	"universum/internal/logger"
+)
+
+// pumpPushes writes the messages published to the subscriber of the session out
+// to its connection, until the connection is done with. The writer is shared with
+// the responses to the commands, so every write holds the writeMutex, and
+// the pushes queued up together are written out with a single flush.
+//
+// A subscriber dropped for lagging behind has its connection closed, which
+// in turn ends the command loop of the connection.
+func pumpPushes(conn net.Conn, writer *bufio.Writer, writeMutex *sync.Mutex,
+	session *engine.Session, done <-chan struct{}, writeTimeout time.Duration) {
+	subscriber := session.Subscriber()
+
+	for {
+		select {
+		case <-done:
+			return
+
+		case <-subscriber.Dropped():
+			logger.Get().Debug("Subscriber lagging behind, closing the connection")
+			conn.Close()
+			return
+
+		case frame := <-subscriber.Pushes():
+			writeMutex.Lock()
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+			err := writePush(writer, frame, session.ResponseDelimiter())
+			for pending := len(subscriber.Pushes()); err == nil && pending > 0; pending-- {
+				err = writePush(writer, <-subscriber.Pushes(), session.ResponseDelimiter())
+			}
+
+			if err == nil {
+				err = writer.Flush()
+			}
+			writeMutex.Unlock()
+
+			if err != nil {
+				logger.Get().Debug("Unable to write the pushes, closing the connection: %v", err)
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func writePush(writer *bufio.Writer, frame string, delimiter string) error {
+	outputWithEOM := frame + delimiter
+	if _, err := writer.Write([]byte(outputWithEOM)); err != nil {
+		return err
+	}
+
+	engine.AddNetworkBytesSent(int64(len(outputWithEOM)))
+	return nil
+}
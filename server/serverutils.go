@@ -1,6 +1,11 @@
 package server
 
-import "universum/resp3"
+import (
+	"fmt"
+	"universum/config"
+	"universum/entity"
+	"universum/resp3"
+)
 
 func getFormattedClientMessage(data interface{}, code uint32, message string) string {
 	return resp3.EncodedRESP3Response([]interface{}{
@@ -9,3 +14,17 @@ func getFormattedClientMessage(data interface{}, code uint32, message string) st
 		message,
 	})
 }
+
+// getRejectionMessage formats the message sent to a connection which is turned
+// away. Such a connection has not negotiated a protocol, so the message is laid
+// out in the protocol the server is configured to speak by default.
+func getRejectionMessage(code uint32, errorPrefix string, message string) string {
+	switch config.Store.Server.DefaultProtocol {
+	case config.ProtocolRESP2:
+		return resp3.EncodeRedisReply(fmt.Errorf("%s %s", errorPrefix, message), 2)
+	case config.ProtocolRESP3:
+		return resp3.EncodeRedisReply(fmt.Errorf("%s %s", errorPrefix, message), 3)
+	default:
+		return getFormattedClientMessage(nil, code, message) + entity.ResponseDelimiter
+	}
+}
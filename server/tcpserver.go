@@ -5,9 +5,9 @@
 //
 // The server manages its lifecycle states, including starting, ready, busy,
 // and shutting down, to efficiently handle incoming connections based on
-// the current load and system resources. It employs a worker pool pattern
-// to distribute connection processing across multiple goroutines, ensuring
-// responsive and concurrent handling of client requests.
+// the current load and system resources. Each connection is served by a
+// goroutine of its own, and a semaphore bounds the number of connections
+// served at the same time, the ones beyond it being turned away as busy.
 package server
 
 import (
@@ -18,8 +18,10 @@ import (
 	"io"
 	"net"
 	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 	"universum/config"
 	"universum/engine"
@@ -31,11 +33,17 @@ import (
 const NetworkTCP string = "tcp"
 const NetworkUDP string = "udp"
 
+// The bounds of the back-off between the retries of a listener failing to accept
+// the connections temporarily, such as when the process runs out of descriptors.
+const minAcceptRetryDelay time.Duration = 5 * time.Millisecond
+const maxAcceptRetryDelay time.Duration = 1 * time.Second
+
 // StartTCPServer initializes and starts the TCP server, managing incoming
-// client connections. It sets up a listener on the configured port, utilizes
-// a worker pool to process connections concurrently, and manages server
-// states to optimize for load and performance. The function should be run
-// in a goroutine and is intended to block until server shutdown is initiated.
+// client connections. It sets up a listener on the configured port, and on
+// the Unix socket if one is configured, serves each connection in a goroutine
+// of its own up to the configured maximum, and manages server states to
+// optimize for load and performance. The function should be run in a
+// goroutine and is intended to block until server shutdown is initiated.
 //
 // Parameters:
 // - wg *sync.WaitGroup: A WaitGroup to manage the lifecycle of server goroutines.
@@ -50,39 +58,30 @@ func StartTCPServer(wg *sync.WaitGroup) {
 	port := fmt.Sprintf(":%d", config.Store.Server.ServerPort)
 	maxConnections := config.Store.Server.MaxConnections
 
-	// Channel to manage a pool of connections
-	connectionQueue := make(chan net.Conn, maxConnections)
-
-	// Start a worker pool for processing connections
-	for i := 0; i < int(maxConnections); i++ {
-		go connectionWorker(i+1, connectionQueue)
-	}
+	// Semaphore bounding the number of connections served at the same time
+	semaphore := make(chan struct{}, maxConnections)
 
 	var listener net.Listener
 	var err error
 
 	if config.Store.Server.EnableTLS {
-		// Load the TLS certificate and key
-		serverCnf := config.Store.Server
-		cert, err := tls.LoadX509KeyPair(serverCnf.TLSCertFilePath, serverCnf.TLSKeyFilePath)
-		if err != nil {
+		// Load the TLS certificate, key and client CA bundle
+		if err := certificates.load(); err != nil {
 			logger.Get().Error("Error loading TLS certificate and key: %v", err.Error())
 			engine.Shutdown(entity.ExitCodeSocketError)
 			return
 		}
 
-		tlsConfig := &tls.Config{
-			Certificates: []tls.Certificate{cert},
-		}
-
-		// Start a TLS listener
-		listener, err = tls.Listen(NetworkTCP, port, tlsConfig)
+		// Start a TLS listener, reloading the certificates as they are rotated
+		listener, err = tls.Listen(NetworkTCP, port, certificates.serverConfig())
 		if err != nil {
 			logger.Get().Error("Error starting TLS listener: %v", err.Error())
 			engine.Shutdown(entity.ExitCodeSocketError)
 			return
 		}
 
+		go certificates.watch(time.Duration(config.Store.Server.TLSReloadInterval) * time.Second)
+
 		logger.Get().Info("%s TLS server started listening on port %s", config.AppCodeName, port)
 	} else {
 		// Start a TCP listener (non-TLS)
@@ -98,83 +97,154 @@ func StartTCPServer(wg *sync.WaitGroup) {
 
 	defer listener.Close()
 
+	// Listen on the Unix socket too, for the clients running on the same host
+	var unixListener net.Listener
+	if config.Store.Server.UnixSocketPath != "" {
+		unixListener, err = listenUnixSocket(config.Store.Server.UnixSocketPath, config.Store.Server.UnixSocketPermissions)
+		if err != nil {
+			logger.Get().Error("Error listening on unix socket, will shutdown: %v", err.Error())
+			engine.Shutdown(entity.ExitCodeSocketError)
+			return
+		}
+
+		defer unixListener.Close()
+		logger.Get().Info("%s server started listening on unix socket %s", config.AppCodeName, config.Store.Server.UnixSocketPath)
+	}
+
+	// Serve the HTTP/JSON gateway too, for the clients which cannot speak RESP
+	var httpListener net.Listener
+	if config.Store.Server.EnableHTTPGateway {
+		httpListener, err = listenHTTPGateway()
+		if err != nil {
+			logger.Get().Error("Error listening for the HTTP gateway, will shutdown: %v", err.Error())
+			engine.Shutdown(entity.ExitCodeSocketError)
+			return
+		}
+
+		defer httpListener.Close()
+		logger.Get().Info("%s HTTP gateway started listening on port :%d", config.AppCodeName, config.Store.Server.HTTPGatewayPort)
+	}
+
+	// Serve the metrics too, for Prometheus to scrape
+	var metricsListener net.Listener
+	if config.Store.Server.EnableMetrics {
+		metricsListener, err = listenMetrics()
+		if err != nil {
+			logger.Get().Error("Error listening for the metrics endpoint, will shutdown: %v", err.Error())
+			engine.Shutdown(entity.ExitCodeSocketError)
+			return
+		}
+
+		defer metricsListener.Close()
+		logger.Get().Info("%s metrics endpoint started listening on port :%d", config.AppCodeName, config.Store.Server.MetricsPort)
+	}
+
 	engine.Startup()
 	atomic.StoreInt32(&entity.ServerState, entity.STATE_READY)
 
-	for {
-		// If the server is shutting down, handle remaining requests and stop accepting new ones
-		if atomic.LoadInt32(&entity.ServerState) == entity.STATE_SHUTTING_DOWN {
-			go handleRequestWhenShuttingDown(listener)
-			continue
-		}
+	if unixListener != nil {
+		go acceptConnections(unixListener, semaphore)
+	}
+
+	if httpListener != nil {
+		go serveHTTPGateway(httpListener)
+	}
+
+	if metricsListener != nil {
+		go serveMetrics(metricsListener)
+	}
 
+	acceptConnections(listener, semaphore)
+}
+
+// acceptConnections accepts the connections coming to the listener and serves
+// each of them in a goroutine of its own, as long as the semaphore has room for
+// them, turning them away otherwise. The TCP and the Unix socket listeners share
+// the same semaphore, and so the same limit. It returns once the listener is
+// closed or fails for good, backing off between the retries when it fails
+// temporarily.
+//
+// Parameters:
+// - listener net.Listener: The listener to accept the connections from.
+// - semaphore chan struct{}: The semaphore bounding the connections served at once.
+func acceptConnections(listener net.Listener, semaphore chan struct{}) {
+	retryDelay := time.Duration(0)
+
+	for {
 		// Accept new incoming connections
 		conn, err := listener.Accept()
 		if err != nil {
-			logger.Get().Error("Error accepting client connection: %v", err.Error())
+			if errors.Is(err, net.ErrClosed) || atomic.LoadInt32(&entity.ServerState) == entity.STATE_SHUTTING_DOWN {
+				return
+			}
+
+			if netErr, ok := err.(net.Error); !ok || !netErr.Temporary() {
+				logger.Get().Error("Error accepting client connections, stopping the listener: %v", err.Error())
+				return
+			}
+
+			retryDelay = min(max(2*retryDelay, minAcceptRetryDelay), maxAcceptRetryDelay)
+			logger.Get().Error("Error accepting client connection, retrying in %v: %v", retryDelay, err.Error())
+			time.Sleep(retryDelay)
 			continue
 		}
+		retryDelay = 0
 
-		var tcpConn net.Conn
-		if config.Store.Server.EnableTLS {
-			tcpConn, _ = conn.(*tls.Conn)
-
-		} else {
-			tcpConn, _ = conn.(*net.TCPConn)
+		// If the server is shutting down, stop serving new connections
+		if atomic.LoadInt32(&entity.ServerState) == entity.STATE_SHUTTING_DOWN {
+			go rejectConnection(conn, entity.CRC_SERVER_SHUTTING_DOWN, "ERR",
+				"Server shutting down, cannot serve the request.")
+			continue
 		}
 
-		if tcpConn == nil {
-			continue // possibly uncastable connection
+		// Turn away the connections beyond the limit, rather than keeping them waiting
+		select {
+		case semaphore <- struct{}{}:
+		default:
+			logger.Get().Warn("Server busy, rejecting incoming connection from %s", conn.RemoteAddr())
+			go rejectConnection(conn, entity.CRC_SERVER_BUSY, "BUSY",
+				"Server busy, maximum number of connections reached.")
+			continue
 		}
 
 		// Add connection tracking and increase the active connection count
-		entity.AddActiveConnection(tcpConn)
+		entity.AddActiveConnection(conn)
 		entity.IncrementActiveTCPConnection()
 
 		// Set NoDelay to prevent Nagle's algorithm, ensuring faster response
-		if tcpConn, ok := tcpConn.(*net.TCPConn); ok {
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
 			tcpConn.SetNoDelay(true)
 		}
 
-		// Limit total accepted connections, dropping ones when max connections reached
-		select {
-		case connectionQueue <- tcpConn:
-			// Successfully added connection to the worker queue
-			continue
-		default:
-		case <-time.After(100 * time.Millisecond): // Retry timeout
-			logger.Get().Warn("Server busy, dropping incoming connection")
-			closeTCPConnection(conn) // Close the connection gracefully after timeout
-		}
+		go serveConnection(conn, semaphore)
 	}
 }
 
-// connectionWorker represents a worker in the server's worker pool. Each worker
-// listens for incoming jobs on the jobs channel, processes connections, and
-// handles concurrency based on the number of connections.
+// serveConnection serves the connection in the goroutine it runs in, holding
+// its place in the semaphore until the connection is closed.
 //
 // Parameters:
-// - id int: The unique ID of the worker (for logging and tracking purposes).
-// - queue <-chan *net.TCPConn: A channel of TCP connections to be processed by the worker.
-func connectionWorker(id int, queue <-chan net.Conn) {
+// - conn net.Conn: The connection to be served.
+// - semaphore chan struct{}: The semaphore bounding the connections served at once.
+func serveConnection(conn net.Conn, semaphore chan struct{}) {
 	defer func() {
-		// Panic recovery to keep the worker alive in case of runtime errors
+		<-semaphore
+
+		// Panic recovery to keep the server alive in case of runtime errors
 		if r := recover(); r != nil {
-			logger.Get().Error("Connection worker [id=%d] panicked: %v. Restarting...", id, r)
-			go connectionWorker(id, queue)
+			logger.Get().Error("Connection handler for %s panicked: %v", conn.RemoteAddr(), r)
 		}
 	}()
 
-	for conn := range queue {
-		handleConnection(conn)
-	}
+	handleConnection(conn)
 }
 
 // handleConnection is responsible for the lifecycle of a single TCP connection
 // from acceptance through processing of commands and closure. It reads commands
 // from the connection, executes them using the engine, and writes responses back
 // to the client. It ensures that each connection is processed efficiently and
-// safely.
+// safely, the commands a client pipelines being executed in batches whose
+// responses are flushed together.
 //
 // Parameters:
 // - conn *net.TCPConn: The TCP connection to be handled.
@@ -182,44 +252,75 @@ func handleConnection(conn net.Conn) {
 	buffer := bufio.NewReader(conn)
 	writer := bufio.NewWriter(conn)
 
+	// The writer is shared with the goroutine pumping the pub/sub pushes
+	var writeMutex sync.Mutex
+	pushesDone := make(chan struct{})
+	isPumpingPushes := false
+
+	session := engine.NewSession()
+
 	// Recover from panics and close the connection gracefully
 	defer func() {
+		close(pushesDone)
+		session.Close()
+
 		err := errors.New("connection pipe broken, closing the connection")
 
-		outputWithEOM := resp3.EncodedRESP3Response(err) + entity.ResponseDelimiter
+		outputWithEOM := resp3.EncodedRESP3Response(err) + session.ResponseDelimiter()
 		engine.AddNetworkBytesSent(int64(len(outputWithEOM)))
 
+		writeMutex.Lock()
 		writer.Write([]byte(outputWithEOM))
 		writer.Flush()
+		writeMutex.Unlock()
 
-		closeTCPConnection(conn)
+		closeConnection(conn)
 	}()
 
 	reqTimeout := time.Duration(config.Store.Server.RequestExecutionTimeout) * time.Second
 	writeTimeout := time.Duration(config.Store.Server.ConnectionWriteTimeout) * time.Second
+	idleTimeout := time.Duration(config.Store.Server.IdleConnectionTimeout) * time.Second
+	batchSize := config.Store.Server.MaxPipelineBatchSize
+
+	// Authenticate the TLS clients by their certificate, when they give one
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if err := authenticateClientCertificate(tlsConn, session, writeTimeout); err != nil {
+			logger.Get().Debug("TLS handshake failed, closing the connection: %v", err)
+			return
+		}
+	}
 
 	for {
-		// Execute the client command with a request timeout
-		output, err := engine.ExecuteCommand(buffer, reqTimeout)
+		// Wait for the next command no longer than the idle timeout, except for
+		// the subscribers, which are waiting on the published messages instead
+		if idleTimeout > 0 {
+			deadline := time.Time{}
+			if session.Subscriber() == nil {
+				deadline = time.Now().Add(idleTimeout)
+			}
+			conn.SetReadDeadline(deadline)
+		}
+
+		// Execute the client commands with a request timeout, in pipelined batches
+		outputWithEOM, err := executePipeline(buffer, session, reqTimeout, batchSize)
 
 		if err != nil {
-			if err == io.EOF {
-				// Connection closed by the client
-				return
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				logger.Get().Debug("Connection idle for too long, closing the connection")
 			}
-			// Handle other errors
-			output = resp3.EncodedRESP3Response(err)
+			// Connection closed by the client, dropped or timed out
+			return
 		}
 
-		outputWithEOM := output + entity.ResponseDelimiter
-
-		// Set a write deadline for sending the response
+		// Set a write deadline for sending the responses
+		writeMutex.Lock()
 		conn.SetWriteDeadline(time.Now().Add(writeTimeout))
 		_, err = writer.Write([]byte(outputWithEOM))
 
 		if err != nil {
 			// Handle socket write errors and terminate the connection if needed
 			if _, ok := err.(net.Error); ok {
+				writeMutex.Unlock()
 				logger.Get().Debug("Connection timed out or dropped, closing the connection")
 				return
 			}
@@ -229,45 +330,80 @@ func handleConnection(conn net.Conn) {
 		engine.AddNetworkBytesSent(int64(len(outputWithEOM)))
 
 		flushErr := writer.Flush()
+		writeMutex.Unlock()
+
 		if flushErr != nil {
 			logger.Get().Debug("Unable to flush the response: %v", flushErr)
 			return
 		}
+
+		// Start pumping the published messages once the client subscribes
+		if session.Subscriber() != nil && !isPumpingPushes {
+			isPumpingPushes = true
+			go pumpPushes(conn, writer, &writeMutex, session, pushesDone, writeTimeout)
+		}
 	}
 }
 
-// handleRequestWhenShuttingDown handles new connections while the server is
-// shutting down. It accepts a new connection and sends a shutdown message to the
-// client, indicating that the server cannot process new requests.
+// executePipeline executes the next command, waiting for it if need be, and then
+// the commands pipelined behind it which are already received, up to the batch
+// size. Their responses are returned together, in the order of the commands, for
+// them to be written out with a single flush.
 //
 // Parameters:
-// - listener net.Listener: The server's listener to accept new connections.
-func handleRequestWhenShuttingDown(listener net.Listener) {
-	conn, err := listener.Accept()
-	if err != nil {
-		logger.Get().Error("Error accepting connection during shutdown: %v", err.Error())
-		return
-	}
+// - buffer *bufio.Reader: The buffered reader of the client connection.
+// - session *engine.Session: The session of the client.
+// - timeout time.Duration: The execution timeout of each command.
+// - batchSize int64: The maximum number of commands to execute.
+//
+// Returns:
+// - string: The responses, each followed by the delimiter of the session.
+// - error: io.EOF once the client has closed the connection, or the network
+// error which failed the read.
+func executePipeline(buffer *bufio.Reader, session *engine.Session, timeout time.Duration, batchSize int64) (string, error) {
+	var responses strings.Builder
+
+	for count := int64(0); count == 0 || (count < batchSize && engine.HasBufferedCommand(buffer)); count++ {
+		output, err := engine.ExecuteCommand(buffer, timeout, session)
+
+		if err != nil {
+			if _, ok := err.(net.Error); ok || err == io.EOF {
+				return "", err
+			}
+			output = resp3.EncodedRESP3Response(err)
+		}
 
-	entity.IncrementActiveTCPConnection()
+		responses.WriteString(output + session.ResponseDelimiter())
+	}
 
-	shutdownMessage := getFormattedClientMessage(nil,
-		entity.CRC_SERVER_SHUTTING_DOWN,
-		"Server shutting down, cannot serve the request.",
-	)
+	return responses.String(), nil
+}
 
-	outputWithEOM := resp3.EncodedRESP3Response(shutdownMessage) + entity.ResponseDelimiter
+// rejectConnection turns away a connection the server cannot serve, because it
+// is busy or shutting down. The client is told why, before the connection is
+// closed, and the connection is counted as rejected rather than active.
+//
+// Parameters:
+// - conn net.Conn: The connection to be turned away.
+// - code uint32: The response code telling the reason.
+// - errorPrefix string: The error code of the reason, for the Redis protocols.
+// - message string: The message telling the reason.
+func rejectConnection(conn net.Conn, code uint32, errorPrefix string, message string) {
+	defer conn.Close()
+	entity.IncrementRejectedTCPConnection()
 
-	writer := bufio.NewWriter(conn)
-	writer.Write([]byte(outputWithEOM))
-	writer.Flush()
+	output := getRejectionMessage(code, errorPrefix, message)
+	engine.AddNetworkBytesSent(int64(len(output)))
 
-	closeTCPConnection(conn)
+	writeTimeout := time.Duration(config.Store.Server.ConnectionWriteTimeout) * time.Second
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	conn.Write([]byte(output))
 }
 
 // WaitForSignal blocks until a shutdown signal is received. It initiates a
 // graceful shutdown process, ensuring that the server stops accepting new
-// connections and properly terminates existing ones.
+// connections and properly terminates existing ones. SIGHUP does not shut the
+// server down, but reloads the TLS certificates instead.
 //
 // Parameters:
 // - wg *sync.WaitGroup: A WaitGroup to manage the shutdown goroutine's lifecycle.
@@ -276,21 +412,33 @@ func WaitForSignal(wg *sync.WaitGroup, sigs chan os.Signal) {
 	defer wg.Done()
 	receivedSignal := <-sigs
 
+	for receivedSignal == syscall.SIGHUP {
+		if config.Store.Server.EnableTLS {
+			logger.Get().Info("Reloading TLS certificates due to signal: %s", receivedSignal.String())
+			certificates.reload()
+		}
+		receivedSignal = <-sigs
+	}
+
 	logger.Get().Fatal("Shutting down the server due to signal: %s", receivedSignal.String())
 
 	// Set the state to SHUTTING DOWN to stop accepting new connections
 	atomic.StoreInt32(&entity.ServerState, entity.STATE_SHUTTING_DOWN)
 
+	// The listener of the socket does not remove its file, having created it under another path
+	removeUnixSocket(config.Store.Server.UnixSocketPath)
+
 	engine.Shutdown(entity.ExitCodeInturrupted)
 }
 
-// closeTCPConnection closes a TCP connection and removes it from the active
-// connections list while decrementing the active connection count.
+// closeConnection closes a client connection, be it over TCP, TLS or the Unix
+// socket, and removes it from the active connections list while decrementing
+// the active connection count.
 //
 // Parameters:
-// - conn net.Conn: The TCP connection to be closed.
-func closeTCPConnection(conn net.Conn) {
+// - conn net.Conn: The connection to be closed.
+func closeConnection(conn net.Conn) {
 	conn.Close()
-	entity.RemoveActiveConnection(conn.(*net.TCPConn))
+	entity.RemoveActiveConnection(conn)
 	entity.DecrementActiveTCPConnection()
 }
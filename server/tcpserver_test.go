@@ -0,0 +1,272 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+	"universum/config"
+	"universum/engine"
+	"universum/entity"
+	"universum/internal/logger"
+	"universum/resp3"
+)
+
+// TestMain starts the engine over the memory store, for the tests to serve the
+// commands of their connections, with the logs and the snapshots kept in a
+// temporary directory.
+func TestMain(m *testing.M) {
+	tmpdir, err := os.MkdirTemp("", "universum-server-tests")
+	if err != nil {
+		panic(err)
+	}
+
+	config.Store = config.GetSkeleton()
+	config.Store.Logging.LogFileDirectory = tmpdir
+	config.Store.Storage.StorageEngine = config.StorageEngineMemory
+	config.Store.Storage.Memory.SnapshotFileDirectory = tmpdir
+	config.Store.Storage.Memory.RestoreSnapshotOnStart = false
+
+	if _, err := config.NewConfigValidator().Validate(config.Store); err != nil {
+		panic(err)
+	}
+
+	logger.Get()
+	engine.Startup()
+
+	code := m.Run()
+	os.RemoveAll(tmpdir)
+	os.Exit(code)
+}
+
+func encodeRequest(t *testing.T, args ...interface{}) string {
+	request, err := resp3.Encode(args)
+	if err != nil {
+		t.Fatalf("Failed to encode %v: %v", args, err)
+	}
+	return request
+}
+
+// readResponse reads the next native response off the connection, along with
+// the delimiter which follows it.
+func readResponse(t *testing.T, reader *bufio.Reader) []interface{} {
+	t.Helper()
+
+	decoded, err := resp3.Decode(reader)
+	if err != nil {
+		t.Fatalf("Failed to read the response: %v", err)
+	}
+
+	delimiter := make([]byte, len(entity.ResponseDelimiter))
+	if _, err := io.ReadFull(reader, delimiter); err != nil || string(delimiter) != entity.ResponseDelimiter {
+		t.Fatalf("Expected the response to be delimited, got %q, %v", delimiter, err)
+	}
+
+	response, ok := decoded.([]interface{})
+	if !ok || len(response) != 3 {
+		t.Fatalf("Expected a native response, got %v", decoded)
+	}
+	return response
+}
+
+func responseCode(response []interface{}) uint32 {
+	code, _ := response[1].(int64)
+	return uint32(code)
+}
+
+// failingListener fails the accepts with the given errors, one after the other,
+// and then as a closed listener.
+type failingListener struct {
+	net.Listener
+	errs    []error
+	accepts int32
+}
+
+func (fl *failingListener) Accept() (net.Conn, error) {
+	n := int(atomic.AddInt32(&fl.accepts, 1))
+	if n <= len(fl.errs) {
+		return nil, fl.errs[n-1]
+	}
+	return nil, net.ErrClosed
+}
+
+func acceptsUntilReturned(t *testing.T, listener net.Listener, timeout time.Duration) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		acceptConnections(listener, make(chan struct{}, 1))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		t.Fatal("Expected the accept loop to return")
+	}
+}
+
+func TestAcceptConnectionsReturnsOnceListenerIsClosed(t *testing.T) {
+	atomic.StoreInt32(&entity.ServerState, entity.STATE_READY)
+
+	listener, err := net.Listen(NetworkTCP, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	listener.Close()
+
+	acceptsUntilReturned(t, listener, time.Second)
+}
+
+func TestAcceptConnectionsBacksOffOnTemporaryErrors(t *testing.T) {
+	atomic.StoreInt32(&entity.ServerState, entity.STATE_READY)
+
+	temporary := &net.OpError{Op: "accept", Net: NetworkTCP, Err: os.NewSyscallError("accept", syscall.EMFILE)}
+	listener := &failingListener{errs: []error{temporary, temporary, temporary}}
+
+	start := time.Now()
+	acceptsUntilReturned(t, listener, 5*time.Second)
+
+	if listener.accepts != 4 {
+		t.Errorf("Expected the accepts to be retried after the temporary errors, got %d accepts", listener.accepts)
+	}
+
+	// 5ms, 10ms and then 20ms
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected the retries to back off, they took %v", elapsed)
+	}
+}
+
+func TestAcceptConnectionsStopsOnPermanentErrors(t *testing.T) {
+	atomic.StoreInt32(&entity.ServerState, entity.STATE_READY)
+
+	listener := &failingListener{errs: []error{errors.New("listener broken"), errors.New("listener broken")}}
+	acceptsUntilReturned(t, listener, time.Second)
+
+	if listener.accepts != 1 {
+		t.Errorf("Expected the accept loop to stop at the permanent error, got %d accepts", listener.accepts)
+	}
+}
+
+// setServerConfig sets the server settings for the test, restoring them after.
+func setServerConfig(t *testing.T, update func(cnf *config.Server)) {
+	previous := *config.Store.Server
+	update(config.Store.Server)
+	t.Cleanup(func() { *config.Store.Server = previous })
+}
+
+func TestBusyConnectionsAreTurnedAway(t *testing.T) {
+	atomic.StoreInt32(&entity.ServerState, entity.STATE_READY)
+
+	listener, err := net.Listen(NetworkTCP, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	semaphore := make(chan struct{}, 1)
+	go acceptConnections(listener, semaphore)
+
+	served, err := net.Dial(NetworkTCP, listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	servedReader := bufio.NewReader(served)
+	served.Write([]byte(encodeRequest(t, engine.CommandPing)))
+	if response := readResponse(t, servedReader); responseCode(response) != entity.CRC_PING_SUCCESS {
+		t.Fatalf("Expected the first connection to be served, got %v", response)
+	}
+
+	rejected, err := net.Dial(NetworkTCP, listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer rejected.Close()
+
+	rejected.SetReadDeadline(time.Now().Add(5 * time.Second))
+	output, err := io.ReadAll(rejected)
+	if err != nil {
+		t.Fatalf("Expected the busy connection to be closed, got %v", err)
+	}
+
+	busy := getRejectionMessage(entity.CRC_SERVER_BUSY, "BUSY", "Server busy, maximum number of connections reached.")
+	if string(output) != busy {
+		t.Errorf("Expected the busy connection to be told so, got %q", output)
+	}
+
+	// the place of the served connection is freed once it is closed
+	served.Close()
+
+	for deadline := time.Now().Add(5 * time.Second); len(semaphore) != 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the closed connection to free its place in the semaphore")
+		}
+	}
+
+	next, err := net.Dial(NetworkTCP, listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer next.Close()
+
+	next.Write([]byte(encodeRequest(t, engine.CommandPing)))
+	if response := readResponse(t, bufio.NewReader(next)); responseCode(response) != entity.CRC_PING_SUCCESS {
+		t.Errorf("Expected the next connection to be served, got %v", response)
+	}
+}
+
+func TestIdleConnectionsAreClosed(t *testing.T) {
+	setServerConfig(t, func(cnf *config.Server) { cnf.IdleConnectionTimeout = 1 })
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	done := make(chan struct{})
+	go func() {
+		handleConnection(server)
+		close(done)
+	}()
+
+	start := time.Now()
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// the error telling the connection is closed, which is then closed
+	if _, err := io.ReadAll(client); err != nil {
+		t.Fatalf("Expected the idle connection to be closed, got %v", err)
+	}
+	<-done
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected the connection to be closed once idle for a second, it was after %v", elapsed)
+	}
+}
+
+func TestPipelinedCommandsAreAnsweredInOrder(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	go handleConnection(server)
+
+	requests := encodeRequest(t, engine.CommandSet, "pipelined", "value", int64(0)) +
+		encodeRequest(t, engine.CommandGet, "pipelined") +
+		encodeRequest(t, engine.CommandDelete, "pipelined") +
+		encodeRequest(t, engine.CommandGet, "pipelined")
+
+	// the commands are written out at once, for them to be executed as a batch
+	go client.Write([]byte(requests))
+
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(client)
+
+	expected := []uint32{entity.CRC_RECORD_UPDATED, entity.CRC_RECORD_FOUND, entity.CRC_RECORD_DELETED, entity.CRC_RECORD_NOT_FOUND}
+	for idx, code := range expected {
+		if response := readResponse(t, reader); responseCode(response) != code {
+			t.Errorf("Expected the response %d to have code %d, got %v", idx, code, response)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+	"universum/config"
+	"universum/engine"
+	"universum/internal/logger"
+)
+
+// certificateStore holds the TLS configuration built out of the certificate, the
+// key and the client CA bundle, and builds it anew when the files change, so that
+// the rotated certificates are used without restarting the server. Each new
+// connection is handed the configuration last loaded, while the connections
+// already established keep the one they started with.
+type certificateStore struct {
+	mutex   sync.Mutex
+	current atomic.Pointer[tls.Config]
+
+	// modTimes maps the files to the modification times they were loaded at.
+	modTimes map[string]time.Time
+}
+
+var certificates = &certificateStore{}
+
+// getTLSFiles returns the files the TLS configuration is built out of.
+func getTLSFiles() []string {
+	files := []string{config.Store.Server.TLSCertFilePath, config.Store.Server.TLSKeyFilePath}
+	if config.Store.Server.TLSClientCAFilePath != "" {
+		files = append(files, config.Store.Server.TLSClientCAFilePath)
+	}
+	return files
+}
+
+// getClientAuthType maps the configured client auth to the TLS one.
+func getClientAuthType(clientAuth string) tls.ClientAuthType {
+	switch clientAuth {
+	case config.TLSClientAuthOptional:
+		return tls.VerifyClientCertIfGiven
+	case config.TLSClientAuthRequired:
+		return tls.RequireAndVerifyClientCert
+	default:
+		return tls.NoClientCert
+	}
+}
+
+// load builds the TLS configuration out of the files, and puts it in use for
+// the new connections. The configuration in use is left as it is on failure.
+func (s *certificateStore) load() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	serverCnf := config.Store.Server
+	modTimes := make(map[string]time.Time)
+
+	for _, file := range getTLSFiles() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(serverCnf.TLSCertFilePath, serverCnf.TLSKeyFilePath)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   getClientAuthType(serverCnf.TLSClientAuth),
+	}
+
+	if serverCnf.TLSClientCAFilePath != "" {
+		bundle, err := os.ReadFile(serverCnf.TLSClientCAFilePath)
+		if err != nil {
+			return err
+		}
+
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(bundle) {
+			return fmt.Errorf("no CA certificate found in %s", serverCnf.TLSClientCAFilePath)
+		}
+		tlsConfig.ClientCAs = clientCAs
+	}
+
+	s.current.Store(tlsConfig)
+	s.modTimes = modTimes
+	return nil
+}
+
+// hasChanged tells whether any of the files was modified, or replaced, since it
+// was last loaded.
+func (s *certificateStore) hasChanged() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, file := range getTLSFiles() {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(s.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// reload loads the files again, logging the outcome. The files failing to load,
+// which happens while they are being rotated, leaves the previous ones in use.
+func (s *certificateStore) reload() {
+	if err := s.load(); err != nil {
+		logger.Get().Error("Error reloading TLS certificates, keeping the previous ones: %v", err.Error())
+		return
+	}
+
+	logger.Get().Info("TLS certificates reloaded")
+}
+
+// watch reloads the files whenever they change on disk, checking them at the
+// given interval. It never returns.
+func (s *certificateStore) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if s.hasChanged() {
+			s.reload()
+		}
+	}
+}
+
+// serverConfig returns the TLS configuration of the listener, which hands each
+// new connection the configuration last loaded.
+func (s *certificateStore) serverConfig() *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return s.current.Load(), nil
+		},
+	}
+}
+
+// authenticateClientCertificate completes the TLS handshake of the connection,
+// and authenticates its session as the user named by the common name of the
+// client certificate, if the client gave one which was verified against the CA
+// bundle. A certificate naming no known user leaves the session to AUTH.
+//
+// Parameters:
+// - conn *tls.Conn: The TLS connection of the client.
+// - session *engine.Session: The session of the client.
+// - timeout time.Duration: The time the handshake is allowed to take.
+//
+// Returns:
+// - error: The error which failed the handshake.
+func authenticateClientCertificate(conn *tls.Conn, session *engine.Session, timeout time.Duration) error {
+	conn.SetDeadline(time.Now().Add(timeout))
+	defer conn.SetDeadline(time.Time{})
+
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+
+	state := conn.ConnectionState()
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	user := state.VerifiedChains[0][0].Subject.CommonName
+	if user != "" && !engine.AuthenticateSession(session, user) {
+		logger.Get().Debug("Client certificate of %s names no known user %s", conn.RemoteAddr(), user)
+	}
+	return nil
+}
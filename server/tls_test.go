@@ -0,0 +1,275 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"universum/config"
+	"universum/engine"
+	"universum/entity"
+	"universum/utils"
+)
+
+// testCA issues the certificates of the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate the CA key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "universum test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create the CA certificate: %v", err)
+	}
+
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM encoded certificate and key of a server for localhost, or
+// of a client with the given common name.
+func (ca *testCA) issue(t *testing.T, serial int64, commonName string, isServer bool) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate the key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	if isServer {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.DNSNames = []string{"localhost"}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to create the certificate: %v", err)
+	}
+
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+// setupTLSTests writes out the server certificate issued by the CA, and points
+// the TLS settings at it, the client certificates being verified if given.
+func setupTLSTests(t *testing.T, ca *testCA) {
+	dir := t.TempDir()
+
+	setServerConfig(t, func(cnf *config.Server) {
+		cnf.EnableTLS = true
+		cnf.TLSCertFilePath = filepath.Join(dir, "server.crt")
+		cnf.TLSKeyFilePath = filepath.Join(dir, "server.key")
+		cnf.TLSClientCAFilePath = filepath.Join(dir, "ca.crt")
+		cnf.TLSClientAuth = config.TLSClientAuthOptional
+	})
+
+	certPEM, keyPEM := ca.issue(t, 2, "localhost", true)
+	os.WriteFile(config.Store.Server.TLSCertFilePath, certPEM, 0600)
+	os.WriteFile(config.Store.Server.TLSKeyFilePath, keyPEM, 0600)
+	os.WriteFile(config.Store.Server.TLSClientCAFilePath, ca.pem, 0600)
+}
+
+// setupAuthentication enables authentication with the admin user, and adds an
+// ACL user reading the keys under app:.
+func setupAuthentication(t *testing.T) {
+	hash, err := utils.HashPassword("s3cret")
+	if err != nil {
+		t.Fatalf("Failed to hash the password: %v", err)
+	}
+
+	previous := *config.Store.Auth
+	config.Store.Auth.AuthenticationEnabled = true
+	config.Store.Auth.DbUserName = "admin"
+	config.Store.Auth.DbUserPassword = hash
+	t.Cleanup(func() { *config.Store.Auth = previous })
+
+	admin := engine.NewSession()
+	if response := executeInTestSession(t, admin, engine.CommandAuth, "admin", "s3cret"); responseCode(response) != entity.CRC_AUTHENTICATED {
+		t.Fatalf("Failed to authenticate as the admin: %v", response)
+	}
+
+	rules := []interface{}{"on", ">pass", "+@read", "~app:*"}
+	if response := executeInTestSession(t, admin, engine.CommandACL, "SETUSER", "reader", rules); responseCode(response) != entity.CRC_ACL_UPDATED {
+		t.Fatalf("Failed to add the ACL user: %v", response)
+	}
+	t.Cleanup(func() { executeInTestSession(t, admin, engine.CommandACL, "DELUSER", []interface{}{"reader"}) })
+}
+
+func executeInTestSession(t *testing.T, session *engine.Session, name string, args ...interface{}) []interface{} {
+	t.Helper()
+
+	output, err := engine.ExecuteParsedCommand(&entity.Command{Name: name, Args: args}, time.Second, session)
+	if err != nil {
+		t.Fatalf("Failed to execute %s: %v", name, err)
+	}
+
+	data, code, message := decodeNativeOutput(output)
+	return []interface{}{data, int64(code), message}
+}
+
+// handshake connects a client with the given certificate, if any, to a session
+// authenticated by its certificate, and returns the session along with the
+// certificate the server presented.
+func handshake(t *testing.T, store *certificateStore, ca *testCA, clientCert []tls.Certificate) (*engine.Session, *x509.Certificate) {
+	client, server := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	tlsClient := tls.Client(client, &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: clientCert})
+	clientDone := make(chan error, 1)
+	go func() { clientDone <- tlsClient.Handshake() }()
+
+	session := engine.NewSession()
+	if err := authenticateClientCertificate(tls.Server(server, store.serverConfig()), session, 5*time.Second); err != nil {
+		t.Fatalf("Expected the handshake to succeed, got %v", err)
+	}
+
+	if err := <-clientDone; err != nil {
+		t.Fatalf("Expected the client handshake to succeed, got %v", err)
+	}
+	return session, tlsClient.ConnectionState().PeerCertificates[0]
+}
+
+func TestClientCertificateAuthenticatesACLUser(t *testing.T) {
+	ca := newTestCA(t)
+	setupTLSTests(t, ca)
+	setupAuthentication(t)
+
+	store := &certificateStore{}
+	if err := store.load(); err != nil {
+		t.Fatalf("Failed to load the certificates: %v", err)
+	}
+
+	testCases := []struct {
+		commonName string
+		user       string
+	}{
+		{"reader", "reader"},
+		{"admin", "admin"},
+		{"nobody", ""},
+	}
+
+	for _, tc := range testCases {
+		certPEM, keyPEM := ca.issue(t, 3, tc.commonName, false)
+		clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			t.Fatalf("Failed to load the client certificate: %v", err)
+		}
+
+		session, _ := handshake(t, store, ca, []tls.Certificate{clientCert})
+		response := executeInTestSession(t, session, engine.CommandACL, "WHOAMI")
+
+		if tc.user == "" {
+			if responseCode(response) != entity.CRC_AUTHENTICATION_REQUIRED {
+				t.Errorf("Expected the certificate of an unknown user to leave the session to AUTH, got %v", response)
+			}
+		} else if response[0] != tc.user {
+			t.Errorf("Expected the certificate of %s to authenticate as %s, got %v", tc.commonName, tc.user, response)
+		}
+	}
+
+	// the ACL of the user applies to the sessions authenticated by certificate
+	certPEM, keyPEM := ca.issue(t, 4, "reader", false)
+	clientCert, _ := tls.X509KeyPair(certPEM, keyPEM)
+	session, _ := handshake(t, store, ca, []tls.Certificate{clientCert})
+
+	if response := executeInTestSession(t, session, engine.CommandGet, "secret"); responseCode(response) != entity.CRC_PERMISSION_DENIED {
+		t.Errorf("Expected the ACL of the user to apply, got %v", response)
+	}
+
+	// without a certificate, the session is left to AUTH
+	session, _ = handshake(t, store, ca, nil)
+	if response := executeInTestSession(t, session, engine.CommandGet, "app:key"); responseCode(response) != entity.CRC_AUTHENTICATION_REQUIRED {
+		t.Errorf("Expected the session without certificate to be unauthenticated, got %v", response)
+	}
+}
+
+func TestCertificatesAreReloadedOnceRotated(t *testing.T) {
+	ca := newTestCA(t)
+	setupTLSTests(t, ca)
+
+	store := &certificateStore{}
+	if err := store.load(); err != nil {
+		t.Fatalf("Failed to load the certificates: %v", err)
+	}
+
+	servedSerial := func() int64 {
+		_, cert := handshake(t, store, ca, nil)
+		return cert.SerialNumber.Int64()
+	}
+
+	if serial := servedSerial(); serial != 2 {
+		t.Fatalf("Expected the loaded certificate to be served, got serial %d", serial)
+	}
+
+	if store.hasChanged() {
+		t.Error("Expected the certificates not to have changed")
+	}
+
+	// a half-rotated certificate fails to load, and the previous one is kept
+	later := time.Now().Add(time.Minute)
+	os.WriteFile(config.Store.Server.TLSCertFilePath, []byte("half-written"), 0600)
+	os.Chtimes(config.Store.Server.TLSCertFilePath, later, later)
+
+	if !store.hasChanged() {
+		t.Fatal("Expected the rewritten certificate to be noticed")
+	}
+
+	store.reload()
+	if serial := servedSerial(); serial != 2 {
+		t.Errorf("Expected the previous certificate to be kept, got serial %d", serial)
+	}
+
+	certPEM, keyPEM := ca.issue(t, 5, "localhost", true)
+	os.WriteFile(config.Store.Server.TLSCertFilePath, certPEM, 0600)
+	os.WriteFile(config.Store.Server.TLSKeyFilePath, keyPEM, 0600)
+
+	later = later.Add(time.Minute)
+	os.Chtimes(config.Store.Server.TLSCertFilePath, later, later)
+	os.Chtimes(config.Store.Server.TLSKeyFilePath, later, later)
+
+	store.reload()
+	if serial := servedSerial(); serial != 5 {
+		t.Errorf("Expected the rotated certificate to be served, got serial %d", serial)
+	}
+
+	if store.hasChanged() {
+		t.Error("Expected the reloaded certificates not to have changed")
+	}
+}
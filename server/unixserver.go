@@ -0,0 +1,83 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const NetworkUnix string = "unix"
+
+// listenUnixSocket starts a listener on the Unix socket at the given path, with
+// the file permissions which decide the local users allowed to connect. The socket
+// is created in a private directory and moved to the path once its permissions
+// are set, for no user to connect in between through the permissions the umask
+// gives it. A socket left at the path by a previous run is replaced, but any other
+// kind of file is left alone, and fails the listener.
+//
+// Parameters:
+// - path string: The path of the socket file.
+// - permissions string: The file permissions of the socket, in octal.
+//
+// Returns:
+// - net.Listener: The listener accepting the connections on the socket.
+// - error: The error which prevented listening on the socket.
+func listenUnixSocket(path string, permissions string) (net.Listener, error) {
+	mode, err := strconv.ParseUint(permissions, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unix socket permissions %s: %v", permissions, err)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s already exists and is not a socket", path)
+		}
+
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("unable to remove the stale socket %s: %v", path, err)
+		}
+	}
+
+	privateDir, err := os.MkdirTemp(filepath.Dir(path), ".sock-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a private directory for %s: %v", path, err)
+	}
+	defer os.RemoveAll(privateDir)
+
+	privatePath := filepath.Join(privateDir, "socket")
+	listener, err := net.Listen(NetworkUnix, privatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// the socket is removed from the path on shutdown, see removeUnixSocket
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if err := os.Chmod(privatePath, os.FileMode(mode)); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("unable to set the permissions of %s: %v", path, err)
+	}
+
+	if err := os.Rename(privatePath, path); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("unable to move the socket to %s: %v", path, err)
+	}
+
+	return listener, nil
+}
+
+// removeUnixSocket removes the socket file at the given path, if there is one.
+//
+// Parameters:
+// - path string: The path of the socket file, empty when none is configured.
+func removeUnixSocket(path string) {
+	if path == "" {
+		return
+	}
+
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+}
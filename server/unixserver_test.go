@@ -0,0 +1,102 @@
+package server
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+	"universum/engine"
+	"universum/entity"
+)
+
+func TestUnixSocketListenerReplacesStaleSocket(t *testing.T) {
+	atomic.StoreInt32(&entity.ServerState, entity.STATE_READY)
+	path := filepath.Join(t.TempDir(), "universum.sock")
+
+	// a socket left behind by a process which was killed
+	stale, err := net.Listen(NetworkUnix, path)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	listener, err := listenUnixSocket(path, "0660")
+	if err != nil {
+		t.Fatalf("Expected the stale socket to be replaced, got %v", err)
+	}
+	defer listener.Close()
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0660 {
+		t.Errorf("Expected the socket to have the configured permissions, got %v, %v", info, err)
+	}
+
+	go acceptConnections(listener, make(chan struct{}, 1))
+
+	conn, err := net.Dial(NetworkUnix, path)
+	if err != nil {
+		t.Fatalf("Failed to connect to the socket: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	conn.Write([]byte(encodeRequest(t, engine.CommandPing)))
+
+	if response := readResponse(t, bufio.NewReader(conn)); responseCode(response) != entity.CRC_PING_SUCCESS {
+		t.Errorf("Expected the command to be served over the socket, got %v", response)
+	}
+}
+
+func TestUnixSocketListenerLeavesOtherFilesAlone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "universum.sock")
+	os.WriteFile(path, []byte("not a socket"), 0644)
+
+	if listener, err := listenUnixSocket(path, "0660"); err == nil {
+		listener.Close()
+		t.Fatal("Expected the listener to fail on a file which is not a socket")
+	}
+
+	if content, err := os.ReadFile(path); err != nil || string(content) != "not a socket" {
+		t.Errorf("Expected the file to be left alone, got %q, %v", content, err)
+	}
+
+	removeUnixSocket(path)
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the file not to be removed as a socket, got %v", err)
+	}
+}
+
+func TestUnixSocketListenerRejectsInvalidPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "universum.sock")
+
+	if listener, err := listenUnixSocket(path, "rwx"); err == nil {
+		listener.Close()
+		t.Fatal("Expected the listener to fail on invalid permissions")
+	}
+}
+
+func TestUnixSocketListenerLeavesOnlyTheSocket(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "universum.sock")
+
+	listener, err := listenUnixSocket(path, "0600")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	// the private directory the socket was created in is gone
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 || entries[0].Name() != "universum.sock" {
+		t.Errorf("Expected only the socket to be left in %s, got %v", dir, entries)
+	}
+
+	listener.Close()
+	removeUnixSocket(path)
+
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket to be removed on shutdown, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"universum/config"
+	"universum/entity"
+	"universum/utils"
+)
+
+// CollectionStore is what the collection commands need of a store: reading a
+// record, and running a read-modify-write cycle over it under the write lock
+// of the store.
+type CollectionStore interface {
+	Get(key string) (entity.Record, uint32)
+
+	// UpdateCollection locks the key, runs MutateCollection over it, and then
+	// writes the value returned, removing the key when it is nil.
+	UpdateCollection(key string, family string, create bool, mutate func(entity.Record) (interface{}, uint32)) uint32
+}
+
+// Collections implements the hash, list, set and sorted set commands over the
+// read-modify-write cycle of a store, which embeds it.
+type Collections struct {
+	store CollectionStore
+}
+
+func NewCollections(store CollectionStore) Collections {
+	return Collections{store: store}
+}
+
+// getRecordOfFamily returns the live record stored against the key, or the
+// CRC_WRONG_TYPE code if the key holds a record of some other family.
+func getRecordOfFamily(store CollectionStore, key string, family string) (entity.Record, uint32) {
+	record, code := store.Get(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return nil, entity.CRC_RECORD_NOT_FOUND
+	}
+
+	if record.GetFamily() != family {
+		return nil, entity.CRC_WRONG_TYPE
+	}
+
+	return record, entity.CRC_RECORD_FOUND
+}
+
+// MutateCollection is the read-modify step of UpdateCollection, to be run with the
+// key locked. The mutation receives the current record, nil when the key does not
+// exist, and returns the new value along with CRC_RECORD_UPDATED, or any other code
+// to abort. It returns the value to write, a nil value removing the key, and the ttl
+// left to the existing record, which is carried over to the new value.
+func MutateCollection(store CollectionStore, key string, family string, create bool,
+	mutate func(entity.Record) (interface{}, uint32)) (interface{}, int64, uint32) {
+
+	record, code := getRecordOfFamily(store, key, family)
+	if code == entity.CRC_WRONG_TYPE {
+		return nil, 0, code
+	}
+
+	if record == nil && !create {
+		return nil, 0, entity.CRC_RECORD_NOT_FOUND
+	}
+
+	expiry := config.InfiniteExpiryTime
+	if record != nil {
+		expiry = record.GetExpiry()
+	}
+
+	value, code := mutate(record)
+	return value, expiry - utils.GetCurrentEPochTime(), code
+}
@@ -0,0 +1,23 @@
+package storage
+
+// SetCondition tells whether a conditional write goes ahead, depending on
+// whether the key holds a live record at the time of the write.
+type SetCondition uint8
+
+const (
+	SetAlways    SetCondition = iota // the write always goes ahead
+	SetIfAbsent                      // only when the key does not exist (NX)
+	SetIfPresent                     // only when the key already exists (XX)
+)
+
+// Allows tells whether the condition holds, given whether the key exists.
+func (condition SetCondition) Allows(exists bool) bool {
+	switch condition {
+	case SetIfAbsent:
+		return !exists
+	case SetIfPresent:
+		return exists
+	default:
+		return true
+	}
+}
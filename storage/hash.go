@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"sort"
+	"universum/config"
+	"universum/entity"
+	"universum/utils"
+)
+
+func (c Collections) HSet(key string, fields map[string]interface{}) (int64, uint32) {
+	for _, value := range fields {
+		if !utils.IsWriteableScalarDatatype(value) {
+			return 0, entity.CRC_INVALID_DATATYPE
+		}
+	}
+
+	var added int64 = 0
+	code := c.updateHash(key, true, func(hash entity.HashValue) uint32 {
+		for field, value := range fields {
+			if _, ok := hash[field]; !ok {
+				added++
+			}
+			hash[field] = value
+		}
+		return entity.CRC_RECORD_UPDATED
+	})
+
+	if code != entity.CRC_RECORD_UPDATED {
+		return 0, code
+	}
+
+	return added, entity.CRC_RECORD_UPDATED
+}
+
+func (c Collections) HGet(key string, field string) (interface{}, uint32) {
+	record, code := c.getHash(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return nil, code
+	}
+
+	value, ok := record.Value[field]
+	if !ok {
+		return nil, entity.CRC_FIELD_NOT_FOUND
+	}
+
+	return value, entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) HMGet(key string, fields []string) (map[string]interface{}, uint32) {
+	responseMap := make(map[string]interface{})
+
+	record, code := c.getHash(key)
+	if code == entity.CRC_WRONG_TYPE {
+		return responseMap, code
+	}
+
+	for _, field := range fields {
+		responseMap[field] = nil
+
+		if record != nil {
+			if value, ok := record.Value[field]; ok {
+				responseMap[field] = value
+			}
+		}
+	}
+
+	return responseMap, code
+}
+
+func (c Collections) HDel(key string, fields []string) (int64, uint32) {
+	var removed int64 = 0
+	code := c.updateHash(key, false, func(hash entity.HashValue) uint32 {
+		for _, field := range fields {
+			if _, ok := hash[field]; ok {
+				delete(hash, field)
+				removed++
+			}
+		}
+		return entity.CRC_RECORD_UPDATED
+	})
+
+	if code != entity.CRC_RECORD_UPDATED {
+		return 0, code
+	}
+
+	return removed, entity.CRC_RECORD_UPDATED
+}
+
+func (c Collections) HExists(key string, field string) (bool, uint32) {
+	record, code := c.getHash(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return false, code
+	}
+
+	if _, ok := record.Value[field]; !ok {
+		return false, entity.CRC_FIELD_NOT_FOUND
+	}
+
+	return true, entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) HLen(key string) (int64, uint32) {
+	record, code := c.getHash(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return 0, code
+	}
+
+	return int64(len(record.Value)), entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) HKeys(key string) ([]string, uint32) {
+	record, code := c.getHash(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return []string{}, code
+	}
+
+	fields := make([]string, 0, len(record.Value))
+	for field := range record.Value {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+	return fields, entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) HGetAll(key string) (map[string]interface{}, uint32) {
+	record, code := c.getHash(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return map[string]interface{}{}, code
+	}
+
+	return record.Value.Clone(), entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) HIncrBy(key string, field string, offset int64) (int64, uint32) {
+	var newValue int64 = config.InvalidNumericValue
+	code := c.updateHash(key, true, func(hash entity.HashValue) uint32 {
+		var oldValue int64 = 0
+
+		// the counters are int64, any other value of the field, integers of other
+		// kinds included, is not incremented
+		if current, ok := hash[field]; ok {
+			if oldValue, ok = current.(int64); !ok {
+				return entity.CRC_INCR_INVALID_TYPE
+			}
+		}
+
+		newValue = oldValue + offset
+		hash[field] = newValue
+		return entity.CRC_RECORD_UPDATED
+	})
+
+	if code != entity.CRC_RECORD_UPDATED {
+		return config.InvalidNumericValue, code
+	}
+
+	return newValue, entity.CRC_RECORD_UPDATED
+}
+
+// getHash returns the live hash record stored against the key, see getRecordOfFamily.
+func (c Collections) getHash(key string) (*entity.HashRecord, uint32) {
+	record, code := getRecordOfFamily(c.store, key, entity.RecordTypeHash)
+	if code != entity.CRC_RECORD_FOUND {
+		return nil, code
+	}
+
+	return record.(*entity.HashRecord), code
+}
+
+// updateHash applies the mutation to a copy of the hash stored against the key.
+// A missing hash is created only if asked for, and a hash left without fields is removed.
+func (c Collections) updateHash(key string, create bool, mutate func(entity.HashValue) uint32) uint32 {
+	return c.store.UpdateCollection(key, entity.RecordTypeHash, create, func(record entity.Record) (interface{}, uint32) {
+		hash := entity.HashValue{}
+		if record != nil {
+			hash = record.(*entity.HashRecord).Value.Clone()
+		}
+
+		code := mutate(hash)
+		if len(hash) == 0 {
+			return nil, code
+		}
+
+		return hash, code
+	})
+}
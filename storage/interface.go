@@ -20,6 +20,60 @@ type DataStore interface {
 	MDelete(keys []string) (map[string]interface{}, uint32)
 	TTL(key string) (int64, uint32)
 	Expire(key string, ttl int64) (bool, uint32)
+
+	SetWithCondition(key string, value interface{}, ttl int64, condition SetCondition) (entity.Record, uint32)
+	GetDelete(key string) (entity.Record, uint32)
+	CompareAndSwap(key string, expected interface{}, value interface{}) (bool, uint32)
+
+	HSet(key string, fields map[string]interface{}) (int64, uint32)
+	HGet(key string, field string) (interface{}, uint32)
+	HMGet(key string, fields []string) (map[string]interface{}, uint32)
+	HDel(key string, fields []string) (int64, uint32)
+	HExists(key string, field string) (bool, uint32)
+	HLen(key string) (int64, uint32)
+	HKeys(key string) ([]string, uint32)
+	HGetAll(key string) (map[string]interface{}, uint32)
+	HIncrBy(key string, field string, offset int64) (int64, uint32)
+
+	LPush(key string, values []interface{}) (int64, uint32)
+	RPush(key string, values []interface{}) (int64, uint32)
+	LPop(key string) (interface{}, uint32)
+	RPop(key string) (interface{}, uint32)
+	LRange(key string, start int64, stop int64) ([]interface{}, uint32)
+	LLen(key string) (int64, uint32)
+	LIndex(key string, index int64) (interface{}, uint32)
+	LTrim(key string, start int64, stop int64) (bool, uint32)
+
+	SAdd(key string, members []string) (int64, uint32)
+	SRem(key string, members []string) (int64, uint32)
+	SIsMember(key string, member string) (bool, uint32)
+	SMembers(key string) ([]string, uint32)
+	SCard(key string) (int64, uint32)
+	SInter(keys []string) ([]string, uint32)
+	SUnion(keys []string) ([]string, uint32)
+	SDiff(keys []string) ([]string, uint32)
+
+	ZAdd(key string, members map[string]float64) (int64, uint32)
+	ZRem(key string, members []string) (int64, uint32)
+	ZScore(key string, member string) (float64, uint32)
+	ZIncrBy(key string, member string, increment float64) (float64, uint32)
+	ZRange(key string, start int64, stop int64) ([]entity.ScoredMember, uint32)
+	ZRangeByScore(key string, min float64, max float64) ([]entity.ScoredMember, uint32)
+	ZRank(key string, member string) (int64, uint32)
+	ZCard(key string) (int64, uint32)
+
+	Scan(cursor string, options ScanOptions) (string, []string, uint32)
+	Range(start string, end string, limit int64, reverse bool) ([]*entity.RecordKV, uint32)
+	Prefix(prefix string, limit int64) ([]*entity.RecordKV, uint32)
+}
+
+// BatchWriter is implemented by the stores which log their writes for durability,
+// to have a group of writes, such as the commands of a transaction, logged together.
+// Writes made between BeginBatch and CommitBatch are applied right away, but only
+// become durable once the batch is committed, and are rolled back if it fails to.
+type BatchWriter interface {
+	BeginBatch()
+	CommitBatch() error
 }
 
 type SnapshotService interface {
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"universum/entity"
+	"universum/utils"
+)
+
+func (c Collections) LPush(key string, values []interface{}) (int64, uint32) {
+	return c.push(key, values, true)
+}
+
+func (c Collections) RPush(key string, values []interface{}) (int64, uint32) {
+	return c.push(key, values, false)
+}
+
+func (c Collections) LPop(key string) (interface{}, uint32) {
+	return c.pop(key, true)
+}
+
+func (c Collections) RPop(key string) (interface{}, uint32) {
+	return c.pop(key, false)
+}
+
+func (c Collections) LRange(key string, start int64, stop int64) ([]interface{}, uint32) {
+	record, code := c.getList(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return []interface{}{}, code
+	}
+
+	from, to := record.Value.Bounds(start, stop)
+	return []interface{}(record.Value[from:to].Clone()), entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) LLen(key string) (int64, uint32) {
+	record, code := c.getList(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return 0, code
+	}
+
+	return int64(len(record.Value)), entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) LIndex(key string, index int64) (interface{}, uint32) {
+	record, code := c.getList(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return nil, code
+	}
+
+	value, ok := record.Value.Index(index)
+	if !ok {
+		return nil, entity.CRC_INDEX_OUT_OF_RANGE
+	}
+
+	return value, entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) LTrim(key string, start int64, stop int64) (bool, uint32) {
+	code := c.updateList(key, false, func(list entity.ListValue) (entity.ListValue, uint32) {
+		from, to := list.Bounds(start, stop)
+		return list[from:to], entity.CRC_RECORD_UPDATED
+	})
+
+	return code == entity.CRC_RECORD_UPDATED, code
+}
+
+func (c Collections) push(key string, values []interface{}, atHead bool) (int64, uint32) {
+	for _, value := range values {
+		if !utils.IsWriteableScalarDatatype(value) {
+			return 0, entity.CRC_INVALID_DATATYPE
+		}
+	}
+
+	var length int64 = 0
+	code := c.updateList(key, true, func(list entity.ListValue) (entity.ListValue, uint32) {
+		if atHead {
+			list = list.PushHead(values...)
+		} else {
+			list = append(list, values...)
+		}
+
+		length = int64(len(list))
+		return list, entity.CRC_RECORD_UPDATED
+	})
+
+	if code != entity.CRC_RECORD_UPDATED {
+		return 0, code
+	}
+
+	return length, entity.CRC_RECORD_UPDATED
+}
+
+func (c Collections) pop(key string, atHead bool) (interface{}, uint32) {
+	var popped interface{}
+	code := c.updateList(key, false, func(list entity.ListValue) (entity.ListValue, uint32) {
+		if len(list) == 0 {
+			return list, entity.CRC_RECORD_NOT_FOUND
+		}
+
+		if atHead {
+			popped, list = list[0], list[1:]
+		} else {
+			popped, list = list[len(list)-1], list[:len(list)-1]
+		}
+		return list, entity.CRC_RECORD_UPDATED
+	})
+
+	if code != entity.CRC_RECORD_UPDATED {
+		return nil, code
+	}
+
+	return popped, entity.CRC_RECORD_FOUND
+}
+
+// getList returns the live list record stored against the key, see getRecordOfFamily.
+func (c Collections) getList(key string) (*entity.ListRecord, uint32) {
+	record, code := getRecordOfFamily(c.store, key, entity.RecordTypeList)
+	if code != entity.CRC_RECORD_FOUND {
+		return nil, code
+	}
+
+	return record.(*entity.ListRecord), code
+}
+
+// updateList applies the mutation to a copy of the list stored against the key.
+// A missing list is created only if asked for, and an emptied list is removed.
+func (c Collections) updateList(key string, create bool, mutate func(entity.ListValue) (entity.ListValue, uint32)) uint32 {
+	return c.store.UpdateCollection(key, entity.RecordTypeList, create, func(record entity.Record) (interface{}, uint32) {
+		list := entity.ListValue{}
+		if record != nil {
+			list = record.(*entity.ListRecord).Value.Clone()
+		}
+
+		list, code := mutate(list)
+		if len(list) == 0 {
+			return nil, code
+		}
+
+		return list, code
+	})
+}
@@ -0,0 +1,259 @@
+package lsm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"universum/config"
+	"universum/entity"
+	"universum/internal/logger"
+	"universum/storage/lsm/manifest"
+	"universum/storage/lsm/sstable"
+	"universum/utils/filesys"
+)
+
+const (
+	CheckpointDirPrefix string = "checkpoint-"
+
+	// checkpointTimeFormat names the checkpoints after the time they were taken
+	// at, in a fixed width so that they sort in the order they were taken.
+	checkpointTimeFormat string = "20060102T150405.000000000"
+
+	// PendingFlushTimeout bounds the wait for the memtables already handed to
+	// the flusher to be written out, before a checkpoint is given up.
+	PendingFlushTimeout = 30 * time.Second
+)
+
+var checkpointMutex sync.Mutex
+
+// Checkpoint writes a consistent copy of the store to a new directory within the
+// checkpoint directory, which a node can start from by using it as its data
+// directory. The SSTables are hard-linked into it, or copied when they are not on
+// the same file system, the records of the memtable are written to an SSTable of
+// their own, and a manifest lists all of them.
+//
+// Returns:
+// - int64: The number of records in the checkpoint, including the tombstones.
+// - int64: The size of the checkpoint on disk, in bytes.
+// - error: The error which prevented the checkpoint from being taken, if any.
+func (lsm *LSMStore) Checkpoint() (int64, int64, error) {
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+
+	checkpointRoot := config.Store.Storage.LSM.CheckpointDirectory
+	if err := os.MkdirAll(checkpointRoot, 0755); err != nil {
+		return 0, 0, fmt.Errorf("failed to create checkpoint directory: %v", err)
+	}
+
+	checkpointName := CheckpointDirPrefix + time.Now().UTC().Format(checkpointTimeFormat)
+	checkpointDir := filepath.Join(checkpointRoot, checkpointName)
+	tempCheckpointDir := checkpointDir + ".tmp"
+
+	if err := os.Mkdir(tempCheckpointDir, 0755); err != nil {
+		return 0, 0, fmt.Errorf("failed to create temporary checkpoint directory: %v", err)
+	}
+
+	recordCount, sizeInBytes, err := lsm.writeCheckpoint(tempCheckpointDir)
+	if err != nil {
+		os.RemoveAll(tempCheckpointDir)
+		return 0, 0, err
+	}
+
+	if err := os.Rename(tempCheckpointDir, checkpointDir); err != nil {
+		os.RemoveAll(tempCheckpointDir)
+		return 0, 0, fmt.Errorf("failed to move checkpoint in place: %v", err)
+	}
+
+	if err := pruneCheckpoints(checkpointRoot, config.Store.Storage.LSM.MaxCheckpoints); err != nil {
+		logger.Get().Error("Failed to remove the old checkpoints: %v", err)
+	}
+
+	logger.Get().Info("Checkpoint %s taken with %d records, size=%d bytes", checkpointDir, recordCount, sizeInBytes)
+	return recordCount, sizeInBytes, nil
+}
+
+// writeCheckpoint links the live SSTables into the directory and writes out the
+// memtable along with the manifest. The writes are only held back while the
+// SSTables and the memtable are captured, so that both are of the same point in
+// time, and the compactions until the SSTables are linked, for them not to be
+// deleted in the meantime.
+func (lsm *LSMStore) writeCheckpoint(directory string) (int64, int64, error) {
+	liveSSTables, memtableRecords, err := lsm.captureCheckpoint(PendingFlushTimeout)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var linkErr error
+	for _, sst := range liveSSTables {
+		if linkErr = linkOrCopyFile(sst.Path(), filepath.Join(directory, filepath.Base(sst.Path()))); linkErr != nil {
+			break
+		}
+	}
+	lsm.compactMu.Unlock()
+
+	if linkErr != nil {
+		return 0, 0, fmt.Errorf("failed to link SSTable into checkpoint: %v", linkErr)
+	}
+
+	entries := make([]*manifest.Entry, 0, len(liveSSTables)+1)
+	var recordCount int64 = 0
+
+	if len(memtableRecords) > 0 {
+		maxRecords := config.Store.Storage.LSM.BloomFilterMaxRecords
+		fpRate := config.Store.Storage.LSM.BloomFalsePositiveRate
+
+		sst, err := sstable.NewSSTableInDirectory(directory, generateSSTableFileName(), sstable.SSTmodeWrite, maxRecords, fpRate)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to create SSTable for the memtable: %v", err)
+		}
+
+		err = sst.FlushRecordsToSSTable(memtableRecords)
+		sst.Close()
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to write the memtable to SSTable: %v", err)
+		}
+
+		// the memtable holds the most recent records, its SSTable goes first
+		liveSSTables = append([]*sstable.SSTable{sst}, liveSSTables...)
+	}
+
+	for _, sst := range liveSSTables {
+		entries = append(entries, manifestEntry(manifest.OpAdd, sst))
+		recordCount += sst.Metadata.NumRecords
+	}
+
+	manifestWriter, err := manifest.Create(filepath.Join(directory, manifest.FileName))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer manifestWriter.Close()
+
+	if err := manifestWriter.Append(entries...); err != nil {
+		return 0, 0, err
+	}
+
+	sizeInBytes, err := getDirectorySizeInBytes(directory)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get checkpoint size: %v", err)
+	}
+
+	return recordCount, sizeInBytes, nil
+}
+
+// captureCheckpoint returns the live SSTables along with the records of the
+// memtable, once no memtable is left to be flushed. The pending flushes are
+// waited for without holding the writes back, and waited for again should a
+// memtable be handed to the flusher before the writes are. It returns with the
+// compactions held back, for the caller to release once the SSTables are linked.
+func (lsm *LSMStore) captureCheckpoint(timeout time.Duration) ([]*sstable.SSTable, []*entity.RecordKV, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		if err := lsm.waitForPendingFlushes(time.Until(deadline)); err != nil {
+			return nil, nil, err
+		}
+
+		lsm.writeMu.Lock()
+		if lsm.pendingFlushes.Load() == 0 {
+			break
+		}
+		lsm.writeMu.Unlock()
+	}
+	defer lsm.writeMu.Unlock()
+
+	lsm.flusherMu.Lock()
+	defer lsm.flusherMu.Unlock()
+
+	lsm.compactMu.Lock()
+
+	liveSSTables := make([]*sstable.SSTable, len(lsm.sstables))
+	copy(liveSSTables, lsm.sstables)
+
+	return liveSSTables, lsm.memTable.GetAll(), nil
+}
+
+// waitForPendingFlushes waits for the memtables handed to the flusher to be
+// written out to their SSTables, for the SSTables not to miss their records. It
+// fails right away once the flusher has stopped, and tells whether a flush was
+// still in progress when it times out.
+func (lsm *LSMStore) waitForPendingFlushes(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for pending := lsm.pendingFlushes.Load(); pending > 0; pending = lsm.pendingFlushes.Load() {
+		if err := lsm.flusherErr.Load(); err != nil {
+			return fmt.Errorf("%d memtable flushes left pending, the flusher stopped: %v", pending, *err)
+		}
+
+		if time.Now().After(deadline) {
+			if startedAt := lsm.flushStartedAt.Load(); startedAt != 0 {
+				return fmt.Errorf("timed out after %v waiting for %d memtable flushes, the flush in progress started %v ago",
+					timeout, pending, time.Since(time.Unix(0, startedAt)).Round(time.Millisecond))
+			}
+			return fmt.Errorf("timed out after %v waiting for %d memtable flushes, the flusher has not taken them", timeout, pending)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return nil
+}
+
+// linkOrCopyFile hard-links the file, the SSTables never being written to once
+// flushed, and falls back to copying it, such as across file systems.
+func linkOrCopyFile(src string, dest string) error {
+	if err := os.Link(src, dest); err == nil {
+		return nil
+	}
+	return filesys.AtomicCopyFileContent(src, dest)
+}
+
+func getDirectorySizeInBytes(directory string) (int64, error) {
+	files, err := os.ReadDir(directory)
+	if err != nil {
+		return 0, err
+	}
+
+	var sizeInBytes int64 = 0
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil {
+			return 0, err
+		}
+		sizeInBytes += info.Size()
+	}
+
+	return sizeInBytes, nil
+}
+
+// pruneCheckpoints removes the oldest checkpoints, keeping the given number of
+// the most recent ones.
+func pruneCheckpoints(checkpointRoot string, keep int64) error {
+	files, err := os.ReadDir(checkpointRoot)
+	if err != nil {
+		return err
+	}
+
+	checkpoints := make([]string, 0)
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() && strings.HasPrefix(name, CheckpointDirPrefix) && !strings.HasSuffix(name, ".tmp") {
+			checkpoints = append(checkpoints, name)
+		}
+	}
+
+	if int64(len(checkpoints)) <= keep {
+		return nil
+	}
+
+	sort.Strings(checkpoints)
+	for _, name := range checkpoints[:int64(len(checkpoints))-keep] {
+		if err := os.RemoveAll(filepath.Join(checkpointRoot, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package lsm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"universum/config"
+	"universum/entity"
+	"universum/storage/lsm/manifest"
+)
+
+func TestCheckpointCanBeStartedFrom(t *testing.T) {
+	store := setupTestStore(t)
+	config.Store.Storage.LSM.CheckpointDirectory = t.TempDir()
+	config.Store.Storage.LSM.MaxCheckpoints = 2
+
+	for i := 0; i < 100; i++ {
+		store.Set(fmt.Sprintf("flushed-%d", i), "value", 6000)
+	}
+	store.memTable.Truncate()
+
+	for i := 0; i < 10; i++ {
+		store.Set(fmt.Sprintf("unflushed-%d", i), "value", 6000)
+	}
+	store.Delete("flushed-0")
+
+	recordCount, sizeInBytes, err := store.Checkpoint()
+	if err != nil {
+		t.Fatalf("Failed to take the checkpoint: %v", err)
+	}
+
+	if recordCount != 111 || sizeInBytes <= 0 {
+		t.Errorf("Expected 111 records in the checkpoint, got %d records of %d bytes", recordCount, sizeInBytes)
+	}
+
+	checkpoints, _ := filepath.Glob(filepath.Join(config.Store.Storage.LSM.CheckpointDirectory, CheckpointDirPrefix+"*"))
+	if len(checkpoints) != 1 {
+		t.Fatalf("Expected a single checkpoint directory, got %v", checkpoints)
+	}
+
+	entries, err := manifest.Read(filepath.Join(checkpoints[0], manifest.FileName))
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Expected the manifest to list the two SSTables, got %v, %v", entries, err)
+	}
+
+	// a fresh node started from the checkpoint
+	config.Store.Storage.LSM.DataStorageDirectory = checkpoints[0]
+	config.Store.Storage.LSM.WriteAheadLogDirectory = t.TempDir()
+
+	restored := CreateNewLSMStore(config.MemtableStorageTypeLB)
+	if err := restored.Initialize(); err != nil {
+		t.Fatalf("Failed to start from the checkpoint: %v", err)
+	}
+
+	for _, key := range []string{"flushed-1", "flushed-99", "unflushed-0", "unflushed-9"} {
+		if exists, code := restored.Exists(key); !exists || code != entity.CRC_RECORD_FOUND {
+			t.Errorf("Expected %s to be in the checkpoint, got code %d", key, code)
+		}
+	}
+
+	if exists, _ := restored.Exists("flushed-0"); exists {
+		t.Errorf("Expected the deleted key not to be in the checkpoint")
+	}
+}
+
+func TestCheckpointsArePruned(t *testing.T) {
+	store := setupTestStore(t)
+	config.Store.Storage.LSM.CheckpointDirectory = t.TempDir()
+	config.Store.Storage.LSM.MaxCheckpoints = 2
+
+	for i := 0; i < 3; i++ {
+		store.Set(fmt.Sprintf("key-%d", i), "value", 0)
+		if _, _, err := store.Checkpoint(); err != nil {
+			t.Fatalf("Failed to take checkpoint #%d: %v", i+1, err)
+		}
+	}
+
+	checkpoints, err := os.ReadDir(config.Store.Storage.LSM.CheckpointDirectory)
+	if err != nil || len(checkpoints) != 2 {
+		t.Fatalf("Expected the two most recent checkpoints to be kept, got %d, %v", len(checkpoints), err)
+	}
+
+	entries, err := manifest.Read(filepath.Join(config.Store.Storage.LSM.CheckpointDirectory, checkpoints[1].Name(), manifest.FileName))
+	if err != nil || len(entries) != 1 || entries[0].NumRecords != 3 {
+		t.Errorf("Expected the latest checkpoint to hold the three records, got %v, %v", entries, err)
+	}
+}
+
+func TestCheckpointDoesNotHoldWritesBackWhileFlushesArePending(t *testing.T) {
+	store := setupTestStore(t)
+	config.Store.Storage.LSM.CheckpointDirectory = t.TempDir()
+	config.Store.Storage.LSM.MaxCheckpoints = 2
+
+	// a memtable handed to the flusher, and not written out yet
+	store.pendingFlushes.Add(1)
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := store.Checkpoint()
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	written := make(chan uint32, 1)
+	go func() {
+		_, code := store.Set("written-during-checkpoint", "value", 0)
+		written <- code
+	}()
+
+	select {
+	case code := <-written:
+		if code != entity.CRC_RECORD_UPDATED {
+			t.Errorf("Expected the write to succeed, got code %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the write not to wait for the checkpoint")
+	}
+
+	store.pendingFlushes.Add(-1)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Failed to take the checkpoint: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the checkpoint to be taken once the flush is done")
+	}
+
+	checkpoints, _ := filepath.Glob(filepath.Join(config.Store.Storage.LSM.CheckpointDirectory, CheckpointDirPrefix+"*"))
+	if len(checkpoints) != 1 {
+		t.Errorf("Expected a single checkpoint directory, got %v", checkpoints)
+	}
+}
+
+func TestCheckpointFailsOnceFlusherHasStopped(t *testing.T) {
+	store := setupTestStore(t)
+	config.Store.Storage.LSM.CheckpointDirectory = t.TempDir()
+
+	// the flusher stopped on a memtable it failed to flush, leaving the next one
+	flushErr := errors.New("disk full")
+	store.flusherErr.Store(&flushErr)
+	store.pendingFlushes.Add(1)
+
+	start := time.Now()
+	_, _, err := store.Checkpoint()
+	if err == nil || !strings.Contains(err.Error(), "flusher stopped: disk full") {
+		t.Fatalf("Expected the checkpoint to fail on the stopped flusher, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the checkpoint to fail right away, it took %v", elapsed)
+	}
+
+	// the other stores are not held back by it
+	other := setupTestStore(t)
+	config.Store.Storage.LSM.CheckpointDirectory = t.TempDir()
+
+	if _, _, err := other.Checkpoint(); err != nil {
+		t.Errorf("Expected the checkpoint of another store to be taken, got %v", err)
+	}
+}
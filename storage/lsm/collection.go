@@ -0,0 +1,38 @@
+package lsm
+
+import (
+	"universum/entity"
+	"universum/storage"
+)
+
+// UpdateCollection runs the read-modify-write cycle of the collection commands over
+// the key, under the store write lock, and then waits for the write to be durable.
+func (lsm *LSMStore) UpdateCollection(key string, family string, create bool,
+	mutate func(entity.Record) (interface{}, uint32)) uint32 {
+
+	code := lsm.mutateCollection(key, family, create, mutate)
+	if code == entity.CRC_RECORD_UPDATED && lsm.awaitDurability() != nil {
+		return entity.CRC_WAL_WRITE_FAILED
+	}
+	return code
+}
+
+func (lsm *LSMStore) mutateCollection(key string, family string, create bool,
+	mutate func(entity.Record) (interface{}, uint32)) uint32 {
+
+	lsm.writeMu.Lock()
+	defer lsm.writeMu.Unlock()
+
+	value, ttl, code := storage.MutateCollection(lsm, key, family, create, mutate)
+	if code != entity.CRC_RECORD_UPDATED {
+		return code
+	}
+
+	if value == nil {
+		lsm.delete(key)
+		return entity.CRC_RECORD_UPDATED
+	}
+
+	_, code = lsm.set(key, value, ttl)
+	return code
+}
@@ -2,10 +2,9 @@ package compaction
 
 import (
 	"fmt"
-	"path/filepath"
-	"strconv"
-	"strings"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 	"universum/config"
 	"universum/entity"
@@ -23,12 +22,28 @@ type Compactor struct {
 	compactionMu  sync.Mutex                   // Concurrency safety for compacting
 	additionMu    sync.Mutex                   // Concurrency safety for adding SSTables
 	MaxLevel      int64                        // Maximum number of levels
+
+	replacementChan chan *SSTReplacement // Where the compactions are sent on, see CompactLevel
+
+	compactions atomic.Int64 // Levels compacted so far
+	failures    atomic.Int64 // Compactions which failed
+	duration    atomic.Int64 // Time spent compacting, in nanoseconds
+}
+
+// Statistics is a point in time view of the compactions done so far.
+type Statistics struct {
+	Compactions int64
+	Failures    int64
+	Duration    time.Duration
 }
 
-func NewCompactor() *Compactor {
+// NewCompactor creates a compactor sending the compactions it does on the given
+// channel, for the LSM store listening to it to apply them.
+func NewCompactor(replacementChan chan *SSTReplacement) *Compactor {
 	return &Compactor{
-		LevelSSTables: make(map[int64][]*sstable.SSTable),
-		MaxLevel:      int64(DefaultMaxLevel),
+		LevelSSTables:   make(map[int64][]*sstable.SSTable),
+		MaxLevel:        int64(DefaultMaxLevel),
+		replacementChan: replacementChan,
 	}
 }
 
@@ -49,7 +64,7 @@ func (c *Compactor) Compact() {
 
 	for {
 		for level := int64(0); level < c.MaxLevel; level++ {
-			if len(c.LevelSSTables[level]) >= int(CompactionThresholdPerLevel) {
+			if len(c.getLevelSSTables(level)) >= int(CompactionThresholdPerLevel) {
 				c.CompactLevel(level)
 			}
 
@@ -58,52 +73,92 @@ func (c *Compactor) Compact() {
 	}
 }
 
+// CompactLevel merges the SSTables of the level, along with the ones of the next
+// level their keys overlap, into a single SSTable of the next level. The merged
+// SSTable and the ones it replaces are sent on the replacement channel of the
+// compactor, and it is up to the receiver to record the replacement and delete
+// the replaced SSTables.
 func (c *Compactor) CompactLevel(level int64) error {
 	c.compactionMu.Lock()
 	defer c.compactionMu.Unlock()
 
-	if _, ok := c.LevelSSTables[level]; !ok {
-		return nil // No SSTables to compact
-	}
-
-	sstablesToCompact := c.LevelSSTables[level]
+	sstablesToCompact := c.getLevelSSTables(level)
 	if len(sstablesToCompact) < int(CompactionThresholdPerLevel) {
 		return nil // Not enough SSTables to compact
 	}
 
+	startTime := time.Now()
+	defer func() {
+		c.duration.Add(int64(time.Since(startTime)))
+	}()
+
+	targetLevel := level + 1
+	if targetLevel >= c.MaxLevel {
+		targetLevel = level // cannot go above maxlevel, so add at the same level
+	}
+
 	overlappingSSTs := c.getOverlappingSSTables(level+1, sstablesToCompact)
 	mergedSST, err := c.mergeSSTables(sstablesToCompact, overlappingSSTs)
 	if err != nil {
+		c.failures.Add(1)
 		logger.Get().Error("SSTable compaction failed: %v", err)
 		return err
 	}
+	mergedSST.Metadata.CompactionLevel = targetLevel
+	c.compactions.Add(1)
+
+	c.removeSSTables(level, sstablesToCompact)
+	c.removeSSTables(level+1, overlappingSSTs)
+	c.AddSSTable(targetLevel, mergedSST)
 
-	SSTReplacementChan <- &SSTReplacement{
-		Obsoletes:  sstablesToCompact,
+	c.replacementChan <- &SSTReplacement{
+		Obsoletes:  append(sstablesToCompact, overlappingSSTs...),
 		Substitute: mergedSST,
+		Level:      targetLevel,
 	}
 
-	time.Sleep(10 * time.Microsecond) // give consumer some time to process the notification
+	return nil
+}
 
-	c.LevelSSTables[level] = nil
+// getLevelSSTables returns a copy of the SSTables of the level, in the order they
+// were added.
+func (c *Compactor) getLevelSSTables(level int64) []*sstable.SSTable {
+	c.additionMu.Lock()
+	defer c.additionMu.Unlock()
 
-	if level+1 >= c.MaxLevel {
-		c.AddSSTable(level, mergedSST) // cannot go above maxlevel, so add at the same level
-	} else {
-		c.AddSSTable(level+1, mergedSST)
-	}
+	return append([]*sstable.SSTable(nil), c.LevelSSTables[level]...)
+}
 
-	err = c.deleteOldSSTables(sstablesToCompact)
-	if err != nil {
-		logger.Get().Error("Failed to clean obsolete sstables post compaction: %v", err)
-		return err
+// removeSSTables removes the SSTables from the level, keeping the ones added to
+// it since they were taken for compaction.
+func (c *Compactor) removeSSTables(level int64, sstables []*sstable.SSTable) {
+	c.additionMu.Lock()
+	defer c.additionMu.Unlock()
+
+	remaining := make([]*sstable.SSTable, 0, len(c.LevelSSTables[level]))
+	for _, sst := range c.LevelSSTables[level] {
+		if !slices.Contains(sstables, sst) {
+			remaining = append(remaining, sst)
+		}
 	}
+	c.LevelSSTables[level] = remaining
+}
 
-	return nil
+// GetStatistics returns the number of compactions done and failed so far, and
+// the time spent on them.
+func (c *Compactor) GetStatistics() Statistics {
+	return Statistics{
+		Compactions: c.compactions.Load(),
+		Failures:    c.failures.Load(),
+		Duration:    time.Duration(c.duration.Load()),
+	}
 }
 
+// mergeSSTables merges the records of the SSTables, the ones of the SSTables
+// given last winning over the others for the same keys. The SSTables of the next
+// level hold older records than the ones compacted, so they are merged first.
 func (c *Compactor) mergeSSTables(sstables, overlapping []*sstable.SSTable) (*sstable.SSTable, error) {
-	allSSTables := append(sstables, overlapping...)
+	allSSTables := append(append([]*sstable.SSTable(nil), overlapping...), sstables...)
 	mergedList := make([]*entity.RecordKV, 0)
 
 	for _, sstable := range allSSTables {
@@ -123,10 +178,7 @@ func (c *Compactor) mergeSSTables(sstables, overlapping []*sstable.SSTable) (*ss
 		compactedMergedList = append(compactedMergedList, recordKV)
 	}
 
-	newSSTFileName, err := c.getMergedSSTFileName(sstables)
-	if err != nil {
-		return nil, err
-	}
+	newSSTFileName := c.getMergedSSTFileName()
 
 	mergedSST, err := sstable.NewSSTable(
 		newSSTFileName,
@@ -147,38 +199,21 @@ func (c *Compactor) mergeSSTables(sstables, overlapping []*sstable.SSTable) (*ss
 	return mergedSST, nil
 }
 
-func (c *Compactor) getMergedSSTFileName(sstables []*sstable.SSTable) (string, error) {
-	var min int64 = 1<<63 - 1
-
-	for _, sst := range sstables {
-		filenamestr := strings.TrimSuffix(filepath.Base(sst.Filename), filepath.Ext(filepath.Base(sst.Filename)))
-		filenameInt, err := strconv.ParseInt(filenamestr, 10, 64)
-
-		if err != nil {
-			return "", fmt.Errorf("failed to parse SST filename %s to int64: %v", sst.Filename, err)
-		}
-
-		if filenameInt < min {
-			min = filenameInt
-		}
-	}
-
-	return fmt.Sprintf("%d.%s", min, sstable.SstFileExtension), nil
+// getMergedSSTFileName names the merged SSTable after the time it is created at,
+// as the flushed ones are, never reusing the name of an SSTable it replaces since
+// those stay live until the replacement is recorded.
+func (c *Compactor) getMergedSSTFileName() string {
+	return fmt.Sprintf("%d.%s", time.Now().UnixNano(), sstable.SstFileExtension)
 }
 
 func (c *Compactor) getOverlappingSSTables(nextLevel int64, sstables []*sstable.SSTable) []*sstable.SSTable {
 	overlappingSSTables := []*sstable.SSTable{}
 
-	if _, ok := c.LevelSSTables[nextLevel]; !ok {
-		return overlappingSSTables // No SSTables at the next level
-	}
-
-	nextLevelSSTables := c.LevelSSTables[nextLevel]
-
-	for _, sst := range sstables {
-		for _, nextSST := range nextLevelSSTables {
+	for _, nextSST := range c.getLevelSSTables(nextLevel) {
+		for _, sst := range sstables {
 			if c.doesOverlap(sst, nextSST) {
 				overlappingSSTables = append(overlappingSSTables, nextSST)
+				break // each SSTable of the next level is merged once
 			}
 		}
 	}
@@ -194,13 +229,3 @@ func (c *Compactor) doesOverlap(sst1, sst2 *sstable.SSTable) bool {
 
 	return !(sst1LastKey < sst2FirstKey || sst2LastKey < sst1FirstKey)
 }
-
-func (c *Compactor) deleteOldSSTables(sstables []*sstable.SSTable) error {
-	for _, sst := range sstables {
-		err := sst.DeleteFromDisk()
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
@@ -2,6 +2,7 @@ package compaction
 
 import (
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -33,7 +34,7 @@ func createDummySSTable(id int64, records []*entity.RecordKV) *sstable.SSTable {
 
 func TestAddSSTable(t *testing.T) {
 	setupConfig(t)
-	compactor := NewCompactor()
+	compactor := NewCompactor(nil)
 
 	records := []*entity.RecordKV{
 		{Key: "key1", Record: &entity.ScalarRecord{Value: "value1"}},
@@ -49,7 +50,8 @@ func TestAddSSTable(t *testing.T) {
 
 func TestCompactLevel(t *testing.T) {
 	setupConfig(t)
-	compactor := NewCompactor()
+	replacementChan := make(chan *SSTReplacement, 1)
+	compactor := NewCompactor(replacementChan)
 
 	records1 := []*entity.RecordKV{
 		{Key: "key1", Record: &entity.ScalarRecord{Value: "value1"}},
@@ -71,9 +73,8 @@ func TestCompactLevel(t *testing.T) {
 	compactor.AddSSTable(0, sst2)
 	compactor.AddSSTable(0, sst3)
 
-	SSTReplacementChan = make(chan *SSTReplacement, 1)
 	go func() {
-		for range SSTReplacementChan {
+		for range replacementChan {
 			time.Sleep(10 * time.Microsecond)
 		}
 	}()
@@ -92,25 +93,25 @@ func TestCompactLevel(t *testing.T) {
 
 func TestMergeSSTables(t *testing.T) {
 	setupConfig(t)
-	compactor := NewCompactor()
+	compactor := NewCompactor(nil)
 
 	futureTime := time.Now().Unix() + 1000
 	pastTime := time.Now().Unix() - 1000
 
 	records1 := []*entity.RecordKV{
-		{Key: "key1", Record: &entity.ScalarRecord{Value: "value1", Expiry: pastTime, State: entity.RecordStateActive}},
-		{Key: "key2", Record: &entity.ScalarRecord{Value: "value2", Expiry: futureTime, State: entity.RecordStateActive}},
-		{Key: "key3", Record: &entity.ScalarRecord{Value: "value3", Expiry: futureTime, State: entity.RecordStateActive}},
-		{Key: "key4", Record: &entity.ScalarRecord{Value: "value4", Expiry: futureTime, State: entity.RecordStateTombstoned}},
-		{Key: "key5", Record: &entity.ScalarRecord{Value: "value5", Expiry: futureTime, State: entity.RecordStateActive}},
-		{Key: "key8", Record: &entity.ScalarRecord{Value: "value8", Expiry: pastTime, State: entity.RecordStateActive}},
+		{Key: "key1", Record: entity.NewRecord("value1", 0, pastTime, entity.RecordStateActive)},
+		{Key: "key2", Record: entity.NewRecord("value2", 0, futureTime, entity.RecordStateActive)},
+		{Key: "key3", Record: entity.NewRecord("value3", 0, futureTime, entity.RecordStateActive)},
+		{Key: "key4", Record: entity.NewRecord("value4", 0, futureTime, entity.RecordStateTombstoned)},
+		{Key: "key5", Record: entity.NewRecord("value5", 0, futureTime, entity.RecordStateActive)},
+		{Key: "key8", Record: entity.NewRecord("value8", 0, pastTime, entity.RecordStateActive)},
 	}
 	records2 := []*entity.RecordKV{
-		{Key: "key2", Record: &entity.ScalarRecord{Value: "value2", Expiry: pastTime, State: entity.RecordStateActive}},
-		{Key: "key5", Record: &entity.ScalarRecord{Value: "value5+1", Expiry: futureTime, State: entity.RecordStateActive}},
-		{Key: "key6", Record: &entity.ScalarRecord{Value: "value6", Expiry: futureTime, State: entity.RecordStateActive}},
-		{Key: "key7", Record: &entity.ScalarRecord{Value: "value7", Expiry: futureTime, State: entity.RecordStateActive}},
-		{Key: "key8", Record: &entity.ScalarRecord{Value: "value8+1", Expiry: futureTime, State: entity.RecordStateActive}},
+		{Key: "key2", Record: entity.NewRecord("value2", 0, pastTime, entity.RecordStateActive)},
+		{Key: "key5", Record: entity.NewRecord("value5+1", 0, futureTime, entity.RecordStateActive)},
+		{Key: "key6", Record: entity.NewRecord("value6", 0, futureTime, entity.RecordStateActive)},
+		{Key: "key7", Record: entity.NewRecord("value7", 0, futureTime, entity.RecordStateActive)},
+		{Key: "key8", Record: entity.NewRecord("value8+1", 0, futureTime, entity.RecordStateActive)},
 	}
 
 	sst1 := createDummySSTable(1, records1)
@@ -127,11 +128,11 @@ func TestMergeSSTables(t *testing.T) {
 	}
 
 	expectedMergedList := []*entity.RecordKV{
-		{Key: "key3", Record: &entity.ScalarRecord{Value: "value3", Expiry: futureTime, State: entity.RecordStateActive}},
-		{Key: "key5", Record: &entity.ScalarRecord{Value: "value5+1", Expiry: futureTime, State: entity.RecordStateActive}},
-		{Key: "key6", Record: &entity.ScalarRecord{Value: "value6", Expiry: futureTime, State: entity.RecordStateActive}},
-		{Key: "key7", Record: &entity.ScalarRecord{Value: "value7", Expiry: futureTime, State: entity.RecordStateActive}},
-		{Key: "key8", Record: &entity.ScalarRecord{Value: "value8+1", Expiry: futureTime, State: entity.RecordStateActive}},
+		{Key: "key3", Record: entity.NewRecord("value3", 0, futureTime, entity.RecordStateActive)},
+		{Key: "key5", Record: entity.NewRecord("value5+1", 0, futureTime, entity.RecordStateActive)},
+		{Key: "key6", Record: entity.NewRecord("value6", 0, futureTime, entity.RecordStateActive)},
+		{Key: "key7", Record: entity.NewRecord("value7", 0, futureTime, entity.RecordStateActive)},
+		{Key: "key8", Record: entity.NewRecord("value8+1", 0, futureTime, entity.RecordStateActive)},
 	}
 
 	if !reflect.DeepEqual(mergedRecords, expectedMergedList) {
@@ -141,7 +142,7 @@ func TestMergeSSTables(t *testing.T) {
 
 func TestGetOverlappingSSTables(t *testing.T) {
 	setupConfig(t)
-	compactor := NewCompactor()
+	compactor := NewCompactor(nil)
 
 	records1 := []*entity.RecordKV{
 		createRecordKV("key1", 1),
@@ -175,49 +176,22 @@ func TestGetOverlappingSSTables(t *testing.T) {
 	}
 }
 
-func TestDeleteOldSSTables(t *testing.T) {
-	setupConfig(t)
-	compactor := NewCompactor()
-
-	records := []*entity.RecordKV{
-		{Key: "key1", Record: &entity.ScalarRecord{Value: "value1"}},
-	}
-	sst1 := createDummySSTable(1, records)
-	sst2 := createDummySSTable(2, records)
-
-	compactor.AddSSTable(0, sst1)
-	compactor.AddSSTable(0, sst2)
-
-	err := compactor.deleteOldSSTables([]*sstable.SSTable{sst1, sst2})
-	if err != nil {
-		t.Errorf("Failed to delete old SSTables: %v", err)
-	}
-
-	_, err = sst1.GetAllRecords()
-	if err == nil {
-		t.Errorf("Expected SSTable %s to be deleted", sst1.Filename)
-	}
-
-	_, err = sst2.GetAllRecords()
-	if err == nil {
-		t.Errorf("Expected SSTable %s to be deleted", sst2.Filename)
-	}
-}
-
 func TestGetMergedSSTFileName(t *testing.T) {
 	setupConfig(t)
-	compactor := NewCompactor()
+	compactor := NewCompactor(nil)
 
 	sst1 := createDummySSTable(123456, nil)
 	sst2 := createDummySSTable(654321, nil)
 
-	mergedFileName, err := compactor.getMergedSSTFileName([]*sstable.SSTable{sst1, sst2})
-	if err != nil {
-		t.Errorf("Failed to generate merged SST file name: %v", err)
+	mergedFileName := compactor.getMergedSSTFileName()
+
+	for _, sst := range []*sstable.SSTable{sst1, sst2} {
+		if mergedFileName == sst.Filename {
+			t.Errorf("Expected the merged SST file name not to reuse %s", sst.Filename)
+		}
 	}
 
-	expectedFileName := fmt.Sprintf("%d.%s", 123456, sstable.SstFileExtension)
-	if mergedFileName != expectedFileName {
-		t.Errorf("Expected %s, got %s", expectedFileName, mergedFileName)
+	if filepath.Ext(mergedFileName) != "."+sstable.SstFileExtension {
+		t.Errorf("Expected an SST file name, got %s", mergedFileName)
 	}
 }
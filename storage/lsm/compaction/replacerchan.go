@@ -2,9 +2,10 @@ package compaction
 
 import "universum/storage/lsm/sstable"
 
+// SSTReplacement notifies of a compaction, the Substitute SSTable at the given
+// Level replacing the Obsoletes ones.
 type SSTReplacement struct {
 	Obsoletes  []*sstable.SSTable
 	Substitute *sstable.SSTable
+	Level      int64
 }
-
-var SSTReplacementChan chan *SSTReplacement
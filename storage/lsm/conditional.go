@@ -0,0 +1,100 @@
+package lsm
+
+import (
+	"reflect"
+	"universum/entity"
+	"universum/storage"
+	"universum/utils"
+)
+
+// SetWithCondition writes the value if the condition holds for the key, and returns
+// the record it replaced, nil if the key did not exist. The check and the write are
+// made under the store write lock, so no other write of the key can come in between.
+func (lsm *LSMStore) SetWithCondition(key string, value interface{}, ttl int64,
+	condition storage.SetCondition) (entity.Record, uint32) {
+
+	previous, code := lsm.setWithCondition(key, value, ttl, condition)
+	if code == entity.CRC_RECORD_UPDATED && lsm.awaitDurability() != nil {
+		return previous, entity.CRC_WAL_WRITE_FAILED
+	}
+	return previous, code
+}
+
+func (lsm *LSMStore) setWithCondition(key string, value interface{}, ttl int64,
+	condition storage.SetCondition) (entity.Record, uint32) {
+
+	lsm.writeMu.Lock()
+	defer lsm.writeMu.Unlock()
+
+	previous, code := lsm.Get(key)
+	if code != entity.CRC_RECORD_FOUND {
+		previous = nil
+	}
+
+	if !condition.Allows(previous != nil) {
+		return previous, entity.CRC_CONDITION_NOT_MET
+	}
+
+	if didSet, code := lsm.set(key, value, ttl); !didSet {
+		return previous, code
+	}
+
+	return previous, entity.CRC_RECORD_UPDATED
+}
+
+// GetDelete removes the key, and returns the record it held.
+func (lsm *LSMStore) GetDelete(key string) (entity.Record, uint32) {
+	previous, code := lsm.getDelete(key)
+	if code == entity.CRC_RECORD_DELETED && lsm.awaitDurability() != nil {
+		return nil, entity.CRC_WAL_WRITE_FAILED
+	}
+	return previous, code
+}
+
+func (lsm *LSMStore) getDelete(key string) (entity.Record, uint32) {
+	lsm.writeMu.Lock()
+	defer lsm.writeMu.Unlock()
+
+	previous, code := lsm.Get(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return nil, entity.CRC_RECORD_NOT_FOUND
+	}
+
+	if deleted, code := lsm.delete(key); !deleted {
+		return nil, code
+	}
+
+	return previous, entity.CRC_RECORD_DELETED
+}
+
+// CompareAndSwap replaces the scalar value stored against the key with the new
+// value, only if it currently equals the expected one. The expiry of the key is
+// left as it was.
+func (lsm *LSMStore) CompareAndSwap(key string, expected interface{}, value interface{}) (bool, uint32) {
+	swapped, code := lsm.compareAndSwap(key, expected, value)
+	if swapped && lsm.awaitDurability() != nil {
+		return false, entity.CRC_WAL_WRITE_FAILED
+	}
+	return swapped, code
+}
+
+func (lsm *LSMStore) compareAndSwap(key string, expected interface{}, value interface{}) (bool, uint32) {
+	lsm.writeMu.Lock()
+	defer lsm.writeMu.Unlock()
+
+	previous, code := lsm.Get(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return false, entity.CRC_RECORD_NOT_FOUND
+	}
+
+	record, ok := previous.(*entity.ScalarRecord)
+	if !ok {
+		return false, entity.CRC_WRONG_TYPE
+	}
+
+	if !reflect.DeepEqual(record.Value, expected) {
+		return false, entity.CRC_VALUE_MISMATCH
+	}
+
+	return lsm.set(key, value, record.Expiry-utils.GetCurrentEPochTime())
+}
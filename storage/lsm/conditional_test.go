@@ -0,0 +1,50 @@
+package lsm
+
+import (
+	"testing"
+	"universum/entity"
+	"universum/storage"
+)
+
+func TestLSMStoreConditionalWrites(t *testing.T) {
+	store := setupTestStore(t)
+
+	if _, code := store.SetWithCondition("lock", "owner-1", 0, storage.SetIfPresent); code != entity.CRC_CONDITION_NOT_MET {
+		t.Errorf("expected XX on missing key to not be met, got %d", code)
+	}
+
+	if _, code := store.SetWithCondition("lock", "owner-1", 0, storage.SetIfAbsent); code != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected NX on missing key to write, got %d", code)
+	}
+
+	previous, code := store.SetWithCondition("lock", "owner-2", 0, storage.SetIfAbsent)
+	if code != entity.CRC_CONDITION_NOT_MET || previous.GetValue() != "owner-1" {
+		t.Errorf("expected NX on existing key to not be met, got %v, %d", previous, code)
+	}
+
+	previous, code = store.SetWithCondition("lock", "owner-2", 0, storage.SetAlways)
+	if code != entity.CRC_RECORD_UPDATED || previous.GetValue() != "owner-1" {
+		t.Errorf("expected previous value owner-1, got %v, %d", previous, code)
+	}
+
+	if swapped, code := store.CompareAndSwap("lock", "owner-1", "owner-3"); swapped || code != entity.CRC_VALUE_MISMATCH {
+		t.Errorf("expected value mismatch, got %v, %d", swapped, code)
+	}
+
+	if swapped, code := store.CompareAndSwap("lock", "owner-2", "owner-3"); !swapped || code != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected swap, got %v, %d", swapped, code)
+	}
+
+	previous, code = store.GetDelete("lock")
+	if code != entity.CRC_RECORD_DELETED || previous.GetValue() != "owner-3" {
+		t.Errorf("expected deleted value owner-3, got %v, %d", previous, code)
+	}
+
+	if _, code := store.GetDelete("lock"); code != entity.CRC_RECORD_NOT_FOUND {
+		t.Errorf("expected deleted key to not be found, got %d", code)
+	}
+
+	if swapped, code := store.CompareAndSwap("lock", "owner-3", "owner-4"); swapped || code != entity.CRC_RECORD_NOT_FOUND {
+		t.Errorf("expected deleted key to not be found, got %v, %d", swapped, code)
+	}
+}
@@ -0,0 +1,68 @@
+package lsm
+
+import (
+	"testing"
+	"time"
+	"universum/entity"
+)
+
+func TestLSMStoreHashOperations(t *testing.T) {
+	store := setupTestStore(t)
+
+	added, code := store.HSet("user:1", map[string]interface{}{"name": "alice", "visits": int64(1)})
+	if added != 2 || code != entity.CRC_RECORD_UPDATED {
+		t.Fatalf("expected 2 fields to be added, got %d, %d", added, code)
+	}
+
+	value, code := store.HGet("user:1", "name")
+	if value != "alice" || code != entity.CRC_RECORD_FOUND {
+		t.Errorf("expected field value alice, got %v, %d", value, code)
+	}
+
+	visits, _ := store.HIncrBy("user:1", "visits", 2)
+	if visits != 3 {
+		t.Errorf("expected visits to be 3, got %d", visits)
+	}
+
+	removed, _ := store.HDel("user:1", []string{"name"})
+	if removed != 1 {
+		t.Errorf("expected 1 field to be removed, got %d", removed)
+	}
+
+	if length, _ := store.HLen("user:1"); length != 1 {
+		t.Errorf("expected 1 field to remain, got %d", length)
+	}
+
+	store.Set("scalar", "value", 0)
+	if _, code := store.HGet("scalar", "name"); code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type for HGET on scalar, got %d", code)
+	}
+}
+
+func TestLSMStoreHashAfterFlush(t *testing.T) {
+	store := setupTestStore(t)
+
+	store.HSet("user:1", map[string]interface{}{"name": "alice", "age": int64(30)})
+
+	store.memTable.Truncate()
+	time.Sleep(2 * time.Second)
+
+	record, code := store.Get("user:1")
+	if code != entity.CRC_RECORD_FOUND {
+		t.Fatalf("expected hash to be found after flush, got %d", code)
+	}
+
+	hash, ok := record.(*entity.HashRecord)
+	if !ok {
+		t.Fatalf("expected hash record after flush, got %T", record)
+	}
+
+	if hash.Value["name"] != "alice" || hash.Value["age"] != int64(30) {
+		t.Errorf("unexpected hash value after flush: %v", hash.Value)
+	}
+
+	added, _ := store.HSet("user:1", map[string]interface{}{"city": "paris"})
+	if length, _ := store.HLen("user:1"); added != 1 || length != 3 {
+		t.Errorf("expected flushed hash to be updated, got added=%d, length=%d", added, length)
+	}
+}
@@ -0,0 +1,112 @@
+package lsm
+
+import (
+	"universum/entity"
+	"universum/storage/lsm/sstable"
+)
+
+// levelIterator walks the records of one level of the tree in key order, that
+// is either the memtable or a single SSTable. SSTable blocks are loaded lazily,
+// one at a time, as the iteration moves forward.
+type levelIterator struct {
+	records  []*entity.RecordKV
+	position int
+
+	sst       *sstable.SSTable // nil for the memtable level
+	nextBlock int
+
+	lower     string // keys before this bound are skipped
+	inclusive bool   // whether the lower bound itself is included
+}
+
+// current returns the record the iterator points to, or nil when exhausted.
+func (li *levelIterator) current() (*entity.RecordKV, error) {
+	for {
+		for li.position < len(li.records) {
+			record := li.records[li.position]
+			if record.Key > li.lower || (li.inclusive && record.Key == li.lower) {
+				return record, nil
+			}
+			li.position++
+		}
+
+		if li.sst == nil || li.nextBlock >= len(li.sst.Index) {
+			return nil, nil
+		}
+
+		records, err := li.sst.GetBlockRecords(li.nextBlock)
+		if err != nil {
+			return nil, err
+		}
+
+		li.records, li.position = records, 0
+		li.nextBlock++
+	}
+}
+
+// mergingIterator merges the levels of the tree into a single stream of keys
+// in ascending order. When a key exists in several levels, only the version of
+// the newest level is returned, so tombstones and expiries shadow older writes.
+type mergingIterator struct {
+	levels []*levelIterator // newest level first
+}
+
+// newMergingIterator creates an iterator over the memtable and all the SSTables,
+// starting at the lower bound key. The memtable is copied right away, and the
+// SSTables are read lazily, so no lock is held while iterating.
+func (lsm *LSMStore) newMergingIterator(lower string, inclusive bool) *mergingIterator {
+	sstables := lsm.sstables
+	levels := make([]*levelIterator, 0, len(sstables)+1)
+
+	levels = append(levels, &levelIterator{
+		records:   lsm.memTable.GetRecordsFrom(lower),
+		lower:     lower,
+		inclusive: inclusive,
+	})
+
+	for _, sst := range sstables {
+		if sst.Metadata.LastKey < lower {
+			continue
+		}
+
+		levels = append(levels, &levelIterator{
+			sst:       sst,
+			nextBlock: sst.SeekBlock(lower),
+			lower:     lower,
+			inclusive: inclusive,
+		})
+	}
+
+	return &mergingIterator{levels: levels}
+}
+
+// Next returns the newest version of the next key, or nil once all the levels
+// are exhausted. Tombstoned and expired records are returned as well, and are
+// left to the caller to skip.
+func (mi *mergingIterator) Next() (*entity.RecordKV, error) {
+	var newest *entity.RecordKV
+
+	for _, level := range mi.levels {
+		record, err := level.current()
+		if err != nil {
+			return nil, err
+		}
+
+		if record != nil && (newest == nil || record.Key < newest.Key) {
+			newest = record
+		}
+	}
+
+	if newest == nil {
+		return nil, nil
+	}
+
+	// move every level holding the key past it, dropping the older versions
+	for _, level := range mi.levels {
+		if record, _ := level.current(); record != nil && record.Key == newest.Key {
+			level.position++
+		}
+	}
+
+	return newest, nil
+}
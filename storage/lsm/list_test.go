@@ -0,0 +1,47 @@
+package lsm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"universum/entity"
+)
+
+func TestLSMStoreListOperations(t *testing.T) {
+	store := setupTestStore(t)
+
+	store.RPush("queue", []interface{}{"b", "c"})
+	length, code := store.LPush("queue", []interface{}{"a"})
+	if length != 3 || code != entity.CRC_RECORD_UPDATED {
+		t.Fatalf("expected list of 3 elements, got %d, %d", length, code)
+	}
+
+	if value, _ := store.LPop("queue"); value != "a" {
+		t.Errorf("expected a from head, got %v", value)
+	}
+
+	if value, _ := store.LIndex("queue", -1); value != "c" {
+		t.Errorf("expected c at tail, got %v", value)
+	}
+
+	store.RPop("queue")
+	store.RPop("queue")
+
+	if exists, _ := store.Exists("queue"); exists {
+		t.Errorf("expected the emptied list to be removed")
+	}
+}
+
+func TestLSMStoreListAfterFlush(t *testing.T) {
+	store := setupTestStore(t)
+
+	store.RPush("queue", []interface{}{"a", int64(2), "c"})
+
+	store.memTable.Truncate()
+	time.Sleep(2 * time.Second)
+
+	values, code := store.LRange("queue", 0, -1)
+	if code != entity.CRC_RECORD_FOUND || !reflect.DeepEqual(values, []interface{}{"a", int64(2), "c"}) {
+		t.Errorf("unexpected list after flush %v, %d", values, code)
+	}
+}
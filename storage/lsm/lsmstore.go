@@ -2,12 +2,16 @@ package lsm
 
 import (
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 	"universum/config"
 	"universum/entity"
 	"universum/internal/logger"
+	"universum/storage"
 	"universum/storage/lsm/compaction"
+	"universum/storage/lsm/manifest"
 	"universum/storage/lsm/memtable"
 	"universum/storage/lsm/sstable"
 	"universum/storage/lsm/wal"
@@ -23,61 +27,95 @@ const (
 )
 
 type LSMStore struct {
+	storage.Collections // the hash, list, set and sorted set commands
+
 	memTable  memtable.MemTable
 	sstables  []*sstable.SSTable
 	walWriter *wal.WALWriter
 	compactor *compaction.Compactor
+	manifest  *manifest.Writer // records the changes to the live SSTables
 	flusherMu sync.Mutex
 	compactMu sync.Mutex
+
+	// the channels the memtables and the compactor hand their work over on
+	flusherChan     chan memtable.MemTable
+	walRotaterChan  chan int64
+	replacementChan chan *compaction.SSTReplacement
+
+	pendingFlushes atomic.Int64          // Memtables handed to the flusher and not flushed yet
+	flushStartedAt atomic.Int64          // When the flush in progress started, in nanoseconds, 0 when idle
+	flusherErr     atomic.Pointer[error] // The error the flusher stopped on, if it did
+
+	// writeMu serialises the writes to the store, so that read-modify-write
+	// cycles, such as the updates of the collection types and the conditional
+	// writes, are never interleaved with other writes of the same key.
+	writeMu sync.Mutex
+
+	// batchUndo holds, while a batch is open, the records the keys written in
+	// the batch held before it, nil for the keys which did not exist, for the
+	// writes to be rolled back should the batch fail to commit.
+	batchUndo map[string]entity.Record
+
+	flushes       atomic.Int64 // Memtables flushed to SSTables so far
+	flushFailures atomic.Int64 // Memtable flushes which failed
+	flushDuration atomic.Int64 // Time spent flushing memtables, in nanoseconds
+
+	walDroppedBytes atomic.Int64 // Bytes of torn or corrupt WAL entries dropped on replay
 }
 
-func CreateNewLSMStore(mtype string) *LSMStore {
-	memtable := memtable.CreateNewMemTable(config.Store.Storage.LSM.MemtableStorageType)
+// Statistics is a point in time view of the LSM store, covering the memtable,
+// the SSTables, and the background jobs working on them.
+type Statistics struct {
+	MemtableSizeInBytes int64
+	MemtableRecordCount int64
+	SSTableCount        int64
 
-	return &LSMStore{
-		memTable: memtable,
-		sstables: make([]*sstable.SSTable, 0),
-	}
+	Flushes       int64
+	FlushFailures int64
+	FlushDuration time.Duration
+
+	WALDroppedBytes        int64
+	WALGroupCommitFailures int64
+
+	Compaction compaction.Statistics
+	BlockCache sstable.BlockCacheStatistics
 }
 
-func (lsm *LSMStore) Initialize() error {
-	sstableFiles, err := getAllSSTableFiles()
-	if err != nil {
-		return err
+func CreateNewLSMStore(mtype string) *LSMStore {
+	lsm := &LSMStore{
+		sstables:        make([]*sstable.SSTable, 0),
+		flusherChan:     make(chan memtable.MemTable, FlusherChanSize),
+		walRotaterChan:  make(chan int64, WALRotaterChanSize),
+		replacementChan: make(chan *compaction.SSTReplacement, CompactionReplacementChanSize),
 	}
 
-	sstables := make([]*sstable.SSTable, len(sstableFiles))
+	lsm.memTable = memtable.CreateNewMemTable(config.Store.Storage.LSM.MemtableStorageType, memtable.Channels{
+		Flusher:    lsm.flusherChan,
+		WALRotater: lsm.walRotaterChan,
+		Pending:    &lsm.pendingFlushes,
+	})
 
-	for i, filename := range sstableFiles {
-		maxRecords := config.Store.Storage.LSM.BloomFilterMaxRecords
-		fpRate := config.Store.Storage.LSM.BloomFalsePositiveRate
-
-		sst, err := sstable.NewSSTable(filename, sstable.SSTmodeRead, maxRecords, fpRate)
-		if err != nil {
-			return fmt.Errorf("failed to read SSTable %s:  %v", filename, err)
-		}
+	lsm.Collections = storage.NewCollections(lsm)
+	return lsm
+}
 
-		err = sst.LoadSSTableFromDisk()
-		if err != nil {
-			return fmt.Errorf("failed to load SSTable %s: %v", filename, err)
-		}
+func (lsm *LSMStore) Initialize() error {
+	lsm.compactor = compaction.NewCompactor(lsm.replacementChan)
 
-		sstables[i] = sst
+	err := lsm.loadSSTables()
+	if err != nil {
+		return err
 	}
-	lsm.sstables = sstables
 
-	lsm.walWriter, err = wal.NewWriter(config.Store.Storage.LSM.WriteAheadLogDirectory)
+	lsm.walWriter, err = wal.NewWriter(config.Store.Storage.LSM.WriteAheadLogDirectory, lsm.walRotaterChan)
 	if err != nil {
 		return fmt.Errorf("failed to initialize write ahead logger: %v", err)
 	}
 
-	memtable.FlusherChan = make(chan memtable.MemTable, FlusherChanSize)
-	memtable.WALRotaterChan = make(chan int64, WALRotaterChanSize)
 	go lsm.BGMemtableFlusher() // start the background flusher job
 
-	lsm.compactor = compaction.NewCompactor()
-	compaction.SSTReplacementChan = make(chan *compaction.SSTReplacement, CompactionReplacementChanSize)
-	go lsm.compactor.Compact() // start the background compaction job
+	go lsm.BGCompactionHandler() // start the background compaction replacement job
+	go lsm.compactor.Compact()   // start the background compaction job
 
 	sstable.BlockCacheStore = sstable.NewBlockCache()
 	return nil
@@ -146,6 +184,20 @@ func (lsm *LSMStore) Get(key string) (entity.Record, uint32) {
 }
 
 func (lsm *LSMStore) Set(key string, value interface{}, ttl int64) (bool, uint32) {
+	lsm.writeMu.Lock()
+	didSet, code := lsm.set(key, value, ttl)
+	lsm.writeMu.Unlock()
+
+	if didSet && lsm.awaitDurability() != nil {
+		return false, entity.CRC_WAL_WRITE_FAILED
+	}
+	return didSet, code
+}
+
+// set writes the value to the memtable and the WAL. The caller must hold writeMu.
+func (lsm *LSMStore) set(key string, value interface{}, ttl int64) (bool, uint32) {
+	lsm.keepUndoRecord(key)
+
 	success, statusCode := lsm.memTable.Set(key, value, ttl, entity.RecordStateActive)
 	if !success && statusCode != entity.CRC_RECORD_UPDATED {
 		return false, statusCode
@@ -156,10 +208,25 @@ func (lsm *LSMStore) Set(key string, value interface{}, ttl int64) (bool, uint32
 		return false, entity.CRC_WAL_WRITE_FAILED
 	}
 
+	storage.NotifyKeyspaceEvent(storage.KeyspaceEventSet, key)
 	return true, entity.CRC_RECORD_UPDATED
 }
 
 func (lsm *LSMStore) Delete(key string) (bool, uint32) {
+	lsm.writeMu.Lock()
+	deleted, code := lsm.delete(key)
+	lsm.writeMu.Unlock()
+
+	if deleted && lsm.awaitDurability() != nil {
+		return false, entity.CRC_WAL_WRITE_FAILED
+	}
+	return deleted, code
+}
+
+// delete writes a tombstone for the key to the memtable and the WAL. The caller
+// must hold writeMu.
+func (lsm *LSMStore) delete(key string) (bool, uint32) {
+	lsm.keepUndoRecord(key)
 	lsm.memTable.Delete(key)
 
 	err := lsm.walWriter.AddToWALBuffer(key, 0, time.Now().Unix(), entity.RecordStateTombstoned)
@@ -167,17 +234,48 @@ func (lsm *LSMStore) Delete(key string) (bool, uint32) {
 		return false, entity.CRC_WAL_WRITE_FAILED
 	}
 
+	storage.NotifyKeyspaceEvent(storage.KeyspaceEventDel, key)
 	return true, entity.CRC_RECORD_DELETED
 }
 
+// awaitDurability waits for the WAL entries of the writes made so far to be as
+// durable as the durability setting asks for, before the writes are acknowledged.
+// The caller must not hold writeMu, so that the writes waiting at the same time
+// are synced to disk together.
+func (lsm *LSMStore) awaitDurability() error {
+	err := lsm.walWriter.WaitForDurability()
+	if err != nil {
+		logger.Get().Error("LSM:: write failed to become durable: %v", err)
+	}
+	return err
+}
+
+// IncrDecrInteger adds the offset to the integer stored against the key, or
+// subtracts it. The read and the write are made under the store write lock, so
+// that concurrent increments of the key are never lost.
 func (lsm *LSMStore) IncrDecrInteger(key string, offset int64, isIncr bool) (int64, uint32) {
+	newValue, code := lsm.incrDecrInteger(key, offset, isIncr)
+	if code == entity.CRC_RECORD_UPDATED && lsm.awaitDurability() != nil {
+		return config.InvalidNumericValue, entity.CRC_WAL_WRITE_FAILED
+	}
+	return newValue, code
+}
+
+func (lsm *LSMStore) incrDecrInteger(key string, offset int64, isIncr bool) (int64, uint32) {
+	lsm.writeMu.Lock()
+	defer lsm.writeMu.Unlock()
+
 	val, code := lsm.Get(key)
 
 	if code != entity.CRC_RECORD_FOUND {
 		return config.InvalidNumericValue, code
 	}
 
-	record := val.(*entity.ScalarRecord)
+	record, ok := val.(*entity.ScalarRecord)
+	if !ok {
+		return config.InvalidNumericValue, entity.CRC_WRONG_TYPE
+	}
+
 	if !utils.IsInteger(record.Value) {
 		return config.InvalidNumericValue, entity.CRC_INCR_INVALID_TYPE
 	}
@@ -192,7 +290,7 @@ func (lsm *LSMStore) IncrDecrInteger(key string, offset int64, isIncr bool) (int
 	}
 
 	ttl := record.Expiry - utils.GetCurrentEPochTime()
-	didSet, setcode := lsm.Set(key, newValue, ttl)
+	didSet, setcode := lsm.set(key, newValue, ttl)
 
 	if !didSet {
 		return config.InvalidNumericValue, setcode
@@ -201,14 +299,31 @@ func (lsm *LSMStore) IncrDecrInteger(key string, offset int64, isIncr bool) (int
 	return newValue, entity.CRC_RECORD_UPDATED
 }
 
+// Append appends the value to the string stored against the key, under the
+// store write lock as IncrDecrInteger does, and returns the new length.
 func (lsm *LSMStore) Append(key string, value string) (int64, uint32) {
+	length, code := lsm.append(key, value)
+	if code == entity.CRC_RECORD_UPDATED && lsm.awaitDurability() != nil {
+		return config.InvalidNumericValue, entity.CRC_WAL_WRITE_FAILED
+	}
+	return length, code
+}
+
+func (lsm *LSMStore) append(key string, value string) (int64, uint32) {
+	lsm.writeMu.Lock()
+	defer lsm.writeMu.Unlock()
+
 	val, code := lsm.Get(key)
 
 	if code != entity.CRC_RECORD_FOUND {
 		return config.InvalidNumericValue, code
 	}
 
-	record := val.(*entity.ScalarRecord)
+	record, ok := val.(*entity.ScalarRecord)
+	if !ok {
+		return config.InvalidNumericValue, entity.CRC_WRONG_TYPE
+	}
+
 	if !utils.IsString(record.Value) {
 		return config.InvalidNumericValue, entity.CRC_INCR_INVALID_TYPE
 	}
@@ -216,7 +331,7 @@ func (lsm *LSMStore) Append(key string, value string) (int64, uint32) {
 	newValue := record.Value.(string) + value
 	ttl := record.Expiry - utils.GetCurrentEPochTime()
 
-	didSet, setcode := lsm.Set(key, newValue, ttl)
+	didSet, setcode := lsm.set(key, newValue, ttl)
 	if !didSet {
 		return config.InvalidNumericValue, setcode
 	}
@@ -230,9 +345,9 @@ func (lsm *LSMStore) MGet(keys []string) (map[string]interface{}, uint32) {
 	for idx := range keys {
 		record, code := lsm.Get(keys[idx])
 
-		if _, ok := record.(*entity.ScalarRecord); ok {
+		if code == entity.CRC_RECORD_FOUND {
 			responseMap[keys[idx]] = map[string]interface{}{
-				"Value": record.(*entity.ScalarRecord).Value,
+				"Value": record.GetValue(),
 				"Code":  code,
 			}
 		} else {
@@ -252,7 +367,7 @@ func (lsm *LSMStore) MSet(kvMap map[string]interface{}) (map[string]interface{},
 	for key, value := range kvMap {
 		didSet, code := lsm.Set(key, value, 0)
 		if code == entity.CRC_RECORD_TOO_BIG || code == entity.CRC_INVALID_DATATYPE {
-			fmt.Printf("Key %s failed due to %d\n", key, code)
+			logger.Get().Debug("MSet:: Key %s failed due to %d", key, code)
 		}
 		responseMap[key] = didSet
 	}
@@ -278,9 +393,7 @@ func (lsm *LSMStore) TTL(key string) (int64, uint32) {
 		return 0, code
 	}
 
-	record := val.(*entity.ScalarRecord)
-
-	ttl := record.Expiry - utils.GetCurrentEPochTime()
+	ttl := val.GetExpiry() - utils.GetCurrentEPochTime()
 	return ttl, entity.CRC_RECORD_FOUND
 }
 
@@ -291,8 +404,74 @@ func (lsm *LSMStore) Expire(key string, ttl int64) (bool, uint32) {
 		return false, code
 	}
 
-	record := val.(*entity.ScalarRecord)
-	return lsm.Set(key, record.Value, ttl)
+	return lsm.Set(key, val.GetValue(), ttl)
+}
+
+// BeginBatch holds back the WAL entries of the writes that follow, until they
+// are written out as a single entry by CommitBatch.
+func (lsm *LSMStore) BeginBatch() {
+	lsm.writeMu.Lock()
+	lsm.batchUndo = make(map[string]entity.Record)
+	lsm.writeMu.Unlock()
+
+	lsm.walWriter.BeginBatch()
+}
+
+// CommitBatch writes the WAL entries held back since BeginBatch as a single
+// entry, so that replaying the WAL restores either all of them or none. Should
+// that fail, the writes of the batch are rolled back, for the store not to serve
+// what a restart would lose.
+func (lsm *LSMStore) CommitBatch() error {
+	err := lsm.walWriter.CommitBatch()
+	if err == nil {
+		err = lsm.awaitDurability()
+	}
+
+	lsm.writeMu.Lock()
+	defer lsm.writeMu.Unlock()
+
+	if err != nil {
+		lsm.rollbackBatch()
+	}
+
+	lsm.batchUndo = nil
+	return err
+}
+
+// keepUndoRecord remembers the record the key holds before its first write in
+// the open batch, if any. The caller must hold writeMu.
+func (lsm *LSMStore) keepUndoRecord(key string) {
+	if lsm.batchUndo == nil {
+		return
+	}
+
+	if _, ok := lsm.batchUndo[key]; ok {
+		return
+	}
+
+	record, code := lsm.Get(key)
+	if code != entity.CRC_RECORD_FOUND {
+		record = nil
+	}
+	lsm.batchUndo[key] = record
+}
+
+// rollbackBatch puts back the records the keys held before the batch. They are
+// put back in the memtable alone, as the WAL never got the writes of the batch.
+// The collections are copied on write, so the records kept are left as they were.
+// The caller must hold writeMu.
+func (lsm *LSMStore) rollbackBatch() {
+	for key, record := range lsm.batchUndo {
+		if record == nil {
+			lsm.memTable.Delete(key)
+			storage.NotifyKeyspaceEvent(storage.KeyspaceEventDel, key)
+			continue
+		}
+
+		ttl := record.GetExpiry() - utils.GetCurrentEPochTime()
+		lsm.memTable.Set(key, record.GetValue(), ttl, entity.RecordStateActive)
+		storage.NotifyKeyspaceEvent(storage.KeyspaceEventSet, key)
+	}
 }
 
 func (lsm *LSMStore) BGMemtableFlusher() error {
@@ -303,45 +482,81 @@ func (lsm *LSMStore) BGMemtableFlusher() error {
 		}
 	}()
 
+	for memtable := range lsm.flusherChan {
+		if err := lsm.flushMemtable(memtable); err != nil {
+			lsm.flusherErr.Store(&err)
+			logger.Get().Error("BGFlusher: stopped, %d memtables left to be flushed: %v", lsm.pendingFlushes.Load(), err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flushMemtable writes the records of the memtable to a new SSTable, and puts it
+// in front of the SSTables read from. Once the SSTable is recorded in the
+// manifest, the WAL segment of the memtable is released. The memtable no longer
+// counts as pending once it returns, whether the flush succeeded or not.
+func (lsm *LSMStore) flushMemtable(table memtable.MemTable) error {
+	defer lsm.pendingFlushes.Add(-1)
+
+	lsm.flushStartedAt.Store(time.Now().UnixNano())
+	defer lsm.flushStartedAt.Store(0)
+
+	if table.GetCount() == 0 {
+		lsm.walWriter.ReleaseFlushedSegment()
+		return nil // nothing to flush, an empty SSTable could not be loaded back
+	}
+
 	maxRecords := config.Store.Storage.LSM.BloomFilterMaxRecords
 	fpRate := config.Store.Storage.LSM.BloomFalsePositiveRate
 
-	for memtable := range memtable.FlusherChan {
-		var sst *sstable.SSTable
-		var err error
+	newFileName := generateSSTableFileName()
+	logger.Get().Info("BGFlusher: Flushing memtable to SSTable: %s", newFileName)
 
-		newFileName := generateSSTableFileName()
-		logger.Get().Info("BGFlusher: Flushing memtable to SSTable: %s", newFileName)
+	startTime := time.Now()
+	sst, err := sstable.NewSSTable(newFileName, sstable.SSTmodeWrite, maxRecords, fpRate)
+	if err != nil {
+		lsm.flushFailures.Add(1)
+		logger.Get().Error("BGFlusher: failed to create new SSTable: %v", err)
+		return err
+	}
 
-		sst, err = sstable.NewSSTable(newFileName, sstable.SSTmodeWrite, maxRecords, fpRate)
+	for i := 0; i < SSTableFlushRetryCount; i++ {
+		err = sst.FlushRecordsToSSTable(table.GetAll())
 		if err != nil {
-			logger.Get().Error("BGFlusher: failed to create new SSTable: %v", err)
-			return err
+			logger.Get().Error("[#%d] BGFlusher: failed to flush SSTable to disk: %v", i+1, err)
+			time.Sleep(10 * time.Millisecond) // sleep for a while before retrying
+
+			if i == SSTableFlushRetryCount-1 {
+				// @TODO: handle error or consider shutting down the service if needed.
+				lsm.flushFailures.Add(1)
+				logger.Get().Error("Background SSTable flush terminated after %d retries, Exiting", i+1)
+				return err
+			}
+			continue
 		}
+		break
+	}
 
-		lsm.flusherMu.Lock()
-
-		for i := 0; i < SSTableFlushRetryCount; i++ {
-			err = sst.FlushRecordsToSSTable(memtable.GetAll())
-			if err != nil {
-				logger.Get().Error("[#%d] BGFlusher: failed to flush SSTable to disk: %v", i+1, err)
-				time.Sleep(10 * time.Millisecond) // sleep for a while before retrying
+	// the SSTable is only live once the manifest lists it
+	err = lsm.manifest.Append(manifestEntry(manifest.OpAdd, sst))
+	if err != nil {
+		lsm.flushFailures.Add(1)
+		logger.Get().Error("BGFlusher: failed to record SSTable %s in the manifest: %v", newFileName, err)
+		return err
+	}
 
-				if i == SSTableFlushRetryCount-1 {
-					// @TODO: handle error or consider shutting down the service if needed.
-					logger.Get().Error("Background SSTable flush terminated after %d retries, Exiting", i+1)
-					return err
-				}
-				continue
-			}
-			break
-		}
+	lsm.flushes.Add(1)
+	lsm.flushDuration.Add(int64(time.Since(startTime)))
+	lsm.walWriter.ReleaseFlushedSegment()
 
-		lsm.sstables = append([]*sstable.SSTable{sst}, lsm.sstables...)
-		lsm.compactor.CompactLevel(sst.Metadata.CompactionLevel)
+	lsm.flusherMu.Lock()
+	lsm.sstables = append([]*sstable.SSTable{sst}, lsm.sstables...)
+	lsm.flusherMu.Unlock()
 
-		lsm.flusherMu.Unlock()
-	}
+	lsm.compactor.AddSSTable(sst.Metadata.CompactionLevel, sst)
+	lsm.compactor.CompactLevel(sst.Metadata.CompactionLevel)
 
 	return nil
 }
@@ -354,27 +569,93 @@ func (lsm *LSMStore) BGCompactionHandler() error {
 		}
 	}()
 
-	for notification := range compaction.SSTReplacementChan {
-		lsm.compactMu.Lock()
+	for notification := range lsm.replacementChan {
+		lsm.replaceSSTables(notification)
+	}
 
-		// replace obsolete sstables and append the newly merged one
-		for _, obsst := range notification.Obsoletes {
-			for sIdx, sstable := range lsm.sstables {
-				if sstable.Metadata.SSTableID == obsst.Metadata.SSTableID {
-					lsm.sstables = append(lsm.sstables[:sIdx], lsm.sstables[sIdx+1:]...)
-					break
-				}
-			}
+	return nil
+}
+
+// replaceSSTables records the compaction in the manifest, replaces the obsolete
+// SSTables with the merged one, and deletes them. Should the manifest fail to
+// record it, the obsolete SSTables are kept on disk, since they are the ones
+// still live on the next start.
+func (lsm *LSMStore) replaceSSTables(notification *compaction.SSTReplacement) {
+	entries := []*manifest.Entry{manifestEntry(manifest.OpAdd, notification.Substitute)}
+	for _, obsst := range notification.Obsoletes {
+		entries = append(entries, manifestEntry(manifest.OpRemove, obsst))
+	}
+
+	recorded := true
+	if err := lsm.manifest.Append(entries...); err != nil {
+		recorded = false
+		logger.Get().Error("BGCompactionHandler: failed to record compaction in the manifest: %v", err)
+	}
+
+	lsm.flusherMu.Lock()
+	lsm.compactMu.Lock()
+
+	// replace obsolete sstables and add the newly merged one
+	sstables := make([]*sstable.SSTable, 0, len(lsm.sstables)+1)
+	for _, sst := range lsm.sstables {
+		if !slices.Contains(notification.Obsoletes, sst) {
+			sstables = append(sstables, sst)
+		}
+	}
+	sstables = append(sstables, notification.Substitute)
+	sortSSTables(sstables)
+	lsm.sstables = sstables
+
+	lsm.compactMu.Unlock()
+	lsm.flusherMu.Unlock()
+
+	if !recorded {
+		return
+	}
+
+	for _, obsst := range notification.Obsoletes {
+		if err := obsst.DeleteFromDisk(); err != nil {
+			logger.Get().Error("Failed to clean obsolete sstable %s post compaction: %v", obsst.Filename, err)
 		}
-		lsm.sstables = append(lsm.sstables, notification.Substitute)
-		lsm.compactMu.Unlock()
 	}
+}
 
-	return nil
+// GetStatistics returns the sizes of the memtable and the SSTables, along with
+// the flushes, the compactions and the block cache usage so far.
+func (lsm *LSMStore) GetStatistics() Statistics {
+	// the flusher and the compaction both replace the SSTables under flusherMu
+	lsm.flusherMu.Lock()
+	sstableCount := int64(len(lsm.sstables))
+	lsm.flusherMu.Unlock()
+
+	stats := Statistics{
+		MemtableSizeInBytes: lsm.memTable.GetSize(),
+		MemtableRecordCount: lsm.memTable.GetCount(),
+		SSTableCount:        sstableCount,
+		Flushes:             lsm.flushes.Load(),
+		FlushFailures:       lsm.flushFailures.Load(),
+		FlushDuration:       time.Duration(lsm.flushDuration.Load()),
+		WALDroppedBytes:     lsm.walDroppedBytes.Load(),
+	}
+
+	if lsm.walWriter != nil {
+		stats.WALGroupCommitFailures = lsm.walWriter.GetGroupCommitFailures()
+	}
+
+	if lsm.compactor != nil {
+		stats.Compaction = lsm.compactor.GetStatistics()
+	}
+
+	if sstable.BlockCacheStore != nil {
+		stats.BlockCache = sstable.BlockCacheStore.GetStatistics()
+	}
+
+	return stats
 }
 
 func (lsm *LSMStore) Close() error {
 	// @TODO handle more resource closures
 	lsm.walWriter.Close()
+	lsm.manifest.Close()
 	return nil
 }
@@ -1,13 +1,21 @@
 package lsm
 
 import (
+	"encoding/binary"
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
 	"testing"
 	"time"
 	"universum/config"
 	"universum/entity"
+	"universum/storage"
 	"universum/storage/lsm/compaction"
+	"universum/storage/lsm/memtable"
 	"universum/storage/lsm/sstable"
+	"universum/storage/lsm/wal"
 )
 
 func setupTestStore(t *testing.T) *LSMStore {
@@ -298,9 +306,7 @@ func TestMSetMGetMDeleteOperations(t *testing.T) {
 }
 
 func TestLSMStore_BGCompactionHandler(t *testing.T) {
-	setupTestStore(t)
-	lsm := &LSMStore{}
-	compaction.SSTReplacementChan = make(chan *compaction.SSTReplacement, 1)
+	lsm := setupTestStore(t)
 
 	records1 := []*entity.RecordKV{
 		{Key: "key1", Record: &entity.ScalarRecord{Value: "value1"}},
@@ -321,11 +327,9 @@ func TestLSMStore_BGCompactionHandler(t *testing.T) {
 	}
 
 	go func() {
-		compaction.SSTReplacementChan <- notification
+		lsm.replacementChan <- notification
 	}()
 
-	go lsm.BGCompactionHandler()
-
 	time.Sleep(1 * time.Second)
 
 	if len(lsm.sstables) != 1 {
@@ -350,3 +354,242 @@ func TestClose(t *testing.T) {
 		t.Fatalf("Failed to close the store: %v", err)
 	}
 }
+
+func TestBatchIsRolledBackWhenCommitFails(t *testing.T) {
+	store := setupTestStore(t)
+	store.Set("kept", "before", 0)
+
+	store.BeginBatch()
+	store.Set("kept", "after", 0)
+	store.Set("added", "value", 0)
+	store.HSet("hash", map[string]interface{}{"field": "value"})
+
+	// the WAL can no longer be written to
+	store.walWriter.Close()
+
+	if err := store.CommitBatch(); err == nil {
+		t.Fatal("Expected the batch to fail to commit")
+	}
+
+	if record, code := store.Get("kept"); code != entity.CRC_RECORD_FOUND || record.GetValue() != "before" {
+		t.Errorf("Expected the overwritten key to be rolled back, got %v, %d", record, code)
+	}
+
+	for _, key := range []string{"added", "hash"} {
+		if exists, _ := store.Exists(key); exists {
+			t.Errorf("Expected the key %s added in the batch to be rolled back", key)
+		}
+	}
+}
+
+func TestConcurrentIncrementsAreNotLost(t *testing.T) {
+	store := setupTestStore(t)
+	store.Set("counter", int64(0), 0)
+	store.Set("text", "", 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				store.IncrDecrInteger("counter", 1, true)
+				store.Append("text", "x")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if record, _ := store.Get("counter"); record.GetValue() != int64(400) {
+		t.Errorf("Expected 400 increments, got %v", record.GetValue())
+	}
+
+	if record, _ := store.Get("text"); len(record.GetValue().(string)) != 400 {
+		t.Errorf("Expected 400 appends, got %d", len(record.GetValue().(string)))
+	}
+}
+
+func TestBatchIsWrittenAsSingleWALEntry(t *testing.T) {
+	store := setupTestStore(t)
+
+	store.BeginBatch()
+	store.Set("tx1", "value1", 0)
+	store.Set("tx2", int64(2), 0)
+	store.HSet("tx3", map[string]interface{}{"field": "value"})
+
+	// writes are visible right away, even before the batch is committed
+	if _, code := store.Get("tx2"); code != entity.CRC_RECORD_FOUND {
+		t.Errorf("Expected batched write to be readable, got code %d", code)
+	}
+
+	if err := store.CommitBatch(); err != nil {
+		t.Fatalf("Failed to commit batch: %v", err)
+	}
+	store.Close()
+
+	walPath := filepath.Join(config.Store.Storage.LSM.WriteAheadLogDirectory, wal.SegmentFileName(1))
+	contents, err := os.ReadFile(walPath)
+	if err != nil {
+		t.Fatalf("Failed to read WAL file: %v", err)
+	}
+
+	// the segment header, then the length and the checksum of each entry, and
+	// the trailer the segment is sealed with once closed
+	headerSize, prefixSize, trailerSize := 8, entity.Int64SizeInBytes+4, 4+entity.Int64SizeInBytes
+	if len(contents) < headerSize+prefixSize+trailerSize {
+		t.Fatalf("Expected the WAL to hold the batch, got %d bytes", len(contents))
+	}
+
+	entryLen := int(binary.BigEndian.Uint64(contents[headerSize : headerSize+entity.Int64SizeInBytes]))
+	if headerSize+prefixSize+entryLen+trailerSize != len(contents) {
+		t.Errorf("Expected the batch to be written as a single WAL entry of %d bytes, WAL has %d bytes",
+			entryLen, len(contents)-headerSize-prefixSize-trailerSize)
+	}
+
+	reader, err := wal.NewReader(config.Store.Storage.LSM.WriteAheadLogDirectory)
+	if err != nil {
+		t.Fatalf("Failed to create WAL reader: %v", err)
+	}
+	defer reader.Close()
+
+	restored := memtable.CreateNewMemTable(config.MemtableStorageTypeLB, memtable.Channels{})
+	keycount, err := reader.RestoreFromWAL(restored)
+	if err != nil || keycount != 3 {
+		t.Errorf("Expected 3 keys to be restored from the batch, got %d (err=%v)", keycount, err)
+	}
+}
+
+func TestWALSegmentIsReleasedOnceFlushed(t *testing.T) {
+	store := setupTestStore(t)
+	waldir := config.Store.Storage.LSM.WriteAheadLogDirectory
+
+	store.Set("flushed", "value", 0)
+	store.memTable.Truncate()
+	if err := store.waitForPendingFlushes(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// the next write seals the segment of the flushed memtable, and releases it
+	store.Set("unflushed", "value", 0)
+
+	if _, err := os.Stat(filepath.Join(waldir, wal.SegmentFileName(1))); !os.IsNotExist(err) {
+		t.Errorf("Expected the segment of the flushed memtable to be deleted, got %v", err)
+	}
+
+	store = restartTestStore(t, store)
+
+	keycount, err := (&LSMStoreSnapshotService{}).Restore(store)
+	if err != nil || keycount != 1 {
+		t.Fatalf("Expected only the unflushed key to be replayed, got %d (err=%v)", keycount, err)
+	}
+
+	if err := store.waitForPendingFlushes(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"flushed", "unflushed"} {
+		if _, code := store.Get(key); code != entity.CRC_RECORD_FOUND {
+			t.Errorf("Expected %s to be restored, got code %d", key, code)
+		}
+	}
+}
+
+func TestWritesAreDurableOnceAcknowledged(t *testing.T) {
+	store := setupTestStore(t)
+	config.Store.Storage.LSM.WriteAheadLogDurability = config.WALDurabilityFsyncPerBatch
+	store = restartTestStore(t, store)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if didSet, code := store.Set(fmt.Sprintf("key-%d", i), "value", 0); !didSet {
+				t.Errorf("Expected the write to be acknowledged, got code %d", code)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// every acknowledged write is in the WAL, without the store being closed
+	reader, err := wal.NewReader(config.Store.Storage.LSM.WriteAheadLogDirectory)
+	if err != nil {
+		t.Fatalf("Failed to create WAL reader: %v", err)
+	}
+	defer reader.Close()
+
+	restored := memtable.CreateNewMemTable(config.MemtableStorageTypeLB, memtable.Channels{})
+	if keycount, err := reader.RestoreFromWAL(restored); err != nil || keycount != 20 {
+		t.Errorf("Expected the 20 acknowledged writes to be restored, got %d (err=%v)", keycount, err)
+	}
+}
+
+func TestKeyspaceEventsOnSetAndDelete(t *testing.T) {
+	store := setupTestStore(t)
+
+	events := make([]string, 0)
+	storage.SetKeyspaceListener(func(event storage.KeyspaceEvent, key string) {
+		events = append(events, string(event)+":"+key)
+	})
+	defer storage.SetKeyspaceListener(nil)
+
+	store.Set("key", "value", 0)
+	store.Delete("key")
+	store.HSet("hash", map[string]interface{}{"field": "value"})
+
+	expected := []string{"set:key", "del:key", "set:hash"}
+	if fmt.Sprint(events) != fmt.Sprint(expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+func TestLSMStoreStatistics(t *testing.T) {
+	store := setupTestStore(t)
+
+	for i := 0; i < 3; i++ {
+		if ok, code := store.Set(fmt.Sprintf("key-%d", i), "value", 0); !ok {
+			t.Fatalf("Failed to set key-%d, code %d", i, code)
+		}
+	}
+
+	stats := store.GetStatistics()
+	if stats.MemtableRecordCount != 3 || stats.MemtableSizeInBytes <= 0 {
+		t.Errorf("Expected 3 records in the memtable, got %d records of %d bytes",
+			stats.MemtableRecordCount, stats.MemtableSizeInBytes)
+	}
+
+	if stats.Flushes != 0 || stats.Compaction.Compactions != 0 {
+		t.Errorf("Expected no flushes nor compactions yet, got %d and %d", stats.Flushes, stats.Compaction.Compactions)
+	}
+
+	if stats.BlockCache.LimitInBytes != config.Store.Storage.LSM.BlockCacheMemoryLimit {
+		t.Errorf("Expected the block cache limit to be %d, got %d",
+			config.Store.Storage.LSM.BlockCacheMemoryLimit, stats.BlockCache.LimitInBytes)
+	}
+}
+
+func TestLSMStoreStatisticsDuringFlushes(t *testing.T) {
+	store := setupTestStore(t)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for store.GetStatistics().Flushes < 5 {
+			runtime.Gosched()
+		}
+	}()
+
+	for i := 0; i < 5; i++ {
+		store.Set(fmt.Sprintf("key-%d", i), "value", 0)
+		store.memTable.Truncate()
+	}
+
+	if err := store.waitForPendingFlushes(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+
+	if stats := store.GetStatistics(); stats.SSTableCount == 0 {
+		t.Errorf("Expected the flushed memtables to be counted in the SSTables, got %d", stats.SSTableCount)
+	}
+}
@@ -0,0 +1,183 @@
+package lsm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"universum/config"
+	"universum/internal/logger"
+	"universum/storage/lsm/manifest"
+	"universum/storage/lsm/sstable"
+)
+
+// loadSSTables loads the SSTables the manifest of the data directory leaves live,
+// sorted the way they are read from, and adds them to the levels of the
+// compactor. A data directory without a manifest has all of its SSTables loaded
+// at level 0, as they were before the manifest was kept.
+//
+// The manifest is then written anew with the live SSTables only, and kept open
+// for the flushes and compactions to come, while the SST files it does not list,
+// such as the ones half-written or left over by a crash, are removed.
+func (lsm *LSMStore) loadSSTables() error {
+	datadir := config.Store.Storage.LSM.DataStorageDirectory
+	maxRecords := config.Store.Storage.LSM.BloomFilterMaxRecords
+	fpRate := config.Store.Storage.LSM.BloomFalsePositiveRate
+
+	live, err := readLiveSSTables(datadir)
+	if err != nil {
+		return err
+	}
+
+	sstables := make([]*sstable.SSTable, 0, len(live))
+	for filename, entry := range live {
+		sst, err := sstable.NewSSTable(filename, sstable.SSTmodeRead, maxRecords, fpRate)
+		if err != nil {
+			return fmt.Errorf("failed to read SSTable %s:  %v", filename, err)
+		}
+
+		err = sst.LoadSSTableFromDisk()
+		if err != nil {
+			return fmt.Errorf("failed to load SSTable %s: %v", filename, err)
+		}
+
+		// the manifest has the last word on the level, the compactions not
+		// rewriting the SSTables they move between the levels
+		sst.Metadata.CompactionLevel = entry.Level
+		sstables = append(sstables, sst)
+	}
+
+	sortSSTables(sstables)
+	lsm.sstables = sstables
+
+	// the compactor merges the SSTables of a level in the order they were added,
+	// the most recent last
+	for i := len(sstables) - 1; i >= 0; i-- {
+		lsm.compactor.AddSSTable(sstables[i].Metadata.CompactionLevel, sstables[i])
+	}
+
+	if err := lsm.rewriteManifest(datadir); err != nil {
+		return err
+	}
+
+	return removeOrphanSSTables(datadir, live)
+}
+
+// readLiveSSTables replays the manifest of the data directory, and returns the
+// SSTables it leaves live keyed by their file names.
+func readLiveSSTables(datadir string) (map[string]*manifest.Entry, error) {
+	manifestPath := filepath.Join(datadir, manifest.FileName)
+
+	if _, err := os.Stat(manifestPath); errors.Is(err, os.ErrNotExist) {
+		sstableFiles, err := getAllSSTableFiles()
+		if err != nil {
+			return nil, err
+		}
+
+		live := make(map[string]*manifest.Entry, len(sstableFiles))
+		for _, filename := range sstableFiles {
+			live[filename] = &manifest.Entry{Op: manifest.OpAdd, Filename: filename, Level: 0}
+		}
+
+		logger.Get().Info("No manifest found in %s, loading all of its %d SSTables", datadir, len(live))
+		return live, nil
+	}
+
+	entries, err := manifest.Read(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %v", err)
+	}
+
+	return manifest.Replay(entries), nil
+}
+
+// rewriteManifest replaces the manifest of the data directory with one listing
+// the live SSTables only, so that it does not grow with every restart, and keeps
+// it open to record the changes to come.
+func (lsm *LSMStore) rewriteManifest(datadir string) error {
+	manifestPath := filepath.Join(datadir, manifest.FileName)
+	tempManifestPath := manifestPath + ".tmp"
+
+	writer, err := manifest.Create(tempManifestPath)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]*manifest.Entry, 0, len(lsm.sstables))
+	for _, sst := range lsm.sstables {
+		entries = append(entries, manifestEntry(manifest.OpAdd, sst))
+	}
+
+	if err := writer.Append(entries...); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+
+	if err := os.Rename(tempManifestPath, manifestPath); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to move manifest in place: %v", err)
+	}
+
+	lsm.manifest = writer
+	return nil
+}
+
+// removeOrphanSSTables removes the SST files of the data directory which are not
+// live.
+func removeOrphanSSTables(datadir string, live map[string]*manifest.Entry) error {
+	files, err := os.ReadDir(datadir)
+	if err != nil {
+		return fmt.Errorf("failed to read SSTable directory: %v", err)
+	}
+
+	sstExtWithDot := fmt.Sprintf(".%s", sstable.SstFileExtension)
+
+	for _, file := range files {
+		if _, ok := live[file.Name()]; ok || !strings.HasSuffix(file.Name(), sstExtWithDot) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(datadir, file.Name())); err != nil {
+			return fmt.Errorf("failed to remove orphan SSTable %s: %v", file.Name(), err)
+		}
+		logger.Get().Info("Removed orphan SSTable %s, not listed in the manifest", file.Name())
+	}
+
+	return nil
+}
+
+// manifestEntry describes the SSTable for the manifest.
+func manifestEntry(op string, sst *sstable.SSTable) *manifest.Entry {
+	return &manifest.Entry{
+		Op:         op,
+		Filename:   filepath.Base(sst.Filename),
+		Level:      sst.Metadata.CompactionLevel,
+		FirstKey:   sst.Metadata.FirstKey,
+		LastKey:    sst.Metadata.LastKey,
+		NumRecords: sst.Metadata.NumRecords,
+	}
+}
+
+// sortSSTables sorts the SSTables in the order they are read from, which is the
+// most recent records first: level by level, and the most recent SSTables first
+// within a level.
+func sortSSTables(sstables []*sstable.SSTable) {
+	sort.SliceStable(sstables, func(i, j int) bool {
+		levelI, levelJ := sstables[i].Metadata.CompactionLevel, sstables[j].Metadata.CompactionLevel
+		if levelI != levelJ {
+			return levelI < levelJ
+		}
+
+		sstExtWithDot := fmt.Sprintf(".%s", sstable.SstFileExtension)
+		timeI, err1 := strconv.ParseInt(strings.TrimSuffix(sstables[i].Filename, sstExtWithDot), 10, 64)
+		timeJ, err2 := strconv.ParseInt(strings.TrimSuffix(sstables[j].Filename, sstExtWithDot), 10, 64)
+
+		if err1 != nil || err2 != nil {
+			return sstables[i].Filename > sstables[j].Filename
+		}
+		return timeI > timeJ
+	})
+}
@@ -0,0 +1,249 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	FileName string = "MANIFEST"
+
+	formatMagic   uint32 = 0x554d4e46 // "UMNF"
+	FormatVersion uint32 = 1
+
+	OpAdd    string = "ADD"    // an SSTable joined the live set
+	OpRemove string = "REMOVE" // an SSTable left the live set, such as on compaction
+
+	maxEditSize int64 = 16 * 1024 * 1024 // 16MB, edits only get large with their keys
+)
+
+var ErrInvalidHeader = errors.New("not a manifest file, or one of an unsupported version")
+
+// Entry records a change to the set of live SSTables, along with what is known
+// of the SSTable it is about.
+type Entry struct {
+	Op         string
+	Filename   string
+	Level      int64
+	FirstKey   string
+	LastKey    string
+	NumRecords int64
+}
+
+// Writer appends the edits to a manifest file. The file starts with a header
+// carrying the format version, followed by the edits, each of them prefixed by
+// its length and its checksum. An edit holds one or more entries, which are
+// applied all together or not at all.
+type Writer struct {
+	fileptr *os.File
+	mu      sync.Mutex
+}
+
+// Create creates the manifest file at the given path, replacing any file which
+// is already there, and writes its header.
+func Create(path string) (*Writer, error) {
+	fileptr, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manifest file: %v", err)
+	}
+
+	header := make([]byte, 0, 2*binary.Size(formatMagic))
+	header = binary.BigEndian.AppendUint32(header, formatMagic)
+	header = binary.BigEndian.AppendUint32(header, FormatVersion)
+
+	if _, err := fileptr.Write(header); err != nil {
+		fileptr.Close()
+		return nil, fmt.Errorf("failed to write manifest header: %v", err)
+	}
+
+	return &Writer{fileptr: fileptr}, nil
+}
+
+// Append writes the entries to the manifest as a single edit, and syncs it to
+// disk before it returns, since the SSTables it describes are only live once it
+// is durable.
+func (w *Writer) Append(entries ...*Entry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	edit := new(bytes.Buffer)
+	binary.Write(edit, binary.BigEndian, int32(len(entries)))
+
+	for _, entry := range entries {
+		if err := entry.serialize(edit); err != nil {
+			return err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, int64(edit.Len()))
+	binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(edit.Bytes()))
+	buf.Write(edit.Bytes())
+
+	if _, err := w.fileptr.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to append to manifest: %v", err)
+	}
+
+	return w.fileptr.Sync()
+}
+
+// Close closes the manifest file.
+func (w *Writer) Close() error {
+	return w.fileptr.Close()
+}
+
+// Read reads all the entries of the manifest file at the given path, in the
+// order they were written. An edit cut short at the end of the file, as left by
+// a crash while it was appended, is dropped, since it never got acknowledged.
+// A corrupt edit anywhere else fails the read.
+func Read(path string) ([]*Entry, error) {
+	fileptr, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest file: %v", err)
+	}
+	defer fileptr.Close()
+
+	reader := bufio.NewReader(fileptr)
+
+	var magic, version uint32
+	if binary.Read(reader, binary.BigEndian, &magic) != nil || binary.Read(reader, binary.BigEndian, &version) != nil ||
+		magic != formatMagic || version != FormatVersion {
+		return nil, ErrInvalidHeader
+	}
+
+	entries := make([]*Entry, 0)
+	for {
+		var editLen int64
+		err := binary.Read(reader, binary.BigEndian, &editLen)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break // Reached end of manifest file, or a torn edit at its end
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest edit length: %v", err)
+		}
+
+		var checksum uint32
+		if err := binary.Read(reader, binary.BigEndian, &checksum); err != nil {
+			break // torn edit at the end of the file
+		}
+
+		if editLen < 0 || editLen > maxEditSize {
+			return nil, fmt.Errorf("invalid manifest edit length: %d", editLen)
+		}
+
+		editBytes := make([]byte, editLen)
+		if _, err := io.ReadFull(reader, editBytes); err != nil {
+			break // torn edit at the end of the file
+		}
+
+		if crc32.ChecksumIEEE(editBytes) != checksum {
+			if _, err := reader.Peek(1); err == io.EOF {
+				break // the last edit was only partly synced to disk
+			}
+			return nil, fmt.Errorf("manifest edit checksum mismatch, possibly corrupt file")
+		}
+
+		editEntries, err := editFromBytes(editBytes)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, editEntries...)
+	}
+
+	return entries, nil
+}
+
+// Replay applies the entries in order, and returns the SSTables they leave live,
+// keyed by their file names.
+func Replay(entries []*Entry) map[string]*Entry {
+	live := make(map[string]*Entry)
+
+	for _, entry := range entries {
+		switch entry.Op {
+		case OpAdd:
+			live[entry.Filename] = entry
+		case OpRemove:
+			delete(live, entry.Filename)
+		}
+	}
+
+	return live
+}
+
+// serialize encodes the entry with binary encoding, the way the SSTable metadata
+// is, the keys being free to hold any bytes.
+func (e *Entry) serialize(buf *bytes.Buffer) error {
+	for _, field := range []int64{e.Level, e.NumRecords} {
+		if err := binary.Write(buf, binary.BigEndian, field); err != nil {
+			return fmt.Errorf("failed to serialize manifest entry: %v", err)
+		}
+	}
+
+	for _, field := range []string{e.Op, e.Filename, e.FirstKey, e.LastKey} {
+		if err := binary.Write(buf, binary.BigEndian, int32(len(field))); err != nil {
+			return fmt.Errorf("failed to serialize manifest entry field length: %v", err)
+		}
+		buf.WriteString(field)
+	}
+
+	return nil
+}
+
+func editFromBytes(editBytes []byte) ([]*Entry, error) {
+	buf := bytes.NewReader(editBytes)
+
+	var entryCount int32
+	if err := binary.Read(buf, binary.BigEndian, &entryCount); err != nil || entryCount < 0 {
+		return nil, fmt.Errorf("failed to deserialize manifest edit entry count")
+	}
+
+	entries := make([]*Entry, 0, entryCount)
+	for i := int32(0); i < entryCount; i++ {
+		entry, err := entryFromReader(buf)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func entryFromReader(buf *bytes.Reader) (*Entry, error) {
+	entry := &Entry{}
+
+	for _, field := range []*int64{&entry.Level, &entry.NumRecords} {
+		if err := binary.Read(buf, binary.BigEndian, field); err != nil {
+			return nil, fmt.Errorf("failed to deserialize manifest entry: %v", err)
+		}
+	}
+
+	for _, field := range []*string{&entry.Op, &entry.Filename, &entry.FirstKey, &entry.LastKey} {
+		var strLen int32
+		if err := binary.Read(buf, binary.BigEndian, &strLen); err != nil {
+			return nil, fmt.Errorf("failed to deserialize manifest entry field length: %v", err)
+		}
+
+		if strLen < 0 || int64(strLen) > int64(buf.Len()) {
+			return nil, fmt.Errorf("invalid manifest entry field length: %d", strLen)
+		}
+
+		strBytes := make([]byte, strLen)
+		if _, err := io.ReadFull(buf, strBytes); err != nil {
+			return nil, fmt.Errorf("failed to deserialize manifest entry field: %v", err)
+		}
+		*field = string(strBytes)
+	}
+
+	if entry.Op == "" || entry.Filename == "" {
+		return nil, fmt.Errorf("manifest entry is missing its op or its file")
+	}
+	return entry, nil
+}
@@ -0,0 +1,101 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	written := []*Entry{
+		{Op: OpAdd, Filename: "2.sst", Level: 0, FirstKey: "a", LastKey: "key\r\nwith breaks", NumRecords: 10},
+		{Op: OpAdd, Filename: "1.sst", Level: 1, FirstKey: "", LastKey: "z", NumRecords: 0},
+	}
+
+	writer, err := Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create manifest: %v", err)
+	}
+
+	if err := writer.Append(written[0]); err != nil {
+		t.Fatalf("Failed to append to manifest: %v", err)
+	}
+	if err := writer.Append(written[1]); err != nil {
+		t.Fatalf("Failed to append to manifest: %v", err)
+	}
+	writer.Close()
+
+	read, err := Read(path)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+
+	if !reflect.DeepEqual(read, written) {
+		t.Errorf("Expected the entries to be read as written, got %+v", read)
+	}
+}
+
+func TestManifestCorruptionIsReported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	writer, _ := Create(path)
+	writer.Append(&Entry{Op: OpAdd, Filename: "1.sst", FirstKey: "a", LastKey: "b", NumRecords: 2})
+	writer.Append(&Entry{Op: OpAdd, Filename: "2.sst", FirstKey: "c", LastKey: "d", NumRecords: 2})
+	writer.Close()
+
+	content, _ := os.ReadFile(path)
+
+	corrupt := append([]byte(nil), content...)
+	corrupt[30] ^= 0xff // within the first edit
+	os.WriteFile(path, corrupt, 0644)
+
+	if _, err := Read(path); err == nil {
+		t.Errorf("Expected the corrupt edit to be reported")
+	}
+
+	os.WriteFile(path, []byte("not a manifest"), 0644)
+	if _, err := Read(path); err != ErrInvalidHeader {
+		t.Errorf("Expected the invalid header to be reported, got %v", err)
+	}
+}
+
+func TestManifestTornTailIsDropped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	writer, _ := Create(path)
+	writer.Append(&Entry{Op: OpAdd, Filename: "1.sst", NumRecords: 2})
+	writer.Append(&Entry{Op: OpAdd, Filename: "2.sst", NumRecords: 2}, &Entry{Op: OpRemove, Filename: "1.sst"})
+	writer.Close()
+
+	content, _ := os.ReadFile(path)
+
+	for _, torn := range [][]byte{content[:len(content)-5], append(content[:len(content)-1:len(content)-1], content[len(content)-1]^0xff)} {
+		os.WriteFile(path, torn, 0644)
+
+		entries, err := Read(path)
+		if err != nil {
+			t.Fatalf("Expected the torn edit to be dropped, got %v", err)
+		}
+
+		if live := Replay(entries); len(live) != 1 || live["1.sst"] == nil {
+			t.Errorf("Expected only the first SSTable to be live, got %v", live)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	live := Replay([]*Entry{
+		{Op: OpAdd, Filename: "1.sst", Level: 0},
+		{Op: OpAdd, Filename: "2.sst", Level: 0},
+		{Op: OpAdd, Filename: "3.sst", Level: 1},
+		{Op: OpRemove, Filename: "1.sst"},
+		{Op: OpRemove, Filename: "2.sst"},
+	})
+
+	if len(live) != 1 || live["3.sst"] == nil || live["3.sst"].Level != 1 {
+		t.Errorf("Expected only the compacted SSTable to be live, got %v", live)
+	}
+}
@@ -0,0 +1,105 @@
+package lsm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"universum/config"
+	"universum/entity"
+	"universum/storage/lsm/manifest"
+)
+
+func restartTestStore(t *testing.T, store *LSMStore) *LSMStore {
+	if err := store.waitForPendingFlushes(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	restarted := CreateNewLSMStore(config.MemtableStorageTypeLB)
+	if err := restarted.Initialize(); err != nil {
+		t.Fatalf("Failed to restart the store: %v", err)
+	}
+	return restarted
+}
+
+func TestSSTablesAreRestoredFromManifest(t *testing.T) {
+	store := setupTestStore(t)
+	datadir := config.Store.Storage.LSM.DataStorageDirectory
+
+	store.Set("key", "value", 0)
+	store.memTable.Truncate()
+
+	// an SSTable half-written by a crash, which the manifest never listed
+	orphanPath := filepath.Join(datadir, generateSSTableFileName())
+	os.WriteFile(orphanPath, []byte("half-written"), 0644)
+
+	store = restartTestStore(t, store)
+
+	if _, err := os.Stat(orphanPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the orphan SSTable to be removed, got %v", err)
+	}
+
+	if len(store.sstables) != 1 || len(store.compactor.LevelSSTables[0]) != 1 {
+		t.Fatalf("Expected the flushed SSTable to be live at level 0, got %d SSTables", len(store.sstables))
+	}
+
+	if record, code := store.Get("key"); code != entity.CRC_RECORD_FOUND || record.(*entity.ScalarRecord).Value != "value" {
+		t.Errorf("Expected the key to be restored from the SSTable, got code %d", code)
+	}
+}
+
+func TestCompactionIsRestoredFromManifest(t *testing.T) {
+	store := setupTestStore(t)
+	datadir := config.Store.Storage.LSM.DataStorageDirectory
+
+	for i := 0; i < 3; i++ {
+		store.Set("key", fmt.Sprintf("value-%d", i), 0)
+		store.Set(fmt.Sprintf("key-%d", i), "value", 0)
+		store.memTable.Truncate()
+
+		if err := store.waitForPendingFlushes(5 * time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the compaction is recorded in the background
+	var live map[string]*manifest.Entry
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		entries, err := manifest.Read(filepath.Join(datadir, manifest.FileName))
+		if err != nil {
+			t.Fatalf("Failed to read the manifest: %v", err)
+		}
+
+		if live = manifest.Replay(entries); len(live) == 1 {
+			break
+		}
+	}
+
+	if len(live) != 1 {
+		t.Fatalf("Expected the three SSTables to be compacted into one, got %v", live)
+	}
+
+	for _, entry := range live {
+		if entry.Level != 1 || entry.FirstKey != "key" || entry.LastKey != "key-2" || entry.NumRecords != 4 {
+			t.Errorf("Expected the compacted SSTable at level 1 with the 4 keys, got %+v", entry)
+		}
+	}
+
+	store = restartTestStore(t, store)
+
+	if len(store.compactor.LevelSSTables[0]) != 0 || len(store.compactor.LevelSSTables[1]) != 1 {
+		t.Errorf("Expected the compacted SSTable at level 1 after restart, got %d at level 0 and %d at level 1",
+			len(store.compactor.LevelSSTables[0]), len(store.compactor.LevelSSTables[1]))
+	}
+
+	if record, code := store.Get("key"); code != entity.CRC_RECORD_FOUND || record.(*entity.ScalarRecord).Value != "value-2" {
+		t.Errorf("Expected the most recent value to survive the compaction, got %v", record)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(datadir, "*.sst"))
+	if len(files) != 1 {
+		t.Errorf("Expected the compacted SSTables to be deleted, got %v", files)
+	}
+}
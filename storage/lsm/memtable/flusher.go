@@ -1,10 +1,20 @@
 package memtable
 
-// FlusherChan receives a message from Memtable if that memtable is full
-// and needs to be flushed to SSTable on disk by the LSM engine.
-// Listener to this flusher channel is implemented in LSMTree
-var FlusherChan chan MemTable
-
-// WALRotaterChan receives a message from Memtable when it's ready to flush
-// the memtable to disk. This message indicates that the WAL can be rotated
-var WALRotaterChan chan int64
+import "sync/atomic"
+
+// Channels are the channels a memtable hands itself over on once it is full,
+// which belong to the LSM store the memtable is part of.
+type Channels struct {
+	// Flusher receives the memtable which is full, to be flushed to an
+	// SSTable on disk by the LSM store.
+	Flusher chan MemTable
+
+	// WALRotater receives a message once the memtable is handed over to be
+	// flushed, telling the WAL writer to rotate its segment.
+	WALRotater chan int64
+
+	// Pending counts the memtables handed over to be flushed which the LSM
+	// store has not finished flushing yet, so that the SSTables on disk can be
+	// told apart from a complete view of the data.
+	Pending *atomic.Int64
+}
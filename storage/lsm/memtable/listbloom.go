@@ -21,9 +21,11 @@ type ListBloomMemTable struct {
 	// bloom filter size and hash count
 	bfSize      uint64
 	bfHashCount uint8
+
+	channels Channels // where the memtable is handed over once full
 }
 
-func NewListBloomMemTable(maxRecords int64, falsePositiveRate float64) *ListBloomMemTable {
+func NewListBloomMemTable(maxRecords int64, falsePositiveRate float64, channels Channels) *ListBloomMemTable {
 	bfSize, bfHashCount := dslib.OptimalBloomFilterSize(maxRecords, falsePositiveRate)
 	return &ListBloomMemTable{
 		skipList:    dslib.NewSkipList(),
@@ -32,6 +34,7 @@ func NewListBloomMemTable(maxRecords int64, falsePositiveRate float64) *ListBloo
 		maxSize:     config.Store.Storage.LSM.WriteBufferSize,
 		bfSize:      bfSize,
 		bfHashCount: bfHashCount,
+		channels:    channels,
 	}
 }
 
@@ -49,8 +52,7 @@ func (m *ListBloomMemTable) Exists(key string) (bool, uint32) {
 		return false, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := &entity.ScalarRecord{
-		Value:  nil,
+	record := &entity.RecordHeader{
 		Expiry: expiry,
 		State:  state,
 	}
@@ -81,12 +83,7 @@ func (m *ListBloomMemTable) Get(key string) (entity.Record, uint32) {
 		return nil, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := &entity.ScalarRecord{
-		Value:  val,
-		LAT:    utils.GetCurrentEPochTime(),
-		Expiry: expiry,
-		State:  state,
-	}
+	record := entity.NewRecord(val, utils.GetCurrentEPochTime(), expiry, state)
 
 	if found && record.IsTombstoned() {
 		return nil, entity.CRC_RECORD_TOMBSTONED
@@ -98,7 +95,6 @@ func (m *ListBloomMemTable) Get(key string) (entity.Record, uint32) {
 		return nil, entity.CRC_RECORD_EXPIRED
 	}
 
-	record.LAT = utils.GetCurrentEPochTime()
 	return record, entity.CRC_RECORD_FOUND
 }
 
@@ -119,8 +115,8 @@ func (m *ListBloomMemTable) Set(key string, value interface{}, ttl int64, state
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	if m.IsFull() {
-		m.Truncate()
+	if m.size >= m.maxSize {
+		m.truncate()
 	}
 
 	m.skipList.Insert(key, value, expiry, state)
@@ -142,7 +138,11 @@ func (m *ListBloomMemTable) IncrDecrInteger(key string, offset int64, isIncr boo
 		return config.InvalidNumericValue, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
+	record, ok := val.(*entity.ScalarRecord)
+	if !ok {
+		return config.InvalidNumericValue, entity.CRC_WRONG_TYPE
+	}
+
 	if !utils.IsInteger(record.Value) {
 		return config.InvalidNumericValue, entity.CRC_INCR_INVALID_TYPE
 	}
@@ -173,7 +173,10 @@ func (m *ListBloomMemTable) Append(key string, value string) (int64, uint32) {
 		return config.InvalidNumericValue, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
+	record, ok := val.(*entity.ScalarRecord)
+	if !ok {
+		return config.InvalidNumericValue, entity.CRC_WRONG_TYPE
+	}
 
 	if !utils.IsString(record.Value) {
 		return config.InvalidNumericValue, entity.CRC_INCR_INVALID_TYPE
@@ -196,7 +199,7 @@ func (m *ListBloomMemTable) MGet(keys []string) (map[string]interface{}, uint32)
 	for idx := range keys {
 		record, code := m.Get(keys[idx])
 
-		if _, ok := record.(*entity.ScalarRecord); ok {
+		if code == entity.CRC_RECORD_FOUND {
 			responseMap[keys[idx]] = map[string]interface{}{
 				"Value": record.GetValue(),
 				"Code":  code,
@@ -241,9 +244,7 @@ func (m *ListBloomMemTable) TTL(key string) (int64, uint32) {
 		return 0, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
-
-	ttl := record.Expiry - utils.GetCurrentEPochTime()
+	ttl := val.GetExpiry() - utils.GetCurrentEPochTime()
 	return ttl, entity.CRC_RECORD_FOUND
 }
 
@@ -254,19 +255,27 @@ func (m *ListBloomMemTable) Expire(key string, ttl int64) (bool, uint32) {
 		return false, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
-	return m.Set(key, record.Value, ttl, entity.RecordStateActive)
+	return m.Set(key, val.GetValue(), ttl, entity.RecordStateActive)
 }
 
 func (m *ListBloomMemTable) GetSize() int64 {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
 	return m.size
 }
 
 func (m *ListBloomMemTable) IsFull() bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
 	return m.size >= m.maxSize
 }
 
 func (m *ListBloomMemTable) GetCount() int64 {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
 	return int64(m.skipList.Size())
 }
 
@@ -277,7 +286,25 @@ func (m *ListBloomMemTable) GetAll() []*entity.RecordKV {
 	return m.skipList.GetAllRecords()
 }
 
+// GetRecordsFrom retrieves the records with keys greater than or equal to the given
+// one in sorted order, including the tombstoned and expired ones.
+func (m *ListBloomMemTable) GetRecordsFrom(from string) []*entity.RecordKV {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.skipList.GetRecordsFrom(from)
+}
+
 func (m *ListBloomMemTable) Truncate() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.truncate()
+}
+
+// truncate hands the records to the flusher and starts over with an empty
+// memtable. The caller must hold the memtable lock.
+func (m *ListBloomMemTable) truncate() error {
 	backupMemtable := &ListBloomMemTable{
 		skipList:    m.skipList,
 		bloomFilter: m.bloomFilter,
@@ -290,8 +317,9 @@ func (m *ListBloomMemTable) Truncate() error {
 	m.size = 0
 	m.sizeMap = sync.Map{}
 
-	FlusherChan <- backupMemtable
-	WALRotaterChan <- time.Now().UnixNano()
+	m.channels.Pending.Add(1)
+	m.channels.Flusher <- backupMemtable
+	m.channels.WALRotater <- time.Now().UnixNano()
 
 	logger.Get().Info("Memtable truncated after size=%d, count=%d",
 		backupMemtable.size, backupMemtable.skipList.Size())
@@ -21,19 +21,22 @@ type MemTable interface {
 	IsFull() bool
 	GetCount() int64
 	GetAll() []*entity.RecordKV
+	GetRecordsFrom(from string) []*entity.RecordKV
 	Truncate() error
 }
 
-func CreateNewMemTable(tabletype string) MemTable {
+// CreateNewMemTable creates a memtable of the given type, which hands itself over
+// on the given channels once it is full.
+func CreateNewMemTable(tabletype string, channels Channels) MemTable {
 	switch tabletype {
 	case config.MemtableStorageTypeLB: // implementated with skiplist + bloom filter
 		lsmCnf := config.Store.Storage.LSM
-		return NewListBloomMemTable(lsmCnf.BloomFilterMaxRecords, lsmCnf.BloomFalsePositiveRate)
+		return NewListBloomMemTable(lsmCnf.BloomFilterMaxRecords, lsmCnf.BloomFalsePositiveRate, channels)
 
 	case config.MemtableStorageTypeTB: // implemented with redblack tree + bloom filter
-		return &TreeBloomMemTable{}
+		return &TreeBloomMemTable{channels: channels}
 
 	default:
-		return &ListBloomMemTable{}
+		return &ListBloomMemTable{channels: channels}
 	}
 }
@@ -13,7 +13,7 @@ func TestCreateNewMemTable_TypeLB(t *testing.T) {
 	config.Store.Storage.LSM.BloomFalsePositiveRate = 0.01
 	config.Store.Logging.LogFileDirectory = tmpdir
 
-	memTable := CreateNewMemTable(config.MemtableStorageTypeLB)
+	memTable := CreateNewMemTable(config.MemtableStorageTypeLB, Channels{})
 	_, ok := memTable.(*ListBloomMemTable)
 	if !ok {
 		t.Errorf("Expected memTable to be of type *ListBloomMemTable, got %T", memTable)
@@ -23,7 +23,7 @@ func TestCreateNewMemTable_TypeLB(t *testing.T) {
 func TestCreateNewMemTable_TypeTB(t *testing.T) {
 	config.Store = config.GetSkeleton()
 
-	memTable := CreateNewMemTable(config.MemtableStorageTypeTB)
+	memTable := CreateNewMemTable(config.MemtableStorageTypeTB, Channels{})
 	_, ok := memTable.(*TreeBloomMemTable)
 	if !ok {
 		t.Errorf("Expected memTable to be of type *ListMapMemTable, got %T", memTable)
@@ -35,7 +35,7 @@ func TestCreateNewMemTable_Default(t *testing.T) {
 	config.Store.Storage.LSM.BloomFilterMaxRecords = 1000
 	config.Store.Storage.LSM.BloomFalsePositiveRate = 0.01
 
-	memTable := CreateNewMemTable("randomvalue")
+	memTable := CreateNewMemTable("randomvalue", Channels{})
 	_, ok := memTable.(*ListBloomMemTable)
 	if !ok {
 		t.Errorf("Expected memTable to be of type *ListBloomMemTable, got %T", memTable)
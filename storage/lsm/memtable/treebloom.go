@@ -21,10 +21,12 @@ type TreeBloomMemTable struct {
 	// Bloom Filter configuration
 	bfSize      uint64
 	bfHashCount uint8
+
+	channels Channels // where the memtable is handed over once full
 }
 
 // NewTreeBloomMemTable initializes a new TreeBloomMemTable with a specified maximum record count and false positive rate.
-func NewTreeBloomMemTable(maxRecords int64, falsePositiveRate float64) *TreeBloomMemTable {
+func NewTreeBloomMemTable(maxRecords int64, falsePositiveRate float64, channels Channels) *TreeBloomMemTable {
 	bfSize, bfHashCount := dslib.OptimalBloomFilterSize(maxRecords, falsePositiveRate)
 	return &TreeBloomMemTable{
 		rbTree:      dslib.NewRBTree(),
@@ -32,6 +34,7 @@ func NewTreeBloomMemTable(maxRecords int64, falsePositiveRate float64) *TreeBloo
 		maxSize:     config.Store.Storage.LSM.WriteBufferSize,
 		bfSize:      bfSize,
 		bfHashCount: bfHashCount,
+		channels:    channels,
 	}
 }
 
@@ -44,13 +47,12 @@ func (m *TreeBloomMemTable) Exists(key string) (bool, uint32) {
 		return false, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	found, val, expiry, state := m.rbTree.Get(key)
+	found, _, expiry, state := m.rbTree.Get(key)
 	if !found {
 		return false, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := &entity.ScalarRecord{
-		Value:  val,
+	record := &entity.RecordHeader{
 		Expiry: expiry,
 		State:  state,
 	}
@@ -82,12 +84,7 @@ func (m *TreeBloomMemTable) Get(key string) (entity.Record, uint32) {
 		return nil, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := &entity.ScalarRecord{
-		Value:  val,
-		LAT:    utils.GetCurrentEPochTime(),
-		Expiry: expiry,
-		State:  state,
-	}
+	record := entity.NewRecord(val, utils.GetCurrentEPochTime(), expiry, state)
 
 	if record.IsTombstoned() {
 		return nil, entity.CRC_RECORD_TOMBSTONED
@@ -99,7 +96,6 @@ func (m *TreeBloomMemTable) Get(key string) (entity.Record, uint32) {
 		return nil, entity.CRC_RECORD_EXPIRED
 	}
 
-	record.LAT = utils.GetCurrentEPochTime()
 	return record, entity.CRC_RECORD_FOUND
 }
 
@@ -121,8 +117,8 @@ func (m *TreeBloomMemTable) Set(key string, value interface{}, ttl int64, state
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	if m.IsFull() {
-		m.Truncate()
+	if m.size >= m.maxSize {
+		m.truncate()
 	}
 
 	m.rbTree.Insert(key, value, expiry, state)
@@ -146,7 +142,11 @@ func (m *TreeBloomMemTable) IncrDecrInteger(key string, offset int64, isIncr boo
 		return config.InvalidNumericValue, code
 	}
 
-	record := val.(*entity.ScalarRecord)
+	record, ok := val.(*entity.ScalarRecord)
+	if !ok {
+		return config.InvalidNumericValue, entity.CRC_WRONG_TYPE
+	}
+
 	if !utils.IsInteger(record.Value) {
 		return config.InvalidNumericValue, entity.CRC_INCR_INVALID_TYPE
 	}
@@ -175,7 +175,11 @@ func (m *TreeBloomMemTable) Append(key string, value string) (int64, uint32) {
 		return config.InvalidNumericValue, code
 	}
 
-	record := val.(*entity.ScalarRecord)
+	record, ok := val.(*entity.ScalarRecord)
+	if !ok {
+		return config.InvalidNumericValue, entity.CRC_WRONG_TYPE
+	}
+
 	if !utils.IsString(record.Value) {
 		return config.InvalidNumericValue, entity.CRC_INCR_INVALID_TYPE
 	}
@@ -198,7 +202,7 @@ func (m *TreeBloomMemTable) MGet(keys []string) (map[string]interface{}, uint32)
 	for idx := range keys {
 		record, code := m.Get(keys[idx])
 
-		if _, ok := record.(*entity.ScalarRecord); ok {
+		if code == entity.CRC_RECORD_FOUND {
 			responseMap[keys[idx]] = map[string]interface{}{
 				"Value": record.GetValue(),
 				"Code":  code,
@@ -245,9 +249,7 @@ func (m *TreeBloomMemTable) TTL(key string) (int64, uint32) {
 		return 0, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
-
-	ttl := record.Expiry - utils.GetCurrentEPochTime()
+	ttl := val.GetExpiry() - utils.GetCurrentEPochTime()
 	return ttl, entity.CRC_RECORD_FOUND
 }
 
@@ -259,22 +261,30 @@ func (m *TreeBloomMemTable) Expire(key string, ttl int64) (bool, uint32) {
 		return false, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
-	return m.Set(key, record.Value, ttl, entity.RecordStateActive)
+	return m.Set(key, val.GetValue(), ttl, entity.RecordStateActive)
 }
 
 // GetSize returns the current size of the memtable.
 func (m *TreeBloomMemTable) GetSize() int64 {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
 	return m.size
 }
 
 // IsFull checks if the memtable has reached its maximum size.
 func (m *TreeBloomMemTable) IsFull() bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
 	return m.size >= m.maxSize
 }
 
 // GetCount returns the total number of entries in the memtable.
 func (m *TreeBloomMemTable) GetCount() int64 {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
 	return m.rbTree.GetSize()
 }
 
@@ -286,8 +296,26 @@ func (m *TreeBloomMemTable) GetAll() []*entity.RecordKV {
 	return m.rbTree.GetAllRecords()
 }
 
+// GetRecordsFrom retrieves the records with keys greater than or equal to the given
+// one in sorted order, including the tombstoned and expired ones.
+func (m *TreeBloomMemTable) GetRecordsFrom(from string) []*entity.RecordKV {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.rbTree.GetRecordsFrom(from)
+}
+
 // Truncate clears the memtable, freeing memory space.
 func (m *TreeBloomMemTable) Truncate() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.truncate()
+}
+
+// truncate hands the records to the flusher and starts over with an empty
+// memtable. The caller must hold the memtable lock.
+func (m *TreeBloomMemTable) truncate() error {
 	backupMemtable := &TreeBloomMemTable{
 		rbTree:      m.rbTree,
 		bloomFilter: m.bloomFilter,
@@ -300,8 +328,9 @@ func (m *TreeBloomMemTable) Truncate() error {
 	m.size = 0
 	m.sizeMap = sync.Map{}
 
-	FlusherChan <- backupMemtable
-	WALRotaterChan <- time.Now().UnixNano()
+	m.channels.Pending.Add(1)
+	m.channels.Flusher <- backupMemtable
+	m.channels.WALRotater <- time.Now().UnixNano()
 
 	logger.Get().Info("Memtable truncated after size=%d, count=%d",
 		backupMemtable.size, backupMemtable.rbTree.GetSize())
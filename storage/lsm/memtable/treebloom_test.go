@@ -2,6 +2,7 @@ package memtable
 
 import (
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 	"universum/config"
@@ -21,7 +22,7 @@ func SetUpTBTests(t *testing.T) {
 func TestTreeBloomMemTable_SetAndGet(t *testing.T) {
 	SetUpTBTests(t)
 
-	mt := NewTreeBloomMemTable(100, 0.01)
+	mt := NewTreeBloomMemTable(100, 0.01, Channels{})
 	key := "testKey"
 	value := "testValue"
 	invalidValue := map[int]int{1: 2}
@@ -45,7 +46,7 @@ func TestTreeBloomMemTable_SetAndGet(t *testing.T) {
 func TestTreeBloomMemTable_Exists(t *testing.T) {
 	SetUpTBTests(t)
 
-	mt := NewTreeBloomMemTable(100, 0.01)
+	mt := NewTreeBloomMemTable(100, 0.01, Channels{})
 	key := "testKey"
 	value := "testValue"
 
@@ -72,7 +73,7 @@ func TestTreeBloomMemTable_Exists(t *testing.T) {
 func TestTreeBloomMemTable_Delete(t *testing.T) {
 	SetUpTBTests(t)
 
-	mt := NewTreeBloomMemTable(100, 0.01)
+	mt := NewTreeBloomMemTable(100, 0.01, Channels{})
 	key := "testKey"
 	value := "testValue"
 
@@ -92,7 +93,7 @@ func TestTreeBloomMemTable_Delete(t *testing.T) {
 func TestTreeBloomMemTable_SizeManagement(t *testing.T) {
 	SetUpTBTests(t)
 
-	mt := NewTreeBloomMemTable(100, 0.01)
+	mt := NewTreeBloomMemTable(100, 0.01, Channels{})
 	key := "testKey"
 	value := "testValue"
 
@@ -114,7 +115,7 @@ func TestTreeBloomMemTable_SizeManagement(t *testing.T) {
 func TestTreeBloomMemTable_KeyExpired(t *testing.T) {
 	SetUpTBTests(t)
 
-	mt := NewTreeBloomMemTable(100, 0.01)
+	mt := NewTreeBloomMemTable(100, 0.01, Channels{})
 	key := "testKey"
 	value := "testValue"
 	ttl := int64(1)
@@ -137,7 +138,7 @@ func TestTreeBloomMemTable_KeyExpired(t *testing.T) {
 func TestTreeBloomMemTable_Expire(t *testing.T) {
 	SetUpTBTests(t)
 
-	mt := NewTreeBloomMemTable(100, 0.01)
+	mt := NewTreeBloomMemTable(100, 0.01, Channels{})
 	key := "testKey"
 	value := "testValue"
 	ttl := int64(10)
@@ -158,7 +159,7 @@ func TestTreeBloomMemTable_Expire(t *testing.T) {
 func TestTreeBloomMemTable_IncrDecr(t *testing.T) {
 	SetUpTBTests(t)
 
-	mt := NewTreeBloomMemTable(100, 0.01)
+	mt := NewTreeBloomMemTable(100, 0.01, Channels{})
 	key := "testKey"
 	initialValue := int64(10)
 
@@ -178,7 +179,7 @@ func TestTreeBloomMemTable_IncrDecr(t *testing.T) {
 func TestTreeBloomMemTable_Append(t *testing.T) {
 	SetUpTBTests(t)
 
-	mt := NewTreeBloomMemTable(100, 0.01)
+	mt := NewTreeBloomMemTable(100, 0.01, Channels{})
 	key := "testKey"
 	initialValue := "abcd"
 	initialInvalidValue := 100
@@ -200,7 +201,7 @@ func TestTreeBloomMemTable_Append(t *testing.T) {
 
 func TestTreeBloomMemTable_MSet(t *testing.T) {
 	SetUpTBTests(t)
-	tbMem := NewTreeBloomMemTable(100, 0.01)
+	tbMem := NewTreeBloomMemTable(100, 0.01, Channels{})
 
 	kvMap := map[string]interface{}{
 		"key1": "value1",
@@ -222,7 +223,7 @@ func TestTreeBloomMemTable_MSet(t *testing.T) {
 
 func TestTreeBloomMemTable_MGet(t *testing.T) {
 	SetUpTBTests(t)
-	tbMem := NewTreeBloomMemTable(100, 0.01)
+	tbMem := NewTreeBloomMemTable(100, 0.01, Channels{})
 
 	kvMap := map[string]interface{}{
 		"key1": "value1",
@@ -250,7 +251,7 @@ func TestTreeBloomMemTable_MGet(t *testing.T) {
 
 func TestTreeBloomMemTable_MDelete(t *testing.T) {
 	SetUpLBTests(t)
-	lbMem := NewTreeBloomMemTable(100, 0.01)
+	lbMem := NewTreeBloomMemTable(100, 0.01, Channels{})
 
 	kvMap := map[string]interface{}{
 		"key1": "value1",
@@ -273,7 +274,7 @@ func TestTreeBloomMemTable_MDelete(t *testing.T) {
 func TestTreeBloomMemTable_TTL(t *testing.T) {
 	SetUpLBTests(t)
 
-	mt := NewTreeBloomMemTable(100, 0.01)
+	mt := NewTreeBloomMemTable(100, 0.01, Channels{})
 	key := "testKey"
 	value := "testValue"
 	ttl := int64(1)
@@ -296,7 +297,7 @@ func TestTreeBloomMemTable_TTL(t *testing.T) {
 func TestTreeBloomMemTable_IsFull(t *testing.T) {
 	SetUpLBTests(t)
 
-	mt := NewTreeBloomMemTable(100, 0.01)
+	mt := NewTreeBloomMemTable(100, 0.01, Channels{})
 	isfull := mt.IsFull()
 
 	if isfull == true {
@@ -307,7 +308,7 @@ func TestTreeBloomMemTable_IsFull(t *testing.T) {
 func TestTreeBloomMemTable_GetCount(t *testing.T) {
 	SetUpLBTests(t)
 
-	mt := NewTreeBloomMemTable(100, 0.01)
+	mt := NewTreeBloomMemTable(100, 0.01, Channels{})
 	count := mt.GetCount()
 
 	if count != 0 {
@@ -327,10 +328,8 @@ func TestTreeBloomMemTable_Truncate(t *testing.T) {
 	SetUpTBTests(t)
 	config.Store.Storage.LSM.WriteBufferSize = 100
 
-	FlusherChan = make(chan MemTable, 2)
-	WALRotaterChan = make(chan int64, 2)
-
-	tbMem := NewTreeBloomMemTable(100, 0.01)
+	channels := Channels{Flusher: make(chan MemTable, 2), WALRotater: make(chan int64, 2), Pending: new(atomic.Int64)}
+	tbMem := NewTreeBloomMemTable(100, 0.01, channels)
 
 	kvMap := map[string]interface{}{
 		"key1": "value1",
@@ -346,7 +345,7 @@ func TestTreeBloomMemTable_Truncate(t *testing.T) {
 	}
 
 	select {
-	case item := <-FlusherChan:
+	case item := <-channels.Flusher:
 		var backupMemtable *TreeBloomMemTable
 		backupMemtable = item.(*TreeBloomMemTable)
 
@@ -354,15 +353,14 @@ func TestTreeBloomMemTable_Truncate(t *testing.T) {
 			t.Error("Expected flushed memtable to have 3 records")
 		}
 	default:
-		t.Error("Expected an entry in FlusherChan for table flushing")
+		t.Error("Expected an entry in the flusher channel for table flushing")
 	}
 }
 
 func TestTreeBloomMemTable_GetAll(t *testing.T) {
 	SetUpTBTests(t)
 
-	FlusherChan = make(chan MemTable, 1)
-	tbMem := NewTreeBloomMemTable(100, 0.01)
+	tbMem := NewTreeBloomMemTable(100, 0.01, Channels{})
 
 	kvMap := map[string]interface{}{
 		"key1": "value1",
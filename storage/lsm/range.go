@@ -0,0 +1,58 @@
+package lsm
+
+import (
+	"strings"
+	"universum/entity"
+	"universum/storage"
+)
+
+// Range returns the live records with keys between start and end, both inclusive,
+// in ascending key order, or descending when reverse is set. At most limit
+// records are returned.
+func (lsm *LSMStore) Range(start string, end string, limit int64, reverse bool) ([]*entity.RecordKV, uint32) {
+	if start > end {
+		return []*entity.RecordKV{}, entity.CRC_RANGE_COMPLETED
+	}
+
+	return lsm.collectRange(start, limit, reverse, func(key string) bool {
+		return key <= end
+	})
+}
+
+// Prefix returns the live records with keys starting with the prefix, in
+// ascending key order. At most limit records are returned.
+func (lsm *LSMStore) Prefix(prefix string, limit int64) ([]*entity.RecordKV, uint32) {
+	return lsm.collectRange(prefix, limit, false, func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// collectRange walks the merged levels from the start key for as long as the keys
+// are within the range, and collects the live records found on the way.
+func (lsm *LSMStore) collectRange(start string, limit int64, reverse bool,
+	inRange func(key string) bool) ([]*entity.RecordKV, uint32) {
+
+	iterator := lsm.newMergingIterator(start, true)
+	collector := storage.NewRangeCollector(limit, reverse)
+
+	for {
+		recordKV, err := iterator.Next()
+		if err != nil {
+			return []*entity.RecordKV{}, entity.CRC_DATA_READ_ERROR
+		}
+
+		if recordKV == nil || !inRange(recordKV.Key) {
+			break
+		}
+
+		if recordKV.Record.IsTombstoned() || recordKV.Record.IsExpired() {
+			continue
+		}
+
+		if !collector.Add(recordKV) {
+			break
+		}
+	}
+
+	return collector.Records(), entity.CRC_RANGE_COMPLETED
+}
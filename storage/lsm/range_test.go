@@ -0,0 +1,87 @@
+package lsm
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+	"universum/entity"
+)
+
+func rangeKeys(records []*entity.RecordKV) []string {
+	keys := make([]string, len(records))
+	for idx, recordKV := range records {
+		keys[idx] = recordKV.Key
+	}
+	return keys
+}
+
+func TestLSMStoreRangeAcrossLevels(t *testing.T) {
+	store := setupTestStore(t)
+
+	for i := 0; i < 10; i++ {
+		store.Set(fmt.Sprintf("ts:%02d", i), int64(i), 0)
+	}
+
+	store.memTable.Truncate()
+	time.Sleep(2 * time.Second)
+
+	store.Delete("ts:02")
+	store.Set("ts:04", "updated", 0)
+	store.Set("ts:05", "expiring", 1)
+	store.Set("other", "x", 0)
+	time.Sleep(2100 * time.Millisecond)
+
+	tests := []struct {
+		name     string
+		start    string
+		end      string
+		limit    int64
+		reverse  bool
+		expected []string
+	}{
+		{"Full", "ts:00", "ts:99", 100, false, []string{"ts:00", "ts:01", "ts:03", "ts:04", "ts:06", "ts:07", "ts:08", "ts:09"}},
+		{"Bounded", "ts:03", "ts:06", 100, false, []string{"ts:03", "ts:04", "ts:06"}},
+		{"Limited", "ts:00", "ts:99", 3, false, []string{"ts:00", "ts:01", "ts:03"}},
+		{"Reverse", "ts:00", "ts:99", 3, true, []string{"ts:09", "ts:08", "ts:07"}},
+		{"Empty", "ts:50", "ts:99", 100, false, []string{}},
+		{"Inverted", "ts:99", "ts:00", 100, false, []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			records, code := store.Range(test.start, test.end, test.limit, test.reverse)
+			if code != entity.CRC_RANGE_COMPLETED {
+				t.Fatalf("unexpected code %d", code)
+			}
+
+			if keys := rangeKeys(records); !reflect.DeepEqual(keys, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, keys)
+			}
+		})
+	}
+
+	records, _ := store.Range("ts:04", "ts:04", 1, false)
+	if len(records) != 1 || records[0].Record.GetValue() != "updated" {
+		t.Errorf("expected the newest version of ts:04, got %v", records)
+	}
+}
+
+func TestLSMStorePrefix(t *testing.T) {
+	store := setupTestStore(t)
+
+	store.Set("user:1", 1, 0)
+	store.Set("user:2", 2, 0)
+	store.Set("user_x", 3, 0)
+	store.Set("users", 4, 0)
+
+	records, code := store.Prefix("user:", 10)
+	if code != entity.CRC_RANGE_COMPLETED || !reflect.DeepEqual(rangeKeys(records), []string{"user:1", "user:2"}) {
+		t.Errorf("unexpected prefix result %v, %d", rangeKeys(records), code)
+	}
+
+	records, _ = store.Prefix("user", 3)
+	if !reflect.DeepEqual(rangeKeys(records), []string{"user:1", "user:2", "user_x"}) {
+		t.Errorf("unexpected limited prefix result %v", rangeKeys(records))
+	}
+}
@@ -0,0 +1,42 @@
+package lsm
+
+import (
+	"universum/entity"
+	"universum/storage"
+)
+
+// Scan iterates over the keyspace in key order, merging the memtable and the
+// SSTables, and examines up to options.Count keys per call. The cursor holds
+// the last examined key, so every key which lives through the whole iteration
+// is returned exactly once.
+func (lsm *LSMStore) Scan(cursor string, options storage.ScanOptions) (string, []string, uint32) {
+	_, lastKey, resume, err := storage.ParseScanCursor(cursor)
+	if err != nil {
+		return storage.ScanCursorStart, []string{}, entity.CRC_INVALID_CMD_INPUT
+	}
+
+	iterator := lsm.newMergingIterator(lastKey, !resume)
+
+	keys := make([]string, 0)
+	var examined int64 = 0
+
+	for {
+		recordKV, err := iterator.Next()
+		if err != nil {
+			return storage.ScanCursorStart, []string{}, entity.CRC_DATA_READ_ERROR
+		}
+
+		if recordKV == nil {
+			return storage.ScanCursorStart, keys, entity.CRC_SCAN_COMPLETED
+		}
+
+		if options.Accepts(recordKV.Key, recordKV.Record) {
+			keys = append(keys, recordKV.Key)
+		}
+
+		examined++
+		if examined >= options.Count {
+			return storage.NewScanCursor(0, recordKV.Key), keys, entity.CRC_SCAN_COMPLETED
+		}
+	}
+}
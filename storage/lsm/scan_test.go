@@ -0,0 +1,72 @@
+package lsm
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+	"universum/entity"
+	"universum/storage"
+)
+
+func TestLSMStoreScanMergesLevels(t *testing.T) {
+	store := setupTestStore(t)
+
+	for i := 0; i < 20; i++ {
+		store.Set(fmt.Sprintf("key:%02d", i), i, 0)
+	}
+
+	store.memTable.Truncate()
+	time.Sleep(2 * time.Second)
+
+	// newer versions in the memtable shadow the flushed ones
+	store.Delete("key:03")
+	store.Set("key:05", "updated", 0)
+	store.Set("key:20", 20, 0)
+	store.HSet("hash:1", map[string]interface{}{"a": 1})
+
+	found := make([]string, 0)
+	cursor := storage.ScanCursorStart
+
+	for {
+		next, keys, code := store.Scan(cursor, storage.ScanOptions{Count: 4, Match: "key:*"})
+		if code != entity.CRC_SCAN_COMPLETED {
+			t.Fatalf("unexpected scan code %d", code)
+		}
+
+		found = append(found, keys...)
+		if next == storage.ScanCursorStart {
+			break
+		}
+		cursor = next
+	}
+
+	expected := make([]string, 0)
+	for i := 0; i <= 20; i++ {
+		if i != 3 {
+			expected = append(expected, fmt.Sprintf("key:%02d", i))
+		}
+	}
+
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected %v, got %v", expected, found)
+	}
+
+	_, keys, _ := store.Scan(storage.ScanCursorStart, storage.ScanOptions{Count: 100, Family: entity.RecordTypeHash})
+	if !reflect.DeepEqual(keys, []string{"hash:1"}) {
+		t.Errorf("unexpected keys for TYPE: %v", keys)
+	}
+}
+
+func TestLSMStoreScanSkipsExpired(t *testing.T) {
+	store := setupTestStore(t)
+
+	store.Set("alive", 1, 0)
+	store.Set("expiring", 1, 1)
+	time.Sleep(2100 * time.Millisecond)
+
+	_, keys, _ := store.Scan(storage.ScanCursorStart, storage.ScanOptions{Count: 10})
+	if !reflect.DeepEqual(keys, []string{"alive"}) {
+		t.Errorf("expected only the live key, got %v", keys)
+	}
+}
@@ -0,0 +1,49 @@
+package lsm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"universum/entity"
+)
+
+func TestLSMStoreSetOperations(t *testing.T) {
+	store := setupTestStore(t)
+
+	store.SAdd("s1", []string{"a", "b", "c"})
+	store.SAdd("s2", []string{"b", "d"})
+
+	if members, _ := store.SInter([]string{"s1", "s2"}); !reflect.DeepEqual(members, []string{"b"}) {
+		t.Errorf("unexpected intersection %v", members)
+	}
+
+	if members, _ := store.SDiff([]string{"s1", "s2"}); !reflect.DeepEqual(members, []string{"a", "c"}) {
+		t.Errorf("unexpected difference %v", members)
+	}
+
+	store.SRem("s2", []string{"b", "d"})
+	if exists, _ := store.Exists("s2"); exists {
+		t.Errorf("expected the emptied set to be removed")
+	}
+}
+
+func TestLSMStoreSetsAfterFlush(t *testing.T) {
+	store := setupTestStore(t)
+
+	store.SAdd("tags", []string{"go", "db"})
+	store.ZAdd("board", map[string]float64{"alice": 2.5, "bob": 1})
+
+	store.memTable.Truncate()
+	time.Sleep(2 * time.Second)
+
+	members, code := store.SMembers("tags")
+	if code != entity.CRC_RECORD_FOUND || !reflect.DeepEqual(members, []string{"db", "go"}) {
+		t.Errorf("unexpected set after flush %v, %d", members, code)
+	}
+
+	expected := []entity.ScoredMember{{Member: "bob", Score: 1}, {Member: "alice", Score: 2.5}}
+	scored, code := store.ZRange("board", 0, -1)
+	if code != entity.CRC_RECORD_FOUND || !reflect.DeepEqual(scored, expected) {
+		t.Errorf("unexpected sorted set after flush %v, %d", scored, code)
+	}
+}
@@ -14,10 +14,16 @@ var (
 
 type LSMStoreSnapshotService struct{}
 
+// Snapshot takes a checkpoint of the store, which a fresh node can be started
+// from, see LSMStore.Checkpoint.
 func (ms *LSMStoreSnapshotService) Snapshot(datastore storage.DataStore) (int64, int64, error) {
-	return 0, 0, nil
+	return datastore.(*LSMStore).Checkpoint()
 }
 
+// Restore replays the WAL segments of the memtables which were not flushed before
+// the last shutdown, the oldest first, and hands the restored memtable over to
+// the flusher, which releases the segments once it is flushed. The bytes of the
+// torn or corrupt entries the replay dropped are kept for the statistics.
 func (ms *LSMStoreSnapshotService) Restore(datastore storage.DataStore) (int64, error) {
 	restoreMutex.Lock()
 	defer restoreMutex.Unlock()
@@ -26,8 +32,10 @@ func (ms *LSMStoreSnapshotService) Restore(datastore storage.DataStore) (int64,
 	if err != nil {
 		return 0, fmt.Errorf("failed to create WAL reader: %v", err)
 	}
+	defer walReader.Close()
 
 	keycount, err := walReader.RestoreFromWAL(datastore.(*LSMStore).memTable)
+	datastore.(*LSMStore).walDroppedBytes.Store(walReader.DroppedBytes())
 	if err != nil {
 		return keycount, fmt.Errorf("failed to restore from WAL: %v", err)
 	}
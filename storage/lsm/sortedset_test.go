@@ -0,0 +1,29 @@
+package lsm
+
+import (
+	"testing"
+	"universum/entity"
+)
+
+func TestLSMStoreSortedSetOperations(t *testing.T) {
+	store := setupTestStore(t)
+
+	store.ZAdd("board", map[string]float64{"alice": 30, "bob": 10})
+
+	if score, _ := store.ZIncrBy("board", "bob", 25); score != 35 {
+		t.Errorf("expected score 35 for bob, got %v", score)
+	}
+
+	if rank, code := store.ZRank("board", "bob"); rank != 1 || code != entity.CRC_RECORD_FOUND {
+		t.Errorf("expected rank 1 for bob, got %d, %d", rank, code)
+	}
+
+	if members, _ := store.ZRangeByScore("board", 31, 40); len(members) != 1 || members[0].Member != "bob" {
+		t.Errorf("unexpected range by score %v", members)
+	}
+
+	store.ZRem("board", []string{"alice", "bob"})
+	if exists, _ := store.Exists("board"); exists {
+		t.Errorf("expected the emptied sorted set to be removed")
+	}
+}
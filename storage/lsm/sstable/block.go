@@ -65,7 +65,7 @@ func (b *Block) GetRecord(key string) (entity.Record, error) {
 		return nil, fmt.Errorf("error in reading record for key '%s': %v", key, err)
 	}
 
-	record, err := resp3.GetScalarRecordFromResp(string(encodedRecord))
+	record, err := resp3.GetRecordFromResp(string(encodedRecord))
 	if err != nil {
 		return nil, fmt.Errorf("record for '%s' found in invalid format", key)
 	}
@@ -270,7 +270,7 @@ func (b *Block) GetAllRecords() ([]*entity.RecordKV, error) {
 			return true // ignore the faulty record, and move on.
 		}
 
-		_, record := (&entity.ScalarRecord{}).FromMap(decodedRecord.(map[string]interface{}))
+		_, record := entity.RecordFromMap(decodedRecord.(map[string]interface{}))
 
 		recordList = append(recordList, &entity.RecordKV{
 			Key:    string(keyBytes),
@@ -26,9 +26,11 @@ func TestBlock_AddRecord(t *testing.T) {
 	bloom := dslib.NewBloomFilter(1000, 5)
 
 	record := &entity.ScalarRecord{
-		Value:  "testValue",
-		LAT:    utils.GetCurrentEPochTime(),
-		Expiry: utils.GetCurrentEPochTime() + 1000,
+		Value: "testValue",
+		RecordHeader: entity.RecordHeader{
+			LAT:    utils.GetCurrentEPochTime(),
+			Expiry: utils.GetCurrentEPochTime() + 1000,
+		},
 	}
 
 	err := block.AddRecord("testKey", record.ToMap(), bloom)
@@ -50,9 +52,11 @@ func TestBlock_SerializeBlock(t *testing.T) {
 	bloom := dslib.NewBloomFilter(1000, 5)
 
 	record := &entity.ScalarRecord{
-		Value:  "testValue",
-		LAT:    utils.GetCurrentEPochTime(),
-		Expiry: utils.GetCurrentEPochTime() + 1000,
+		Value: "testValue",
+		RecordHeader: entity.RecordHeader{
+			LAT:    utils.GetCurrentEPochTime(),
+			Expiry: utils.GetCurrentEPochTime() + 1000,
+		},
 	}
 
 	block.AddRecord("testKey", record.ToMap(), bloom)
@@ -75,9 +79,11 @@ func TestBlock_ReadRecordAtOffset(t *testing.T) {
 	bloom := dslib.NewBloomFilter(1000, 5)
 
 	record := &entity.ScalarRecord{
-		Value:  "testValue",
-		LAT:    utils.GetCurrentEPochTime(),
-		Expiry: utils.GetCurrentEPochTime() + 1000,
+		Value: "testValue",
+		RecordHeader: entity.RecordHeader{
+			LAT:    utils.GetCurrentEPochTime(),
+			Expiry: utils.GetCurrentEPochTime() + 1000,
+		},
 	}
 
 	block.AddRecord("testKey", record.ToMap(), bloom)
@@ -121,9 +127,11 @@ func TestBlock_RemainingSpace(t *testing.T) {
 	}
 
 	record := &entity.ScalarRecord{
-		Value:  "testValue",
-		LAT:    utils.GetCurrentEPochTime(),
-		Expiry: utils.GetCurrentEPochTime() + 1000,
+		Value: "testValue",
+		RecordHeader: entity.RecordHeader{
+			LAT:    utils.GetCurrentEPochTime(),
+			Expiry: utils.GetCurrentEPochTime() + 1000,
+		},
 	}
 
 	bloom := dslib.NewBloomFilter(1000, 5)
@@ -138,9 +146,11 @@ func TestBlock_ValidateBlock(t *testing.T) {
 	bloom := dslib.NewBloomFilter(1000, 5)
 
 	record := &entity.ScalarRecord{
-		Value:  "testValue",
-		LAT:    utils.GetCurrentEPochTime(),
-		Expiry: utils.GetCurrentEPochTime() + 1000,
+		Value: "testValue",
+		RecordHeader: entity.RecordHeader{
+			LAT:    utils.GetCurrentEPochTime(),
+			Expiry: utils.GetCurrentEPochTime() + 1000,
+		},
 	}
 
 	block.AddRecord("testKey", record.ToMap(), bloom)
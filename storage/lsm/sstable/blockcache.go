@@ -4,6 +4,7 @@ import (
 	"container/list"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"universum/config"
 	"universum/entity"
 )
@@ -24,6 +25,20 @@ type BlockCacheShard struct {
 
 type BlockCache struct {
 	shards [ShardCount]*BlockCacheShard
+
+	hits      atomic.Int64 // Lookups which found the block in the cache
+	misses    atomic.Int64 // Lookups which did not
+	evictions atomic.Int64 // Blocks evicted to make room for others
+	size      atomic.Int64 // Size of the blocks held, over all the shards
+}
+
+// BlockCacheStatistics is a point in time view of the block cache usage.
+type BlockCacheStatistics struct {
+	Hits         int64
+	Misses       int64
+	Evictions    int64
+	SizeInBytes  int64
+	LimitInBytes int64
 }
 
 type CacheItem struct {
@@ -53,8 +68,11 @@ func (bc *BlockCache) GetBlock(blockID uint64) (*Block, bool) {
 
 	if element, found := shard.cache.Load(blockID); found {
 		shard.eviction.MoveToFront(element.(*list.Element))
+		bc.hits.Add(1)
 		return element.(*list.Element).Value.(*CacheItem).BlockData, true
 	}
+
+	bc.misses.Add(1)
 	return nil, false
 }
 
@@ -69,7 +87,10 @@ func (bc *BlockCache) Add(block *Block) {
 
 	counter := maxEvictionRetriesIfFull
 	for shard.currentSize+block.CurrentSize > shard.maxsize && counter > 0 {
-		shard.evict()
+		if evicted := shard.evict(); evicted > 0 {
+			bc.evictions.Add(1)
+			bc.size.Add(-evicted)
+		}
 		counter--
 	}
 
@@ -81,15 +102,33 @@ func (bc *BlockCache) Add(block *Block) {
 	element := shard.eviction.PushFront(item)
 	shard.cache.Store(blockID, element)
 	shard.currentSize += block.CurrentSize
+	bc.size.Add(block.CurrentSize)
 }
 
-func (shard *BlockCacheShard) evict() {
+// evict removes the least recently used block of the shard, and returns its
+// size, which is 0 when the shard is empty.
+func (shard *BlockCacheShard) evict() int64 {
 	element := shard.eviction.Back()
-	if element != nil {
-		cacheItem := element.Value.(*CacheItem)
-		shard.cache.Delete(cacheItem.BlockID)
-		shard.eviction.Remove(element)
-		shard.currentSize -= cacheItem.BlockData.CurrentSize
+	if element == nil {
+		return 0
+	}
+
+	cacheItem := element.Value.(*CacheItem)
+	shard.cache.Delete(cacheItem.BlockID)
+	shard.eviction.Remove(element)
+	shard.currentSize -= cacheItem.BlockData.CurrentSize
+	return cacheItem.BlockData.CurrentSize
+}
+
+// GetStatistics returns the hits, misses and evictions of the cache so far,
+// along with the size of the blocks it holds.
+func (bc *BlockCache) GetStatistics() BlockCacheStatistics {
+	return BlockCacheStatistics{
+		Hits:         bc.hits.Load(),
+		Misses:       bc.misses.Load(),
+		Evictions:    bc.evictions.Load(),
+		SizeInBytes:  bc.size.Load(),
+		LimitInBytes: config.Store.Storage.LSM.BlockCacheMemoryLimit,
 	}
 }
 
@@ -131,3 +131,23 @@ func TestBlockCacheShardDistribution(t *testing.T) {
 		t.Fatalf("Block with ID %d should be in shard2", block2.Id)
 	}
 }
+
+func TestBlockCacheStatistics(t *testing.T) {
+	setupBlockCacheTests()
+	blockCache := NewBlockCache()
+	block := createTestBlock([]string{"key1", "key2"})
+
+	blockCache.Add(block)
+	blockCache.GetBlock(block.Id)
+	blockCache.GetBlock(block.Id + 1)
+
+	stats := blockCache.GetStatistics()
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("Expected 1 hit and 1 miss, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+
+	if stats.SizeInBytes != block.CurrentSize || stats.LimitInBytes != 22500 {
+		t.Fatalf("Expected the cache to hold %d of 22500 bytes, got %d of %d",
+			block.CurrentSize, stats.SizeInBytes, stats.LimitInBytes)
+	}
+}
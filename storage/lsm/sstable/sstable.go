@@ -41,11 +41,17 @@ type SSTable struct {
 }
 
 func NewSSTable(filename string, writeMode uint8, maxRecords int64, falsePositiveRate float64) (*SSTable, error) {
+	datadir := config.Store.Storage.LSM.DataStorageDirectory
+	return NewSSTableInDirectory(datadir, filename, writeMode, maxRecords, falsePositiveRate)
+}
+
+// NewSSTableInDirectory opens the SSTable just like NewSSTable does, but in the
+// given directory instead of the data directory, such as a checkpoint one.
+func NewSSTableInDirectory(directory string, filename string, writeMode uint8, maxRecords int64, falsePositiveRate float64) (*SSTable, error) {
 	var file *os.File
 	var err error
 
-	datadir := config.Store.Storage.LSM.DataStorageDirectory
-	sstFullPath := filepath.Clean(fmt.Sprintf("%s/%s", datadir, filename))
+	sstFullPath := filepath.Clean(fmt.Sprintf("%s/%s", directory, filename))
 
 	if writeMode == SSTmodeWrite {
 		file, err = os.Create(sstFullPath)
@@ -334,6 +340,35 @@ func (sst *SSTable) GetAllRecords() ([]*entity.RecordKV, error) {
 	return records, nil
 }
 
+// SeekBlock returns the index position of the first block which may hold keys
+// greater than or equal to the given one, or len(sst.Index) if there is none.
+func (sst *SSTable) SeekBlock(key string) int {
+	return sort.Search(len(sst.Index), func(i int) bool {
+		return sst.Index[i].GetLastKey() >= key
+	})
+}
+
+// GetBlockRecords returns the records of the block at the given index position,
+// sorted by key. The block is served from the block cache when present, but is
+// not added to it, so that long scans do not evict the blocks of point lookups.
+func (sst *SSTable) GetBlockRecords(position int) ([]*entity.RecordKV, error) {
+	indexEntry := sst.Index[position]
+	blockId := GenerateBlockID(indexEntry.GetFirstKey(), indexEntry.GetLastKey())
+
+	block, found := BlockCacheStore.GetBlock(blockId)
+	if !found {
+		blockOffset, blockSize := utils.UnpackNumbers(indexEntry.GetOffset())
+
+		var err error
+		block, err = sst.LoadBlock(int64(blockOffset), int64(blockSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load block: %v", err)
+		}
+	}
+
+	return block.GetAllRecords()
+}
+
 func (sst *SSTable) FindBlockForKey(key string, index []*sstIndexEntry) (*sstIndexEntry, error) {
 	idx := sort.Search(len(index), func(i int) bool {
 		return sst.Index[i].GetFirstKey() > key
@@ -563,6 +598,16 @@ func (sst *SSTable) FlushMetadata() error {
 	return nil
 }
 
+// Path returns the path of the SSTable file on disk.
+func (sst *SSTable) Path() string {
+	return sst.fileptr.Name()
+}
+
+// Close closes the SSTable file, which can no longer be read from afterwards.
+func (sst *SSTable) Close() error {
+	return sst.fileptr.Close()
+}
+
 func (sst *SSTable) DeleteFromDisk() error {
 	sst.fileptr.Close()
 	return os.Remove(sst.fileptr.Name())
@@ -68,7 +68,7 @@ func TestLoadSSTableFromDisk(t *testing.T) {
 	}
 	defer os.Remove(filepath.Clean(fmt.Sprintf("%s/%s", cnf.DataStorageDirectory, fileName)))
 
-	mem := memtable.CreateNewMemTable(config.DefaultMemtableStorageType).(*memtable.ListBloomMemTable)
+	mem := memtable.CreateNewMemTable(config.DefaultMemtableStorageType, memtable.Channels{}).(*memtable.ListBloomMemTable)
 	for i := 0; i < 100; i++ {
 		key := fmt.Sprintf("key%d", i)
 		val := fmt.Sprintf("value%d", i)
@@ -116,7 +116,7 @@ func TestFlushMemTableToSSTable(t *testing.T) {
 	}
 	defer os.Remove(filepath)
 
-	mem := memtable.CreateNewMemTable(config.DefaultMemtableStorageType).(*memtable.ListBloomMemTable)
+	mem := memtable.CreateNewMemTable(config.DefaultMemtableStorageType, memtable.Channels{}).(*memtable.ListBloomMemTable)
 	mem.Set("key1", "value1", 100, entity.RecordStateActive)
 	mem.Set("key2", "value2", 100, entity.RecordStateActive)
 
@@ -157,7 +157,7 @@ func TestLoadBlockAndFindRecord(t *testing.T) {
 	}
 	defer os.Remove(filepath)
 
-	mem := memtable.CreateNewMemTable(config.DefaultMemtableStorageType).(*memtable.ListBloomMemTable)
+	mem := memtable.CreateNewMemTable(config.DefaultMemtableStorageType, memtable.Channels{}).(*memtable.ListBloomMemTable)
 	mem.Set("key1", "value1", 10, entity.RecordStateActive)
 	mem.Set("key2", "value2", 10, entity.RecordStateActive)
 
@@ -0,0 +1,65 @@
+package wal
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"universum/entity"
+)
+
+const (
+	formatMagic   uint32 = 0x5557414c // "UWAL"
+	sealMagic     uint32 = 0x5557534c // "UWSL"
+	FormatVersion uint32 = 1
+
+	// headerSize is the size of the header each segment starts with, the magic
+	// followed by the format version.
+	headerSize int64 = 8
+
+	// trailerSize is the size of the trailer a segment is sealed with, the seal
+	// magic followed by the size of the segment before the trailer.
+	trailerSize int64 = 4 + entity.Int64SizeInBytes
+
+	// entryPrefixSize is the size of what goes before each entry, its length
+	// followed by the checksum of the length and the entry.
+	entryPrefixSize int64 = entity.Int64SizeInBytes + 4
+
+	// maxEntrySize bounds the length of an entry, any longer one being read as a
+	// corrupt length rather than as one cut short by the end of the segment.
+	maxEntrySize int64 = 1024 * 1024 * 1024 // 1GB
+)
+
+var ErrUnsupportedVersion = errors.New("WAL segment of an unsupported format version")
+
+// segmentHeader returns the header of a new segment.
+func segmentHeader() []byte {
+	header := make([]byte, 0, headerSize)
+	header = binary.BigEndian.AppendUint32(header, formatMagic)
+	header = binary.BigEndian.AppendUint32(header, FormatVersion)
+	return header
+}
+
+// segmentTrailer returns the trailer a segment of the given size is sealed with,
+// once it is synced whole and nothing more is written to it.
+func segmentTrailer(size int64) []byte {
+	trailer := make([]byte, 0, trailerSize)
+	trailer = binary.BigEndian.AppendUint32(trailer, sealMagic)
+	trailer = binary.BigEndian.AppendUint64(trailer, uint64(size))
+	return trailer
+}
+
+// encodeEntry prefixes the encoded entry with its length and its checksum, which
+// tells an entry torn or corrupted on disk apart from an intact one on replay.
+func encodeEntry(payload []byte) []byte {
+	entry := make([]byte, 0, entryPrefixSize+int64(len(payload)))
+	entry = binary.BigEndian.AppendUint64(entry, uint64(len(payload)))
+	entry = binary.BigEndian.AppendUint32(entry, entryChecksum(entry, payload))
+	return append(entry, payload...)
+}
+
+// entryChecksum returns the checksum of the encoded length of an entry and of
+// the entry, the length being covered as well for a corrupt length not to pass
+// for that of an intact entry.
+func entryChecksum(encodedLength []byte, payload []byte) uint32 {
+	return crc32.Update(crc32.ChecksumIEEE(encodedLength), crc32.IEEETable, payload)
+}
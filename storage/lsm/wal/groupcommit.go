@@ -0,0 +1,157 @@
+package wal
+
+import (
+	"fmt"
+	"universum/config"
+	"universum/internal/logger"
+)
+
+// WaitForDurability waits for the entries added so far to be synced to disk when
+// the WAL is written with fsync-per-batch, and returns right away otherwise, the
+// entries being as durable as they get once added.
+//
+// The entries are synced with group commits: the first writer to wait becomes
+// the leader, writes out all the entries added by then with a single write and a
+// single fsync, and wakes up all the writers waiting for them once they are
+// durable. The writers coming in meanwhile are committed together by the next
+// leader. Callers should not hold any lock the other writers need to add their
+// entries, or there would be nobody to commit together with.
+//
+// Should a group commit fail, the segment is left as it is, its tail being no
+// longer trusted, and the entries of the group are written out again to a new
+// segment. The writers waiting for them fail only if that fails as well, in which
+// case the entries are kept for the next group commit to retry, and the writes
+// may yet turn out durable. A write is never acknowledged before it is synced.
+func (ww *WALWriter) WaitForDurability() error {
+	if ww.durability != config.WALDurabilityFsyncPerBatch {
+		return nil
+	}
+
+	ww.mutex.Lock()
+	defer ww.mutex.Unlock()
+
+	target, abandoned := ww.appendedSeq, ww.abandonedCommits
+	for ww.durableSeq < target {
+		if ww.abandonedCommits != abandoned {
+			return ww.commitErr
+		}
+
+		if ww.committing {
+			ww.committed.Wait()
+			continue
+		}
+
+		ww.commitGroup()
+	}
+
+	return nil
+}
+
+// commitGroup writes out the entries added so far with a single write, and syncs
+// them to disk. The writer mutex is released while they are written, for the
+// writers to keep adding entries for the next group commit in the meantime. The
+// caller must hold the writer mutex, and no other group commit may be running.
+func (ww *WALWriter) commitGroup() {
+	err := ww.commitErr
+	if err == nil {
+		if err = ww.writeGroup(); err == nil {
+			ww.committed.Broadcast()
+			return
+		}
+
+		ww.groupCommitFailures++
+		logger.Get().Error("LSM:WAL:: Group commit to segment %d failed, retrying in a new segment: %v", ww.segmentSeq, err)
+	}
+
+	// the tail of the segment is not to be trusted anymore, whatever made it to the disk
+	if err = ww.switchSegment(); err == nil {
+		err = ww.writeGroup()
+	}
+
+	if err != nil {
+		ww.commitErr = fmt.Errorf("WAL group commit failed: %v", err)
+		ww.abandonedCommits++
+		logger.Get().Error("LSM:WAL:: %v, the writes waiting for it are failed", ww.commitErr)
+	} else {
+		ww.commitErr = nil
+	}
+
+	ww.committed.Broadcast()
+}
+
+// writeGroup writes out the buffered entries and syncs them, with the writer
+// mutex released meanwhile. Should that fail, the entries are put back in front
+// of the ones added since, for the next attempt to write them all out in order.
+// The caller must hold the writer mutex.
+func (ww *WALWriter) writeGroup() error {
+	pending, seq, fileptr := ww.buffer, ww.appendedSeq, ww.fileptr
+	ww.buffer, ww.spareBuffer = ww.spareBuffer, nil
+	ww.committing = true
+	ww.mutex.Unlock()
+
+	_, err := fileptr.Write(pending.Bytes())
+	if err == nil {
+		err = fileptr.Sync()
+	}
+
+	ww.mutex.Lock()
+	ww.committing = false
+
+	if err != nil {
+		pending.Write(ww.buffer.Bytes())
+		ww.buffer.Reset()
+		ww.buffer, ww.spareBuffer = pending, ww.buffer
+		return err
+	}
+
+	pending.Reset()
+	ww.spareBuffer = pending
+	ww.durableSeq = seq
+	ww.groupCommits++
+	return nil
+}
+
+// switchSegment moves on to a new segment after a failed group commit, leaving
+// the one being written to unsealed, for the replay to drop the entry the failed
+// write may have cut short as a torn tail. The entries of the group which did
+// make it whole are replayed twice, which leaves the keys as they were. The
+// segment is released along with the next one, which takes over its memtable.
+// The caller must hold the writer mutex.
+func (ww *WALWriter) switchSegment() error {
+	fileptr, err := openSegment(ww.filedir, ww.segmentSeq+1)
+	if err != nil {
+		return err
+	}
+
+	if err := ww.fileptr.Close(); err != nil {
+		logger.Get().Error("Failed to close WAL segment %d: %v", ww.segmentSeq, err)
+	}
+
+	ww.fileptr = fileptr
+	ww.segmentSeq++
+	return nil
+}
+
+// commitPending waits for the running group commit, if any, and commits the
+// entries added since, such as before the segment is rotated or closed. The
+// caller must hold the writer mutex.
+func (ww *WALWriter) commitPending() error {
+	for ww.committing {
+		ww.committed.Wait()
+	}
+
+	if ww.durableSeq < ww.appendedSeq || ww.commitErr != nil {
+		ww.commitGroup()
+	}
+
+	return ww.commitErr
+}
+
+// GetGroupCommitFailures returns the number of group commits which failed so
+// far, whether or not their entries were written out to a new segment after.
+func (ww *WALWriter) GetGroupCommitFailures() int64 {
+	ww.mutex.Lock()
+	defer ww.mutex.Unlock()
+
+	return ww.groupCommitFailures
+}
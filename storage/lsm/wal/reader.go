@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -17,64 +18,372 @@ import (
 )
 
 type WALReader struct {
-	fileptr *os.File
+	fileptrs     []*os.File
+	recoveryMode string
+	droppedBytes int64
 }
 
-func NewReader(filedir string) (*WALReader, error) {
-	filePath := filepath.Clean(filepath.Join(filedir, config.DefaultWALFileName))
-	fileptr, err := os.Open(filePath)
+var errChecksumMismatch = errors.New("entry checksum mismatch")
 
+// NewReader opens the WAL segments of the directory for the replay, the oldest
+// first. The segments of the memtables already flushed are released as they are
+// flushed, so the ones left are the segments of the memtables which were not.
+func NewReader(filedir string) (*WALReader, error) {
+	segments, err := listSegments(filedir)
 	if err != nil {
-		return nil, fmt.Errorf("WALReader: failed to open WAL file: %v", err)
+		return nil, fmt.Errorf("WALReader: %v", err)
+	}
+
+	fileptrs := make([]*os.File, 0, len(segments))
+	for _, seg := range segments {
+		fileptr, err := os.Open(filepath.Clean(seg.path))
+		if err != nil {
+			for _, opened := range fileptrs {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("WALReader: failed to open WAL file: %v", err)
+		}
+		fileptrs = append(fileptrs, fileptr)
 	}
 
 	return &WALReader{
-		fileptr: fileptr,
+		fileptrs:     fileptrs,
+		recoveryMode: config.Store.Storage.LSM.WriteAheadLogRecoveryMode,
 	}, nil
 }
 
+// DroppedBytes returns the number of bytes of the torn and the corrupt entries
+// the replay dropped.
+func (wr *WALReader) DroppedBytes() int64 {
+	return wr.droppedBytes
+}
+
+// readEntries reads the entries of all segments, in the order they were written.
 func (wr *WALReader) readEntries() ([]*WALRecord, error) {
 	var entries []*WALRecord
 
-	for {
-		var commandLen int64
-		err := binary.Read(wr.fileptr, binary.BigEndian, &commandLen)
-		if err == io.EOF {
-			break // Reached end of WAL file
-		}
+	for _, fileptr := range wr.fileptrs {
+		segmentEntries, err := wr.readSegmentEntries(fileptr)
 		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(fileptr.Name()), err)
+		}
+		entries = append(entries, segmentEntries...)
+	}
+
+	return entries, nil
+}
+
+// readSegmentEntries reads the entries of the segment. A segment which was not
+// sealed was still being written to when the server stopped, so that its tail
+// may hold an entry cut short by a crash in the middle of its write, followed by
+// whatever the file system left past it, such as preallocated zeros. Everything
+// from its first bad entry on is dropped as torn, none of it having been
+// acknowledged, unless a valid entry follows the bad one. A sealed segment was
+// synced whole, so that anything wrong with it is corruption, as is a bad entry
+// followed by a valid one. Corruption fails the read, unless the recovery mode
+// tolerates it, in which case the entry is skipped, along with the rest of the
+// segment when its length is the corrupt part. Segments written before the
+// entries had checksums are read all the same, without the checksums, and only
+// an entry ending such a segment passes for torn.
+func (wr *WALReader) readSegmentEntries(fileptr *os.File) ([]*WALRecord, error) {
+	var entries []*WALRecord
+
+	info, err := fileptr.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat WAL file: %v", err)
+	}
+
+	size := info.Size()
+	reader := bufio.NewReader(fileptr)
+	name := filepath.Base(fileptr.Name())
+
+	offset, checksummed, err := readHeader(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := false
+	if checksummed {
+		size, sealed = readTrailer(fileptr, size)
+	}
+
+	if offset > size {
+		return nil, wr.dropTail(name, sealed, 0, size, errors.New("segment header cut short"))
+	}
+
+	prefixSize := int64(entity.Int64SizeInBytes)
+	if checksummed {
+		prefixSize = entryPrefixSize
+	}
+
+	for offset < size {
+		remaining := size - offset
+		if remaining < prefixSize {
+			if err := wr.dropTail(name, sealed, offset, remaining, errors.New("entry prefix cut short")); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		var commandLen int64
+		var checksum uint32
+		if err := binary.Read(reader, binary.BigEndian, &commandLen); err != nil {
 			return nil, fmt.Errorf("failed to read command length: %v", err)
 		}
 
+		if checksummed {
+			if err := binary.Read(reader, binary.BigEndian, &checksum); err != nil {
+				return nil, fmt.Errorf("failed to read command checksum: %v", err)
+			}
+		}
+
+		if commandLen < 0 || commandLen > maxEntrySize {
+			if wr.isTornTail(fileptr, sealed, checksummed, offset, -1, size) {
+				wr.dropTornEntry(name, offset, remaining)
+				break
+			}
+
+			// where the next entry starts is lost along with the length
+			if err := wr.dropCorruptEntry(name, offset, remaining, fmt.Errorf("invalid entry length %d", commandLen)); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		if commandLen > remaining-prefixSize {
+			if wr.isTornTail(fileptr, sealed, checksummed, offset, size, size) {
+				wr.dropTornEntry(name, offset, remaining)
+				break
+			}
+
+			if err := wr.dropCorruptEntry(name, offset, remaining, fmt.Errorf("entry length %d past the end of the segment", commandLen)); err != nil {
+				return nil, err
+			}
+			break
+		}
+
 		commandBytes := make([]byte, commandLen)
-		_, err = wr.fileptr.Read(commandBytes)
-		if err != nil {
+		if _, err := io.ReadFull(reader, commandBytes); err != nil {
 			return nil, fmt.Errorf("failed to read command bytes: %v", err)
 		}
 
-		buf := bytes.NewReader(commandBytes)
-		reader := bufio.NewReader(buf)
-		command, err := resp3.Decode(reader)
+		entrySize := prefixSize + commandLen
+		var decoded []*WALRecord
+
+		encodedLength := binary.BigEndian.AppendUint64(nil, uint64(commandLen))
+		if checksummed && entryChecksum(encodedLength, commandBytes) != checksum {
+			err = errChecksumMismatch
+		} else {
+			decoded, err = decodeEntry(commandBytes)
+		}
+
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode command: %v", err)
+			if wr.isTornTail(fileptr, sealed, checksummed, offset, offset+entrySize, size) {
+				wr.dropTornEntry(name, offset, remaining)
+				break
+			}
+
+			if err := wr.dropCorruptEntry(name, offset, entrySize, err); err != nil {
+				return nil, err
+			}
+			offset += entrySize
+			continue
 		}
 
-		parsedCommand, ok := command.(map[string]interface{})
+		entries = append(entries, decoded...)
+		offset += entrySize
+	}
+
+	return entries, nil
+}
+
+// readHeader reads the header of the segment, and returns the offset its first
+// entry is at, and whether its entries carry a checksum. A segment without the
+// header is one written before the header, whose entries start right away. The
+// offset is past the end of a segment whose header was cut short.
+func readHeader(reader *bufio.Reader, size int64) (int64, bool, error) {
+	header, _ := reader.Peek(int(min(size, headerSize)))
+
+	if len(header) < 4 || binary.BigEndian.Uint32(header) != formatMagic {
+		return 0, false, nil
+	}
+
+	if int64(len(header)) < headerSize {
+		return headerSize, true, nil
+	}
+
+	if binary.BigEndian.Uint32(header[4:]) != FormatVersion {
+		return 0, false, ErrUnsupportedVersion
+	}
+
+	reader.Discard(int(headerSize))
+	return headerSize, true, nil
+}
+
+// readTrailer tells whether the segment was sealed, and returns the size of the
+// segment before its trailer if so, or its whole size otherwise.
+func readTrailer(fileptr *os.File, size int64) (int64, bool) {
+	if size < headerSize+trailerSize {
+		return size, false
+	}
+
+	trailer := make([]byte, trailerSize)
+	if _, err := fileptr.ReadAt(trailer, size-trailerSize); err != nil {
+		return size, false
+	}
+
+	if binary.BigEndian.Uint32(trailer) != sealMagic || int64(binary.BigEndian.Uint64(trailer[4:])) != size-trailerSize {
+		return size, false
+	}
+	return size - trailerSize, true
+}
+
+// dropTail drops what is left of the segment from the offset on, which cannot be
+// read as an entry. It is torn by a crash when the segment was not sealed, and
+// corrupt otherwise.
+func (wr *WALReader) dropTail(name string, sealed bool, offset int64, size int64, err error) error {
+	if sealed {
+		return wr.dropCorruptEntry(name, offset, size, err)
+	}
+
+	wr.dropTornEntry(name, offset, size)
+	return nil
+}
+
+// isTornTail tells whether the bad entry at the offset, which ends at entryEnd,
+// -1 when its length is lost, is the first of the torn tail of a segment which
+// was not sealed, no valid entry following it. Without the checksums, the
+// entries following cannot be told valid, and only an entry which ends the
+// segment is torn.
+func (wr *WALReader) isTornTail(fileptr *os.File, sealed bool, checksummed bool, offset int64, entryEnd int64, size int64) bool {
+	if sealed {
+		return false
+	}
+
+	if !checksummed {
+		return entryEnd == size
+	}
+
+	return !hasValidEntry(fileptr, offset+1, size)
+}
+
+// hasValidEntry tells whether a valid entry starts anywhere in the segment from
+// the offset on, before its size. Any offset is tried, the bad entry before it
+// having lost where the next one starts, and an entry is only taken for valid
+// when both its checksum and its decoding pass.
+func hasValidEntry(fileptr *os.File, offset int64, size int64) bool {
+	if size-offset < entryPrefixSize {
+		return false
+	}
+
+	tail := make([]byte, size-offset)
+	if _, err := fileptr.ReadAt(tail, offset); err != nil {
+		return false
+	}
+
+	for start := int64(0); start+entryPrefixSize <= int64(len(tail)); start++ {
+		length := int64(binary.BigEndian.Uint64(tail[start:]))
+		if length <= 0 || length > int64(len(tail))-start-entryPrefixSize {
+			continue
+		}
+
+		payload := tail[start+entryPrefixSize : start+entryPrefixSize+length]
+		if entryChecksum(tail[start:start+entity.Int64SizeInBytes], payload) != binary.BigEndian.Uint32(tail[start+entity.Int64SizeInBytes:]) {
+			continue
+		}
+
+		if _, err := decodeEntry(payload); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dropTornEntry drops the torn tail of the segment, from the offset on.
+func (wr *WALReader) dropTornEntry(name string, offset int64, size int64) {
+	wr.droppedBytes += size
+	logger.Get().Warn("LSM:WAL:: Dropped %d bytes of the torn tail of %s, offset=%d", size, name, offset)
+}
+
+// dropCorruptEntry drops the corrupt entry when the recovery mode tolerates it,
+// or returns the error the read fails with otherwise.
+func (wr *WALReader) dropCorruptEntry(name string, offset int64, size int64, err error) error {
+	if wr.recoveryMode != config.WALRecoveryModeTolerant {
+		return fmt.Errorf("corrupt entry at offset %d: %v, the WAL recovery mode %s does not tolerate it", offset, err, wr.recoveryMode)
+	}
+
+	wr.droppedBytes += size
+	logger.Get().Warn("LSM:WAL:: Dropped %d bytes of a corrupt entry in %s, offset=%d: %v", size, name, offset, err)
+	return nil
+}
+
+// decodeEntry decodes the entry, which holds either a single record, or the
+// records of a batch.
+func decodeEntry(commandBytes []byte) ([]*WALRecord, error) {
+	buf := bytes.NewReader(commandBytes)
+	reader := bufio.NewReader(buf)
+	command, err := resp3.Decode(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode command: %v", err)
+	}
+
+	parsedCommand, ok := command.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to parse decoded command as map")
+	}
+
+	// a batch holds the entries of a transaction, which are only ever
+	// written to the log together and are restored together as well.
+	if batch, ok := parsedCommand["Batch"]; ok {
+		batchEntries, ok := batch.([]interface{})
 		if !ok {
-			return nil, fmt.Errorf("failed to parse decoded command as map")
+			return nil, fmt.Errorf("failed to parse decoded batch as list")
 		}
 
-		entry := &WALRecord{
-			Key:    parsedCommand["Key"].(string),
-			Value:  parsedCommand["Value"],
-			Expiry: parsedCommand["Expiry"].(int64),
-			State:  uint8(parsedCommand["State"].(int64)),
+		entries := make([]*WALRecord, 0, len(batchEntries))
+		for _, batchEntry := range batchEntries {
+			parsedEntry, ok := batchEntry.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("failed to parse decoded batch entry as map")
+			}
+
+			entry, err := walRecordFromMap(parsedEntry)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
 		}
+		return entries, nil
+	}
 
-		entries = append(entries, entry)
+	entry, err := walRecordFromMap(parsedCommand)
+	if err != nil {
+		return nil, err
 	}
+	return []*WALRecord{entry}, nil
+}
 
-	return entries, nil
+// walRecordFromMap converts a decoded WAL entry into a WALRecord.
+func walRecordFromMap(parsedCommand map[string]interface{}) (*WALRecord, error) {
+	// entries written before the collection types existed carry no
+	// family, and are read back as scalars.
+	family, _ := parsedCommand["Family"].(string)
+
+	key, keyOk := parsedCommand["Key"].(string)
+	expiry, expiryOk := parsedCommand["Expiry"].(int64)
+	state, stateOk := parsedCommand["State"].(int64)
+	if !keyOk || !expiryOk || !stateOk {
+		return nil, fmt.Errorf("failed to parse decoded entry, missing its key, expiry or state")
+	}
+
+	return &WALRecord{
+		Key:    key,
+		Family: family,
+		Value:  entity.ValueOfFamily(family, parsedCommand["Value"]),
+		Expiry: expiry,
+		State:  uint8(state),
+	}, nil
 }
 
 func (wr *WALReader) RestoreFromWAL(memTable memtable.MemTable) (int64, error) {
@@ -100,10 +409,16 @@ func (wr *WALReader) RestoreFromWAL(memTable memtable.MemTable) (int64, error) {
 
 	}
 
+	if wr.droppedBytes > 0 {
+		logger.Get().Warn("LSM:WAL:: Dropped %d bytes of torn or corrupt entries from write ahead logs", wr.droppedBytes)
+	}
+
 	logger.Get().Info("LSM:WAL:: Restored %d keys from write ahead logs", keycount)
 	return keycount, nil
 }
 
 func (wr *WALReader) Close() {
-	wr.fileptr.Close()
+	for _, fileptr := range wr.fileptrs {
+		fileptr.Close()
+	}
 }
@@ -1,11 +1,13 @@
 package wal
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"universum/config"
+	"universum/dslib"
 	"universum/entity"
 	"universum/storage/lsm/memtable"
 )
@@ -50,7 +52,7 @@ func TestReadEntries(t *testing.T) {
 		{Key: "key1", Value: "value2", Expiry: 0, State: entity.RecordStateTombstoned},
 	}
 
-	ww, _ := NewWriter(dir)
+	ww, _ := NewWriter(dir, nil)
 	for _, entry := range entries {
 		err := ww.AddToWALBuffer(entry.Key, entry.Value, entry.Expiry, entry.State)
 		if err != nil {
@@ -96,7 +98,7 @@ func TestRestoreFromWAL(t *testing.T) {
 		{Key: "key2", Value: "value2", Expiry: 0, State: entity.RecordStateTombstoned},
 	}
 
-	ww, _ := NewWriter(dir)
+	ww, _ := NewWriter(dir, nil)
 	for _, entry := range entries {
 		err := ww.AddToWALBuffer(entry.Key, entry.Value, entry.Expiry, entry.State)
 		if err != nil {
@@ -112,7 +114,7 @@ func TestRestoreFromWAL(t *testing.T) {
 	}
 	defer reader.Close()
 
-	memTable := memtable.CreateNewMemTable(config.MemtableStorageTypeLB)
+	memTable := memtable.CreateNewMemTable(config.MemtableStorageTypeLB, memtable.Channels{})
 
 	keycount, err := reader.RestoreFromWAL(memTable)
 	if keycount != int64(len(entries)) {
@@ -181,8 +183,426 @@ func TestReadEntriesWithCorruptedData(t *testing.T) {
 	}
 	defer reader.Close()
 
-	_, err = reader.readEntries()
-	if err == nil {
-		t.Errorf("Expected error when reading corrupted data, but got nil")
+	// too short for an entry, it can only be one cut short by a crash
+	readEntries, err := reader.readEntries()
+	if err != nil || len(readEntries) != 0 {
+		t.Errorf("Expected the torn entry to be dropped, got %d entries (err=%v)", len(readEntries), err)
+	}
+
+	if reader.DroppedBytes() != 3 {
+		t.Errorf("Expected 3 bytes to be dropped, got %d", reader.DroppedBytes())
+	}
+}
+
+// writeTestSegment writes the keys to a WAL segment, and returns its path. The
+// segment is sealed as it is on close, or else left as a crash would leave it.
+func writeTestSegment(t *testing.T, dir string, sealed bool, keys ...string) string {
+	ww, err := NewWriter(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed to create WALWriter: %v", err)
+	}
+
+	for _, key := range keys {
+		if err := ww.AddToWALBuffer(key, "value", 0, entity.RecordStateActive); err != nil {
+			t.Fatalf("Failed to write entry: %v", err)
+		}
+	}
+
+	if sealed {
+		ww.Close()
+	} else {
+		ww.fileptr.Close()
+	}
+
+	return filepath.Join(dir, SegmentFileName(ww.segmentSeq))
+}
+
+func readTestSegments(t *testing.T, dir string) ([]*WALRecord, int64, error) {
+	reader, err := NewReader(dir)
+	if err != nil {
+		t.Fatalf("Failed to create WALReader: %v", err)
+	}
+	defer reader.Close()
+
+	entries, err := reader.readEntries()
+	return entries, reader.DroppedBytes(), err
+}
+
+func TestReadEntriesStopsAtTornTail(t *testing.T) {
+	setupReaderTests(t)
+	dir := t.TempDir()
+
+	segmentPath := writeTestSegment(t, dir, false, "key1", "key2")
+	content, _ := os.ReadFile(segmentPath)
+	entrySize := (len(content) - int(headerSize)) / 2
+
+	for _, cut := range []int{1, 5, 13, entrySize - 1} {
+		os.WriteFile(segmentPath, content[:len(content)-cut], 0644)
+
+		entries, droppedBytes, err := readTestSegments(t, dir)
+		if err != nil {
+			t.Fatalf("Expected the torn entry to be dropped, got %v", err)
+		}
+
+		if len(entries) != 1 || entries[0].Key != "key1" || droppedBytes != int64(entrySize-cut) {
+			t.Errorf("Expected only key1 to be read with the torn bytes dropped, got %d entries and %d bytes dropped", len(entries), droppedBytes)
+		}
+	}
+}
+
+func TestReadEntriesDropsTornTailFollowedByZeros(t *testing.T) {
+	setupReaderTests(t)
+	dir := t.TempDir()
+	config.Store.Storage.LSM.WriteAheadLogRecoveryMode = config.WALRecoveryModeStrict
+
+	segmentPath := writeTestSegment(t, dir, false, "key1", "key2")
+	content, _ := os.ReadFile(segmentPath)
+	entrySize := (len(content) - int(headerSize)) / 2
+
+	// the second entry half written, followed by the space the file system
+	// preallocated, and then by a length prefix cut short with garbage past it
+	for _, tail := range [][]byte{make([]byte, 4096), append(make([]byte, 3), 0xde, 0xad, 0xbe, 0xef)} {
+		torn := append(append([]byte(nil), content[:len(content)-entrySize/2]...), tail...)
+		os.WriteFile(segmentPath, torn, 0644)
+
+		entries, droppedBytes, err := readTestSegments(t, dir)
+		if err != nil {
+			t.Fatalf("Expected the torn tail to be dropped in strict mode, got %v", err)
+		}
+
+		if len(entries) != 1 || entries[0].Key != "key1" || droppedBytes != int64(len(torn)-int(headerSize)-entrySize) {
+			t.Errorf("Expected only key1 to be read with the torn tail dropped, got %d entries and %d bytes dropped", len(entries), droppedBytes)
+		}
+	}
+
+	// a valid entry past the bad one makes it corruption
+	corrupt := append([]byte(nil), content[:int(headerSize)+entrySize/2]...)
+	corrupt = append(append(corrupt, make([]byte, 16)...), content[int(headerSize)+entrySize:]...)
+	os.WriteFile(segmentPath, corrupt, 0644)
+
+	if _, _, err := readTestSegments(t, dir); err == nil {
+		t.Errorf("Expected a bad entry followed by a valid one to fail the read in strict mode")
+	}
+}
+
+func TestReadEntriesWithChecksumMismatch(t *testing.T) {
+	setupReaderTests(t)
+	dir := t.TempDir()
+
+	segmentPath := writeTestSegment(t, dir, false, "key1", "key2", "key3")
+	content, _ := os.ReadFile(segmentPath)
+	entrySize := (len(content) - int(headerSize)) / 3
+
+	// a flipped bit in the last entry, which was only partly synced to disk
+	corrupt := append([]byte(nil), content...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	os.WriteFile(segmentPath, corrupt, 0644)
+
+	entries, droppedBytes, err := readTestSegments(t, dir)
+	if err != nil || len(entries) != 2 || droppedBytes != int64(entrySize) {
+		t.Errorf("Expected the last entry to be dropped, got %d entries, %d bytes dropped (err=%v)", len(entries), droppedBytes, err)
+	}
+
+	// the same in a sealed segment, which was synced whole
+	sealedContent := append(append([]byte(nil), corrupt...), segmentTrailer(int64(len(corrupt)))...)
+	os.WriteFile(segmentPath, sealedContent, 0644)
+
+	if _, _, err := readTestSegments(t, dir); err == nil {
+		t.Errorf("Expected the corrupt end of a sealed segment to fail the read in strict mode")
+	}
+
+	// a flipped bit in the middle of the log
+	corrupt = append([]byte(nil), content...)
+	corrupt[int(headerSize)+entrySize+int(entryPrefixSize)] ^= 0xff
+	os.WriteFile(segmentPath, corrupt, 0644)
+
+	config.Store.Storage.LSM.WriteAheadLogRecoveryMode = config.WALRecoveryModeStrict
+	if _, _, err := readTestSegments(t, dir); err == nil {
+		t.Errorf("Expected the corrupt entry to fail the read in strict mode")
+	}
+
+	config.Store.Storage.LSM.WriteAheadLogRecoveryMode = config.WALRecoveryModeTolerant
+	entries, droppedBytes, err = readTestSegments(t, dir)
+	if err != nil || len(entries) != 2 || entries[1].Key != "key3" || droppedBytes != int64(entrySize) {
+		t.Errorf("Expected the corrupt entry to be skipped, got %d entries, %d bytes dropped (err=%v)", len(entries), droppedBytes, err)
+	}
+}
+
+func TestReadEntriesWithCorruptLength(t *testing.T) {
+	setupReaderTests(t)
+	dir := t.TempDir()
+	config.Store.Storage.LSM.WriteAheadLogRecoveryMode = config.WALRecoveryModeTolerant
+
+	first := writeTestSegment(t, dir, true, "key1", "key2")
+	writeTestSegment(t, dir, false, "key3")
+
+	content, _ := os.ReadFile(first)
+	corrupt := append([]byte(nil), content...)
+	corrupt[headerSize] = 0xff // the length of the first entry turns negative
+	os.WriteFile(first, corrupt, 0644)
+
+	// the rest of the segment is dropped, the next one read
+	entries, droppedBytes, err := readTestSegments(t, dir)
+	if err != nil || len(entries) != 1 || entries[0].Key != "key3" || droppedBytes != int64(len(content))-headerSize-trailerSize {
+		t.Errorf("Expected only the next segment to be read, got %d entries, %d bytes dropped (err=%v)", len(entries), droppedBytes, err)
+	}
+}
+
+func TestReadEntriesWithLengthPastTheEnd(t *testing.T) {
+	setupReaderTests(t)
+	dir := t.TempDir()
+
+	segmentPath := writeTestSegment(t, dir, true, "key1", "key2")
+	content, _ := os.ReadFile(segmentPath)
+	entrySize := (len(content) - int(headerSize+trailerSize)) / 2
+
+	// the length of the second entry, grown past the end of the segment
+	corrupt := append([]byte(nil), content...)
+	corrupt[int(headerSize)+entrySize+entity.Int64SizeInBytes-2] = 0x10
+	os.WriteFile(segmentPath, corrupt, 0644)
+
+	if _, _, err := readTestSegments(t, dir); err == nil {
+		t.Errorf("Expected the corrupt length in a sealed segment to fail the read in strict mode")
+	}
+
+	// without the trailer, the segment was being written to when the server stopped
+	os.WriteFile(segmentPath, corrupt[:len(corrupt)-int(trailerSize)], 0644)
+
+	entries, droppedBytes, err := readTestSegments(t, dir)
+	if err != nil || len(entries) != 1 || droppedBytes != int64(entrySize) {
+		t.Errorf("Expected the entry to be dropped as torn, got %d entries, %d bytes dropped (err=%v)", len(entries), droppedBytes, err)
+	}
+}
+
+func TestReadEntriesOfUnsupportedVersion(t *testing.T) {
+	setupReaderTests(t)
+	dir := t.TempDir()
+
+	segmentPath := writeTestSegment(t, dir, true, "key1")
+	content, _ := os.ReadFile(segmentPath)
+	content[headerSize-1] = 0xff
+	os.WriteFile(segmentPath, content, 0644)
+
+	if _, _, err := readTestSegments(t, dir); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected the unsupported version to be reported, got %v", err)
+	}
+}
+
+func TestRestoreFromWALHashRecord(t *testing.T) {
+	setupReaderTests(t)
+	dir := createTempDir(t)
+	defer cleanupDir(t, dir)
+
+	ww, _ := NewWriter(dir, nil)
+	err := ww.AddToWALBuffer("hash", entity.HashValue{"name": "alice", "age": int64(30)}, 0, entity.RecordStateActive)
+	if err != nil {
+		t.Fatalf("Failed to write entry: %v", err)
+	}
+	ww.Close()
+
+	reader, err := NewReader(dir)
+	if err != nil {
+		t.Fatalf("Failed to create WALReader: %v", err)
+	}
+	defer reader.Close()
+
+	memTable := memtable.CreateNewMemTable(config.MemtableStorageTypeLB, memtable.Channels{})
+	if _, err := reader.RestoreFromWAL(memTable); err != nil {
+		t.Fatalf("Failed to restore from WAL: %v", err)
+	}
+
+	record, code := memTable.Get("hash")
+	if record == nil || code != entity.CRC_RECORD_FOUND {
+		t.Fatalf("Expected hash to exist in memtable")
+	}
+
+	hashRecord, ok := record.(*entity.HashRecord)
+	if !ok {
+		t.Fatalf("Expected hash record to be restored, got %T", record)
+	}
+
+	if hashRecord.Value["name"] != "alice" || hashRecord.Value["age"] != int64(30) {
+		t.Errorf("Unexpected hash value restored: %v", hashRecord.Value)
+	}
+}
+
+func TestRestoreFromWALListRecord(t *testing.T) {
+	setupReaderTests(t)
+	dir := createTempDir(t)
+	defer cleanupDir(t, dir)
+
+	ww, _ := NewWriter(dir, nil)
+	err := ww.AddToWALBuffer("queue", entity.ListValue{"a", int64(2)}, 0, entity.RecordStateActive)
+	if err != nil {
+		t.Fatalf("Failed to write entry: %v", err)
+	}
+	ww.Close()
+
+	reader, err := NewReader(dir)
+	if err != nil {
+		t.Fatalf("Failed to create WALReader: %v", err)
+	}
+	defer reader.Close()
+
+	memTable := memtable.CreateNewMemTable(config.MemtableStorageTypeLB, memtable.Channels{})
+	if _, err := reader.RestoreFromWAL(memTable); err != nil {
+		t.Fatalf("Failed to restore from WAL: %v", err)
+	}
+
+	record, _ := memTable.Get("queue")
+	listRecord, ok := record.(*entity.ListRecord)
+	if !ok {
+		t.Fatalf("Expected list record to be restored, got %T", record)
+	}
+
+	if len(listRecord.Value) != 2 || listRecord.Value[0] != "a" || listRecord.Value[1] != int64(2) {
+		t.Errorf("Unexpected list value restored: %v", listRecord.Value)
+	}
+}
+
+func TestRestoreFromWALSortedSetRecord(t *testing.T) {
+	setupReaderTests(t)
+	dir := createTempDir(t)
+	defer cleanupDir(t, dir)
+
+	zset := dslib.NewSortedSet()
+	zset.Add("alice", 0.1234567891)
+	zset.Add("bob", 2)
+
+	ww, _ := NewWriter(dir, nil)
+	err := ww.AddToWALBuffer("board", zset, 0, entity.RecordStateActive)
+	if err != nil {
+		t.Fatalf("Failed to write entry: %v", err)
+	}
+	ww.Close()
+
+	reader, err := NewReader(dir)
+	if err != nil {
+		t.Fatalf("Failed to create WALReader: %v", err)
+	}
+	defer reader.Close()
+
+	memTable := memtable.CreateNewMemTable(config.MemtableStorageTypeLB, memtable.Channels{})
+	if _, err := reader.RestoreFromWAL(memTable); err != nil {
+		t.Fatalf("Failed to restore from WAL: %v", err)
+	}
+
+	record, _ := memTable.Get("board")
+	zsetRecord, ok := record.(*entity.SortedSetRecord)
+	if !ok {
+		t.Fatalf("Expected sorted set record to be restored, got %T", record)
+	}
+
+	if score, _ := zsetRecord.Value.Score("alice"); score != 0.1234567891 || zsetRecord.Value.Len() != 2 {
+		t.Errorf("Unexpected sorted set value restored: %v", zsetRecord.Value.RangeByRank(0, -1))
+	}
+}
+
+func TestRestoreFromWALBatch(t *testing.T) {
+	setupReaderTests(t)
+	dir := createTempDir(t)
+	defer cleanupDir(t, dir)
+
+	ww, _ := NewWriter(dir, nil)
+	ww.AddToWALBuffer("before", "value", 0, entity.RecordStateActive)
+
+	ww.BeginBatch()
+	ww.AddToWALBuffer("tx1", "value1", 0, entity.RecordStateActive)
+	ww.AddToWALBuffer("tx2", entity.HashValue{"field": "value"}, 0, entity.RecordStateActive)
+	if err := ww.CommitBatch(); err != nil {
+		t.Fatalf("Failed to commit batch: %v", err)
+	}
+
+	ww.AddToWALBuffer("after", "value", 0, entity.RecordStateActive)
+	ww.Close()
+
+	reader, err := NewReader(dir)
+	if err != nil {
+		t.Fatalf("Failed to create WALReader: %v", err)
+	}
+	defer reader.Close()
+
+	readEntries, err := reader.readEntries()
+	if err != nil {
+		t.Fatalf("Failed to read entries: %v", err)
+	}
+
+	expectedKeys := []string{"before", "tx1", "tx2", "after"}
+	if len(readEntries) != len(expectedKeys) {
+		t.Fatalf("Expected %d entries, got %d", len(expectedKeys), len(readEntries))
+	}
+
+	for i, entry := range readEntries {
+		if entry.Key != expectedKeys[i] {
+			t.Errorf("Entry %d mismatch: expected key %s, got %s", i, expectedKeys[i], entry.Key)
+		}
+	}
+
+	if _, ok := readEntries[2].Value.(entity.HashValue); !ok {
+		t.Errorf("Expected batched hash value to be restored, got %T", readEntries[2].Value)
+	}
+}
+
+func TestReadEntriesWithTornBatch(t *testing.T) {
+	setupReaderTests(t)
+	dir := createTempDir(t)
+	defer cleanupDir(t, dir)
+
+	ww, _ := NewWriter(dir, nil)
+	ww.BeginBatch()
+	ww.AddToWALBuffer("tx1", "value1", 0, entity.RecordStateActive)
+	ww.AddToWALBuffer("tx2", "value2", 0, entity.RecordStateActive)
+	ww.CommitBatch()
+	ww.fileptr.Close() // the segment is left unsealed, as by a crash
+
+	walFilePath := filepath.Join(dir, SegmentFileName(1))
+	info, err := os.Stat(walFilePath)
+	if err != nil {
+		t.Fatalf("Failed to stat WAL file: %v", err)
+	}
+
+	// cut the batch entry short, as a crash in the middle of the write would
+	if err := os.Truncate(walFilePath, info.Size()-10); err != nil {
+		t.Fatalf("Failed to truncate WAL file: %v", err)
+	}
+
+	reader, err := NewReader(dir)
+	if err != nil {
+		t.Fatalf("Failed to create WALReader: %v", err)
+	}
+	defer reader.Close()
+
+	readEntries, err := reader.readEntries()
+	if err == nil && len(readEntries) != 0 {
+		t.Errorf("Expected no entry of a torn batch to be read, got %d", len(readEntries))
+	}
+}
+
+func TestRestoreFromWALSegmentsInOrder(t *testing.T) {
+	setupReaderTests(t)
+	dir := createTempDir(t)
+	defer cleanupDir(t, dir)
+
+	ww, _ := NewWriter(dir, nil)
+	ww.AddToWALBuffer("key1", "value1", 0, entity.RecordStateActive)
+	ww.AddToWALBuffer("key2", "value1", 0, entity.RecordStateActive)
+	ww.RotateWALFile()
+	ww.AddToWALBuffer("key1", "value2", 0, entity.RecordStateActive)
+	ww.Close()
+
+	reader, err := NewReader(dir)
+	if err != nil {
+		t.Fatalf("Failed to create WALReader: %v", err)
+	}
+	defer reader.Close()
+
+	memTable := memtable.CreateNewMemTable(config.MemtableStorageTypeLB, memtable.Channels{})
+	if keycount, err := reader.RestoreFromWAL(memTable); err != nil || keycount != 3 {
+		t.Fatalf("Expected the 3 entries of both segments to be restored, got %d (err=%v)", keycount, err)
+	}
+
+	record, _ := memTable.Get("key1")
+	if record == nil || record.(*entity.ScalarRecord).Value != "value2" {
+		t.Errorf("Expected the entry of the most recent segment to win, got %v", record)
 	}
 }
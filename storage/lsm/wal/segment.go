@@ -0,0 +1,124 @@
+package wal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"universum/config"
+	"universum/internal/logger"
+	"universum/utils/filesys"
+)
+
+const (
+	segmentFilePrefix    string = "writeahead-"
+	segmentFileExtension string = ".aof"
+
+	// legacySegmentSeq is the sequence the single WAL file, written before the
+	// log was split into segments, is replayed at, which is before all segments.
+	legacySegmentSeq int64 = 0
+)
+
+// segment is a WAL file covering the writes to one memtable, numbered in the
+// order the memtables were written to.
+type segment struct {
+	seq  int64
+	path string
+}
+
+// SegmentFileName returns the name of the WAL segment with the given sequence,
+// padded so that the segments sort in the order they were written.
+func SegmentFileName(seq int64) string {
+	return fmt.Sprintf("%s%020d%s", segmentFilePrefix, seq, segmentFileExtension)
+}
+
+// listSegments returns the WAL segments of the directory, the oldest first. The
+// single WAL file written before the segments is listed first, if still around.
+func listSegments(filedir string) ([]segment, error) {
+	files, err := os.ReadDir(filedir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WAL directory: %v", err)
+	}
+
+	segments := make([]segment, 0)
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() {
+			continue
+		}
+
+		if name == config.DefaultWALFileName {
+			segments = append(segments, segment{seq: legacySegmentSeq, path: filepath.Join(filedir, name)})
+			continue
+		}
+
+		if !strings.HasPrefix(name, segmentFilePrefix) || !strings.HasSuffix(name, segmentFileExtension) {
+			continue
+		}
+
+		seq, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, segmentFilePrefix), segmentFileExtension), 10, 64)
+		if err != nil || seq <= legacySegmentSeq {
+			continue
+		}
+		segments = append(segments, segment{seq: seq, path: filepath.Join(filedir, name)})
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].seq < segments[j].seq
+	})
+
+	return segments, nil
+}
+
+// releaseSegments removes the segments up to and including the given sequence,
+// or moves them to the archive directory when one is configured. They are
+// released the oldest first and the release stops at the first failure, so that
+// the segments left behind are always the most recent ones, and replaying them
+// never brings back a value older than one already flushed.
+func releaseSegments(filedir string, throughSeq int64) error {
+	segments, err := listSegments(filedir)
+	if err != nil {
+		return err
+	}
+
+	archiveDir := config.Store.Storage.LSM.WriteAheadLogArchiveDirectory
+	if archiveDir != "" {
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			return fmt.Errorf("failed to create WAL archive directory: %v", err)
+		}
+	}
+
+	for _, seg := range segments {
+		if seg.seq > throughSeq {
+			break
+		}
+
+		if archiveDir == "" {
+			err = os.Remove(seg.path)
+		} else {
+			err = archiveSegment(seg.path, filepath.Join(archiveDir, filepath.Base(seg.path)))
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to release WAL segment %s: %v", filepath.Base(seg.path), err)
+		}
+		logger.Get().Info("LSM:WAL:: Released WAL segment %s, its memtable being flushed", filepath.Base(seg.path))
+	}
+
+	return nil
+}
+
+// archiveSegment moves the segment to the archive directory, copying it over
+// when the directory is on another file system.
+func archiveSegment(src string, dest string) error {
+	if err := os.Rename(src, dest); err == nil {
+		return nil
+	}
+
+	if err := filesys.AtomicCopyFileContent(src, dest); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
@@ -2,9 +2,7 @@ package wal
 
 import (
 	"bytes"
-	"encoding/binary"
 	"fmt"
-	"io"
 	"math"
 	"os"
 	"path/filepath"
@@ -14,12 +12,12 @@ import (
 	"universum/entity"
 	"universum/internal/logger"
 	"universum/resp3"
-	"universum/storage/lsm/memtable"
 	"universum/utils"
 )
 
 type WALRecord struct {
 	Key    string
+	Family string
 	Value  interface{}
 	Expiry int64
 	State  uint8
@@ -27,6 +25,8 @@ type WALRecord struct {
 
 type WALWriter struct {
 	fileptr       *os.File
+	filedir       string
+	segmentSeq    int64 // sequence of the segment being written to
 	buffer        *bytes.Buffer
 	maxBufferSize int64
 	mutex         sync.Mutex
@@ -36,26 +36,73 @@ type WALWriter struct {
 	syncCounter   int64
 	syncThreshold int64
 	walSize       int64
+	durability    string // how durable the entries are once added, see config.WALDurabilityNone and others
+
+	// batch collects the entries added while a batch is open, so that they
+	// are written out together as a single entry once it is committed.
+	batch []interface{}
+
+	rotaterChan chan int64 // where the memtables tell the segment to be rotated
+
+	// sealedSegments holds the sequences of the segments sealed on rotation, whose
+	// memtables are not flushed yet, and flushedMemtables counts the memtables
+	// flushed before the rotation sealing their segment. The memtables are
+	// flushed in the order they were truncated in, which is the order the
+	// segments are sealed in, so that the two are paired in that order.
+	sealedSegments   []int64
+	flushedMemtables int64
+
+	// the entries added with fsync-per-batch are held in the buffer until the
+	// next group commit, see WaitForDurability.
+	spareBuffer         *bytes.Buffer
+	committed           *sync.Cond // signalled on the end of each group commit
+	committing          bool       // whether a group commit is writing out entries
+	appendedSeq         int64      // entries added so far
+	durableSeq          int64      // entries synced to disk so far
+	groupCommits        int64      // group commits done so far
+	groupCommitFailures int64      // group commits which failed, retried in a new segment or not
+	abandonedCommits    int64      // group commits failed even in a new segment, whose writers are failed
+	commitErr           error      // the error the last group commit failed with, until one succeeds
 }
 
-// NewWAL initializes a new WAL instance.
-func NewWriter(filedir string) (*WALWriter, error) {
-	filePath := filepath.Clean(filepath.Join(filedir, config.DefaultWALFileName))
-	fileptr, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// NewWriter initializes a new WAL instance, writing to a new segment numbered
+// after the ones already in the directory, which are kept for the replay. The
+// segment is rotated on each message received on the rotater channel.
+func NewWriter(filedir string, rotaterChan chan int64) (*WALWriter, error) {
+	segments, err := listSegments(filedir)
+	if err != nil {
+		return nil, err
+	}
+
+	segmentSeq := legacySegmentSeq + 1
+	if len(segments) > 0 {
+		segmentSeq = segments[len(segments)-1].seq + 1
+	}
 
+	fileptr, err := openSegment(filedir, segmentSeq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open WAL file: %v", err)
+		return nil, err
 	}
 
 	writer := &WALWriter{
 		fileptr:       fileptr,
+		filedir:       filedir,
+		segmentSeq:    segmentSeq,
+		rotaterChan:   rotaterChan,
 		isFlushing:    false,
 		syncCounter:   0,
 		syncThreshold: fileSyncThreshold,
 		walSize:       0,
+		durability:    durabilityOf(config.Store.Storage.LSM),
 	}
 
-	if config.Store.Storage.LSM.WriteAheadLogAsyncFlush {
+	switch writer.durability {
+	case config.WALDurabilityFsyncPerBatch:
+		writer.buffer = new(bytes.Buffer)
+		writer.spareBuffer = new(bytes.Buffer)
+		writer.committed = sync.NewCond(&writer.mutex)
+
+	case config.WALDurabilityNone:
 		cnf := config.Store.Storage.LSM
 		writer.maxBufferSize = int64(math.Min(float64(cnf.WriteAheadLogBufferSize), maxBufferSize))
 		flushInterval := time.Duration(math.Min(float64(cnf.WriteAheadLogFrequency), float64(maxFlushInterval)))
@@ -70,61 +117,114 @@ func NewWriter(filedir string) (*WALWriter, error) {
 	return writer, nil
 }
 
+// durabilityOf returns the durability the WAL is written with. The asynchronous
+// flush predates the durability setting, and stands for none when it is unset.
+func durabilityOf(cnf *config.LSM) string {
+	if cnf.WriteAheadLogDurability != "" {
+		return cnf.WriteAheadLogDurability
+	}
+
+	if cnf.WriteAheadLogAsyncFlush {
+		return config.WALDurabilityNone
+	}
+	return config.WALDurabilityOSBuffered
+}
+
 // AddToWALBuffer adds the key-value pair to the buffer.
 func (ww *WALWriter) AddToWALBuffer(key string, value interface{}, ttl int64, state uint8) error {
 	ww.mutex.Lock()
 	defer ww.mutex.Unlock()
 
 	select {
-	case <-memtable.WALRotaterChan:
-		ww.RotateWALFile()
+	case <-ww.rotaterChan:
+		if err := ww.RotateWALFile(); err != nil {
+			return fmt.Errorf("AddToWALBuffer:: WAL rotation failed: %v", err)
+		}
 
 	default:
 	}
 
+	if ww.batch != nil {
+		ww.batch = append(ww.batch, ww.getEntry(key, value, ttl, state))
+		return nil
+	}
+
 	encodedCommand, err := ww.getEncodedEntries(key, value, ttl, state)
 	if err != nil {
 		return fmt.Errorf("AddToWALBuffer:: WAL append failed: %v", err)
 	}
 
-	commandBytes := []byte(encodedCommand)
-	commandLen := int64(len(commandBytes))
+	return ww.appendEntry(encodedCommand)
+}
 
-	// when WriteAheadLogAsyncFlush is false, then writer will write to file immediately
-	// without calling fsync. Otherwise it'll just append to the buffer and writer to file
-	// through an async worker, and commit fsync immediately.
-	if !config.Store.Storage.LSM.WriteAheadLogAsyncFlush {
-		buf := bytes.NewBuffer(make([]byte, 0, commandLen+entity.Int64SizeInBytes))
-		if err := binary.Write(buf, binary.BigEndian, commandLen); err != nil {
-			return err
-		}
+// BeginBatch opens a batch, holding back the entries added from now on until
+// CommitBatch writes them out together.
+func (ww *WALWriter) BeginBatch() {
+	ww.mutex.Lock()
+	defer ww.mutex.Unlock()
 
-		if _, err := buf.Write(commandBytes); err != nil {
-			return err
-		}
+	ww.batch = make([]interface{}, 0)
+}
 
-		_, err := ww.fileptr.Write(buf.Bytes())
-		if err != nil {
-			return fmt.Errorf("AddToWALBuffer: WAL append failed: %v", err)
-		}
+// CommitBatch closes the open batch and writes all of its entries as a single WAL
+// entry, so that replaying the log restores either all of them or none at all.
+func (ww *WALWriter) CommitBatch() error {
+	ww.mutex.Lock()
+	defer ww.mutex.Unlock()
 
+	entries := ww.batch
+	ww.batch = nil
+
+	if len(entries) == 0 {
 		return nil
-	} else {
-		if err := binary.Write(ww.buffer, binary.BigEndian, commandLen); err != nil {
+	}
+
+	encodedBatch, err := resp3.Encode(map[string]interface{}{"Batch": entries})
+	if err != nil {
+		return fmt.Errorf("CommitBatch: failed to resp-encode batch of %d entries, err=%v", len(entries), err)
+	}
+
+	return ww.appendEntry(encodedBatch)
+}
+
+// appendEntry writes the entry, prefixed by its length and its checksum, the way
+// the durability asks for: to the buffer written out in the background for none,
+// to the WAL file for os-buffered, to the WAL file along with an fsync for
+// fsync-per-write, and to the buffer of the next group commit for fsync-per-batch.
+// The caller must hold the writer mutex.
+func (ww *WALWriter) appendEntry(encodedCommand string) error {
+	entryBytes := encodeEntry([]byte(encodedCommand))
+
+	switch ww.durability {
+	case config.WALDurabilityNone:
+		if _, err := ww.buffer.Write(entryBytes); err != nil {
 			return err
 		}
 
-		if _, err := ww.buffer.Write(commandBytes); err != nil {
+		if int64(ww.buffer.Len()) >= ww.maxBufferSize && !ww.isFlushing {
+			ww.isFlushing = true
+
+			select {
+			case ww.flusherCh <- struct{}{}:
+			default:
+			}
+		}
+
+	case config.WALDurabilityFsyncPerBatch:
+		if _, err := ww.buffer.Write(entryBytes); err != nil {
 			return err
 		}
-	}
+		ww.appendedSeq++
 
-	if int64(ww.buffer.Len()) >= ww.maxBufferSize && !ww.isFlushing {
-		ww.isFlushing = true
+	default:
+		if _, err := ww.fileptr.Write(entryBytes); err != nil {
+			return fmt.Errorf("AddToWALBuffer: WAL append failed: %v", err)
+		}
 
-		select {
-		case ww.flusherCh <- struct{}{}:
-		default:
+		if ww.durability == config.WALDurabilityFsyncPerWrite {
+			if err := ww.fileptr.Sync(); err != nil {
+				return fmt.Errorf("AddToWALBuffer: WAL sync failed: %v", err)
+			}
 		}
 	}
 
@@ -133,26 +233,28 @@ func (ww *WALWriter) AddToWALBuffer(key string, value interface{}, ttl int64, st
 
 // logEncodedEntries encodes the key, value, and other params into the buffer.
 func (ww *WALWriter) getEncodedEntries(key string, value interface{}, ttl int64, state uint8) (string, error) {
-	var command = make(map[string]interface{})
+	encodedCommand, err := resp3.Encode(ww.getEntry(key, value, ttl, state))
+	if err != nil {
+		return "", fmt.Errorf("failed to resp-encode command for key %s, err=%v", key, err)
+	}
 
+	return encodedCommand, nil
+}
+
+// getEntry builds the map form of a WAL entry, which is what gets encoded into the log.
+func (ww *WALWriter) getEntry(key string, value interface{}, ttl int64, state uint8) map[string]interface{} {
 	expiry := utils.GetCurrentEPochTime() + ttl
 	if ttl == 0 {
 		expiry = config.InfiniteExpiryTime
 	}
 
-	command = map[string]interface{}{
+	return map[string]interface{}{
 		"Key":    key,
+		"Family": entity.GetValueFamily(value),
 		"Value":  value,
 		"Expiry": expiry,
 		"State":  state,
 	}
-
-	encodedCommand, err := resp3.Encode(command)
-	if err != nil {
-		return "", fmt.Errorf("failed to resp-encode command for key %s, err=%v", key, err)
-	}
-
-	return encodedCommand, nil
 }
 
 // startFlusher starts the WAL flusher that runs on a hybrid system (size and time-based flush).
@@ -182,6 +284,12 @@ func (ww *WALWriter) flush() {
 	ww.mutex.Lock()
 	defer ww.mutex.Unlock()
 
+	ww.flushBuffer()
+}
+
+// flushBuffer writes the buffered data to the WAL file, and syncs it once every
+// few flushes. The caller must hold the writer mutex.
+func (ww *WALWriter) flushBuffer() bool {
 	bufLength := ww.buffer.Len()
 	if ww.buffer.Len() > 0 {
 		isFlushed := false
@@ -202,7 +310,7 @@ func (ww *WALWriter) flush() {
 
 		if !isFlushed {
 			logger.Get().Error("Failed to flush WAL buffer into file after %d retries", retryCount)
-			return
+			return false
 		}
 
 		ww.buffer.Next(bufLength)
@@ -217,6 +325,8 @@ func (ww *WALWriter) flush() {
 			ww.syncCounter = 0
 		}
 	}
+
+	return true
 }
 
 // attemptFlush writes the buffer to the file.
@@ -229,33 +339,145 @@ func (ww *WALWriter) attemptFlush(retryCount int) error {
 	return nil
 }
 
-// RotateWALFile truncates the WAL file to zero bytes.
+// RotateWALFile seals the segment being written to, once the memtable it covers
+// is handed to the flusher, and moves on to a new segment for the next memtable.
+// The sealed segment is released as soon as its memtable is flushed, see
+// ReleaseFlushedSegment. The caller must hold the writer mutex.
 func (ww *WALWriter) RotateWALFile() error {
-	err := ww.fileptr.Truncate(0)
-	if err != nil {
+	switch ww.durability {
+	case config.WALDurabilityNone:
+		if !ww.flushBuffer() {
+			return fmt.Errorf("failed to flush WAL buffer into segment %d", ww.segmentSeq)
+		}
+
+	case config.WALDurabilityFsyncPerBatch:
+		if err := ww.commitPending(); err != nil {
+			return err
+		}
+	}
+
+	if err := ww.sealSegment(); err != nil {
 		return err
 	}
 
-	_, err = ww.fileptr.Seek(0, io.SeekStart)
+	fileptr, err := openSegment(ww.filedir, ww.segmentSeq+1)
 	if err != nil {
+		return err
+	}
+
+	if err := ww.fileptr.Close(); err != nil {
+		logger.Get().Error("Failed to close WAL segment %d: %v", ww.segmentSeq, err)
+	}
+
+	sealedSeq := ww.segmentSeq
+	ww.fileptr = fileptr
+	ww.segmentSeq++
+
+	if ww.flushedMemtables > 0 {
+		ww.flushedMemtables--
+		ww.releaseSegments(sealedSeq)
 		return nil
 	}
 
+	ww.sealedSegments = append(ww.sealedSegments, sealedSeq)
 	return nil
 }
 
+// ReleaseFlushedSegment is called once a memtable handed to the flusher has been
+// flushed to an SSTable, and releases the segments covering it, removing them or
+// moving them to the archive directory. Should the segment of the memtable not be
+// sealed yet, which happens on the next write, it is released on rotation.
+func (ww *WALWriter) ReleaseFlushedSegment() {
+	ww.mutex.Lock()
+	defer ww.mutex.Unlock()
+
+	if len(ww.sealedSegments) == 0 {
+		ww.flushedMemtables++
+		return
+	}
+
+	sealedSeq := ww.sealedSegments[0]
+	ww.sealedSegments = ww.sealedSegments[1:]
+	ww.releaseSegments(sealedSeq)
+}
+
+// releaseSegments releases the segments up to and including the given one. The
+// segments a release fails for are replayed on the next start, and retried on
+// the next release.
+func (ww *WALWriter) releaseSegments(throughSeq int64) {
+	if err := releaseSegments(ww.filedir, throughSeq); err != nil {
+		logger.Get().Error("LSM:WAL:: %v", err)
+	}
+}
+
+// openSegment creates the WAL segment with the given sequence for appending, and
+// writes its header.
+func openSegment(filedir string, seq int64) (*os.File, error) {
+	filePath := filepath.Clean(filepath.Join(filedir, SegmentFileName(seq)))
+	fileptr, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAL file: %v", err)
+	}
+
+	if _, err := fileptr.Write(segmentHeader()); err != nil {
+		fileptr.Close()
+		return nil, fmt.Errorf("failed to write WAL header: %v", err)
+	}
+
+	return fileptr, nil
+}
+
 // Close closes the WAL file and stops the ticker.
 func (ww *WALWriter) Close() {
-	if config.Store.Storage.LSM.WriteAheadLogAsyncFlush {
+	switch ww.durability {
+	case config.WALDurabilityNone:
 		if ww.ticker != nil {
 			ww.ticker.Stop()
 		}
 
 		ww.flush() // final flush before closing
+
+	case config.WALDurabilityFsyncPerBatch:
+		ww.mutex.Lock()
+		if err := ww.commitPending(); err != nil {
+			logger.Get().Error("Failed to commit WAL entries before closing: %v", err)
+		}
+		ww.mutex.Unlock()
 	}
 
+	ww.mutex.Lock()
+	if err := ww.sealSegment(); err != nil {
+		logger.Get().Error("Failed to seal WAL segment before closing: %v", err)
+	}
+	ww.mutex.Unlock()
+
 	err := ww.fileptr.Close()
 	if err != nil {
 		logger.Get().Error("Failed to close WAL file: %v", err)
 	}
 }
+
+// sealSegment ends the segment with its trailer, once nothing more is to be
+// written to it, and syncs it. The replay tells by the trailer that the segment
+// was synced whole, and that whatever is wrong with it is no torn write.
+func (ww *WALWriter) sealSegment() error {
+	info, err := ww.fileptr.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat WAL segment %d: %v", ww.segmentSeq, err)
+	}
+
+	// the entries are synced first, for the trailer never to reach the disk without them
+	if err := ww.fileptr.Sync(); err != nil {
+		return fmt.Errorf("failed to sync WAL segment %d: %v", ww.segmentSeq, err)
+	}
+
+	if _, err := ww.fileptr.Write(segmentTrailer(info.Size())); err != nil {
+		return fmt.Errorf("failed to seal WAL segment %d: %v", ww.segmentSeq, err)
+	}
+
+	if err := ww.fileptr.Sync(); err != nil {
+		return fmt.Errorf("failed to sync WAL segment %d: %v", ww.segmentSeq, err)
+	}
+	return nil
+}
@@ -41,13 +41,13 @@ func TestNewWriter(t *testing.T) {
 	dir := createTempDir(t)
 	defer cleanupDir(t, dir)
 
-	writer, err := NewWriter(dir)
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
 		t.Fatalf("Failed to create WALWriter: %v", err)
 	}
 	defer writer.Close()
 
-	walFilePath := filepath.Join(dir, config.DefaultWALFileName)
+	walFilePath := filepath.Join(dir, SegmentFileName(1))
 	if _, err := os.Stat(walFilePath); os.IsNotExist(err) {
 		t.Fatalf("WAL file does not exist at path: %s", walFilePath)
 	}
@@ -60,7 +60,7 @@ func TestAddToWALBufferSyncFlush(t *testing.T) {
 
 	config.Store.Storage.LSM.WriteAheadLogAsyncFlush = false
 
-	writer, err := NewWriter(dir)
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
 		t.Fatalf("Failed to create WALWriter: %v", err)
 	}
@@ -71,7 +71,7 @@ func TestAddToWALBufferSyncFlush(t *testing.T) {
 		t.Fatalf("AddToWALBuffer failed: %v", err)
 	}
 
-	walFilePath := filepath.Join(dir, config.DefaultWALFileName)
+	walFilePath := filepath.Join(dir, SegmentFileName(1))
 	data, err := os.ReadFile(walFilePath)
 	if err != nil {
 		t.Fatalf("Failed to read WAL file: %v", err)
@@ -89,7 +89,7 @@ func TestAddToWALBufferAsyncFlush(t *testing.T) {
 
 	config.Store.Storage.LSM.WriteAheadLogAsyncFlush = true
 
-	writer, err := NewWriter(dir)
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
 		t.Fatalf("Failed to create WALWriter: %v", err)
 	}
@@ -102,7 +102,7 @@ func TestAddToWALBufferAsyncFlush(t *testing.T) {
 
 	time.Sleep(2 * time.Second)
 
-	walFilePath := filepath.Join(dir, config.DefaultWALFileName)
+	walFilePath := filepath.Join(dir, SegmentFileName(1))
 	data, err := os.ReadFile(walFilePath)
 	if err != nil {
 		t.Fatalf("Failed to read WAL file: %v", err)
@@ -120,7 +120,7 @@ func TestFlush(t *testing.T) {
 
 	config.Store.Storage.LSM.WriteAheadLogAsyncFlush = true
 
-	writer, err := NewWriter(dir)
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
 		t.Fatalf("Failed to create WALWriter: %v", err)
 	}
@@ -133,7 +133,7 @@ func TestFlush(t *testing.T) {
 
 	writer.flush()
 
-	walFilePath := filepath.Join(dir, config.DefaultWALFileName)
+	walFilePath := filepath.Join(dir, SegmentFileName(1))
 	data, err := os.ReadFile(walFilePath)
 	if err != nil {
 		t.Fatalf("Failed to read WAL file: %v", err)
@@ -152,7 +152,7 @@ func TestBufferFlushOnMaxSize(t *testing.T) {
 	config.Store.Storage.LSM.WriteAheadLogAsyncFlush = true
 	config.Store.Storage.LSM.WriteAheadLogBufferSize = 100 // bytes
 
-	writer, err := NewWriter(dir)
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
 		t.Fatalf("Failed to create WALWriter: %v", err)
 	}
@@ -184,7 +184,7 @@ func TestBufferFlushOnMaxSize(t *testing.T) {
 		t.Errorf("Expected buffer length to be 0 after flush, got %d", writer.buffer.Len())
 	}
 
-	walFilePath := filepath.Join(dir, config.DefaultWALFileName)
+	walFilePath := filepath.Join(dir, SegmentFileName(1))
 	data, err := os.ReadFile(walFilePath)
 	if err != nil {
 		t.Fatalf("Failed to read WAL file: %v", err)
@@ -200,9 +200,9 @@ func TestRotateWALFile(t *testing.T) {
 	dir := createTempDir(t)
 	defer cleanupDir(t, dir)
 
-	config.Store.Storage.LSM.WriteAheadLogAsyncFlush = false
+	config.Store.Storage.LSM.WriteAheadLogAsyncFlush = true
 
-	writer, err := NewWriter(dir)
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
 		t.Fatalf("Failed to create WALWriter: %v", err)
 	}
@@ -218,14 +218,107 @@ func TestRotateWALFile(t *testing.T) {
 		t.Fatalf("RotateWALFile failed: %v", err)
 	}
 
-	walFilePath := filepath.Join(dir, config.DefaultWALFileName)
-	fileInfo, err := os.Stat(walFilePath)
+	// the buffered entry is written out to the sealed segment
+	fileInfo, err := os.Stat(filepath.Join(dir, SegmentFileName(1)))
+	if err != nil || fileInfo.Size() == 0 {
+		t.Fatalf("Expected the sealed segment to hold the entry, got %v", err)
+	}
+
+	fileInfo, err = os.Stat(filepath.Join(dir, SegmentFileName(2)))
+	if err != nil || fileInfo.Size() != headerSize {
+		t.Fatalf("Expected an empty segment to be written to after rotation, got %v", err)
+	}
+
+	if writer.segmentSeq != 2 || len(writer.sealedSegments) != 1 {
+		t.Errorf("Expected segment 1 sealed and segment 2 written to, got %d and %v", writer.segmentSeq, writer.sealedSegments)
+	}
+}
+
+func TestSegmentsAreReleasedOnceFlushed(t *testing.T) {
+	setupWriterTests(t)
+	dir := createTempDir(t)
+	defer cleanupDir(t, dir)
+
+	writer, err := NewWriter(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed to create WALWriter: %v", err)
+	}
+	defer writer.Close()
+
+	writer.AddToWALBuffer("key1", "value1", 0, entity.RecordStateActive)
+	writer.RotateWALFile()
+	writer.AddToWALBuffer("key2", "value2", 0, entity.RecordStateActive)
+
+	writer.ReleaseFlushedSegment()
+	if _, err := os.Stat(filepath.Join(dir, SegmentFileName(1))); !os.IsNotExist(err) {
+		t.Errorf("Expected the segment of the flushed memtable to be deleted, got %v", err)
+	}
+
+	// the memtable of segment 2 is flushed before the rotation sealing it
+	writer.ReleaseFlushedSegment()
+	if _, err := os.Stat(filepath.Join(dir, SegmentFileName(2))); err != nil {
+		t.Errorf("Expected the segment being written to be kept, got %v", err)
+	}
+
+	writer.RotateWALFile()
+	if _, err := os.Stat(filepath.Join(dir, SegmentFileName(2))); !os.IsNotExist(err) {
+		t.Errorf("Expected the segment to be deleted once sealed, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, SegmentFileName(3))); err != nil {
+		t.Errorf("Expected the segment being written to be kept, got %v", err)
+	}
+}
+
+func TestSegmentsAreArchivedOnceFlushed(t *testing.T) {
+	setupWriterTests(t)
+	dir := createTempDir(t)
+	defer cleanupDir(t, dir)
+
+	archiveDir := filepath.Join(t.TempDir(), "archive")
+	config.Store.Storage.LSM.WriteAheadLogArchiveDirectory = archiveDir
+
+	writer, err := NewWriter(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed to create WALWriter: %v", err)
+	}
+	defer writer.Close()
+
+	writer.AddToWALBuffer("key1", "value1", 0, entity.RecordStateActive)
+	writer.RotateWALFile()
+	writer.ReleaseFlushedSegment()
+
+	if _, err := os.Stat(filepath.Join(dir, SegmentFileName(1))); !os.IsNotExist(err) {
+		t.Errorf("Expected the segment to be moved out of the WAL directory, got %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(archiveDir, SegmentFileName(1)))
+	if err != nil || len(data) == 0 {
+		t.Errorf("Expected the segment to be archived with its entry, got %v", err)
+	}
+}
+
+func TestNewWriterStartsNewSegment(t *testing.T) {
+	setupWriterTests(t)
+	dir := createTempDir(t)
+	defer cleanupDir(t, dir)
+
+	os.WriteFile(filepath.Join(dir, config.DefaultWALFileName), nil, 0644)
+	os.WriteFile(filepath.Join(dir, SegmentFileName(7)), nil, 0644)
+
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
-		t.Fatalf("Failed to stat WAL file: %v", err)
+		t.Fatalf("Failed to create WALWriter: %v", err)
+	}
+	defer writer.Close()
+
+	if writer.segmentSeq != 8 {
+		t.Errorf("Expected the writer to start segment 8, got %d", writer.segmentSeq)
 	}
 
-	if fileInfo.Size() != 0 {
-		t.Fatalf("WAL file size is not zero after rotation")
+	segments, _ := listSegments(dir)
+	if len(segments) != 3 || segments[0].seq != legacySegmentSeq || segments[2].seq != 8 {
+		t.Errorf("Expected the legacy file first and the new segment last, got %v", segments)
 	}
 }
 
@@ -236,7 +329,7 @@ func TestConcurrentAddToWALBuffer(t *testing.T) {
 
 	config.Store.Storage.LSM.WriteAheadLogAsyncFlush = false
 
-	writer, err := NewWriter(dir)
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
 		t.Fatalf("Failed to create WALWriter: %v", err)
 	}
@@ -263,7 +356,7 @@ func TestConcurrentAddToWALBuffer(t *testing.T) {
 
 	wg.Wait()
 
-	walFilePath := filepath.Join(dir, config.DefaultWALFileName)
+	walFilePath := filepath.Join(dir, SegmentFileName(1))
 	data, err := os.ReadFile(walFilePath)
 	if err != nil {
 		t.Fatalf("Failed to read WAL file: %v", err)
@@ -281,7 +374,7 @@ func TestWriterClose(t *testing.T) {
 
 	config.Store.Storage.LSM.WriteAheadLogAsyncFlush = true
 
-	writer, err := NewWriter(dir)
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
 		t.Fatalf("Failed to create WALWriter: %v", err)
 	}
@@ -293,7 +386,7 @@ func TestWriterClose(t *testing.T) {
 
 	writer.Close()
 
-	walFilePath := filepath.Join(dir, config.DefaultWALFileName)
+	walFilePath := filepath.Join(dir, SegmentFileName(1))
 	data, err := os.ReadFile(walFilePath)
 	if err != nil {
 		t.Fatalf("Failed to read WAL file: %v", err)
@@ -309,7 +402,7 @@ func TestGetEncodedEntries(t *testing.T) {
 	dir := createTempDir(t)
 	defer cleanupDir(t, dir)
 
-	writer, err := NewWriter(dir)
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
 		t.Fatalf("Failed to create WALWriter: %v", err)
 	}
@@ -328,7 +421,7 @@ func TestAttemptFlushFailure(t *testing.T) {
 
 	config.Store.Storage.LSM.WriteAheadLogAsyncFlush = true
 
-	writer, err := NewWriter(dir)
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
 		t.Fatalf("Failed to create WALWriter: %v", err)
 	}
@@ -349,7 +442,7 @@ func TestSyncCounterThreshold(t *testing.T) {
 
 	config.Store.Storage.LSM.WriteAheadLogAsyncFlush = true
 
-	writer, err := NewWriter(dir)
+	writer, err := NewWriter(dir, nil)
 	if err != nil {
 		t.Fatalf("Failed to create WALWriter: %v", err)
 	}
@@ -368,3 +461,156 @@ func TestSyncCounterThreshold(t *testing.T) {
 		t.Errorf("Expected syncCounter to be reset to 0, got %d", writer.syncCounter)
 	}
 }
+
+func TestGroupCommitCoalescesWriters(t *testing.T) {
+	setupWriterTests(t)
+	dir := t.TempDir()
+
+	config.Store.Storage.LSM.WriteAheadLogDurability = config.WALDurabilityFsyncPerBatch
+
+	writer, err := NewWriter(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed to create WALWriter: %v", err)
+	}
+	defer writer.Close()
+
+	numWriters := 10
+	for i := 0; i < numWriters; i++ {
+		if err := writer.AddToWALBuffer(fmt.Sprintf("key%d", i), "value", 0, entity.RecordStateActive); err != nil {
+			t.Fatalf("AddToWALBuffer failed: %v", err)
+		}
+	}
+
+	// held back until committed
+	if info, _ := os.Stat(filepath.Join(dir, SegmentFileName(1))); info.Size() != headerSize {
+		t.Fatalf("Expected the entries to be held back until committed, WAL has %d bytes", info.Size())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWriters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := writer.WaitForDurability(); err != nil {
+				t.Errorf("WaitForDurability failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if writer.groupCommits != 1 || writer.durableSeq != int64(numWriters) {
+		t.Errorf("Expected the %d writers to be committed together, got %d group commits for %d entries",
+			numWriters, writer.groupCommits, writer.durableSeq)
+	}
+
+	reader, _ := NewReader(dir)
+	defer reader.Close()
+
+	if entries, err := reader.readEntries(); err != nil || len(entries) != numWriters {
+		t.Errorf("Expected the %d entries to be in the WAL, got %d (err=%v)", numWriters, len(entries), err)
+	}
+}
+
+func TestGroupCommitFailureIsRetriedInNewSegment(t *testing.T) {
+	setupWriterTests(t)
+	dir := t.TempDir()
+
+	config.Store.Storage.LSM.WriteAheadLogDurability = config.WALDurabilityFsyncPerBatch
+
+	writer, err := NewWriter(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed to create WALWriter: %v", err)
+	}
+	defer writer.Close()
+
+	writer.AddToWALBuffer("key1", "value1", 0, entity.RecordStateActive)
+	writer.fileptr.Close()
+
+	if err := writer.WaitForDurability(); err != nil {
+		t.Fatalf("Expected the failed commit to be retried in a new segment, got %v", err)
+	}
+
+	if writer.segmentSeq != 2 || writer.GetGroupCommitFailures() != 1 {
+		t.Errorf("Expected 1 failed commit moving on to segment 2, got %d failures in segment %d",
+			writer.GetGroupCommitFailures(), writer.segmentSeq)
+	}
+
+	if err := writer.AddToWALBuffer("key2", "value2", 0, entity.RecordStateActive); err != nil {
+		t.Fatalf("AddToWALBuffer failed: %v", err)
+	}
+
+	if err := writer.WaitForDurability(); err != nil {
+		t.Fatalf("Expected the writes after a failed commit to succeed, got %v", err)
+	}
+
+	reader, _ := NewReader(dir)
+	defer reader.Close()
+
+	if entries, err := reader.readEntries(); err != nil || len(entries) != 2 {
+		t.Errorf("Expected the 2 entries to be in the WAL, got %d (err=%v)", len(entries), err)
+	}
+}
+
+func TestGroupCommitFailingInNewSegmentIsRetriedByNextCommit(t *testing.T) {
+	setupWriterTests(t)
+	dir := t.TempDir()
+
+	config.Store.Storage.LSM.WriteAheadLogDurability = config.WALDurabilityFsyncPerBatch
+
+	writer, err := NewWriter(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed to create WALWriter: %v", err)
+	}
+	defer writer.Close()
+
+	writer.AddToWALBuffer("key1", "value1", 0, entity.RecordStateActive)
+	writer.fileptr.Close()
+	os.RemoveAll(dir) // no new segment can be opened either
+
+	if err := writer.WaitForDurability(); err == nil {
+		t.Errorf("Expected the failed commit to be reported")
+	}
+
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to recreate the WAL directory: %v", err)
+	}
+
+	if err := writer.AddToWALBuffer("key2", "value2", 0, entity.RecordStateActive); err != nil {
+		t.Fatalf("AddToWALBuffer failed: %v", err)
+	}
+
+	if err := writer.WaitForDurability(); err != nil {
+		t.Fatalf("Expected the next commit to move on to a new segment, got %v", err)
+	}
+
+	reader, _ := NewReader(dir)
+	defer reader.Close()
+
+	// the entries of the failed commit are kept, and written out along with the next ones
+	if entries, err := reader.readEntries(); err != nil || len(entries) != 2 {
+		t.Errorf("Expected the 2 entries to be in the WAL, got %d (err=%v)", len(entries), err)
+	}
+}
+
+func TestFsyncPerWriteWritesRightAway(t *testing.T) {
+	setupWriterTests(t)
+	dir := t.TempDir()
+
+	config.Store.Storage.LSM.WriteAheadLogDurability = config.WALDurabilityFsyncPerWrite
+
+	writer, err := NewWriter(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed to create WALWriter: %v", err)
+	}
+	defer writer.Close()
+
+	writer.AddToWALBuffer("key1", "value1", 0, entity.RecordStateActive)
+
+	if info, _ := os.Stat(filepath.Join(dir, SegmentFileName(1))); info.Size() == headerSize {
+		t.Errorf("Expected the entry to be written right away")
+	}
+
+	if err := writer.WaitForDurability(); err != nil {
+		t.Errorf("Expected nothing to wait for, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"universum/entity"
+	"universum/storage"
+)
+
+// UpdateCollection runs the read-modify-write cycle of the collection commands over
+// the key, under the shard write lock.
+func (ms *MemoryStore) UpdateCollection(key string, family string, create bool,
+	mutate func(entity.Record) (interface{}, uint32)) uint32 {
+
+	shard := ms.getShardByKey(key)
+	shard.writeLock.Lock()
+	defer shard.writeLock.Unlock()
+
+	value, ttl, code := storage.MutateCollection(ms, key, family, create, mutate)
+	if code != entity.CRC_RECORD_UPDATED {
+		return code
+	}
+
+	if value == nil {
+		ms.delete(shard, key)
+		return entity.CRC_RECORD_UPDATED
+	}
+
+	_, code = ms.set(shard, key, value, ttl)
+	return code
+}
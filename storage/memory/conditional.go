@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"reflect"
+	"universum/entity"
+	"universum/storage"
+	"universum/utils"
+)
+
+// SetWithCondition writes the value if the condition holds for the key, and returns
+// the record it replaced, nil if the key did not exist. The check and the write are
+// made under the shard write lock, so no other write of the key can come in between.
+func (ms *MemoryStore) SetWithCondition(key string, value interface{}, ttl int64,
+	condition storage.SetCondition) (entity.Record, uint32) {
+
+	shard := ms.getShardByKey(key)
+	shard.writeLock.Lock()
+	defer shard.writeLock.Unlock()
+
+	previous, code := ms.Get(key)
+	if code != entity.CRC_RECORD_FOUND {
+		previous = nil
+	}
+
+	if !condition.Allows(previous != nil) {
+		return previous, entity.CRC_CONDITION_NOT_MET
+	}
+
+	if didSet, code := ms.set(shard, key, value, ttl); !didSet {
+		return previous, code
+	}
+
+	return previous, entity.CRC_RECORD_UPDATED
+}
+
+// GetDelete removes the key, and returns the record it held.
+func (ms *MemoryStore) GetDelete(key string) (entity.Record, uint32) {
+	shard := ms.getShardByKey(key)
+	shard.writeLock.Lock()
+	defer shard.writeLock.Unlock()
+
+	previous, code := ms.Get(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return nil, entity.CRC_RECORD_NOT_FOUND
+	}
+
+	ms.delete(shard, key)
+	return previous, entity.CRC_RECORD_DELETED
+}
+
+// CompareAndSwap replaces the scalar value stored against the key with the new
+// value, only if it currently equals the expected one. The expiry of the key is
+// left as it was.
+func (ms *MemoryStore) CompareAndSwap(key string, expected interface{}, value interface{}) (bool, uint32) {
+	shard := ms.getShardByKey(key)
+	shard.writeLock.Lock()
+	defer shard.writeLock.Unlock()
+
+	previous, code := ms.Get(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return false, entity.CRC_RECORD_NOT_FOUND
+	}
+
+	record, ok := previous.(*entity.ScalarRecord)
+	if !ok {
+		return false, entity.CRC_WRONG_TYPE
+	}
+
+	if !reflect.DeepEqual(record.Value, expected) {
+		return false, entity.CRC_VALUE_MISMATCH
+	}
+
+	return ms.set(shard, key, value, record.Expiry-utils.GetCurrentEPochTime())
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"sync"
+	"testing"
+	"universum/entity"
+	"universum/storage"
+)
+
+func TestMemstore_SetWithCondition(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	previous, code := m.SetWithCondition("lock", "owner-1", 0, storage.SetIfPresent)
+	if previous != nil || code != entity.CRC_CONDITION_NOT_MET {
+		t.Errorf("expected XX on missing key to not be met, got %v, %d", previous, code)
+	}
+
+	previous, code = m.SetWithCondition("lock", "owner-1", 0, storage.SetIfAbsent)
+	if previous != nil || code != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected NX on missing key to write, got %v, %d", previous, code)
+	}
+
+	previous, code = m.SetWithCondition("lock", "owner-2", 0, storage.SetIfAbsent)
+	if code != entity.CRC_CONDITION_NOT_MET || previous.GetValue() != "owner-1" {
+		t.Errorf("expected NX on existing key to not be met, got %v, %d", previous, code)
+	}
+
+	previous, code = m.SetWithCondition("lock", "owner-3", 0, storage.SetIfPresent)
+	if code != entity.CRC_RECORD_UPDATED || previous.GetValue() != "owner-1" {
+		t.Errorf("expected XX on existing key to write, got %v, %d", previous, code)
+	}
+
+	record, _ := m.Get("lock")
+	if record.GetValue() != "owner-3" {
+		t.Errorf("expected value owner-3, got %v", record.GetValue())
+	}
+}
+
+func TestMemstore_GetDelete(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	if previous, code := m.GetDelete("missing"); previous != nil || code != entity.CRC_RECORD_NOT_FOUND {
+		t.Errorf("expected missing key to not be found, got %v, %d", previous, code)
+	}
+
+	m.Set("token", "abc", 0)
+
+	previous, code := m.GetDelete("token")
+	if code != entity.CRC_RECORD_DELETED || previous.GetValue() != "abc" {
+		t.Errorf("expected deleted value abc, got %v, %d", previous, code)
+	}
+
+	if exists, _ := m.Exists("token"); exists {
+		t.Error("expected key to be deleted")
+	}
+}
+
+func TestMemstore_CompareAndSwap(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	if swapped, code := m.CompareAndSwap("version", int64(1), int64(2)); swapped || code != entity.CRC_RECORD_NOT_FOUND {
+		t.Errorf("expected missing key to not be found, got %v, %d", swapped, code)
+	}
+
+	m.Set("version", int64(1), 100)
+
+	if swapped, code := m.CompareAndSwap("version", int64(5), int64(2)); swapped || code != entity.CRC_VALUE_MISMATCH {
+		t.Errorf("expected value mismatch, got %v, %d", swapped, code)
+	}
+
+	if swapped, code := m.CompareAndSwap("version", int64(1), int64(2)); !swapped || code != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected swap, got %v, %d", swapped, code)
+	}
+
+	if ttl, _ := m.TTL("version"); ttl <= 0 || ttl > 100 {
+		t.Errorf("expected swap to keep the ttl, got %d", ttl)
+	}
+
+	m.HSet("hash", map[string]interface{}{"field": "value"})
+	if swapped, code := m.CompareAndSwap("hash", "value", "other"); swapped || code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type, got %v, %d", swapped, code)
+	}
+}
+
+func TestMemstore_ConditionalWritesAreAtomic(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+	m.Set("counter", int64(0), 0)
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	acquired := 0
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, code := m.SetWithCondition("lock", "owner", 0, storage.SetIfAbsent); code == entity.CRC_RECORD_UPDATED {
+				mutex.Lock()
+				acquired++
+				mutex.Unlock()
+			}
+
+			// retry the increment until the swap wins over the others
+			for {
+				record, _ := m.Get("counter")
+				current := record.GetValue().(int64)
+				if swapped, _ := m.CompareAndSwap("counter", current, current+1); swapped {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if acquired != 1 {
+		t.Errorf("expected exactly one NX write to succeed, got %d", acquired)
+	}
+
+	if record, _ := m.Get("counter"); record.GetValue() != int64(50) {
+		t.Errorf("expected no increment to be lost, got %v", record.GetValue())
+	}
+}
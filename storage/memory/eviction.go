@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"universum/config"
+	"universum/entity"
+	"universum/internal/logger"
+	"universum/storage"
+
+	"golang.org/x/exp/rand"
+)
+
+const (
+	// evictionSampleSize is the number of keys sampled from a shard to pick
+	// a single eviction candidate. Bigger samples approximate true LRU/LFU
+	// better at the cost of more work per evicted key.
+	evictionSampleSize int = 16
+
+	// maxConsecutiveEvictionMisses bounds the number of shards inspected in a row
+	// without finding an eligible candidate, after which the eviction gives up.
+	// Misses move on to the next shard, so this amounts to one full pass over the
+	// store. Volatile policies hit this when no key in the store has an expiry.
+	maxConsecutiveEvictionMisses int = int(ShardCount)
+)
+
+type evictionCandidate struct {
+	key    string
+	record entity.Record
+}
+
+// EvictSample evicts up to maxKeys records from the store as per the given eviction
+// policy. For every key to be evicted, a random shard is picked and a random sample of
+// its records is inspected; the sampled record which ranks first for the policy is
+// removed. Already expired records are always preferred over live ones.
+func EvictSample(store *MemoryStore, policy string, maxKeys int64) int64 {
+	var evictedCount int64 = 0
+	var misses int = 0
+
+	isEligible, isPreferred := getEvictionRanking(policy)
+	if isPreferred == nil {
+		return 0
+	}
+
+	shards := store.GetAllShards()
+	randomGenerator := rand.New(rand.NewSource(uint64(time.Now().UnixNano())))
+
+	shardIndex := randomGenerator.Intn(len(shards))
+
+	for evictedCount < maxKeys && misses < maxConsecutiveEvictionMisses {
+		shard := shards[shardIndex]
+		candidates := sampleShard(shard, evictionSampleSize, isEligible, randomGenerator)
+
+		if len(candidates) == 0 {
+			misses++
+			shardIndex = (shardIndex + 1) % len(shards)
+			continue
+		}
+
+		misses = 0
+		shardIndex = randomGenerator.Intn(len(shards))
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return isPreferred(candidates[i].record, candidates[j].record)
+		})
+
+		victim := candidates[0]
+		if shard.data.CompareAndDelete(victim.key, victim.record) {
+			storage.NotifyKeyspaceEvent(storage.KeyspaceEventEvicted, victim.key)
+			evictedCount++
+		}
+	}
+
+	if evictedCount > 0 {
+		logger.Get().Debug("AutoEvictionWorker:: Policy=%s, Count=%d", policy, evictedCount)
+	}
+
+	return evictedCount
+}
+
+// sampleShard collects a random sample of up to sampleSize eligible records from the
+// given shard. The sync.Map walks its keys in the same order every time, so the whole
+// shard is walked and the sample drawn from it by reservoir sampling, for the keys
+// not to be picked by the order they are stored in. The sample is shuffled, as its
+// first slots are biased towards the first keys walked.
+func sampleShard(shard *Shard, sampleSize int, isEligible func(entity.Record) bool, randomGenerator *rand.Rand) []*evictionCandidate {
+	candidates := make([]*evictionCandidate, 0, sampleSize)
+	eligible := 0
+
+	shard.GetData().Range(func(key interface{}, value interface{}) bool {
+		strkey, ok := key.(string)
+		if !ok {
+			return true
+		}
+
+		record, ok := value.(entity.Record)
+		if !ok || !isEligible(record) {
+			return true
+		}
+
+		eligible++
+		if len(candidates) < sampleSize {
+			candidates = append(candidates, &evictionCandidate{key: strkey, record: record})
+		} else if slot := randomGenerator.Intn(eligible); slot < sampleSize {
+			candidates[slot] = &evictionCandidate{key: strkey, record: record}
+		}
+		return true
+	})
+
+	randomGenerator.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	return candidates
+}
+
+// getEvictionRanking returns the eligibility filter and the ordering function for the
+// given eviction policy. The ordering function tells if record a should be evicted
+// before record b. Unknown policies return a nil ordering function.
+func getEvictionRanking(policy string) (func(entity.Record) bool, func(a, b entity.Record) bool) {
+	anyRecord := func(_ entity.Record) bool { return true }
+	volatileRecord := func(r entity.Record) bool { return r.GetExpiry() != config.InfiniteExpiryTime }
+
+	switch policy {
+	case config.EvictionPolicyLRU:
+		return anyRecord, func(a, b entity.Record) bool {
+			if a.IsExpired() != b.IsExpired() {
+				return a.IsExpired()
+			}
+			return a.GetLAT() < b.GetLAT()
+		}
+
+	case config.EvictionPolicyLFU:
+		return anyRecord, func(a, b entity.Record) bool {
+			if a.IsExpired() != b.IsExpired() {
+				return a.IsExpired()
+			}
+
+			aFreq, bFreq := a.GetFrequency(), b.GetFrequency()
+			if aFreq != bFreq {
+				return aFreq < bFreq
+			}
+			return a.GetLAT() < b.GetLAT()
+		}
+
+	case config.EvictionPolicyRandom:
+		// the sample is shuffled, so keep its order as is
+		return anyRecord, func(a, b entity.Record) bool {
+			return a.IsExpired() && !b.IsExpired()
+		}
+
+	case config.EvictionPolicyVolatileLRU:
+		return volatileRecord, func(a, b entity.Record) bool {
+			if a.IsExpired() != b.IsExpired() {
+				return a.IsExpired()
+			}
+			return a.GetLAT() < b.GetLAT()
+		}
+
+	case config.EvictionPolicyVolatileTTL:
+		return volatileRecord, func(a, b entity.Record) bool {
+			return a.GetExpiry() < b.GetExpiry()
+		}
+
+	default:
+		return anyRecord, nil
+	}
+}
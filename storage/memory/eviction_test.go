@@ -0,0 +1,313 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+	"universum/config"
+	"universum/entity"
+	"universum/utils"
+)
+
+func TestEvictSample_EvictsRequestedCount(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	for i := 0; i < 500; i++ {
+		m.Set(fmt.Sprintf("key-%d", i), i, 0)
+	}
+
+	evicted := EvictSample(m, config.EvictionPolicyLRU, 100)
+	if evicted != 100 {
+		t.Errorf("expected 100 keys to be evicted, got %d", evicted)
+	}
+
+	remaining := 0
+	for _, shard := range m.GetAllShards() {
+		shard.GetData().Range(func(_, _ interface{}) bool {
+			remaining++
+			return true
+		})
+	}
+
+	if remaining != 400 {
+		t.Errorf("expected 400 keys to remain, got %d", remaining)
+	}
+}
+
+func TestEvictSample_PrefersLeastRecentlyUsed(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	staleKey, freshKey := sameShardKeys(m)
+
+	m.Set(staleKey, "value", 0)
+	m.Set(freshKey, "value", 0)
+
+	stale, _ := m.Get(staleKey)
+	stale.(*entity.ScalarRecord).LAT = 1
+
+	if evicted := EvictSample(m, config.EvictionPolicyLRU, 1); evicted != 1 {
+		t.Fatalf("expected 1 key to be evicted, got %d", evicted)
+	}
+
+	if exists, _ := m.Exists(staleKey); exists {
+		t.Errorf("expected least recently used key to be evicted")
+	}
+
+	if exists, _ := m.Exists(freshKey); !exists {
+		t.Errorf("expected recently used key to survive eviction")
+	}
+}
+
+func TestEvictSample_EmptyStore(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	evicted := EvictSample(m, config.EvictionPolicyLRU, 10)
+	if evicted != 0 {
+		t.Errorf("expected no keys to be evicted from empty store, got %d", evicted)
+	}
+}
+
+func TestEvictSample_LFUPrefersLeastFrequentlyUsed(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	coldKey, hotKey := sameShardKeys(m)
+	m.Set(coldKey, "value", 0)
+	m.Set(hotKey, "value", 0)
+
+	for i := 0; i < 10; i++ {
+		m.Get(hotKey)
+	}
+
+	if evicted := EvictSample(m, config.EvictionPolicyLFU, 1); evicted != 1 {
+		t.Fatalf("expected 1 key to be evicted, got %d", evicted)
+	}
+
+	if exists, _ := m.Exists(coldKey); exists {
+		t.Errorf("expected least frequently used key to be evicted")
+	}
+
+	if exists, _ := m.Exists(hotKey); !exists {
+		t.Errorf("expected frequently used key to survive eviction")
+	}
+}
+
+func TestEvictSample_LFUEvictsKeysNoLongerInUse(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	oldKey, recentKey := sameShardKeys(m)
+	m.Set(oldKey, "value", 0)
+	m.Set(recentKey, "value", 0)
+
+	// the old key was hot an hour ago, and has not been used since
+	record, _ := m.Get(oldKey)
+	anHourAgo := utils.GetCurrentEPochTime() - 3600
+	for i := 0; i < 10000; i++ {
+		record.Touch(anHourAgo)
+	}
+
+	for i := 0; i < 10; i++ {
+		m.Get(recentKey)
+	}
+
+	if frequency := record.GetFrequency(); frequency != 0 {
+		t.Errorf("expected the frequency of the idle key to decay, got %d", frequency)
+	}
+
+	if evicted := EvictSample(m, config.EvictionPolicyLFU, 1); evicted != 1 {
+		t.Fatalf("expected 1 key to be evicted, got %d", evicted)
+	}
+
+	if exists, _ := m.Exists(oldKey); exists {
+		t.Errorf("expected the key hot an hour ago to be evicted")
+	}
+
+	if exists, _ := m.Exists(recentKey); !exists {
+		t.Errorf("expected the key in use to survive eviction")
+	}
+}
+
+func TestRecordFrequencyGrowsLogarithmically(t *testing.T) {
+	record := entity.NewRecord("value", 0, 0, entity.RecordStateActive)
+
+	now := utils.GetCurrentEPochTime()
+	for i := 0; i < 1000; i++ {
+		record.Touch(now)
+	}
+
+	// about 5 + sqrt(2 * 1000 / 10), far below the number of accesses
+	if frequency := record.GetFrequency(); frequency < 10 || frequency > 40 {
+		t.Errorf("expected the frequency to grow logarithmically, got %d after 1000 accesses", frequency)
+	}
+}
+
+func TestEvictSample_VolatileOnlyEvictsKeysWithExpiry(t *testing.T) {
+	SetUpMemstoreTests()
+
+	for _, policy := range []string{config.EvictionPolicyVolatileLRU, config.EvictionPolicyVolatileTTL} {
+		m := CreateNewMemoryStore()
+
+		for i := 0; i < 50; i++ {
+			m.Set(fmt.Sprintf("persistent-%d", i), i, 0)
+			m.Set(fmt.Sprintf("volatile-%d", i), i, int64(100+i))
+		}
+
+		evicted := EvictSample(m, policy, 1000)
+		if evicted != 50 {
+			t.Errorf("[%s] expected all 50 volatile keys to be evicted, got %d", policy, evicted)
+		}
+
+		for i := 0; i < 50; i++ {
+			if exists, _ := m.Exists(fmt.Sprintf("persistent-%d", i)); !exists {
+				t.Errorf("[%s] expected persistent key %d to survive eviction", policy, i)
+			}
+		}
+	}
+}
+
+func TestEvictSample_VolatileTTLPrefersNearestExpiry(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	soonKey, laterKey := sameShardKeys(m)
+	m.Set(soonKey, "value", 10)
+	m.Set(laterKey, "value", 1000)
+
+	if evicted := EvictSample(m, config.EvictionPolicyVolatileTTL, 1); evicted != 1 {
+		t.Fatalf("expected 1 key to be evicted, got %d", evicted)
+	}
+
+	if exists, _ := m.Exists(soonKey); exists {
+		t.Errorf("expected key with nearest expiry to be evicted")
+	}
+}
+
+func TestEvictSample_Random(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	for i := 0; i < 100; i++ {
+		m.Set(fmt.Sprintf("key-%d", i), i, 0)
+	}
+
+	if evicted := EvictSample(m, config.EvictionPolicyRandom, 30); evicted != 30 {
+		t.Errorf("expected 30 keys to be evicted, got %d", evicted)
+	}
+}
+
+func TestMemstore_WritesRejected(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.SetWritesRejected(true)
+
+	if success, code := m.Set("key", "value", 0); success || code != entity.CRC_MEMORY_LIMIT_EXCEEDED {
+		t.Errorf("expected set to be rejected, got %v, %d", success, code)
+	}
+
+	result, code := m.MSet(map[string]interface{}{"key1": "value1"})
+	if code != entity.CRC_MEMORY_LIMIT_EXCEEDED || result["key1"] != false {
+		t.Errorf("expected mset to be rejected, got %v, %d", result, code)
+	}
+
+	m.SetWritesRejected(false)
+
+	if success, code := m.Set("key", "value", 0); !success || code != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected set to succeed after rejection is lifted, got %v, %d", success, code)
+	}
+}
+
+// sameShardKeys returns two distinct keys which hash into the same shard,
+// so that both of them are always part of the same eviction sample.
+func sameShardKeys(m *MemoryStore) (string, string) {
+	first := "first"
+	for i := 0; ; i++ {
+		candidate := fmt.Sprintf("second-%d", i)
+		if m.getShardByKey(candidate) == m.getShardByKey(first) {
+			return first, candidate
+		}
+	}
+}
+
+// shardKeys returns count distinct keys which hash into the same shard.
+func shardKeys(m *MemoryStore, count int) []string {
+	shard := m.getShardByKey("key-0")
+	keys := make([]string, 0, count)
+
+	for i := 0; len(keys) < count; i++ {
+		if key := fmt.Sprintf("key-%d", i); m.getShardByKey(key) == shard {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func TestEvictSample_RandomSpreadsAcrossKeyspace(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	keys := shardKeys(m, 20)
+	for _, key := range keys {
+		m.Set(key, "value", 0)
+	}
+
+	// the keys are all in one shard, and the evicted key is put back, so that every
+	// eviction walks the same keys in the same order
+	evictedKeys := make(map[string]bool)
+	for trial := 0; trial < 100; trial++ {
+		if evicted := EvictSample(m, config.EvictionPolicyRandom, 1); evicted != 1 {
+			t.Fatalf("expected 1 key to be evicted, got %d", evicted)
+		}
+
+		for _, key := range keys {
+			if exists, _ := m.Exists(key); !exists {
+				evictedKeys[key] = true
+				m.Set(key, "value", 0)
+			}
+		}
+	}
+
+	if len(evictedKeys) < len(keys)/2 {
+		t.Errorf("expected the evictions to spread across the %d keys, only %d of them were evicted", len(keys), len(evictedKeys))
+	}
+}
+
+func TestEvictSample_LFUFindsColdKeysAnywhereInShard(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	keys := shardKeys(m, 4*evictionSampleSize)
+	for _, key := range keys {
+		m.Set(key, "value", 0)
+		for i := 0; i < 5; i++ {
+			m.Get(key)
+		}
+	}
+
+	// the cold key is the last one the shard walks, past any fixed sample
+	var coldKey string
+	m.getShardByKey(keys[0]).GetData().Range(func(key, _ interface{}) bool {
+		coldKey = key.(string)
+		return true
+	})
+
+	record, _ := m.Get(coldKey)
+	record.SetFrequency(0)
+
+	for evictions := 0; ; evictions++ {
+		if exists, _ := m.Exists(coldKey); !exists {
+			break
+		}
+
+		if evictions == len(keys)/2 {
+			t.Fatalf("expected the cold key to be evicted early, %d hot keys were evicted before it", evictions)
+		}
+
+		if evicted := EvictSample(m, config.EvictionPolicyLFU, 1); evicted != 1 {
+			t.Fatalf("expected 1 key to be evicted, got %d", evicted)
+		}
+	}
+}
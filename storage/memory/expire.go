@@ -4,7 +4,6 @@ import (
 	"time"
 	"universum/entity"
 	"universum/internal/logger"
-	"universum/storage"
 	"universum/utils"
 
 	"golang.org/x/exp/rand"
@@ -12,7 +11,7 @@ import (
 
 const maxRecordDeletionLocalLimit int64 = 1000
 
-func ExpireRandomSample(store storage.DataStore, shards [ShardCount]*Shard) int64 {
+func ExpireRandomSample(store *MemoryStore, shards [ShardCount]*Shard) int64 {
 	var deletedCount int64 = 0
 
 	randomGenerator := rand.New(rand.NewSource(uint64(time.Now().UnixNano())))
@@ -20,12 +19,18 @@ func ExpireRandomSample(store storage.DataStore, shards [ShardCount]*Shard) int6
 	randomShard := shards[randomIndex]
 
 	randomShard.GetData().Range(func(key interface{}, value interface{}) bool {
-		record := value.(*entity.ScalarRecord)
+		record := value.(entity.Record)
 
-		if record.Expiry < utils.GetCurrentEPochTime() {
+		if record.GetExpiry() < utils.GetCurrentEPochTime() {
 			strkey, _ := key.(string)
 
-			if deleted, _ := store.Delete(strkey); deleted {
+			// removed only if not overwritten since, which the write lock
+			// of the shard keeps from happening in the middle of it.
+			randomShard.writeLock.Lock()
+			expired := store.expire(randomShard, strkey, value)
+			randomShard.writeLock.Unlock()
+
+			if expired {
 				deletedCount++
 			}
 		}
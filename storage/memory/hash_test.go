@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+	"universum/config"
+	"universum/entity"
+)
+
+func TestMemstore_HashSetAndGet(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	added, code := m.HSet("user:1", map[string]interface{}{"name": "alice", "age": int64(30)})
+	if added != 2 || code != entity.CRC_RECORD_UPDATED {
+		t.Fatalf("expected 2 fields to be added, got %d, %d", added, code)
+	}
+
+	added, _ = m.HSet("user:1", map[string]interface{}{"name": "bob", "city": "paris"})
+	if added != 1 {
+		t.Errorf("expected only the new field to be counted, got %d", added)
+	}
+
+	value, code := m.HGet("user:1", "name")
+	if value != "bob" || code != entity.CRC_RECORD_FOUND {
+		t.Errorf("expected updated field value, got %v, %d", value, code)
+	}
+
+	if _, code := m.HGet("user:1", "missing"); code != entity.CRC_FIELD_NOT_FOUND {
+		t.Errorf("expected field not found, got %d", code)
+	}
+
+	if _, code := m.HGet("user:2", "name"); code != entity.CRC_RECORD_NOT_FOUND {
+		t.Errorf("expected record not found, got %d", code)
+	}
+
+	values, _ := m.HMGet("user:1", []string{"name", "missing"})
+	if values["name"] != "bob" || values["missing"] != nil {
+		t.Errorf("unexpected HMGET response %v", values)
+	}
+
+	if exists, _ := m.HExists("user:1", "city"); !exists {
+		t.Errorf("expected field city to exist")
+	}
+
+	if length, _ := m.HLen("user:1"); length != 3 {
+		t.Errorf("expected 3 fields, got %d", length)
+	}
+
+	if fields, _ := m.HKeys("user:1"); !reflect.DeepEqual(fields, []string{"age", "city", "name"}) {
+		t.Errorf("unexpected HKEYS response %v", fields)
+	}
+
+	all, _ := m.HGetAll("user:1")
+	if len(all) != 3 || all["age"] != int64(30) {
+		t.Errorf("unexpected HGETALL response %v", all)
+	}
+
+	record, _ := m.Get("user:1")
+	if record.GetFamily() != entity.RecordTypeHash {
+		t.Errorf("expected hash record family, got %s", record.GetFamily())
+	}
+}
+
+func TestMemstore_HashDelete(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.HSet("hash", map[string]interface{}{"a": 1, "b": 2})
+
+	removed, code := m.HDel("hash", []string{"a", "missing"})
+	if removed != 1 || code != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected 1 field to be removed, got %d, %d", removed, code)
+	}
+
+	m.HDel("hash", []string{"b"})
+	if exists, _ := m.Exists("hash"); exists {
+		t.Errorf("expected hash to be removed along with its last field")
+	}
+
+	if _, code := m.HDel("hash", []string{"b"}); code != entity.CRC_RECORD_NOT_FOUND {
+		t.Errorf("expected record not found, got %d", code)
+	}
+}
+
+func TestMemstore_HashIncrBy(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	value, code := m.HIncrBy("counters", "hits", 5)
+	if value != 5 || code != entity.CRC_RECORD_UPDATED {
+		t.Errorf("expected 5, got %d, %d", value, code)
+	}
+
+	value, _ = m.HIncrBy("counters", "hits", -2)
+	if value != 3 {
+		t.Errorf("expected 3, got %d", value)
+	}
+
+	m.HSet("counters", map[string]interface{}{"name": "text", "empty": nil, "small": int32(1)})
+	for _, field := range []string{"name", "empty", "small"} {
+		if _, code := m.HIncrBy("counters", field, 1); code != entity.CRC_INCR_INVALID_TYPE {
+			t.Errorf("expected invalid type for field %s, got %d", field, code)
+		}
+	}
+}
+
+func TestMemstore_HashKeepsExpiry(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.HSet("hash", map[string]interface{}{"a": 1})
+	m.Expire("hash", 100)
+	m.HSet("hash", map[string]interface{}{"b": 2})
+
+	if ttl, _ := m.TTL("hash"); ttl <= 0 || ttl > 100 {
+		t.Errorf("expected expiry to be kept across updates, got ttl %d", ttl)
+	}
+
+	record, _ := m.Get("hash")
+	if _, ok := record.(*entity.HashRecord); !ok {
+		t.Errorf("expected hash record to survive EXPIRE, got %T", record)
+	}
+}
+
+func TestMemstore_HashWrongType(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.Set("scalar", int64(1), 0)
+	m.HSet("hash", map[string]interface{}{"a": 1})
+
+	if _, code := m.HSet("scalar", map[string]interface{}{"a": 1}); code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type for HSET on scalar, got %d", code)
+	}
+
+	if _, code := m.HGet("scalar", "a"); code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type for HGET on scalar, got %d", code)
+	}
+
+	if _, code := m.IncrDecrInteger("hash", 1, true); code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type for INCR on hash, got %d", code)
+	}
+
+	if _, code := m.Append("hash", "a"); code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type for APPEND on hash, got %d", code)
+	}
+
+	if _, code := m.HSet("hash", map[string]interface{}{"nested": map[string]interface{}{}}); code != entity.CRC_INVALID_DATATYPE {
+		t.Errorf("expected invalid datatype for nested map, got %d", code)
+	}
+}
+
+func TestMemstore_HashSnapshotRestore(t *testing.T) {
+	SetUpMemstoreTests()
+	config.Store.Storage.Memory.SnapshotFileDirectory = t.TempDir()
+	config.Store.Storage.Memory.SnapshotCompressionAlgo = config.CompressionAlgoLZ4
+
+	m := CreateNewMemoryStore()
+	m.Set("scalar", "value", 0)
+	m.HSet("hash", map[string]interface{}{"name": "alice", "age": int64(30)})
+
+	snapshotService := &MemoryStoreSnapshotService{}
+	if _, _, err := snapshotService.Snapshot(m); err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+
+	restored := CreateNewMemoryStore()
+	keycount, err := snapshotService.Restore(restored)
+	if err != nil || keycount != 2 {
+		t.Fatalf("expected 2 keys to be restored, got %d, %v", keycount, err)
+	}
+
+	value, code := restored.HGet("hash", "age")
+	if value != int64(30) || code != entity.CRC_RECORD_FOUND {
+		t.Errorf("expected restored hash field, got %v, %d", value, code)
+	}
+
+	record, _ := restored.Get("scalar")
+	if record == nil || record.GetValue() != "value" {
+		t.Errorf("expected restored scalar record, got %v", record)
+	}
+}
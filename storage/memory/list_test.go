@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+	"universum/config"
+	"universum/entity"
+)
+
+func TestMemstore_ListPushAndPop(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	length, code := m.RPush("queue", []interface{}{"b", "c"})
+	if length != 2 || code != entity.CRC_RECORD_UPDATED {
+		t.Fatalf("expected list of 2 elements, got %d, %d", length, code)
+	}
+
+	length, _ = m.LPush("queue", []interface{}{"a", "z"})
+	if length != 4 {
+		t.Errorf("expected list of 4 elements, got %d", length)
+	}
+
+	values, _ := m.LRange("queue", 0, -1)
+	if !reflect.DeepEqual(values, []interface{}{"z", "a", "b", "c"}) {
+		t.Errorf("unexpected list contents %v", values)
+	}
+
+	if value, code := m.LPop("queue"); value != "z" || code != entity.CRC_RECORD_FOUND {
+		t.Errorf("expected z from head, got %v, %d", value, code)
+	}
+
+	if value, _ := m.RPop("queue"); value != "c" {
+		t.Errorf("expected c from tail, got %v", value)
+	}
+
+	m.LPop("queue")
+	m.LPop("queue")
+
+	if exists, _ := m.Exists("queue"); exists {
+		t.Errorf("expected the emptied list to be removed")
+	}
+
+	if _, code := m.LPop("queue"); code != entity.CRC_RECORD_NOT_FOUND {
+		t.Errorf("expected record not found on empty list, got %d", code)
+	}
+}
+
+func TestMemstore_ListRangeIndexAndTrim(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.RPush("list", []interface{}{int64(0), int64(1), int64(2), int64(3), int64(4)})
+
+	tests := []struct {
+		start, stop int64
+		expected    []interface{}
+	}{
+		{1, 2, []interface{}{int64(1), int64(2)}},
+		{-2, -1, []interface{}{int64(3), int64(4)}},
+		{3, 100, []interface{}{int64(3), int64(4)}},
+		{-100, 0, []interface{}{int64(0)}},
+		{4, 1, []interface{}{}},
+		{10, 20, []interface{}{}},
+	}
+
+	for _, test := range tests {
+		values, _ := m.LRange("list", test.start, test.stop)
+		if !reflect.DeepEqual(values, test.expected) {
+			t.Errorf("LRANGE %d %d: expected %v, got %v", test.start, test.stop, test.expected, values)
+		}
+	}
+
+	if value, _ := m.LIndex("list", -1); value != int64(4) {
+		t.Errorf("expected last element, got %v", value)
+	}
+
+	if _, code := m.LIndex("list", 5); code != entity.CRC_INDEX_OUT_OF_RANGE {
+		t.Errorf("expected index out of range, got %d", code)
+	}
+
+	if trimmed, _ := m.LTrim("list", 1, -2); !trimmed {
+		t.Errorf("expected list to be trimmed")
+	}
+
+	if length, _ := m.LLen("list"); length != 3 {
+		t.Errorf("expected 3 elements after trim, got %d", length)
+	}
+
+	m.LTrim("list", 5, 10)
+	if exists, _ := m.Exists("list"); exists {
+		t.Errorf("expected list trimmed to nothing to be removed")
+	}
+}
+
+func TestMemstore_ListWrongType(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.HSet("hash", map[string]interface{}{"a": 1})
+
+	if _, code := m.LPush("hash", []interface{}{"a"}); code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type for LPUSH on hash, got %d", code)
+	}
+
+	if _, code := m.LLen("hash"); code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type for LLEN on hash, got %d", code)
+	}
+}
+
+func TestMemstore_ListSnapshotRestore(t *testing.T) {
+	SetUpMemstoreTests()
+	config.Store.Storage.Memory.SnapshotFileDirectory = t.TempDir()
+	config.Store.Storage.Memory.SnapshotCompressionAlgo = config.CompressionAlgoLZ4
+
+	m := CreateNewMemoryStore()
+	m.RPush("queue", []interface{}{"a", int64(2), "c"})
+
+	snapshotService := &MemoryStoreSnapshotService{}
+	if _, _, err := snapshotService.Snapshot(m); err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+
+	restored := CreateNewMemoryStore()
+	if _, err := snapshotService.Restore(restored); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	values, code := restored.LRange("queue", 0, -1)
+	if code != entity.CRC_RECORD_FOUND || !reflect.DeepEqual(values, []interface{}{"a", int64(2), "c"}) {
+		t.Errorf("unexpected restored list %v, %d", values, code)
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"hash/fnv"
 	"os"
 	"sync"
+	"sync/atomic"
 	"universum/config"
 	"universum/entity"
+	"universum/internal/logger"
+	"universum/storage"
 	"universum/utils"
 )
 
@@ -15,7 +18,14 @@ const (
 )
 
 type MemoryStore struct {
+	storage.Collections // the hash, list, set and sorted set commands
+
 	shards [ShardCount]*Shard
+
+	// writesRejected is set by the eviction worker under NOEVICTION_REJECT_WRITES
+	// policy when the memory limit is exceeded, making all writes fail until
+	// the usage goes back under the limit.
+	writesRejected int32
 }
 
 func CreateNewMemoryStore() *MemoryStore {
@@ -33,6 +43,7 @@ func CreateNewMemoryStore() *MemoryStore {
 		}
 	}
 
+	store.Collections = storage.NewCollections(store)
 	return store
 }
 
@@ -55,6 +66,18 @@ func (ms *MemoryStore) GetAllShards() [ShardCount]*Shard {
 	return ms.shards
 }
 
+func (ms *MemoryStore) SetWritesRejected(rejected bool) {
+	if rejected {
+		atomic.StoreInt32(&ms.writesRejected, 1)
+	} else {
+		atomic.StoreInt32(&ms.writesRejected, 0)
+	}
+}
+
+func (ms *MemoryStore) IsWritesRejected() bool {
+	return atomic.LoadInt32(&ms.writesRejected) == 1
+}
+
 func (ms *MemoryStore) Exists(key string) (bool, uint32) {
 	shard := ms.getShardByKey(key)
 	val, ok := shard.data.Load(key)
@@ -63,9 +86,9 @@ func (ms *MemoryStore) Exists(key string) (bool, uint32) {
 		return false, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
+	record := val.(entity.Record)
 	if record.IsExpired() {
-		shard.data.Delete(key)
+		ms.expire(shard, key, val)
 		return false, entity.CRC_RECORD_EXPIRED
 	}
 
@@ -80,22 +103,29 @@ func (ms *MemoryStore) Get(key string) (entity.Record, uint32) {
 		return nil, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
+	record := val.(entity.Record)
 	if record.IsExpired() {
-		shard.data.Delete(key)
+		ms.expire(shard, key, val)
 		return nil, entity.CRC_RECORD_EXPIRED
 	}
 
-	record.LAT = utils.GetCurrentEPochTime()
+	record.Touch(utils.GetCurrentEPochTime())
 	return record, entity.CRC_RECORD_FOUND
 }
 
 func (ms *MemoryStore) Set(key string, value interface{}, ttl int64) (bool, uint32) {
-	record := &entity.ScalarRecord{
-		Value:  value,
-		LAT:    utils.GetCurrentEPochTime(),
-		Expiry: config.InfiniteExpiryTime,
-		State:  entity.RecordStateActive,
+	shard := ms.getShardByKey(key)
+	shard.writeLock.Lock()
+	defer shard.writeLock.Unlock()
+
+	return ms.set(shard, key, value, ttl)
+}
+
+// set validates and stores the value against the key, in the shard it belongs
+// to. The caller must hold the write lock of the shard.
+func (ms *MemoryStore) set(shard *Shard, key string, value interface{}, ttl int64) (bool, uint32) {
+	if ms.IsWritesRejected() {
+		return false, entity.CRC_MEMORY_LIMIT_EXCEEDED
 	}
 
 	if !utils.IsWriteableDatatype(value) {
@@ -106,29 +136,76 @@ func (ms *MemoryStore) Set(key string, value interface{}, ttl int64) (bool, uint
 		return false, entity.CRC_RECORD_TOO_BIG
 	}
 
+	expiry := config.InfiniteExpiryTime
 	if ttl > 0 {
-		record.Expiry = utils.GetCurrentEPochTime() + ttl
+		expiry = utils.GetCurrentEPochTime() + ttl
+	}
+
+	record := entity.NewRecord(value, utils.GetCurrentEPochTime(), expiry, entity.RecordStateActive)
+
+	// carry over the access frequency, so that overwriting a hot
+	// key does not make it a preferred LFU eviction candidate.
+	if prev, ok := shard.data.Load(key); ok {
+		record.SetFrequency(prev.(entity.Record).GetFrequency())
 	}
 
-	shard := ms.getShardByKey(key)
 	shard.data.Store(key, record)
+	storage.NotifyKeyspaceEvent(storage.KeyspaceEventSet, key)
+
 	return true, entity.CRC_RECORD_UPDATED
 }
 
 func (ms *MemoryStore) Delete(key string) (bool, uint32) {
 	shard := ms.getShardByKey(key)
-	shard.data.Delete(key)
+	shard.writeLock.Lock()
+	defer shard.writeLock.Unlock()
+
+	ms.delete(shard, key)
 	return true, entity.CRC_RECORD_DELETED
 }
 
+// delete removes the key from the shard it belongs to, and tells whether it
+// was there. The caller must hold the write lock of the shard.
+func (ms *MemoryStore) delete(shard *Shard, key string) bool {
+	if _, deleted := shard.data.LoadAndDelete(key); !deleted {
+		return false
+	}
+
+	storage.NotifyKeyspaceEvent(storage.KeyspaceEventDel, key)
+	return true
+}
+
+// expire removes the expired record from the shard it belongs to, unless some
+// other record was stored against the key in the meantime, and tells whether
+// it was removed.
+func (ms *MemoryStore) expire(shard *Shard, key string, record interface{}) bool {
+	if !shard.data.CompareAndDelete(key, record) {
+		return false
+	}
+
+	storage.NotifyKeyspaceEvent(storage.KeyspaceEventExpired, key)
+	return true
+}
+
+// IncrDecrInteger adds the offset to the integer stored against the key, or
+// subtracts it. The read and the write are made under the shard write lock, so
+// that concurrent increments of the key are never lost.
 func (ms *MemoryStore) IncrDecrInteger(key string, offset int64, isIncr bool) (int64, uint32) {
+	shard := ms.getShardByKey(key)
+	shard.writeLock.Lock()
+	defer shard.writeLock.Unlock()
+
 	val, code := ms.Get(key)
 
 	if code != entity.CRC_RECORD_FOUND {
 		return config.InvalidNumericValue, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
+	record, ok := val.(*entity.ScalarRecord)
+	if !ok {
+		return config.InvalidNumericValue, entity.CRC_WRONG_TYPE
+	}
+
 	if !utils.IsInteger(record.Value) {
 		return config.InvalidNumericValue, entity.CRC_INCR_INVALID_TYPE
 	}
@@ -143,7 +220,7 @@ func (ms *MemoryStore) IncrDecrInteger(key string, offset int64, isIncr bool) (i
 	}
 
 	ttl := record.Expiry - utils.GetCurrentEPochTime()
-	didSet, setcode := ms.Set(key, newValue, ttl)
+	didSet, setcode := ms.set(shard, key, newValue, ttl)
 
 	if !didSet {
 		return config.InvalidNumericValue, setcode
@@ -152,14 +229,24 @@ func (ms *MemoryStore) IncrDecrInteger(key string, offset int64, isIncr bool) (i
 	return newValue, entity.CRC_RECORD_UPDATED
 }
 
+// Append appends the value to the string stored against the key, under the
+// shard write lock as IncrDecrInteger does, and returns the new length.
 func (ms *MemoryStore) Append(key string, value string) (int64, uint32) {
+	shard := ms.getShardByKey(key)
+	shard.writeLock.Lock()
+	defer shard.writeLock.Unlock()
+
 	val, code := ms.Get(key)
 
 	if code != entity.CRC_RECORD_FOUND {
 		return config.InvalidNumericValue, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
+	record, ok := val.(*entity.ScalarRecord)
+	if !ok {
+		return config.InvalidNumericValue, entity.CRC_WRONG_TYPE
+	}
+
 	if !utils.IsString(record.Value) {
 		return config.InvalidNumericValue, entity.CRC_INCR_INVALID_TYPE
 	}
@@ -167,7 +254,7 @@ func (ms *MemoryStore) Append(key string, value string) (int64, uint32) {
 	newValue := record.Value.(string) + value
 	ttl := record.Expiry - utils.GetCurrentEPochTime()
 
-	didSet, setcode := ms.Set(key, newValue, ttl)
+	didSet, setcode := ms.set(shard, key, newValue, ttl)
 	if !didSet {
 		return config.InvalidNumericValue, setcode
 	}
@@ -181,7 +268,7 @@ func (ms *MemoryStore) MGet(keys []string) (map[string]interface{}, uint32) {
 	for idx := range keys {
 		record, code := ms.Get(keys[idx])
 
-		if _, ok := record.(*entity.ScalarRecord); ok {
+		if code == entity.CRC_RECORD_FOUND {
 			responseMap[keys[idx]] = map[string]interface{}{
 				"Value": record.GetValue(),
 				"Code":  code,
@@ -200,10 +287,17 @@ func (ms *MemoryStore) MGet(keys []string) (map[string]interface{}, uint32) {
 func (ms *MemoryStore) MSet(kvMap map[string]interface{}) (map[string]interface{}, uint32) {
 	responseMap := make(map[string]interface{})
 
+	if ms.IsWritesRejected() {
+		for key := range kvMap {
+			responseMap[key] = false
+		}
+		return responseMap, entity.CRC_MEMORY_LIMIT_EXCEEDED
+	}
+
 	for key, value := range kvMap {
 		didSet, code := ms.Set(key, value, 0)
 		if code == entity.CRC_RECORD_TOO_BIG || code == entity.CRC_INVALID_DATATYPE {
-			fmt.Printf("Key %s failed due to %d\n", key, code)
+			logger.Get().Debug("MSet:: Key %s failed due to %d", key, code)
 		}
 		responseMap[key] = didSet
 	}
@@ -229,21 +323,25 @@ func (ms *MemoryStore) TTL(key string) (int64, uint32) {
 		return 0, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
-
-	ttl := record.Expiry - utils.GetCurrentEPochTime()
+	ttl := val.GetExpiry() - utils.GetCurrentEPochTime()
 	return ttl, entity.CRC_RECORD_FOUND
 }
 
+// Expire stores the value of the key again with the new ttl. The read and the
+// write are made under the shard write lock, for a concurrent write of the key
+// not to be overwritten with the value read before it.
 func (ms *MemoryStore) Expire(key string, ttl int64) (bool, uint32) {
+	shard := ms.getShardByKey(key)
+	shard.writeLock.Lock()
+	defer shard.writeLock.Unlock()
+
 	val, code := ms.Get(key)
 
 	if code != entity.CRC_RECORD_FOUND {
 		return false, entity.CRC_RECORD_NOT_FOUND
 	}
 
-	record := val.(*entity.ScalarRecord)
-	return ms.Set(key, record.Value, ttl)
+	return ms.set(shard, key, val.GetValue(), ttl)
 }
 
 func (ms *MemoryStore) getShardByKey(key string) *Shard {
@@ -1,12 +1,32 @@
 package memory
 
 import (
+	"os"
+	"sync"
 	"testing"
 	"time"
 	"universum/config"
 	"universum/entity"
+	"universum/internal/logger"
 )
 
+// TestMain points the logger at a temporary directory, for the tests not to leave
+// a log file behind in the package directory.
+func TestMain(m *testing.M) {
+	logdir, err := os.MkdirTemp("", "universum-test-logs")
+	if err != nil {
+		panic(err)
+	}
+
+	config.Store = config.GetSkeleton()
+	config.Store.Logging.LogFileDirectory = logdir
+	logger.Get()
+
+	code := m.Run()
+	os.RemoveAll(logdir)
+	os.Exit(code)
+}
+
 func SetUpMemstoreTests() {
 	config.Store = config.GetSkeleton()
 	config.Store.Storage.StorageEngine = config.StorageEngineMemory
@@ -172,6 +192,35 @@ func TestMemstore_Append(t *testing.T) {
 	}
 }
 
+func TestMemstore_ConcurrentIncrDecrAndAppend(t *testing.T) {
+	SetUpMemstoreTests()
+
+	m := CreateNewMemoryStore()
+	m.Set("counter", int64(0), 0)
+	m.Set("text", "", 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.IncrDecrInteger("counter", 1, true)
+				m.Append("text", "x")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if record, _ := m.Get("counter"); record.GetValue() != int64(800) {
+		t.Errorf("expected 800 increments, got %v", record.GetValue())
+	}
+
+	if record, _ := m.Get("text"); len(record.GetValue().(string)) != 800 {
+		t.Errorf("expected 800 appends, got %d", len(record.GetValue().(string)))
+	}
+}
+
 func TestMemstore_MSet(t *testing.T) {
 	SetUpMemstoreTests()
 	m := CreateNewMemoryStore()
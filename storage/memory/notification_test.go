@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"testing"
+	"universum/config"
+	"universum/entity"
+	"universum/storage"
+)
+
+type keyspaceEventRecorder struct {
+	events []string
+}
+
+func recordKeyspaceEvents(t *testing.T) *keyspaceEventRecorder {
+	recorder := &keyspaceEventRecorder{}
+	storage.SetKeyspaceListener(func(event storage.KeyspaceEvent, key string) {
+		recorder.events = append(recorder.events, string(event)+":"+key)
+	})
+
+	t.Cleanup(func() { storage.SetKeyspaceListener(nil) })
+	return recorder
+}
+
+func (r *keyspaceEventRecorder) expect(t *testing.T, expected ...string) {
+	t.Helper()
+
+	if len(r.events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, r.events)
+	}
+
+	for idx := range expected {
+		if r.events[idx] != expected[idx] {
+			t.Fatalf("expected events %v, got %v", expected, r.events)
+		}
+	}
+
+	r.events = nil
+}
+
+func expireKey(m *MemoryStore, key string) {
+	record, _ := m.Get(key)
+	record.(*entity.ScalarRecord).Expiry = 1
+}
+
+func TestKeyspaceEvents_SetAndDelete(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+	recorder := recordKeyspaceEvents(t)
+
+	m.Set("key", "value", 0)
+	recorder.expect(t, "set:key")
+
+	m.Delete("key")
+	recorder.expect(t, "del:key")
+
+	// deleting a missing key is not an event
+	m.Delete("key")
+	recorder.expect(t)
+}
+
+func TestKeyspaceEvents_LazyExpiry(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.Set("read", "value", 0)
+	m.Set("checked", "value", 0)
+	expireKey(m, "read")
+	expireKey(m, "checked")
+
+	recorder := recordKeyspaceEvents(t)
+
+	if _, code := m.Get("read"); code != entity.CRC_RECORD_EXPIRED {
+		t.Fatalf("expected expired record, got %d", code)
+	}
+	recorder.expect(t, "expired:read")
+
+	if _, code := m.Exists("checked"); code != entity.CRC_RECORD_EXPIRED {
+		t.Fatalf("expected expired record, got %d", code)
+	}
+	recorder.expect(t, "expired:checked")
+}
+
+func TestKeyspaceEvents_ActiveExpiryAndEviction(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.Set("expiring", "value", 0)
+	expireKey(m, "expiring")
+
+	recorder := recordKeyspaceEvents(t)
+
+	for attempts := 0; len(recorder.events) == 0 && attempts < 10000; attempts++ {
+		ExpireRandomSample(m, m.GetAllShards())
+	}
+	recorder.expect(t, "expired:expiring")
+
+	m.Set("evictable", "value", 0)
+	recorder.expect(t, "set:evictable")
+
+	if evicted := EvictSample(m, config.EvictionPolicyRandom, 1); evicted != 1 {
+		t.Fatalf("expected 1 key to be evicted, got %d", evicted)
+	}
+	recorder.expect(t, "evicted:evictable")
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"universum/entity"
+	"universum/storage"
+)
+
+// Range returns the live records with keys between start and end, both inclusive,
+// in ascending key order, or descending when reverse is set. At most limit records
+// are returned. The memory engine keeps no global key order, so all the shards are
+// walked and the keys in range sorted.
+func (ms *MemoryStore) Range(start string, end string, limit int64, reverse bool) ([]*entity.RecordKV, uint32) {
+	return ms.collectRange(limit, reverse, func(key string) bool {
+		return start <= key && key <= end
+	})
+}
+
+// Prefix returns the live records with keys starting with the prefix, in
+// ascending key order. At most limit records are returned.
+func (ms *MemoryStore) Prefix(prefix string, limit int64) ([]*entity.RecordKV, uint32) {
+	return ms.collectRange(limit, false, func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// collectRange gathers the live records of all the shards with keys in range,
+// and orders them by key.
+func (ms *MemoryStore) collectRange(limit int64, reverse bool, inRange func(key string) bool) ([]*entity.RecordKV, uint32) {
+	matched := make([]*entity.RecordKV, 0)
+
+	for _, shard := range ms.shards {
+		shard.data.Range(func(k, v interface{}) bool {
+			key, record := k.(string), v.(entity.Record)
+
+			if inRange(key) && !record.IsExpired() {
+				matched = append(matched, &entity.RecordKV{Key: key, Record: record})
+			}
+			return true
+		})
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Key < matched[j].Key
+	})
+
+	collector := storage.NewRangeCollector(limit, reverse)
+	for _, recordKV := range matched {
+		if !collector.Add(recordKV) {
+			break
+		}
+	}
+
+	return collector.Records(), entity.CRC_RANGE_COMPLETED
+}
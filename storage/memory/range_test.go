@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+	"universum/entity"
+)
+
+func TestMemstore_RangeAndPrefix(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	for _, key := range []string{"a:1", "a:2", "a:3", "b:1", "c:1"} {
+		m.Set(key, key, 0)
+	}
+
+	keysOf := func(records []*entity.RecordKV) []string {
+		keys := make([]string, len(records))
+		for idx, recordKV := range records {
+			keys[idx] = recordKV.Key
+		}
+		return keys
+	}
+
+	records, code := m.Range("a:2", "b:1", 10, false)
+	if code != entity.CRC_RANGE_COMPLETED || !reflect.DeepEqual(keysOf(records), []string{"a:2", "a:3", "b:1"}) {
+		t.Errorf("unexpected range %v, %d", keysOf(records), code)
+	}
+
+	records, _ = m.Range("a", "z", 2, true)
+	if !reflect.DeepEqual(keysOf(records), []string{"c:1", "b:1"}) {
+		t.Errorf("unexpected reverse range %v", keysOf(records))
+	}
+
+	records, _ = m.Prefix("a:", 2)
+	if !reflect.DeepEqual(keysOf(records), []string{"a:1", "a:2"}) {
+		t.Errorf("unexpected prefix %v", keysOf(records))
+	}
+}
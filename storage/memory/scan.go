@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"sort"
+	"universum/entity"
+	"universum/storage"
+)
+
+// Scan iterates over the keyspace shard by shard, examining up to options.Count
+// keys per call. Within a shard the keys are visited in sorted order, so the
+// cursor only needs the shard index and the last examined key to resume, and
+// every key which lives through the whole iteration is returned exactly once.
+// The shards are read without taking any lock, so writers are never blocked.
+func (ms *MemoryStore) Scan(cursor string, options storage.ScanOptions) (string, []string, uint32) {
+	shardIndex, lastKey, resume, err := storage.ParseScanCursor(cursor)
+	if err != nil || shardIndex >= int64(ShardCount) {
+		return storage.ScanCursorStart, []string{}, entity.CRC_INVALID_CMD_INPUT
+	}
+
+	keys := make([]string, 0)
+	var examined int64 = 0
+
+	for ; shardIndex < int64(ShardCount); shardIndex++ {
+		shard := ms.shards[shardIndex]
+
+		for _, key := range shard.sortedKeys(lastKey, resume) {
+			if val, ok := shard.data.Load(key); ok && options.Accepts(key, val.(entity.Record)) {
+				keys = append(keys, key)
+			}
+
+			examined++
+			if examined >= options.Count {
+				return storage.NewScanCursor(shardIndex, key), keys, entity.CRC_SCAN_COMPLETED
+			}
+		}
+
+		resume = false
+	}
+
+	return storage.ScanCursorStart, keys, entity.CRC_SCAN_COMPLETED
+}
+
+// sortedKeys returns the keys held by the shard in sorted order. When resuming
+// an iteration, only the keys after the given one are returned.
+func (s *Shard) sortedKeys(after string, resume bool) []string {
+	keys := make([]string, 0)
+
+	s.data.Range(func(k, _ interface{}) bool {
+		if key := k.(string); !resume || key > after {
+			keys = append(keys, key)
+		}
+		return true
+	})
+
+	sort.Strings(keys)
+	return keys
+}
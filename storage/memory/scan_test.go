@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"universum/entity"
+	"universum/storage"
+)
+
+// scanAll runs a complete SCAN iteration and returns all the keys found.
+func scanAll(t *testing.T, m *MemoryStore, options storage.ScanOptions) []string {
+	found := make([]string, 0)
+	cursor := storage.ScanCursorStart
+
+	for calls := 0; ; calls++ {
+		if calls > 1000 {
+			t.Fatalf("scan did not terminate")
+		}
+
+		next, keys, code := m.Scan(cursor, options)
+		if code != entity.CRC_SCAN_COMPLETED {
+			t.Fatalf("unexpected scan code %d", code)
+		}
+
+		found = append(found, keys...)
+		if next == storage.ScanCursorStart {
+			break
+		}
+		cursor = next
+	}
+
+	sort.Strings(found)
+	return found
+}
+
+func TestMemstore_ScanReturnsEveryKeyOnce(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	expected := make([]string, 0)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key:%03d", i)
+		m.Set(key, i, 0)
+		expected = append(expected, key)
+	}
+
+	found := scanAll(t, m, storage.ScanOptions{Count: 7})
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected all %d keys once, got %d keys", len(expected), len(found))
+	}
+}
+
+func TestMemstore_ScanFilters(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.Set("user:1", "a", 0)
+	m.Set("user:2", "b", 0)
+	m.Set("session:1", "c", 0)
+	m.HSet("user:3", map[string]interface{}{"name": "d"})
+
+	found := scanAll(t, m, storage.ScanOptions{Count: 10, Match: "user:*"})
+	if !reflect.DeepEqual(found, []string{"user:1", "user:2", "user:3"}) {
+		t.Errorf("unexpected keys for MATCH: %v", found)
+	}
+
+	found = scanAll(t, m, storage.ScanOptions{Count: 10, Family: entity.RecordTypeHash})
+	if !reflect.DeepEqual(found, []string{"user:3"}) {
+		t.Errorf("unexpected keys for TYPE: %v", found)
+	}
+}
+
+func TestMemstore_ScanInvalidCursor(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	for _, cursor := range []string{"garbage", "999:key", "-1:key"} {
+		if _, _, code := m.Scan(cursor, storage.ScanOptions{Count: 10}); code != entity.CRC_INVALID_CMD_INPUT {
+			t.Errorf("expected invalid input for cursor %q, got %d", cursor, code)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+	"universum/config"
+	"universum/entity"
+)
+
+func TestMemstore_SetAddAndRemove(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	added, code := m.SAdd("tags", []string{"go", "db", "go"})
+	if added != 2 || code != entity.CRC_RECORD_UPDATED {
+		t.Fatalf("expected 2 members added, got %d, %d", added, code)
+	}
+
+	if isMember, _ := m.SIsMember("tags", "db"); !isMember {
+		t.Errorf("expected db to be a member")
+	}
+
+	if _, code := m.SIsMember("tags", "rust"); code != entity.CRC_MEMBER_NOT_FOUND {
+		t.Errorf("expected member not found, got %d", code)
+	}
+
+	if card, _ := m.SCard("tags"); card != 2 {
+		t.Errorf("expected cardinality 2, got %d", card)
+	}
+
+	removed, _ := m.SRem("tags", []string{"go", "db", "rust"})
+	if removed != 2 {
+		t.Errorf("expected 2 members removed, got %d", removed)
+	}
+
+	if exists, _ := m.Exists("tags"); exists {
+		t.Errorf("expected the emptied set to be removed")
+	}
+}
+
+func TestMemstore_SetAlgebra(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.SAdd("s1", []string{"a", "b", "c"})
+	m.SAdd("s2", []string{"b", "c", "d"})
+
+	tests := []struct {
+		name     string
+		combine  func(keys []string) ([]string, uint32)
+		keys     []string
+		expected []string
+	}{
+		{"Inter", m.SInter, []string{"s1", "s2"}, []string{"b", "c"}},
+		{"InterWithMissing", m.SInter, []string{"s1", "missing"}, []string{}},
+		{"Union", m.SUnion, []string{"s1", "s2"}, []string{"a", "b", "c", "d"}},
+		{"Diff", m.SDiff, []string{"s1", "s2"}, []string{"a"}},
+		{"DiffFromMissing", m.SDiff, []string{"missing", "s1"}, []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			members, code := test.combine(test.keys)
+			if code != entity.CRC_RECORD_FOUND || !reflect.DeepEqual(members, test.expected) {
+				t.Errorf("expected %v, got %v, %d", test.expected, members, code)
+			}
+		})
+	}
+
+	if members, _ := m.SMembers("s1"); !reflect.DeepEqual(members, []string{"a", "b", "c"}) {
+		t.Errorf("expected s1 to be left untouched, got %v", members)
+	}
+}
+
+func TestMemstore_SetWrongType(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.SAdd("set", []string{"a"})
+	m.RPush("list", []interface{}{"a"})
+
+	if _, code := m.SAdd("list", []string{"a"}); code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type for SADD on list, got %d", code)
+	}
+
+	if _, code := m.SUnion([]string{"set", "list"}); code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type for SUNION with a list, got %d", code)
+	}
+}
+
+func TestMemstore_SetSnapshotRestore(t *testing.T) {
+	SetUpMemstoreTests()
+	config.Store.Storage.Memory.SnapshotFileDirectory = t.TempDir()
+	config.Store.Storage.Memory.SnapshotCompressionAlgo = config.CompressionAlgoLZ4
+
+	m := CreateNewMemoryStore()
+	m.SAdd("tags", []string{"go", "db"})
+
+	snapshotService := &MemoryStoreSnapshotService{}
+	if _, _, err := snapshotService.Snapshot(m); err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+
+	restored := CreateNewMemoryStore()
+	if _, err := snapshotService.Restore(restored); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	members, code := restored.SMembers("tags")
+	if code != entity.CRC_RECORD_FOUND || !reflect.DeepEqual(members, []string{"db", "go"}) {
+		t.Errorf("unexpected restored set %v, %d", members, code)
+	}
+}
@@ -5,6 +5,11 @@ import "sync"
 type Shard struct {
 	id   int64
 	data *sync.Map
+
+	// writeLock serialises the writes to the shard, so that read-modify-write
+	// cycles, such as the updates of the collection types and the conditional
+	// writes, are never interleaved with other writes of the same key.
+	writeLock sync.Mutex
 }
 
 func NewShard(id int64) *Shard {
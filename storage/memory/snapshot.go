@@ -67,19 +67,16 @@ func (ms *MemoryStoreSnapshotService) Snapshot(store storage.DataStore) (int64,
 		currentShard := shards[shardIndex]
 
 		currentShard.GetData().Range(func(key interface{}, value interface{}) bool {
-			record := value.(*entity.ScalarRecord)
+			record := value.(entity.Record)
 
-			if record.Expiry < utils.GetCurrentEPochTime() {
+			if record.GetExpiry() < utils.GetCurrentEPochTime() {
 				return true // record already expired so skip
 			}
 
-			serialisedRecord, err := resp3.Encode(map[string]interface{}{
-				"Key":    key,
-				"Value":  record.Value,
-				"LAT":    record.LAT,
-				"Expiry": record.Expiry,
-				"State":  record.State,
-			})
+			recordMap := record.ToMap()
+			recordMap["Key"] = key
+
+			serialisedRecord, err := resp3.Encode(recordMap)
 
 			if err != nil {
 				logger.Get().Warn("Failed to serialise record for snapshot (skipping); ERR=%v", err)
@@ -218,7 +215,7 @@ func (ms *MemoryStoreSnapshotService) Restore(datastore storage.DataStore) (int6
 				}
 
 				lastSuccessfulOffset = currentOffset // updated last successful offset
-				key, record := (&entity.ScalarRecord{}).FromMap(decodedMap)
+				key, record := entity.RecordFromMap(decodedMap)
 
 				if record.GetExpiry() <= utils.GetCurrentEPochTime() {
 					continue
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+	"universum/config"
+	"universum/entity"
+)
+
+func TestMemstore_SortedSetOperations(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	added, code := m.ZAdd("board", map[string]float64{"alice": 30, "bob": 10, "carol": 20})
+	if added != 3 || code != entity.CRC_RECORD_UPDATED {
+		t.Fatalf("expected 3 members added, got %d, %d", added, code)
+	}
+
+	if added, _ := m.ZAdd("board", map[string]float64{"bob": 40}); added != 0 {
+		t.Errorf("expected no members added on score update, got %d", added)
+	}
+
+	expected := []entity.ScoredMember{{Member: "carol", Score: 20}, {Member: "alice", Score: 30}, {Member: "bob", Score: 40}}
+	if members, _ := m.ZRange("board", 0, -1); !reflect.DeepEqual(members, expected) {
+		t.Errorf("expected %v, got %v", expected, members)
+	}
+
+	if members, _ := m.ZRangeByScore("board", 25, 100); !reflect.DeepEqual(members, expected[1:]) {
+		t.Errorf("expected %v, got %v", expected[1:], members)
+	}
+
+	if rank, _ := m.ZRank("board", "alice"); rank != 1 {
+		t.Errorf("expected rank 1 for alice, got %d", rank)
+	}
+
+	if score, _ := m.ZIncrBy("board", "carol", 25.5); score != 45.5 {
+		t.Errorf("expected score 45.5 for carol, got %v", score)
+	}
+
+	if score, code := m.ZScore("board", "carol"); score != 45.5 || code != entity.CRC_RECORD_FOUND {
+		t.Errorf("expected score 45.5 for carol, got %v, %d", score, code)
+	}
+
+	if _, code := m.ZScore("board", "dave"); code != entity.CRC_MEMBER_NOT_FOUND {
+		t.Errorf("expected member not found, got %d", code)
+	}
+
+	if removed, _ := m.ZRem("board", []string{"alice", "dave"}); removed != 1 {
+		t.Errorf("expected 1 member removed, got %d", removed)
+	}
+
+	if card, _ := m.ZCard("board"); card != 2 {
+		t.Errorf("expected cardinality 2, got %d", card)
+	}
+}
+
+func TestMemstore_SortedSetWrongType(t *testing.T) {
+	SetUpMemstoreTests()
+	m := CreateNewMemoryStore()
+
+	m.SAdd("set", []string{"a"})
+
+	if _, code := m.ZAdd("set", map[string]float64{"a": 1}); code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type for ZADD on set, got %d", code)
+	}
+
+	if _, code := m.ZCard("set"); code != entity.CRC_WRONG_TYPE {
+		t.Errorf("expected wrong type for ZCARD on set, got %d", code)
+	}
+}
+
+func TestMemstore_SortedSetSnapshotRestore(t *testing.T) {
+	SetUpMemstoreTests()
+	config.Store.Storage.Memory.SnapshotFileDirectory = t.TempDir()
+	config.Store.Storage.Memory.SnapshotCompressionAlgo = config.CompressionAlgoLZ4
+
+	m := CreateNewMemoryStore()
+	m.ZAdd("board", map[string]float64{"alice": 0.1234567891, "bob": -3})
+
+	snapshotService := &MemoryStoreSnapshotService{}
+	if _, _, err := snapshotService.Snapshot(m); err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+
+	restored := CreateNewMemoryStore()
+	if _, err := snapshotService.Restore(restored); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	expected := []entity.ScoredMember{{Member: "bob", Score: -3}, {Member: "alice", Score: 0.1234567891}}
+	members, code := restored.ZRange("board", 0, -1)
+	if code != entity.CRC_RECORD_FOUND || !reflect.DeepEqual(members, expected) {
+		t.Errorf("unexpected restored sorted set %v, %d", members, code)
+	}
+}
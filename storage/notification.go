@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"sync/atomic"
+	"universum/config"
+)
+
+// KeyspaceEvent names the kind of change made to a key, as announced by the
+// stores to the keyspace listener.
+type KeyspaceEvent string
+
+const (
+	KeyspaceEventSet     KeyspaceEvent = KeyspaceEvent(config.KeyspaceEventSet)
+	KeyspaceEventDel     KeyspaceEvent = KeyspaceEvent(config.KeyspaceEventDel)
+	KeyspaceEventExpired KeyspaceEvent = KeyspaceEvent(config.KeyspaceEventExpired)
+	KeyspaceEventEvicted KeyspaceEvent = KeyspaceEvent(config.KeyspaceEventEvicted)
+)
+
+// KeyspaceListener receives the keyspace events from the stores. It is called
+// synchronously from the write paths, possibly with locks held, so it must be
+// quick and must not call back into the store.
+type KeyspaceListener func(event KeyspaceEvent, key string)
+
+var keyspaceListener atomic.Pointer[KeyspaceListener]
+
+// SetKeyspaceListener registers the listener for the keyspace events emitted by
+// all the stores, replacing the previous one. A nil listener turns them off.
+func SetKeyspaceListener(listener KeyspaceListener) {
+	if listener == nil {
+		keyspaceListener.Store(nil)
+		return
+	}
+
+	keyspaceListener.Store(&listener)
+}
+
+// NotifyKeyspaceEvent announces the event on the key to the registered listener,
+// and costs a single atomic load when there is none.
+func NotifyKeyspaceEvent(event KeyspaceEvent, key string) {
+	if listener := keyspaceListener.Load(); listener != nil {
+		(*listener)(event, key)
+	}
+}
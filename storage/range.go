@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"universum/entity"
+)
+
+const DefaultRangeLimit int64 = 1000
+
+// RangeCollector gathers the records of an ordered range scan, which are fed to
+// it in ascending key order. It keeps the first `limit` records, or the last ones
+// when collecting in reverse, so a reverse scan needs no backward iteration.
+type RangeCollector struct {
+	limit   int64
+	reverse bool
+	records []*entity.RecordKV
+}
+
+func NewRangeCollector(limit int64, reverse bool) *RangeCollector {
+	return &RangeCollector{
+		limit:   limit,
+		reverse: reverse,
+		records: make([]*entity.RecordKV, 0),
+	}
+}
+
+// Add collects the record, and tells if the scan should carry on. Forward scans
+// can stop as soon as the limit is reached, reverse ones have to reach the end
+// of the range.
+func (rc *RangeCollector) Add(record *entity.RecordKV) bool {
+	rc.records = append(rc.records, record)
+
+	if !rc.reverse {
+		return int64(len(rc.records)) < rc.limit
+	}
+
+	if int64(len(rc.records)) > rc.limit {
+		rc.records = rc.records[1:]
+	}
+	return true
+}
+
+// Records returns the collected records, in descending key order for reverse scans.
+func (rc *RangeCollector) Records() []*entity.RecordKV {
+	if rc.reverse {
+		for i, j := 0, len(rc.records)-1; i < j; i, j = i+1, j-1 {
+			rc.records[i], rc.records[j] = rc.records[j], rc.records[i]
+		}
+	}
+	return rc.records
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"universum/entity"
+	"universum/utils"
+)
+
+const (
+	// ScanCursorStart is the cursor which begins a new SCAN iteration, and
+	// which is handed back once the iteration is complete.
+	ScanCursorStart = "0"
+
+	DefaultScanCount int64 = 10
+)
+
+// ScanOptions filter the keys returned by a SCAN iteration.
+type ScanOptions struct {
+	Match  string // glob pattern the keys must match, empty matches all keys
+	Count  int64  // number of keys to examine in a single call
+	Family string // record family the keys must hold, empty matches all families
+}
+
+// Accepts tells if the key and its record pass the filters. Tombstoned and
+// expired records are never accepted.
+func (so *ScanOptions) Accepts(key string, record entity.Record) bool {
+	if record == nil || record.IsTombstoned() || record.IsExpired() {
+		return false
+	}
+
+	if so.Family != "" && record.GetFamily() != so.Family {
+		return false
+	}
+
+	return so.Match == "" || utils.MatchGlob(so.Match, key)
+}
+
+// NewScanCursor builds the cursor to resume a SCAN iteration right after the
+// last examined key. The segment is engine specific, eg. the memory engine
+// uses it for the shard index. Cursors are opaque to the clients.
+func NewScanCursor(segment int64, lastKey string) string {
+	return fmt.Sprintf("%d:%s", segment, lastKey)
+}
+
+// ParseScanCursor splits a cursor built by NewScanCursor into its segment and
+// last examined key. For the start cursor, resume is false.
+func ParseScanCursor(cursor string) (segment int64, lastKey string, resume bool, err error) {
+	if cursor == ScanCursorStart {
+		return 0, "", false, nil
+	}
+
+	rawSegment, lastKey, found := strings.Cut(cursor, ":")
+	if !found {
+		return 0, "", false, fmt.Errorf("invalid cursor %q", cursor)
+	}
+
+	segment, err = strconv.ParseInt(rawSegment, 10, 64)
+	if err != nil || segment < 0 {
+		return 0, "", false, fmt.Errorf("invalid cursor %q", cursor)
+	}
+
+	return segment, lastKey, true, nil
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"universum/entity"
+)
+
+func (c Collections) SAdd(key string, members []string) (int64, uint32) {
+	var added int64 = 0
+	code := c.updateSet(key, true, func(set entity.SetValue) uint32 {
+		for _, member := range members {
+			if _, ok := set[member]; !ok {
+				set[member] = struct{}{}
+				added++
+			}
+		}
+		return entity.CRC_RECORD_UPDATED
+	})
+
+	if code != entity.CRC_RECORD_UPDATED {
+		return 0, code
+	}
+
+	return added, entity.CRC_RECORD_UPDATED
+}
+
+func (c Collections) SRem(key string, members []string) (int64, uint32) {
+	var removed int64 = 0
+	code := c.updateSet(key, false, func(set entity.SetValue) uint32 {
+		for _, member := range members {
+			if _, ok := set[member]; ok {
+				delete(set, member)
+				removed++
+			}
+		}
+		return entity.CRC_RECORD_UPDATED
+	})
+
+	if code != entity.CRC_RECORD_UPDATED {
+		return 0, code
+	}
+
+	return removed, entity.CRC_RECORD_UPDATED
+}
+
+func (c Collections) SIsMember(key string, member string) (bool, uint32) {
+	record, code := c.getSet(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return false, code
+	}
+
+	if _, ok := record.Value[member]; !ok {
+		return false, entity.CRC_MEMBER_NOT_FOUND
+	}
+
+	return true, entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) SMembers(key string) ([]string, uint32) {
+	record, code := c.getSet(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return []string{}, code
+	}
+
+	return record.Value.Members(), entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) SCard(key string) (int64, uint32) {
+	record, code := c.getSet(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return 0, code
+	}
+
+	return int64(len(record.Value)), entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) SInter(keys []string) ([]string, uint32) {
+	return c.combineSets(keys, func(result entity.SetValue, other entity.SetValue) {
+		for member := range result {
+			if _, ok := other[member]; !ok {
+				delete(result, member)
+			}
+		}
+	})
+}
+
+func (c Collections) SUnion(keys []string) ([]string, uint32) {
+	return c.combineSets(keys, func(result entity.SetValue, other entity.SetValue) {
+		for member := range other {
+			result[member] = struct{}{}
+		}
+	})
+}
+
+func (c Collections) SDiff(keys []string) ([]string, uint32) {
+	return c.combineSets(keys, func(result entity.SetValue, other entity.SetValue) {
+		for member := range other {
+			delete(result, member)
+		}
+	})
+}
+
+// combineSets folds the sets stored against the keys into a copy of the first
+// one, using the given combination. Missing keys are treated as empty sets.
+func (c Collections) combineSets(keys []string, combine func(result entity.SetValue, other entity.SetValue)) ([]string, uint32) {
+	result := entity.SetValue{}
+
+	for idx, key := range keys {
+		record, code := c.getSet(key)
+		if code == entity.CRC_WRONG_TYPE {
+			return []string{}, code
+		}
+
+		other := entity.SetValue{}
+		if record != nil {
+			other = record.Value
+		}
+
+		if idx == 0 {
+			result = other.Clone()
+			continue
+		}
+
+		combine(result, other)
+	}
+
+	return result.Members(), entity.CRC_RECORD_FOUND
+}
+
+// getSet returns the live set record stored against the key, see getRecordOfFamily.
+func (c Collections) getSet(key string) (*entity.SetRecord, uint32) {
+	record, code := getRecordOfFamily(c.store, key, entity.RecordTypeSet)
+	if code != entity.CRC_RECORD_FOUND {
+		return nil, code
+	}
+
+	return record.(*entity.SetRecord), code
+}
+
+// updateSet applies the mutation to a copy of the set stored against the key.
+// A missing set is created only if asked for, and a set left without members is removed.
+func (c Collections) updateSet(key string, create bool, mutate func(entity.SetValue) uint32) uint32 {
+	return c.store.UpdateCollection(key, entity.RecordTypeSet, create, func(record entity.Record) (interface{}, uint32) {
+		set := entity.SetValue{}
+		if record != nil {
+			set = record.(*entity.SetRecord).Value.Clone()
+		}
+
+		code := mutate(set)
+		if len(set) == 0 {
+			return nil, code
+		}
+
+		return set, code
+	})
+}
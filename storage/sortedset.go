@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"universum/config"
+	"universum/dslib"
+	"universum/entity"
+)
+
+func (c Collections) ZAdd(key string, members map[string]float64) (int64, uint32) {
+	var added int64 = 0
+	code := c.updateSortedSet(key, true, func(zset entity.SortedSetValue) uint32 {
+		for member, score := range members {
+			if zset.Add(member, score) {
+				added++
+			}
+		}
+		return entity.CRC_RECORD_UPDATED
+	})
+
+	if code != entity.CRC_RECORD_UPDATED {
+		return 0, code
+	}
+
+	return added, entity.CRC_RECORD_UPDATED
+}
+
+func (c Collections) ZRem(key string, members []string) (int64, uint32) {
+	var removed int64 = 0
+	code := c.updateSortedSet(key, false, func(zset entity.SortedSetValue) uint32 {
+		for _, member := range members {
+			if zset.Remove(member) {
+				removed++
+			}
+		}
+		return entity.CRC_RECORD_UPDATED
+	})
+
+	if code != entity.CRC_RECORD_UPDATED {
+		return 0, code
+	}
+
+	return removed, entity.CRC_RECORD_UPDATED
+}
+
+func (c Collections) ZScore(key string, member string) (float64, uint32) {
+	record, code := c.getSortedSet(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return config.InvalidNumericValue, code
+	}
+
+	score, ok := record.Value.Score(member)
+	if !ok {
+		return config.InvalidNumericValue, entity.CRC_MEMBER_NOT_FOUND
+	}
+
+	return score, entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) ZIncrBy(key string, member string, increment float64) (float64, uint32) {
+	var newScore float64 = 0
+	code := c.updateSortedSet(key, true, func(zset entity.SortedSetValue) uint32 {
+		oldScore, _ := zset.Score(member)
+		newScore = oldScore + increment
+		zset.Add(member, newScore)
+		return entity.CRC_RECORD_UPDATED
+	})
+
+	if code != entity.CRC_RECORD_UPDATED {
+		return config.InvalidNumericValue, code
+	}
+
+	return newScore, entity.CRC_RECORD_UPDATED
+}
+
+func (c Collections) ZRange(key string, start int64, stop int64) ([]entity.ScoredMember, uint32) {
+	record, code := c.getSortedSet(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return []entity.ScoredMember{}, code
+	}
+
+	return record.Value.RangeByRank(start, stop), entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) ZRangeByScore(key string, min float64, max float64) ([]entity.ScoredMember, uint32) {
+	record, code := c.getSortedSet(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return []entity.ScoredMember{}, code
+	}
+
+	return record.Value.RangeByScore(min, max), entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) ZRank(key string, member string) (int64, uint32) {
+	record, code := c.getSortedSet(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return config.InvalidNumericValue, code
+	}
+
+	rank, ok := record.Value.Rank(member)
+	if !ok {
+		return config.InvalidNumericValue, entity.CRC_MEMBER_NOT_FOUND
+	}
+
+	return rank, entity.CRC_RECORD_FOUND
+}
+
+func (c Collections) ZCard(key string) (int64, uint32) {
+	record, code := c.getSortedSet(key)
+	if code != entity.CRC_RECORD_FOUND {
+		return 0, code
+	}
+
+	return record.Value.Len(), entity.CRC_RECORD_FOUND
+}
+
+// getSortedSet returns the live sorted set record stored against the key, see getRecordOfFamily.
+func (c Collections) getSortedSet(key string) (*entity.SortedSetRecord, uint32) {
+	record, code := getRecordOfFamily(c.store, key, entity.RecordTypeSortedSet)
+	if code != entity.CRC_RECORD_FOUND {
+		return nil, code
+	}
+
+	return record.(*entity.SortedSetRecord), code
+}
+
+// updateSortedSet applies the mutation to a copy of the sorted set stored against the
+// key. A missing sorted set is created only if asked for, and an emptied one is removed.
+func (c Collections) updateSortedSet(key string, create bool, mutate func(entity.SortedSetValue) uint32) uint32 {
+	return c.store.UpdateCollection(key, entity.RecordTypeSortedSet, create, func(record entity.Record) (interface{}, uint32) {
+		var zset entity.SortedSetValue = dslib.NewSortedSet()
+		if record != nil {
+			zset = record.(*entity.SortedSetRecord).Value.Clone()
+		}
+
+		code := mutate(zset)
+		if zset.Len() == 0 {
+			return nil, code
+		}
+
+		return zset, code
+	})
+}
@@ -0,0 +1,117 @@
+package utils
+
+// MatchGlob tells if the string matches the glob style pattern, following
+// the same rules as the key patterns of Redis:
+//
+//   - `*` matches any sequence of characters, including an empty one
+//   - `?` matches exactly one character
+//   - `[abc]`, `[a-z]` match one character of the set, `[^abc]` negates it
+//   - `\` escapes the next character, so it is matched literally
+//
+// Unlike path.Match, `*` also matches across `/` and `:` separators, which
+// are commonly used in key names.
+func MatchGlob(pattern string, str string) bool {
+	p, s := 0, 0
+
+	// position to resume from on a mismatch after the last `*`
+	starPattern, starString := -1, 0
+
+	for s < len(str) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				starPattern, starString = p, s
+				p++
+				continue
+
+			case '?':
+				p++
+				s++
+				continue
+
+			case '[':
+				if matched, width := matchGlobClass(pattern[p:], str[s]); width > 0 {
+					if matched {
+						p += width
+						s++
+						continue
+					}
+					break
+				}
+				// unterminated class, the bracket is matched literally
+				if str[s] == '[' {
+					p++
+					s++
+					continue
+				}
+
+			case '\\':
+				if p+1 < len(pattern) && pattern[p+1] == str[s] {
+					p += 2
+					s++
+					continue
+				}
+
+			default:
+				if pattern[p] == str[s] {
+					p++
+					s++
+					continue
+				}
+			}
+		}
+
+		if starPattern == -1 {
+			return false
+		}
+
+		// let the last `*` swallow one more character and retry
+		starString++
+		p, s = starPattern+1, starString
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+
+	return p == len(pattern)
+}
+
+// matchGlobClass matches the character against the `[...]` class at the start
+// of the pattern. It returns the match result along with the width of the class
+// in the pattern, which is 0 if the class is not terminated.
+func matchGlobClass(pattern string, char byte) (bool, int) {
+	i := 1
+	negate := false
+
+	if i < len(pattern) && (pattern[i] == '^' || pattern[i] == '!') {
+		negate = true
+		i++
+	}
+
+	matched := false
+	for first := true; i < len(pattern); first = false {
+		if pattern[i] == ']' && !first {
+			return matched != negate, i + 1
+		}
+
+		low := pattern[i]
+		if low == '\\' && i+1 < len(pattern) {
+			i++
+			low = pattern[i]
+		}
+
+		high := low
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			high = pattern[i+2]
+			i += 2
+		}
+
+		if low <= char && char <= high {
+			matched = true
+		}
+		i++
+	}
+
+	return false, 0
+}
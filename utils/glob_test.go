@@ -0,0 +1,40 @@
+package utils
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		str      string
+		expected bool
+	}{
+		{"*", "", true},
+		{"*", "anything", true},
+		{"user:*", "user:1001", true},
+		{"user:*", "session:1001", false},
+		{"user:*:name", "user:a/b:name", true},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{"h[a-c]llo", "hdllo", false},
+		{"h\\*llo", "h*llo", true},
+		{"h\\*llo", "hello", false},
+		{"*a*b*c", "xxaxxbxxc", true},
+		{"*a*b*c", "xxaxxcxxb", false},
+		{"a[", "a[", true},
+		{"exact", "exact", true},
+		{"exact", "exactly", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+"_"+test.str, func(t *testing.T) {
+			if result := MatchGlob(test.pattern, test.str); result != test.expected {
+				t.Errorf("MatchGlob(%q, %q) = %v, want %v", test.pattern, test.str, result, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	passwordHashScheme     string = "pbkdf2-sha256"
+	passwordHashIterations int    = 100000
+	passwordSaltLength     int    = 16
+)
+
+// HashPassword derives a salted hash of the password with PBKDF2-HMAC-SHA256,
+// encoded as `pbkdf2-sha256$<iterations>$<salt>$<hash>` with a fresh random salt.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	hash := pbkdf2SHA256([]byte(password), salt, passwordHashIterations)
+
+	return fmt.Sprintf("%s$%d$%s$%s", passwordHashScheme, passwordHashIterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+// IsHashedPassword tells whether the value looks like a hash made by HashPassword.
+func IsHashedPassword(value string) bool {
+	return strings.HasPrefix(value, passwordHashScheme+"$") && strings.Count(value, "$") == 3
+}
+
+// VerifyPassword tells whether the password matches the hash made by HashPassword.
+// The hashes are compared in constant time.
+func VerifyPassword(password string, encodedHash string) bool {
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 4 || parts[0] != passwordHashScheme {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	hash := pbkdf2SHA256([]byte(password), salt, iterations)
+	return subtle.ConstantTimeCompare(hash, expected) == 1
+}
+
+// pbkdf2SHA256 derives a single block (32 bytes) key with PBKDF2-HMAC-SHA256.
+func pbkdf2SHA256(password []byte, salt []byte, iterations int) []byte {
+	return pbkdf2.Key(password, salt, iterations, sha256.Size, sha256.New)
+}
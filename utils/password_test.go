@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestHashAndVerifyPassword(t *testing.T) {
+	hash, err := HashPassword("s3cret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !IsHashedPassword(hash) {
+		t.Errorf("expected %q to be recognised as a hash", hash)
+	}
+
+	if !VerifyPassword("s3cret", hash) {
+		t.Error("expected the password to match its hash")
+	}
+
+	if VerifyPassword("s3cret!", hash) {
+		t.Error("expected a different password not to match")
+	}
+
+	other, _ := HashPassword("s3cret")
+	if other == hash {
+		t.Error("expected hashes of the same password to be salted differently")
+	}
+}
+
+func TestVerifyPasswordRejectsMalformedHashes(t *testing.T) {
+	for _, hash := range []string{"", "s3cret", "pbkdf2-sha256$x$c2FsdA$aGFzaA", "md5$1$c2FsdA$aGFzaA"} {
+		if VerifyPassword("s3cret", hash) {
+			t.Errorf("expected malformed hash %q not to verify", hash)
+		}
+	}
+
+	if IsHashedPassword("s3cret") {
+		t.Error("expected a plaintext password not to be recognised as a hash")
+	}
+}
+
+func TestPBKDF2SHA256Vector(t *testing.T) {
+	// test vector for PBKDF2-HMAC-SHA256 from RFC 7914, section 11
+	key := pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1)
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"
+
+	if hex.EncodeToString(key) != expected {
+		t.Errorf("expected %s, got %s", expected, hex.EncodeToString(key))
+	}
+}
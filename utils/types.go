@@ -2,6 +2,7 @@ package utils
 
 import (
 	"reflect"
+	"universum/entity"
 )
 
 const (
@@ -57,7 +58,21 @@ func GetTypeEncoding(v interface{}) uint8 {
 	return TYPE_ENCODING_RAW
 }
 
+// IsWriteableDatatype tells if the value can be stored against a key,
+// either as a scalar or as one of the collection types.
 func IsWriteableDatatype(value interface{}) bool {
+	switch value.(type) {
+	case entity.HashValue, entity.ListValue, entity.SetValue, entity.SortedSetValue:
+		return true
+
+	default:
+		return IsWriteableScalarDatatype(value)
+	}
+}
+
+// IsWriteableScalarDatatype tells if the value can be stored as a scalar,
+// which is also what the members of the collection types are limited to.
+func IsWriteableScalarDatatype(value interface{}) bool {
 	switch value.(type) {
 	case nil:
 		// not very sure if nil should be allowed,
@@ -1,6 +1,9 @@
 package utils
 
-import "testing"
+import (
+	"testing"
+	"universum/entity"
+)
 
 func TestGetTypeEncoding(t *testing.T) {
 	tests := []struct {
@@ -45,6 +48,9 @@ func TestIsWriteableDatatype(t *testing.T) {
 		{name: "BoolType", value: true, expected: true},
 		{name: "UnsupportedType", value: struct{}{}, expected: false},
 		{name: "PointerType", value: &struct{}{}, expected: false},
+		{name: "PlainMapType", value: map[string]interface{}{"a": 1}, expected: false},
+		{name: "HashType", value: entity.HashValue{"a": 1}, expected: true},
+		{name: "SetType", value: entity.SetValue{"a": {}}, expected: true},
 	}
 
 	for _, test := range tests {